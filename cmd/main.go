@@ -2,21 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
 	"github.com/vinodhini/software-api/config"
-	"github.com/vinodhini/software-api/internal/controllers"
-	"github.com/vinodhini/software-api/internal/middleware"
-	"github.com/vinodhini/software-api/internal/repositories"
-	"github.com/vinodhini/software-api/internal/routes"
-	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/internal/app"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/migrations"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/pkg/utils"
 )
 
 // @title Vinodhini Software API
@@ -31,83 +33,50 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
-	// Initialize database
-	db, err := config.InitDB(cfg)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// "migrate up|down [n]|status" manages the MongoDB schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
 	}
 
-	// Create indexes
-	if err := config.CreateIndexes(db); err != nil {
-		log.Fatalf("Failed to create indexes: %v", err)
+	// Initialize mailer
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
 	}
 
-	// Initialize repositories
-	userRepo := repositories.NewUserRepository(db)
-	projectRepo := repositories.NewProjectRepository(db)
-	serviceRequestRepo := repositories.NewServiceRequestRepository(db)
-	messageRepo := repositories.NewMessageRepository(db)
-	counterRepo := repositories.NewCounterRepository(db)
-	serviceTypeRepo := repositories.NewServiceTypeRepository(db)
-	employeeRepo := repositories.NewEmployeeRepository(db)
-
-	// Initialize services
-	authService := services.NewAuthService(userRepo, cfg)
-	userService := services.NewUserService(userRepo, projectRepo)
-	clientService := services.NewClientService(userRepo)
-	projectService := services.NewProjectService(projectRepo, counterRepo)
-	serviceRequestService := services.NewServiceRequestService(serviceRequestRepo, projectRepo, counterRepo)
-	messageService := services.NewMessageService(messageRepo, counterRepo, projectRepo)
-	serviceTypeService := services.NewServiceTypeService(serviceTypeRepo)
-	employeeService := services.NewEmployeeService(employeeRepo, userRepo)
-
-	// Initialize controllers
-	authController := controllers.NewAuthController(authService)
-	userController := controllers.NewUserController(userService)
-	clientController := controllers.NewClientController(clientService)
-	projectController := controllers.NewProjectController(projectService)
-	serviceRequestController := controllers.NewServiceRequestController(serviceRequestService)
-	messageController := controllers.NewMessageController(messageService)
-	serviceTypeController := controllers.NewServiceTypeController(serviceTypeService)
-	employeeController := controllers.NewEmployeeController(employeeService)
-
-	// Setup Gin
-	if cfg.Server.Env == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	// Load JWT signing keys
+	keys, err := loadSigningKeys(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
 	}
 
-	router := gin.New()
-	router.Use(gin.Recovery())
-	router.Use(middleware.LoggerMiddleware())
-	router.Use(middleware.ErrorHandler())
-	router.Use(middleware.SecurityHeadersMiddleware())
-	router.Use(middleware.RateLimitMiddleware(cfg.RateLimit.Limit, cfg.RateLimit.Window))
-
-	// CORS configuration
-	corsConfig := cors.Config{
-		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "User-Agent", "Referer", "sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: false,
-		MaxAge:           12 * time.Hour,
+	// Load the access policy
+	policyEngine, err := policy.Load(cfg.Auth.PolicyFile)
+	if err != nil {
+		log.Fatalf("Failed to load access policy: %v", err)
 	}
-	router.Use(cors.New(corsConfig))
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	// Initialize repositories
+	repos, err := app.OpenRepositories(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", cfg.Storage.Backend, err)
+	}
 
-	// Setup routes
-	routes.SetupRoutes(router, cfg, authController, userController, projectController, serviceRequestController, messageController, clientController, serviceTypeController, employeeController)
+	api := app.New(cfg, repos, mail, keys, policyEngine)
 
 	// Server setup
 	srv := &http.Server{
 		Addr:    cfg.Server.Host + ":" + cfg.Server.Port,
-		Handler: router,
+		Handler: api.Router,
 	}
 
+	// Purge soft-deleted records once their retention period has passed
+	retentionCtx, stopRetention := context.WithCancel(context.Background())
+	go api.Retention.Run(retentionCtx)
+
 	// Graceful shutdown
 	go func() {
 		log.Printf("Server starting on %s:%s", cfg.Server.Host, cfg.Server.Port)
@@ -122,6 +91,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopRetention()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -132,3 +102,84 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// runMigrate runs the migrate subcommand on the MongoDB database: up applies
+// every pending migration, down reverts the latest n (1 by default) and
+// status lists them all.
+func runMigrate(cfg *config.Config, args []string) error {
+	if cfg.Storage.Backend != config.StorageMongo {
+		return fmt.Errorf("migrate manages MongoDB, but STORAGE_BACKEND is %s", cfg.Storage.Backend)
+	}
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [n]|status")
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Client().Disconnect(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.MigrationTimeout)
+	defer cancel()
+	migrator := migrations.New(db)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		app.LogMigrations("Applied", applied)
+		if err == nil && len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+		return err
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, n)
+		app.LogMigrations("Reverted", reverted)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown migrate command %q; use up, down [n] or status", args[0])
+}
+
+// loadSigningKeys uses the PEM keys in JWT_KEYS_DIR when configured and falls
+// back to HS256 with JWT_SECRET otherwise. The built-in default secret is
+// refused in production.
+func loadSigningKeys(cfg *config.Config) (*utils.KeySet, error) {
+	if cfg.JWT.KeysDir != "" {
+		keys, err := utils.LoadKeySet(cfg.JWT.KeysDir, cfg.JWT.ActiveKeyID)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Signing tokens with key %q", keys.ActiveKeyID())
+		return keys, nil
+	}
+
+	if cfg.JWT.Secret == "your-secret-key" {
+		if cfg.Server.Env == "production" {
+			return nil, errors.New("JWT_SECRET is not set; configure JWT_SECRET or JWT_KEYS_DIR")
+		}
+		log.Println("WARNING: signing tokens with the default JWT_SECRET; set JWT_KEYS_DIR or JWT_SECRET before deploying")
+	}
+
+	return utils.NewHMACKeySet(cfg.JWT.Secret), nil
+}
@@ -0,0 +1,32 @@
+// Command openapi-gen writes the OpenAPI document of the API. It is run by
+// go generate in internal/openapi after routes or request types change.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/vinodhini/software-api/internal/openapi"
+)
+
+func main() {
+	root := flag.String("root", ".", "repository root")
+	out := flag.String("out", "internal/openapi/openapi.json", "output file")
+	flag.Parse()
+
+	doc, err := openapi.Generate(*root)
+	if err != nil {
+		log.Fatalf("Failed to generate OpenAPI document: %v", err)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+}
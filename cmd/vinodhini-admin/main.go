@@ -0,0 +1,335 @@
+// Command vinodhini-admin bootstraps and operates the API from the command
+// line. It reads the same settings as the server and works through the same
+// services, so its changes are validated and audited like those made over
+// HTTP.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/app"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/term"
+)
+
+const usage = `Usage: vinodhini-admin <command> [flags] [arguments]
+
+Commands:
+  create-admin -email <email> -name <name> [-password <password>]
+  reset-password [-password <password>] <user-id|email>
+  activate <user-id|email>
+  deactivate <user-id|email>
+  list-projects [-search <text>] [-status <status>] [-page <n>] [-page-size <n>] [-deleted]
+  reseed-counters
+  export [-o <file>]
+  import [-i <file>]
+
+Passwords that are not given as flags are prompted for without echo, or
+read from the first line of standard input when it is not a terminal.
+Avoid -password: the password ends up in the shell history and is visible
+to other users in the process list.
+Exports go to standard output and imports are read from standard input
+unless a file is given.
+`
+
+// env is what the commands work with.
+type env struct {
+	repos    *repositories.Repositories
+	services *app.Services
+	actor    models.Actor
+}
+
+type command func(e *env, args []string) error
+
+var commands = map[string]command{
+	"create-admin":    createAdmin,
+	"reset-password":  resetPassword,
+	"activate":        setStatus(models.UserStatusActive),
+	"deactivate":      setStatus(models.UserStatusInactive),
+	"list-projects":   listProjects,
+	"reseed-counters": reseedCounters,
+	"export":          exportData,
+	"import":          importData,
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	e, err := open(config.Load())
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	if err := run(e, os.Args[2:]); err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+// open builds the services over the configured storage backend.
+func open(cfg *config.Config) (*env, error) {
+	if cfg.Storage.Backend == config.StorageMemory {
+		return nil, errors.New("the memory backend keeps nothing between runs; set STORAGE_BACKEND to mongo or postgres")
+	}
+
+	repos, err := app.OpenRepositories(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	policyEngine, err := policy.Load(cfg.Auth.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Changes are recorded in the audit log as made by the operating system
+	// user, with the rights of an admin
+	actorID := "cli"
+	if u, err := user.Current(); err == nil {
+		actorID += ":" + u.Username
+	}
+
+	return &env{
+		repos: repos,
+		// The CLI issues no tokens, so the signing keys are never used
+		services: app.NewServices(cfg, repos, mail, utils.NewHMACKeySet(cfg.JWT.Secret), policyEngine),
+		actor:    models.Actor{ID: actorID, Role: string(models.RoleAdmin)},
+	}, nil
+}
+
+func createAdmin(e *env, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := flags.String("email", "", "email address")
+	name := flags.String("name", "", "display name")
+	password := flags.String("password", "", "password, visible in the shell history and process list (prompted for if empty)")
+	flags.Parse(args)
+
+	req := &models.CreateAdminRequest{Email: *email, Name: *name, Password: *password}
+	if req.Password == "" {
+		var err error
+		if req.Password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	admin, err := e.services.Auth.CreateAdmin(req, e.actor)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created admin %s <%s>\n", admin.UserID, admin.Email)
+	return nil
+}
+
+func resetPassword(e *env, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	password := flags.String("password", "", "new password, visible in the shell history and process list (prompted for if empty)")
+	flags.Parse(args)
+
+	target, err := findUser(e, flags.Args())
+	if err != nil {
+		return err
+	}
+	if *password == "" {
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+	if len(*password) < 6 {
+		return errors.New("password must be at least 6 characters")
+	}
+
+	// The update also signs the user out everywhere
+	if _, err := e.services.Users.Update(target.UserID, &models.UpdateUserRequest{Password: *password}, services.MatchVersion(target.Version), e.actor); err != nil {
+		return err
+	}
+	fmt.Printf("Reset the password of %s <%s>\n", target.UserID, target.Email)
+	return nil
+}
+
+func setStatus(status string) command {
+	return func(e *env, args []string) error {
+		target, err := findUser(e, args)
+		if err != nil {
+			return err
+		}
+		updated, err := e.services.Users.Update(target.UserID, &models.UpdateUserRequest{Status: status}, services.MatchVersion(target.Version), e.actor)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s <%s> is now %s\n", updated.UserID, updated.Email, updated.Status)
+		return nil
+	}
+}
+
+// findUser looks up the user named by the only argument, an ID or an email
+// address.
+func findUser(e *env, args []string) (*models.User, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected one user ID or email address")
+	}
+	if strings.Contains(args[0], "@") {
+		found, err := e.repos.Users.FindByEmail(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("no user with email %s", args[0])
+		}
+		return found, nil
+	}
+	return e.services.Users.GetByID(args[0], e.actor.ID, e.actor.Role)
+}
+
+func listProjects(e *env, args []string) error {
+	flags := flag.NewFlagSet("list-projects", flag.ExitOnError)
+	query := &models.PaginationQuery{}
+	flags.StringVar(&query.Search, "search", "", "match name, description or ID")
+	flags.StringVar(&query.Status, "status", "", "active, pending, completed or rejected")
+	flags.IntVar(&query.Page, "page", 1, "page number")
+	flags.IntVar(&query.PageSize, "page-size", 50, "projects per page")
+	flags.BoolVar(&query.IncludeDeleted, "deleted", false, "include deleted projects")
+	flags.Parse(args)
+
+	projects, total, err := e.services.Projects.List(query, e.actor.ID, e.actor.Role)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCLIENT\tSTATUS\tPROGRESS\tDELETED")
+	for _, project := range projects {
+		client := project.ClientID
+		if project.Client != nil {
+			client = fmt.Sprintf("%s (%s)", project.Client.Name, project.ClientID)
+		}
+		deleted := ""
+		if project.DeletedAt != nil {
+			deleted = project.DeletedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d%%\t%s\n", project.ID, project.Name, client, project.Status, project.Progress, deleted)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d of %d project(s)\n", len(projects), total)
+	return nil
+}
+
+func reseedCounters(e *env, args []string) error {
+	highest, err := e.services.Data.SeedCounters()
+	if err != nil {
+		return err
+	}
+	for _, counter := range []string{repositories.UserCounter, repositories.ProjectCounter, repositories.ServiceRequestCounter, repositories.MessageCounter} {
+		fmt.Printf("%s: at least %d\n", counter, highest[counter])
+	}
+	return nil
+}
+
+func exportData(e *env, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "output file (standard output if empty)")
+	flags.Parse(args)
+
+	data, err := e.services.Data.Export()
+	if err != nil {
+		return err
+	}
+	raw, err := bson.MarshalExtJSONIndent(data, false, false, "", "  ")
+	if err != nil {
+		return err
+	}
+	raw = append(raw, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(raw)
+	} else {
+		err = os.WriteFile(*output, raw, 0600)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported %s", counts(data))
+	return nil
+}
+
+func importData(e *env, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	input := flags.String("i", "", "input file (standard input if empty)")
+	flags.Parse(args)
+
+	var raw []byte
+	var err error
+	if *input == "" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(*input)
+	}
+	if err != nil {
+		return err
+	}
+
+	var data models.DataExport
+	if err := bson.UnmarshalExtJSON(raw, false, &data); err != nil {
+		return fmt.Errorf("invalid export: %w", err)
+	}
+	if err := e.services.Data.Import(&data, e.actor); err != nil {
+		return err
+	}
+	log.Printf("Imported %s", counts(&data))
+	return nil
+}
+
+func counts(data *models.DataExport) string {
+	return fmt.Sprintf("%d user(s), %d project(s), %d service request(s), %d message(s) and %d service type(s)",
+		len(data.Users), len(data.Projects), len(data.ServiceRequests), len(data.Messages), len(data.ServiceTypes))
+}
+
+// readPassword prompts for a password without echoing it. When standard input
+// is not a terminal it reads the first line instead, so a password can be
+// piped in.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
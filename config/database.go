@@ -2,10 +2,10 @@ package config
 
 import (
 	"context"
+	"database/sql"
 	"log"
-	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	_ "github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -29,29 +29,22 @@ func InitDB(cfg *Config) (*mongo.Database, error) {
 	return client.Database(cfg.MongoDB.Database), nil
 }
 
-func CreateIndexes(db *mongo.Database) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	_, err := db.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{Key: "email", Value: 1}},
-		Options: options.Index().SetUnique(true),
-	})
+// InitPostgres opens the PostgreSQL database and checks that it answers. The
+// schema is created by the postgres repositories' Migrate.
+func InitPostgres(cfg *Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.Postgres.DSN)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = db.Collection("projects").Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
-	})
-	if err != nil {
-		return err
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Postgres.Timeout)
+	defer cancel()
 
-	_, err = db.Collection("service_requests").Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
-	})
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	log.Println("Indexes created successfully")
-	return err
+	log.Println("PostgreSQL connected successfully")
+	return db, nil
 }
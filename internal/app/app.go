@@ -0,0 +1,160 @@
+// Package app wires repositories into the services, controllers and routes
+// of the API. The server and the HTTP tests build the API the same way,
+// whatever storage backend holds the repositories.
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/controllers"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/middleware"
+	"github.com/vinodhini/software-api/internal/oidc"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/routes"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// App is the API assembled over one set of repositories.
+type App struct {
+	// Router serves every route, with the middleware of the server
+	Router *gin.Engine
+	// Retention purges expired soft-deleted records; run it in the background
+	Retention services.RetentionService
+}
+
+// Services are the services of the API, built over one set of repositories.
+// The admin CLI uses them without the HTTP layer.
+type Services struct {
+	IDs             services.IDGenerator
+	Audit           services.AuditService
+	Lockout         services.LockoutService
+	Auth            services.AuthService
+	Integrity       services.IntegrityService
+	Users           services.UserService
+	Clients         services.ClientService
+	Projects        services.ProjectService
+	ServiceRequests services.ServiceRequestService
+	Messages        services.MessageService
+	ServiceTypes    services.ServiceTypeService
+	Employees       services.EmployeeService
+	Passwords       services.PasswordService
+	Invitations     services.InvitationService
+	Registrations   services.RegistrationService
+	TwoFactor       services.TwoFactorService
+	APIKeys         services.APIKeyService
+	Retention       services.RetentionService
+	Data            services.DataService
+}
+
+// NewServices builds every service. keys signs session tokens and
+// policyEngine decides who may do what.
+func NewServices(cfg *config.Config, repos *repositories.Repositories, mail mailer.Mailer, keys *utils.KeySet, policyEngine *policy.Engine) *Services {
+	idGenerator := services.NewIDGenerator(repos.Counters, cfg)
+	auditService := services.NewAuditService(repos.AuditEvents)
+	lockoutService := services.NewLockoutService(repos.LoginAttempts, repos.LockoutEvents, cfg)
+	integrityService := services.NewIntegrityService(repos.Users, repos.Projects, repos.ServiceRequests, repos.Messages, cfg, auditService)
+	return &Services{
+		IDs:             idGenerator,
+		Audit:           auditService,
+		Lockout:         lockoutService,
+		Auth:            services.NewAuthService(repos.Users, idGenerator, repos.Sessions, repos.UserTokens, lockoutService, mail, keys, cfg, auditService),
+		Integrity:       integrityService,
+		Users:           services.NewUserService(repos.Users, repos.Projects, repos.Sessions, policyEngine, integrityService, auditService),
+		Clients:         services.NewClientService(repos.Users, idGenerator, repos.Sessions, policyEngine, integrityService, auditService),
+		Projects:        services.NewProjectService(repos.Projects, idGenerator, policyEngine, integrityService, auditService),
+		ServiceRequests: services.NewServiceRequestService(repos.ServiceRequests, idGenerator, repos.UnitOfWork, policyEngine, integrityService, auditService),
+		Messages:        services.NewMessageService(repos.Messages, idGenerator, repos.Projects, policyEngine, auditService),
+		ServiceTypes:    services.NewServiceTypeService(repos.ServiceTypes, auditService),
+		Employees:       services.NewEmployeeService(repos.Employees, repos.Users, idGenerator, auditService),
+		Passwords:       services.NewPasswordService(repos.Users, repos.UserTokens, repos.Sessions, mail, cfg, auditService),
+		Invitations:     services.NewInvitationService(repos.Invitations, repos.Users, idGenerator, mail, cfg, auditService),
+		Registrations:   services.NewRegistrationService(repos.Users, mail, cfg, auditService),
+		TwoFactor:       services.NewTwoFactorService(repos.Users, lockoutService, cfg, auditService),
+		APIKeys:         services.NewAPIKeyService(repos.APIKeys, repos.Users, policyEngine, cfg, auditService),
+		Retention:       services.NewRetentionService(repos.Users, repos.Projects, repos.ServiceRequests, repos.Messages, cfg),
+		Data:            services.NewDataService(repos.Users, repos.Projects, repos.ServiceRequests, repos.Messages, repos.ServiceTypes, repos.Counters, auditService),
+	}
+}
+
+// New builds the API. keys signs session tokens and policyEngine decides who
+// may do what.
+func New(cfg *config.Config, repos *repositories.Repositories, mail mailer.Mailer, keys *utils.KeySet, policyEngine *policy.Engine) *App {
+	svc := NewServices(cfg, repos, mail, keys, policyEngine)
+
+	// Single sign-on stays disabled unless an issuer is configured
+	var oidcClient *oidc.Client
+	if cfg.OIDC.IssuerURL != "" {
+		oidcClient = oidc.NewClient(oidc.Config{
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		}, nil)
+	}
+	oidcService := services.NewOIDCService(oidcClient, repos.OIDCStates, repos.Users, svc.IDs, svc.Auth, policyEngine, cfg, svc.Audit)
+
+	// Initialize controllers
+	authController := controllers.NewAuthController(svc.Auth)
+	userController := controllers.NewUserController(svc.Users)
+	clientController := controllers.NewClientController(svc.Clients)
+	projectController := controllers.NewProjectController(svc.Projects)
+	serviceRequestController := controllers.NewServiceRequestController(svc.ServiceRequests)
+	messageController := controllers.NewMessageController(svc.Messages)
+	serviceTypeController := controllers.NewServiceTypeController(svc.ServiceTypes)
+	employeeController := controllers.NewEmployeeController(svc.Employees)
+	passwordController := controllers.NewPasswordController(svc.Passwords)
+	invitationController := controllers.NewInvitationController(svc.Invitations)
+	registrationController := controllers.NewRegistrationController(svc.Registrations)
+	twoFactorController := controllers.NewTwoFactorController(svc.TwoFactor)
+	lockoutController := controllers.NewLockoutController(svc.Lockout)
+	jwksController := controllers.NewJWKSController(keys)
+	apiKeyController := controllers.NewAPIKeyController(svc.APIKeys)
+	oidcController := controllers.NewOIDCController(oidcService)
+	docsController := controllers.NewDocsController()
+	auditController := controllers.NewAuditController(svc.Audit)
+
+	// Setup Gin
+	if cfg.Server.Env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.SecurityHeadersMiddleware())
+	router.Use(middleware.RateLimitMiddleware(cfg.RateLimit.Limit, cfg.RateLimit.Window))
+
+	// CORS configuration
+	corsConfig := cors.Config{
+		AllowAllOrigins:  true,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", middleware.RequestIDHeader, "If-Match", "User-Agent", "Referer", "sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform"},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader, "ETag"},
+		AllowCredentials: false,
+		MaxAge:           12 * time.Hour,
+	}
+	router.Use(cors.New(corsConfig))
+
+	// Health check
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	// Setup routes
+	routes.SetupRoutes(router, cfg, authController, userController, projectController, serviceRequestController, messageController, clientController, serviceTypeController, employeeController, passwordController, invitationController, registrationController, twoFactorController, lockoutController, jwksController, apiKeyController, oidcController, docsController, auditController, middleware.AuthMiddleware(keys, repos.Sessions, repos.Users, svc.APIKeys), policyEngine)
+
+	return &App{
+		Router:    router,
+		Retention: svc.Retention,
+	}
+}
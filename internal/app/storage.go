@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/migrations"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/repositories/memory"
+	"github.com/vinodhini/software-api/internal/repositories/postgres"
+)
+
+// MigrationTimeout bounds a migration run, including the wait for another
+// instance that is migrating.
+const MigrationTimeout = 10 * time.Minute
+
+// OpenRepositories returns the repositories of the configured storage
+// backend. Databases are brought up to date first by applying their pending
+// migrations.
+func OpenRepositories(cfg *config.Config) (*repositories.Repositories, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		log.Println("WARNING: using in-memory storage; nothing is kept across restarts")
+		return memory.NewRepositories(), nil
+	case config.StoragePostgres:
+		db, err := config.InitPostgres(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := postgres.Migrate(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		return postgres.NewRepositories(db), nil
+	}
+
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), MigrationTimeout)
+	defer cancel()
+	applied, err := migrations.New(db).Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	LogMigrations("Applied", applied)
+	return repositories.NewMongoRepositories(db, cfg.MongoDB.AllowNonTransactional)
+}
+
+// LogMigrations logs each migration that was applied or reverted.
+func LogMigrations(action string, steps []migrations.Migration) {
+	for _, step := range steps {
+		log.Printf("%s migration %d %s", action, step.Version, step.Name)
+	}
+}
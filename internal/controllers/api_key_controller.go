@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService services.APIKeyService) *APIKeyController {
+	return &APIKeyController{apiKeyService: apiKeyService}
+}
+
+// @Summary Create a personal API key
+// @Tags api-keys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAPIKeyRequest true "Create API Key Request"
+// @Success 201 {object} utils.Response
+// @Router /api/api-keys [post]
+func (c *APIKeyController) Create(ctx *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	response, err := c.apiKeyService.Create(&req, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "API key created. Copy the key now, it will not be shown again", response)
+}
+
+// @Summary List the current user's API keys
+// @Tags api-keys
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/api-keys [get]
+func (c *APIKeyController) List(ctx *gin.Context) {
+	keys, err := c.apiKeyService.List(ctx.GetString("user_id"))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "API keys retrieved successfully", keys)
+}
+
+// @Summary Revoke an API key
+// @Tags api-keys
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "API Key ID"
+// @Success 200 {object} utils.Response
+// @Router /api/api-keys/{id} [delete]
+func (c *APIKeyController) Revoke(ctx *gin.Context) {
+	if err := c.apiKeyService.Revoke(ctx.Param("id"), actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "API key revoked successfully", nil)
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type AuditController struct {
+	auditService services.AuditService
+}
+
+func NewAuditController(auditService services.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+// @Summary List audit events
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param actor_id query string false "Filter by actor user ID"
+// @Param action query string false "Filter by action (create, update, delete, ...)"
+// @Param resource_type query string false "Filter by resource type (project, service_request, user, ...)"
+// @Param resource_id query string false "Filter by resource ID"
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events before this RFC 3339 time"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/audit [get]
+func (c *AuditController) List(ctx *gin.Context) {
+	var query models.AuditQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 10
+	}
+
+	events, total, err := c.auditService.List(&query)
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	totalPages := int(total) / query.PageSize
+	if int(total)%query.PageSize != 0 {
+		totalPages++
+	}
+
+	pagination := utils.Pagination{
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		Total:     total,
+		TotalPage: totalPages,
+	}
+
+	utils.PaginatedSuccessResponse(ctx, http.StatusOK, events, pagination)
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -28,17 +30,17 @@ func NewAuthController(authService services.AuthService) *AuthController {
 func (c *AuthController) Register(ctx *gin.Context) {
 	var req models.RegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	user, err := c.authService.Register(&req)
+	user, err := c.authService.Register(&req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	utils.SuccessResponse(ctx, http.StatusCreated, "User registered successfully", user)
+	utils.SuccessResponse(ctx, http.StatusCreated, "User registered successfully. Please check your email to verify your account", user)
 }
 
 // @Summary Login user
@@ -51,20 +53,191 @@ func (c *AuthController) Register(ctx *gin.Context) {
 func (c *AuthController) Login(ctx *gin.Context) {
 	var req models.LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	response, err := c.authService.Login(&req)
+	response, err := c.authService.Login(&req, clientInfo(ctx))
 	if err != nil {
-		// Check if it's an inactive user error and return 403 Forbidden
-		if strings.Contains(strings.ToLower(err.Error()), "inactive") {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusUnauthorized, err.Error())
+		if loginBlocked(ctx, err) {
+			return
 		}
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	if response.ChallengeToken != "" {
+		utils.SuccessResponse(ctx, http.StatusOK, "Two-factor authentication required", response)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Login successful", response)
+}
+
+// @Summary Complete a login with a two-factor code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorLoginRequest true "Two-Factor Login Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/login/2fa [post]
+func (c *AuthController) LoginTwoFactor(ctx *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	response, err := c.authService.LoginTwoFactor(&req, clientInfo(ctx))
+	if err != nil {
+		if loginBlocked(ctx, err) {
+			return
+		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Login successful", response)
 }
+
+// @Summary Start mandatory two-factor enrolment during login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorChallengeRequest true "Two-Factor Challenge Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/login/2fa/setup [post]
+func (c *AuthController) LoginTwoFactorSetup(ctx *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	setup, err := c.authService.LoginTwoFactorSetup(&req)
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Scan the secret with your authenticator app and confirm a code to finish logging in", setup)
+}
+
+// @Summary Refresh access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshTokenRequest true "Refresh Token Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/refresh [post]
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	response, err := c.authService.Refresh(&req, clientInfo(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Token refreshed successfully", response)
+}
+
+// @Summary Logout the current session
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/auth/logout [post]
+func (c *AuthController) Logout(ctx *gin.Context) {
+	sessionID := ctx.GetString("session_id")
+
+	if err := c.authService.Logout(sessionID); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Logged out successfully", nil)
+}
+
+// @Summary Verify email address
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyEmailRequest true "Verify Email Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/verify-email [post]
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if err := c.authService.VerifyEmail(&req); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Email verified successfully", nil)
+}
+
+// @Summary Resend the email verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResendVerificationRequest true "Resend Verification Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/resend-verification [post]
+func (c *AuthController) ResendVerification(ctx *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if err := c.authService.ResendVerification(&req); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "If the account is awaiting verification, a new link has been sent", nil)
+}
+
+// loginBlocked writes the response for a throttled or locked login and reports
+// whether err was such an error.
+func loginBlocked(ctx *gin.Context, err error) bool {
+	var blocked *services.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	if blocked.Locked {
+		utils.ErrorResponseWithCode(ctx, http.StatusLocked, "ACCOUNT_LOCKED", blocked.Error())
+	} else {
+		utils.ErrorResponseWithCode(ctx, http.StatusTooManyRequests, "LOGIN_THROTTLED", blocked.Error())
+	}
+	return true
+}
+
+func clientInfo(ctx *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+// actor identifies the caller for the audit log. On public routes only the
+// request details are set.
+func actor(ctx *gin.Context) models.Actor {
+	return models.Actor{
+		ID:        ctx.GetString("user_id"),
+		Role:      ctx.GetString("user_role"),
+		IP:        ctx.ClientIP(),
+		RequestID: ctx.GetString("request_id"),
+	}
+}
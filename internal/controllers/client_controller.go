@@ -1,12 +1,11 @@
 package controllers
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -20,18 +19,6 @@ func NewClientController(clientService services.ClientService) *ClientController
 	}
 }
 
-type ClientResponse struct {
-	ID       string `json:"user_id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Company  string `json:"company"`
-	Address  string `json:"address"`
-	Role     string `json:"role"`
-	Status   string `json:"status"`
-	Hide     bool   `json:"hide"`
-}
-
 // @Summary Create client
 // @Tags clients
 // @Security BearerAuth
@@ -43,7 +30,7 @@ type ClientResponse struct {
 func (c *ClientController) Create(ctx *gin.Context) {
 	var req models.CreateClientRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+		utils.BindError(ctx, err)
 		return
 	}
 
@@ -52,13 +39,13 @@ func (c *ClientController) Create(ctx *gin.Context) {
 		req.Role = "client"
 	}
 
-	client, err := c.clientService.Create(&req)
+	client, err := c.clientService.Create(&req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to create client")
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	response := ClientResponse{
+	response := models.ClientResponse{
 		ID:      client.UserID,
 		Name:    client.Name,
 		Email:   client.Email,
@@ -68,6 +55,7 @@ func (c *ClientController) Create(ctx *gin.Context) {
 		Role:    string(client.Role),
 		Status:  client.Status,
 		Hide:    client.Hide,
+		Version: client.Version,
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "Client created successfully", response)
@@ -80,32 +68,35 @@ func (c *ClientController) Create(ctx *gin.Context) {
 // @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
 // @Param search query string false "Search term"
+// @Param include_deleted query bool false "Include deleted records (requires the restore permission)"
 // @Success 200 {object} utils.PaginatedResponse
 // @Router /api/clients [get]
 func (c *ClientController) List(ctx *gin.Context) {
 	query := &models.PaginationQuery{}
 	if err := ctx.ShouldBindQuery(query); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters")
+		utils.BindError(ctx, err)
 		return
 	}
 
 	clients, total, err := c.clientService.List(query)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch clients")
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	var response []ClientResponse
+	var response []models.ClientResponse
 	for _, client := range clients {
-		response = append(response, ClientResponse{
-			ID:      client.UserID,
-			Name:    client.Name,
-			Email:   client.Email,
-			Phone:   client.Phone,
-			Company: client.Company,
-			Address: client.Address,
-			Role:    string(client.Role),
-			Status:  client.Status,
+		response = append(response, models.ClientResponse{
+			ID:        client.UserID,
+			Name:      client.Name,
+			Email:     client.Email,
+			Phone:     client.Phone,
+			Company:   client.Company,
+			Address:   client.Address,
+			Role:      string(client.Role),
+			Status:    client.Status,
+			Version:   client.Version,
+			DeletedAt: client.DeletedAt,
 		})
 	}
 
@@ -122,7 +113,7 @@ func (c *ClientController) List(ctx *gin.Context) {
 // @Tags clients
 // @Security BearerAuth
 // @Produce json
-// @Param id path int true "Client ID"
+// @Param id path string true "Client ID"
 // @Success 200 {object} utils.Response
 // @Router /api/clients/{id} [get]
 func (c *ClientController) GetByID(ctx *gin.Context) {
@@ -134,11 +125,11 @@ func (c *ClientController) GetByID(ctx *gin.Context) {
 
 	client, err := c.clientService.GetByID(id)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusNotFound, "Client not found")
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	response := ClientResponse{
+	response := models.ClientResponse{
 		ID:      client.UserID,
 		Name:    client.Name,
 		Email:   client.Email,
@@ -148,8 +139,10 @@ func (c *ClientController) GetByID(ctx *gin.Context) {
 		Role:    string(client.Role),
 		Status:  client.Status,
 		Hide:    client.Hide,
+		Version: client.Version,
 	}
 
+	setETag(ctx, client.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Client retrieved successfully", response)
 }
 
@@ -158,31 +151,31 @@ func (c *ClientController) GetByID(ctx *gin.Context) {
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param id path int true "Client ID"
+// @Param id path string true "Client ID"
+// @Param If-Match header string true "ETag of the version being updated"
 // @Param request body models.UpdateUserRequest true "Update Client Request"
 // @Success 200 {object} utils.Response
 // @Router /api/clients/{id} [put]
 func (c *ClientController) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
-	fmt.Printf("Update request for client ID: %s\n", id)
+	match, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		fmt.Printf("JSON binding error: %v\n", err)
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	fmt.Printf("Update request payload: %+v\n", req)
-
-	client, err := c.clientService.Update(id, &req)
+	client, err := c.clientService.Update(id, &req, match, actor(ctx))
 	if err != nil {
-		fmt.Printf("Service update error: %v\n", err)
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to update client: "+err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	response := ClientResponse{
+	response := models.ClientResponse{
 		ID:      client.UserID,
 		Name:    client.Name,
 		Email:   client.Email,
@@ -192,8 +185,10 @@ func (c *ClientController) Update(ctx *gin.Context) {
 		Role:    string(client.Role),
 		Status:  client.Status,
 		Hide:    client.Hide,
+		Version: client.Version,
 	}
 
+	setETag(ctx, client.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Client updated successfully", response)
 }
 
@@ -201,7 +196,7 @@ func (c *ClientController) Update(ctx *gin.Context) {
 // @Tags clients
 // @Security BearerAuth
 // @Produce json
-// @Param id path int true "Client ID"
+// @Param id path string true "Client ID"
 // @Success 200 {object} utils.Response
 // @Router /api/clients/{id} [delete]
 func (c *ClientController) Delete(ctx *gin.Context) {
@@ -211,8 +206,8 @@ func (c *ClientController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.clientService.Delete(id); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to delete client")
+	if err := c.clientService.Delete(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/openapi"
+)
+
+// docsCSP relaxes the default policy so the docs page can load Swagger UI from
+// its CDN.
+const docsCSP = "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' https://unpkg.com; img-src 'self' data:"
+
+type DocsController struct{}
+
+func NewDocsController() *DocsController {
+	return &DocsController{}
+}
+
+// @Summary OpenAPI specification of this API
+// @Tags docs
+// @Produce json
+// @Success 200 "OpenAPI 3 document"
+// @Router /api/openapi.json [get]
+func (c *DocsController) Spec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", openapi.JSON())
+}
+
+// @Summary Interactive API documentation
+// @Tags docs
+// @Produce html
+// @Success 200 "Swagger UI page"
+// @Router /api/docs [get]
+func (c *DocsController) Page(ctx *gin.Context) {
+	ctx.Header("Content-Security-Policy", docsCSP)
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage())
+}
+
+// @Summary Script of the documentation page
+// @Tags docs
+// @Produce plain
+// @Success 200 "JavaScript"
+// @Router /api/docs/init.js [get]
+func (c *DocsController) Script(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/javascript; charset=utf-8", openapi.DocsScript())
+}
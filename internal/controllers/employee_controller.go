@@ -4,8 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -19,21 +19,18 @@ func NewEmployeeController(employeeService services.EmployeeService) *EmployeeCo
 	}
 }
 
-type EmployeeResponse struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	Email      string `json:"email"`
-	Role       string `json:"role"`
-	Status     string `json:"status"`
-	Phone      string `json:"phone,omitempty"`
-	Department string `json:"department,omitempty"`
-	Salary     int    `json:"salary,omitempty"`
-}
-
+// @Summary Create employee
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateEmployeeRequest true "Create Employee Request"
+// @Success 201 {object} utils.Response
+// @Router /api/employees [post]
 func (c *EmployeeController) Create(ctx *gin.Context) {
 	var req models.CreateEmployeeRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+		utils.BindError(ctx, err)
 		return
 	}
 
@@ -42,50 +39,62 @@ func (c *EmployeeController) Create(ctx *gin.Context) {
 		req.Role = "employee"
 	}
 
-	employee, err := c.employeeService.Create(&req)
+	employee, err := c.employeeService.Create(&req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to create employee")
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	response := EmployeeResponse{
+	response := models.EmployeeResponse{
 		ID:         employee.UserID,
 		Name:       employee.Name,
 		Email:      employee.Email,
 		Role:       string(employee.Role),
 		Status:     employee.Status,
 		Phone:      employee.Phone,
-		Department:  employee.Department,
-		Salary:      employee.Salary,
+		Department: employee.Department,
+		Salary:     employee.Salary,
+		Version:    employee.Version,
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "Employee created successfully", response)
 }
 
+// @Summary List employees
+// @Tags employees
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param search query string false "Search term"
+// @Param include_deleted query bool false "Include deleted records (requires the restore permission)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/employees [get]
 func (c *EmployeeController) List(ctx *gin.Context) {
 	query := &models.PaginationQuery{}
 	if err := ctx.ShouldBindQuery(query); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters")
+		utils.BindError(ctx, err)
 		return
 	}
 
 	employees, total, err := c.employeeService.List(query)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch employees")
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	var response []EmployeeResponse
+	var response []models.EmployeeResponse
 	for _, emp := range employees {
-		response = append(response, EmployeeResponse{
+		response = append(response, models.EmployeeResponse{
 			ID:         emp.UserID,
 			Name:       emp.Name,
 			Email:      emp.Email,
 			Role:       string(emp.Role),
 			Status:     emp.Status,
 			Phone:      emp.Phone,
-			Department:  emp.Department,
-			Salary:      emp.Salary,
+			Department: emp.Department,
+			Salary:     emp.Salary,
+			Version:    emp.Version,
 		})
 	}
 
@@ -98,6 +107,13 @@ func (c *EmployeeController) List(ctx *gin.Context) {
 	utils.PaginatedSuccessResponse(ctx, http.StatusOK, response, pagination)
 }
 
+// @Summary Get employee by ID
+// @Tags employees
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 200 {object} utils.Response
+// @Router /api/employees/{id} [get]
 func (c *EmployeeController) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
@@ -107,20 +123,22 @@ func (c *EmployeeController) GetByID(ctx *gin.Context) {
 
 	employee, err := c.employeeService.GetByID(id)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusNotFound, "Employee not found")
+		utils.HandleError(ctx, err)
 		return
 	}
 
-	response := EmployeeResponse{
+	response := models.EmployeeResponse{
 		ID:         employee.UserID,
 		Name:       employee.Name,
 		Email:      employee.Email,
 		Role:       string(employee.Role),
 		Status:     employee.Status,
 		Phone:      employee.Phone,
-		Department:  employee.Department,
-		Salary:      employee.Salary,
+		Department: employee.Department,
+		Salary:     employee.Salary,
+		Version:    employee.Version,
 	}
 
+	setETag(ctx, employee.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Employee retrieved successfully", response)
 }
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// Users, projects and service requests are versioned: reads return the version
+// as the ETag and updates must send it back in If-Match.
+
+// setETag sends version as the entity tag of the response.
+func setETag(ctx *gin.Context, version int64) {
+	ctx.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatch returns the versions named by If-Match, which may be "*" or a
+// comma-separated list of tags. A missing header is answered with 428; tags
+// that are not one of our versions can never match, and a header with nothing
+// else is answered with 412. Weak tags are accepted.
+func ifMatch(ctx *gin.Context) (services.VersionMatch, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		utils.ErrorResponseWithCode(ctx, http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "If-Match header with the resource's ETag is required")
+		return services.VersionMatch{}, false
+	}
+	if header == "*" {
+		return services.AnyVersion, true
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+		if version, err := strconv.ParseInt(tag, 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		utils.HandleError(ctx, services.ErrVersionMismatch)
+		return services.VersionMatch{}, false
+	}
+	return services.MatchVersion(versions...), true
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type InvitationController struct {
+	invitationService services.InvitationService
+}
+
+func NewInvitationController(invitationService services.InvitationService) *InvitationController {
+	return &InvitationController{invitationService: invitationService}
+}
+
+// @Summary Invite a user
+// @Tags invitations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateInvitationRequest true "Create Invitation Request"
+// @Success 201 {object} utils.Response
+// @Router /api/invitations [post]
+func (c *InvitationController) Create(ctx *gin.Context) {
+	var req models.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	invitation, err := c.invitationService.Create(&req, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+// @Summary List invitations
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param search query string false "Search term"
+// @Param status query string false "Filter by status (pending, accepted, revoked)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/invitations [get]
+func (c *InvitationController) List(ctx *gin.Context) {
+	var query models.InvitationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 10
+	}
+
+	invitations, total, err := c.invitationService.List(&query)
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	totalPages := int(total) / query.PageSize
+	if int(total)%query.PageSize != 0 {
+		totalPages++
+	}
+
+	pagination := utils.Pagination{
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		Total:     total,
+		TotalPage: totalPages,
+	}
+
+	utils.PaginatedSuccessResponse(ctx, http.StatusOK, invitations, pagination)
+}
+
+// @Summary Resend an invitation
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} utils.Response
+// @Router /api/invitations/{id}/resend [post]
+func (c *InvitationController) Resend(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	invitation, err := c.invitationService.Resend(id, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Invitation resent successfully", invitation)
+}
+
+// @Summary Revoke an invitation
+// @Tags invitations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} utils.Response
+// @Router /api/invitations/{id} [delete]
+func (c *InvitationController) Revoke(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.invitationService.Revoke(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Invitation revoked successfully", nil)
+}
+
+// @Summary Accept an invitation and set a password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.AcceptInvitationRequest true "Accept Invitation Request"
+// @Success 201 {object} utils.Response
+// @Router /api/auth/accept-invite [post]
+func (c *InvitationController) Accept(ctx *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	user, err := c.invitationService.Accept(&req, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusCreated, "Invitation accepted successfully", user)
+}
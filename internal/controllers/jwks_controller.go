@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type JWKSController struct {
+	keys *utils.KeySet
+}
+
+func NewJWKSController(keys *utils.KeySet) *JWKSController {
+	return &JWKSController{keys: keys}
+}
+
+// @Summary Public keys for verifying access tokens
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKS
+// @Router /.well-known/jwks.json [get]
+func (c *JWKSController) Get(ctx *gin.Context) {
+	// Short cache so verifiers pick up a newly added key soon after rotation
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.keys.JWKS())
+}
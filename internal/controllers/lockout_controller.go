@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type LockoutController struct {
+	lockoutService services.LockoutService
+}
+
+func NewLockoutController(lockoutService services.LockoutService) *LockoutController {
+	return &LockoutController{lockoutService: lockoutService}
+}
+
+// @Summary List currently locked accounts
+// @Tags lockouts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/lockouts [get]
+func (c *LockoutController) ListLocked(ctx *gin.Context) {
+	attempts, err := c.lockoutService.ListLocked()
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Locked accounts retrieved successfully", attempts)
+}
+
+// @Summary List lockout events
+// @Tags lockouts
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param email query string false "Filter by email"
+// @Param type query string false "Filter by type (locked, unlocked)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/lockouts/events [get]
+func (c *LockoutController) ListEvents(ctx *gin.Context) {
+	var query models.LockoutEventQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 10
+	}
+
+	events, total, err := c.lockoutService.ListEvents(&query)
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	totalPages := int(total) / query.PageSize
+	if int(total)%query.PageSize != 0 {
+		totalPages++
+	}
+
+	pagination := utils.Pagination{
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		Total:     total,
+		TotalPage: totalPages,
+	}
+
+	utils.PaginatedSuccessResponse(ctx, http.StatusOK, events, pagination)
+}
+
+// @Summary Unlock an account
+// @Tags lockouts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.UnlockAccountRequest true "Unlock Account Request"
+// @Success 200 {object} utils.Response
+// @Router /api/lockouts/unlock [post]
+func (c *LockoutController) Unlock(ctx *gin.Context) {
+	var req models.UnlockAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if err := c.lockoutService.Unlock(req.Email, ctx.GetString("user_id")); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Account unlocked successfully", nil)
+}
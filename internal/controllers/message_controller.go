@@ -5,8 +5,8 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -18,30 +18,37 @@ func NewMessageController(messageService services.MessageService) *MessageContro
 	return &MessageController{messageService: messageService}
 }
 
+// @Summary Create message
+// @Tags messages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateMessageRequest true "Create Message Request"
+// @Success 201 {object} utils.Response
+// @Router /api/messages [post]
 func (c *MessageController) Create(ctx *gin.Context) {
 	var req models.CreateMessageRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
-	
-	message, err := c.messageService.Create(userID.(string), &req, userID.(string), userRole.(string))
+	message, err := c.messageService.Create(&req, actor(ctx))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only message their own projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "Message created successfully", message)
 }
 
+// @Summary Get message by ID
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} utils.Response
+// @Router /api/messages/{id} [get]
 func (c *MessageController) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 	userID, _ := ctx.Get("user_id")
@@ -49,52 +56,69 @@ func (c *MessageController) GetByID(ctx *gin.Context) {
 
 	message, err := c.messageService.GetByID(id, userID.(string), userRole.(string))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only view their own projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusNotFound, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Message retrieved successfully", message)
 }
 
+// @Summary Delete message
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} utils.Response
+// @Router /api/messages/{id} [delete]
 func (c *MessageController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
 
-	if err := c.messageService.Delete(id, userID.(string), userRole.(string)); err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only delete messages from their own projects" ||
-		   err.Error() == "access denied: can only delete own messages" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
-		}
+	if err := c.messageService.Delete(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Message deleted successfully", nil)
 }
 
+// @Summary Restore a deleted message
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} utils.Response
+// @Router /api/messages/{id}/restore [post]
+func (c *MessageController) Restore(ctx *gin.Context) {
+	restored, err := c.messageService.Restore(ctx.Param("id"), actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Message restored successfully", restored)
+}
+
+// @Summary List messages of a project
+// @Tags messages
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param include_deleted query bool false "Include deleted records (requires the restore permission)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/projects/{id}/messages [get]
 func (c *MessageController) ListByProject(ctx *gin.Context) {
 	projectID := ctx.Param("id")
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	includeDeleted, _ := strconv.ParseBool(ctx.Query("include_deleted"))
 	userID, _ := ctx.Get("user_id")
 	userRole, _ := ctx.Get("user_role")
 
-	messages, total, err := c.messageService.ListByProject(projectID, page, pageSize, userID.(string), userRole.(string))
+	messages, total, err := c.messageService.ListByProject(projectID, page, pageSize, includeDeleted, userID.(string), userRole.(string))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only view their own projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -127,14 +151,9 @@ func (c *MessageController) List(ctx *gin.Context) {
 	userID, _ := ctx.Get("user_id")
 	userRole, _ := ctx.Get("user_role")
 
-	messages, total, err := c.messageService.ListByProject("", page, pageSize, userID.(string), userRole.(string))
+	messages, total, err := c.messageService.ListByProject("", page, pageSize, false, userID.(string), userRole.(string))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only view their own projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
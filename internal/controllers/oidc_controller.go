@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type OIDCController struct {
+	oidcService services.OIDCService
+}
+
+func NewOIDCController(oidcService services.OIDCService) *OIDCController {
+	return &OIDCController{oidcService: oidcService}
+}
+
+// @Summary Start a single sign-on login
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/auth/oidc/login [get]
+func (c *OIDCController) Login(ctx *gin.Context) {
+	response, err := c.oidcService.Begin()
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Redirect to the identity provider to continue", response)
+}
+
+// @Summary Complete a single sign-on login
+// @Tags auth
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string true "State from the login request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/oidc/callback [get]
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	var req models.OIDCCallbackRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	response, err := c.oidcService.Callback(&req, clientInfo(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Login successful", response)
+}
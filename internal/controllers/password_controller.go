@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type PasswordController struct {
+	passwordService services.PasswordService
+}
+
+func NewPasswordController(passwordService services.PasswordService) *PasswordController {
+	return &PasswordController{passwordService: passwordService}
+}
+
+// @Summary Request a password reset link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Forgot Password Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/forgot-password [post]
+func (c *PasswordController) ForgotPassword(ctx *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if err := c.passwordService.ForgotPassword(&req); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "If an account exists for this email, a password reset link has been sent", nil)
+}
+
+// @Summary Reset password with a reset token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset Password Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/reset-password [post]
+func (c *PasswordController) ResetPassword(ctx *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if err := c.passwordService.ResetPassword(&req, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Password reset successfully", nil)
+}
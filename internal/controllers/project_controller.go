@@ -4,8 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -17,22 +17,37 @@ func NewProjectController(projectService services.ProjectService) *ProjectContro
 	return &ProjectController{projectService: projectService}
 }
 
+// @Summary Create project
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateProjectRequest true "Create Project Request"
+// @Success 201 {object} utils.Response
+// @Router /api/projects [post]
 func (c *ProjectController) Create(ctx *gin.Context) {
 	var req models.CreateProjectRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	project, err := c.projectService.Create(&req)
+	project, err := c.projectService.Create(&req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "Project created successfully", project)
 }
 
+// @Summary Get project by ID
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.Response
+// @Router /api/projects/{id} [get]
 func (c *ProjectController) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 	userID, _ := ctx.Get("user_id")
@@ -40,60 +55,97 @@ func (c *ProjectController) GetByID(ctx *gin.Context) {
 
 	project, err := c.projectService.GetByID(id, userID.(string), userRole.(string))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only view their own projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusNotFound, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, project.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Project retrieved successfully", project)
 }
 
+// @Summary Update project
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param If-Match header string true "ETag of the version being updated"
+// @Param request body models.UpdateProjectRequest true "Update Project Request"
+// @Success 200 {object} utils.Response
+// @Router /api/projects/{id} [put]
 func (c *ProjectController) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
+	match, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateProjectRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	project, err := c.projectService.Update(id, &req, userID.(string), userRole.(string))
+	project, err := c.projectService.Update(id, &req, match, actor(ctx))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only update their own projects" ||
-		   err.Error() == "access denied: employees can only update project status" ||
-		   err.Error() == "access denied: clients cannot update project status" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, project.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Project updated successfully", project)
 }
 
+// @Summary Delete project
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.Response
+// @Router /api/projects/{id} [delete]
 func (c *ProjectController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.projectService.Delete(id); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := c.projectService.Delete(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Project deleted successfully", nil)
 }
 
+// @Summary Restore a deleted project
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} utils.Response
+// @Router /api/projects/{id}/restore [post]
+func (c *ProjectController) Restore(ctx *gin.Context) {
+	restored, err := c.projectService.Restore(ctx.Param("id"), actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Project restored successfully", restored)
+}
+
+// @Summary List projects
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param search query string false "Search term"
+// @Param status query string false "Filter by status"
+// @Param include_deleted query bool false "Include deleted records (requires the restore permission)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/projects [get]
 func (c *ProjectController) List(ctx *gin.Context) {
 	var query models.PaginationQuery
 	if err := ctx.ShouldBindQuery(&query); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
@@ -106,16 +158,10 @@ func (c *ProjectController) List(ctx *gin.Context) {
 
 	userID, _ := ctx.Get("user_id")
 	userRole, _ := ctx.Get("user_role")
-	
-	var clientID *string
-	if userRole == "client" {
-		uid := userID.(string)
-		clientID = &uid
-	}
 
-	projects, total, err := c.projectService.List(&query, clientID, userID.(string), userRole.(string))
+	projects, total, err := c.projectService.List(&query, userID.(string), userRole.(string))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -134,50 +180,61 @@ func (c *ProjectController) List(ctx *gin.Context) {
 	utils.PaginatedSuccessResponse(ctx, http.StatusOK, projects, pagination)
 }
 
+// @Summary Assign employees to a project
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body models.AssignEmployeesRequest true "Assign Employees Request"
+// @Success 200 {object} utils.Response
+// @Router /api/projects/{id}/assign [post]
 func (c *ProjectController) AssignEmployees(ctx *gin.Context) {
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
 
 	var req models.AssignEmployeesRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	if err := c.projectService.AssignEmployees(id, &req, userID.(string), userRole.(string)); err != nil {
-		if err.Error() == "access denied: only admins can assign employees to projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
-		}
+	if err := c.projectService.AssignEmployees(id, &req, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Employees assigned successfully", nil)
 }
 
+// @Summary Update project progress
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param If-Match header string true "ETag of the version being updated"
+// @Param request body models.UpdateProjectProgressRequest true "Update Progress Request"
+// @Success 200 {object} utils.Response
+// @Router /api/projects/{id}/progress [patch]
 func (c *ProjectController) UpdateProgress(ctx *gin.Context) {
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
+	match, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateProjectProgressRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	project, err := c.projectService.UpdateProjectProgress(id, &req, userID.(string), userRole.(string))
+	project, err := c.projectService.UpdateProjectProgress(id, &req, match, actor(ctx))
 	if err != nil {
-		if err.Error() == "access denied: employee not assigned to this project" || 
-		   err.Error() == "access denied: client can only update their own projects" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, project.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Project progress updated successfully", project)
 }
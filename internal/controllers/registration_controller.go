@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type RegistrationController struct {
+	registrationService services.RegistrationService
+}
+
+func NewRegistrationController(registrationService services.RegistrationService) *RegistrationController {
+	return &RegistrationController{registrationService: registrationService}
+}
+
+// @Summary List registrations waiting for approval
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/registrations [get]
+func (c *RegistrationController) List(ctx *gin.Context) {
+	var query models.RegistrationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 10
+	}
+
+	users, total, err := c.registrationService.ListPending(&query)
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	totalPages := int(total) / query.PageSize
+	if int(total)%query.PageSize != 0 {
+		totalPages++
+	}
+
+	pagination := utils.Pagination{
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+		Total:     total,
+		TotalPage: totalPages,
+	}
+
+	utils.PaginatedSuccessResponse(ctx, http.StatusOK, users, pagination)
+}
+
+// @Summary Approve a registration
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} utils.Response
+// @Router /api/registrations/{id}/approve [post]
+func (c *RegistrationController) Approve(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	user, err := c.registrationService.Approve(id, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Registration approved successfully", user)
+}
+
+// @Summary Reject a registration
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} utils.Response
+// @Router /api/registrations/{id}/reject [post]
+func (c *RegistrationController) Reject(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.registrationService.Reject(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Registration rejected successfully", nil)
+}
@@ -4,8 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -17,68 +17,135 @@ func NewServiceRequestController(serviceRequestService services.ServiceRequestSe
 	return &ServiceRequestController{serviceRequestService: serviceRequestService}
 }
 
+// @Summary Create service request
+// @Tags service-requests
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateServiceRequestRequest true "Create Service Request Request"
+// @Success 201 {object} utils.Response
+// @Router /api/service-requests [post]
 func (c *ServiceRequestController) Create(ctx *gin.Context) {
 	var req models.CreateServiceRequestRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	userID, _ := ctx.Get("user_id")
-	serviceRequest, err := c.serviceRequestService.Create(userID.(string), &req)
+	serviceRequest, err := c.serviceRequestService.Create(&req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "Service request created successfully", serviceRequest)
 }
 
+// @Summary Get service request by ID
+// @Tags service-requests
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Service Request ID"
+// @Success 200 {object} utils.Response
+// @Router /api/service-requests/{id} [get]
 func (c *ServiceRequestController) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
+	userID, _ := ctx.Get("user_id")
+	userRole, _ := ctx.Get("user_role")
 
-	serviceRequest, err := c.serviceRequestService.GetByID(id)
+	serviceRequest, err := c.serviceRequestService.GetByID(id, userID.(string), userRole.(string))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusNotFound, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, serviceRequest.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Service request retrieved successfully", serviceRequest)
 }
 
+// @Summary Update service request
+// @Tags service-requests
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Service Request ID"
+// @Param If-Match header string true "ETag of the version being updated"
+// @Param request body models.UpdateServiceRequestRequest true "Update Service Request Request"
+// @Success 200 {object} utils.Response
+// @Router /api/service-requests/{id} [put]
 func (c *ServiceRequestController) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
+	match, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateServiceRequestRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	serviceRequest, err := c.serviceRequestService.Update(id, &req)
+	serviceRequest, err := c.serviceRequestService.Update(id, &req, match, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, serviceRequest.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "Service request updated successfully", serviceRequest)
 }
 
+// @Summary Delete service request
+// @Tags service-requests
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Service Request ID"
+// @Success 200 {object} utils.Response
+// @Router /api/service-requests/{id} [delete]
 func (c *ServiceRequestController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.serviceRequestService.Delete(id); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := c.serviceRequestService.Delete(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Service request deleted successfully", nil)
 }
 
+// @Summary Restore a deleted service request
+// @Tags service-requests
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Service Request ID"
+// @Success 200 {object} utils.Response
+// @Router /api/service-requests/{id}/restore [post]
+func (c *ServiceRequestController) Restore(ctx *gin.Context) {
+	restored, err := c.serviceRequestService.Restore(ctx.Param("id"), actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Service request restored successfully", restored)
+}
+
+// @Summary List service requests
+// @Tags service-requests
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param search query string false "Search term"
+// @Param status query string false "Filter by status"
+// @Param include_deleted query bool false "Include deleted records (requires the restore permission)"
+// @Success 200 {object} utils.PaginatedResponse
+// @Router /api/service-requests [get]
 func (c *ServiceRequestController) List(ctx *gin.Context) {
 	var query models.PaginationQuery
 	if err := ctx.ShouldBindQuery(&query); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
@@ -89,17 +156,12 @@ func (c *ServiceRequestController) List(ctx *gin.Context) {
 		query.PageSize = 10
 	}
 
-	var clientID *string
+	userID, _ := ctx.Get("user_id")
 	userRole, _ := ctx.Get("user_role")
-	if userRole == "client" {
-		userIDVal, _ := ctx.Get("user_id")
-		uid := userIDVal.(string)
-		clientID = &uid
-	}
 
-	requests, total, err := c.serviceRequestService.List(&query, clientID)
+	requests, total, err := c.serviceRequestService.List(&query, userID.(string), userRole.(string))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -118,31 +180,45 @@ func (c *ServiceRequestController) List(ctx *gin.Context) {
 	utils.PaginatedSuccessResponse(ctx, http.StatusOK, requests, pagination)
 }
 
+// @Summary Approve a service request and create its project
+// @Tags service-requests
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Service Request ID"
+// @Param request body models.ApproveServiceRequestRequest true "Approve Service Request Request"
+// @Success 200 {object} utils.Response
+// @Router /api/service-requests/{id}/approve [post]
 func (c *ServiceRequestController) Approve(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	var req struct {
-		EmployeeIDs []string `json:"employee_ids" binding:"required"`
-	}
+	var req models.ApproveServiceRequestRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	project, err := c.serviceRequestService.Approve(id, &req.EmployeeIDs)
+	project, err := c.serviceRequestService.Approve(id, &req.EmployeeIDs, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Service request approved and project created successfully", project)
 }
 
+// @Summary Reject a service request
+// @Tags service-requests
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Service Request ID"
+// @Success 200 {object} utils.Response
+// @Router /api/service-requests/{id}/reject [post]
 func (c *ServiceRequestController) Reject(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.serviceRequestService.Reject(id); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := c.serviceRequestService.Reject(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
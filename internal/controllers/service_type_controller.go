@@ -4,47 +4,68 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
 type ServiceTypeController struct {
-	serviceTypeService *services.ServiceTypeService
+	serviceTypeService services.ServiceTypeService
 }
 
-func NewServiceTypeController(serviceTypeService *services.ServiceTypeService) *ServiceTypeController {
+func NewServiceTypeController(serviceTypeService services.ServiceTypeService) *ServiceTypeController {
 	return &ServiceTypeController{serviceTypeService: serviceTypeService}
 }
 
+// @Summary Create service type
+// @Tags service-types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateServiceTypeRequest true "Create Service Type Request"
+// @Success 201 {object} utils.Response
+// @Router /api/service-types [post]
 func (c *ServiceTypeController) Create(ctx *gin.Context) {
 	var req models.CreateServiceTypeRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	serviceType, err := c.serviceTypeService.Create(&req)
+	serviceType, err := c.serviceTypeService.Create(&req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusCreated, "Service type created successfully", serviceType)
 }
 
+// @Summary Get service type by ID
+// @Tags service-types
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Service Type ID"
+// @Success 200 {object} utils.Response
+// @Router /api/service-types/{id} [get]
 func (c *ServiceTypeController) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 
 	serviceType, err := c.serviceTypeService.GetByID(id)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusNotFound, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Service type retrieved successfully", serviceType)
 }
 
+// @Summary List service types
+// @Tags service-types
+// @Produce json
+// @Param status query string false "Filter by status (active returns only active types)"
+// @Success 200 {object} utils.Response
+// @Router /api/service-types [get]
 func (c *ServiceTypeController) GetAll(ctx *gin.Context) {
 	status := ctx.Query("status")
 
@@ -62,36 +83,52 @@ func (c *ServiceTypeController) GetAll(ctx *gin.Context) {
 	}
 
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Service types retrieved successfully", serviceTypes)
 }
 
+// @Summary Update service type
+// @Tags service-types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Service Type ID"
+// @Param request body models.UpdateServiceTypeRequest true "Update Service Type Request"
+// @Success 200 {object} utils.Response
+// @Router /api/service-types/{id} [put]
 func (c *ServiceTypeController) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
 	var req models.UpdateServiceTypeRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	serviceType, err := c.serviceTypeService.Update(id, &req)
+	serviceType, err := c.serviceTypeService.Update(id, &req, actor(ctx))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "Service type updated successfully", serviceType)
 }
 
+// @Summary Delete service type
+// @Tags service-types
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Service Type ID"
+// @Success 200 {object} utils.Response
+// @Router /api/service-types/{id} [delete]
 func (c *ServiceTypeController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.serviceTypeService.Delete(id); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := c.serviceTypeService.Delete(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+type TwoFactorController struct {
+	twoFactorService services.TwoFactorService
+}
+
+func NewTwoFactorController(twoFactorService services.TwoFactorService) *TwoFactorController {
+	return &TwoFactorController{twoFactorService: twoFactorService}
+}
+
+// @Summary Start two-factor enrolment
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} utils.Response
+// @Router /api/auth/2fa/setup [post]
+func (c *TwoFactorController) Setup(ctx *gin.Context) {
+	setup, err := c.twoFactorService.Setup(ctx.GetString("user_id"))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Scan the secret with your authenticator app and confirm a code to enable two-factor authentication", setup)
+}
+
+// @Summary Confirm two-factor enrolment
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Two-Factor Code Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/2fa/enable [post]
+func (c *TwoFactorController) Enable(ctx *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	codes, err := c.twoFactorService.Enable(&req, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Two-factor authentication enabled. Store the recovery codes somewhere safe", models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Two-Factor Code Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/2fa/disable [post]
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	if err := c.twoFactorService.Disable(&req, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// @Summary Regenerate two-factor recovery codes
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Two-Factor Code Request"
+// @Success 200 {object} utils.Response
+// @Router /api/auth/2fa/recovery-codes [post]
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BindError(ctx, err)
+		return
+	}
+
+	codes, err := c.twoFactorService.RegenerateRecoveryCodes(&req, actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "Recovery codes regenerated. Previous codes no longer work", models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
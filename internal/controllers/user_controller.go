@@ -1,12 +1,11 @@
 package controllers
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
@@ -22,7 +21,7 @@ func NewUserController(userService services.UserService) *UserController {
 // @Tags users
 // @Security BearerAuth
 // @Produce json
-// @Param id path int true "User ID"
+// @Param id path string true "User ID"
 // @Success 200 {object} utils.Response
 // @Router /api/users/{id} [get]
 func (c *UserController) GetByID(ctx *gin.Context) {
@@ -32,15 +31,11 @@ func (c *UserController) GetByID(ctx *gin.Context) {
 
 	user, err := c.userService.GetByID(id, userID.(string), userRole.(string))
 	if err != nil {
-		if err.Error() == "access denied: employees can only view their own profile" || 
-		   err.Error() == "access denied: clients can only view their own profile" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusNotFound, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, user.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "User retrieved successfully", user)
 }
 
@@ -49,34 +44,32 @@ func (c *UserController) GetByID(ctx *gin.Context) {
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param id path int true "User ID"
+// @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the version being updated"
 // @Param request body models.UpdateUserRequest true "Update User Request"
 // @Success 200 {object} utils.Response
 // @Router /api/users/{id} [put]
+// @Router /api/employees/{id} [put]
 func (c *UserController) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
+	match, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
-	user, err := c.userService.Update(id, &req, userID.(string), userRole.(string))
+	user, err := c.userService.Update(id, &req, match, actor(ctx))
 	if err != nil {
-		if err.Error() == "access denied: employees can only update their own profile" || 
-		   err.Error() == "access denied: clients can only update their own profile" ||
-		   err.Error() == "access denied: employees cannot modify role, department, or salary" ||
-		   err.Error() == "access denied: clients cannot modify role or company" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
 
+	setETag(ctx, user.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "User updated successfully", user)
 }
 
@@ -85,45 +78,32 @@ func (c *UserController) Update(ctx *gin.Context) {
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param id path int true "User ID"
+// @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the version being updated"
 // @Param request body models.UpdateUserRequest true "Update User Request"
 // @Success 200 {object} utils.Response
 // @Router /api/users/{id} [patch]
+// @Router /api/employees/{id} [patch]
 func (c *UserController) Patch(ctx *gin.Context) {
 	id := ctx.Param("id")
-	userID, _ := ctx.Get("user_id")
-	userRole, _ := ctx.Get("user_role")
-	
-	// Debug logging
-	fmt.Printf("PATCH request for user ID: %s\n", id)
+	match, ok := ifMatch(ctx)
+	if !ok {
+		return
+	}
 
 	var req models.UpdateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		fmt.Printf("JSON binding error: %v\n", err)
-		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+		utils.BindError(ctx, err)
 		return
 	}
-	
-	// Debug logging
-	fmt.Printf("Update request: %+v\n", req)
 
-	user, err := c.userService.Update(id, &req, userID.(string), userRole.(string))
+	user, err := c.userService.Update(id, &req, match, actor(ctx))
 	if err != nil {
-		fmt.Printf("Update error: %v\n", err)
-		if err.Error() == "access denied: employees can only update their own profile" || 
-		   err.Error() == "access denied: clients can only update their own profile" ||
-		   err.Error() == "access denied: employees cannot modify role, department, or salary" ||
-		   err.Error() == "access denied: clients cannot modify role or company" {
-			utils.ErrorResponse(ctx, http.StatusForbidden, err.Error())
-		} else {
-			utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to update user")
-		}
+		utils.HandleError(ctx, err)
 		return
 	}
-	
-	// Debug logging
-	fmt.Printf("Updated user: %+v\n", user)
 
+	setETag(ctx, user.Version)
 	utils.SuccessResponse(ctx, http.StatusOK, "User updated successfully", user)
 }
 
@@ -131,20 +111,38 @@ func (c *UserController) Patch(ctx *gin.Context) {
 // @Tags users
 // @Security BearerAuth
 // @Produce json
-// @Param id path int true "User ID"
+// @Param id path string true "User ID"
 // @Success 200 {object} utils.Response
 // @Router /api/users/{id} [delete]
+// @Router /api/employees/{id} [delete]
 func (c *UserController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.userService.Delete(id); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := c.userService.Delete(id, actor(ctx)); err != nil {
+		utils.HandleError(ctx, err)
 		return
 	}
 
 	utils.SuccessResponse(ctx, http.StatusOK, "User deleted successfully", nil)
 }
 
+// @Summary Restore a deleted user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} utils.Response
+// @Router /api/users/{id}/restore [post]
+func (c *UserController) Restore(ctx *gin.Context) {
+	restored, err := c.userService.Restore(ctx.Param("id"), actor(ctx))
+	if err != nil {
+		utils.HandleError(ctx, err)
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, "User restored successfully", restored)
+}
+
 // @Summary List users
 // @Tags users
 // @Security BearerAuth
@@ -153,12 +151,13 @@ func (c *UserController) Delete(ctx *gin.Context) {
 // @Param page_size query int false "Page size"
 // @Param search query string false "Search term"
 // @Param role query string false "Filter by role"
+// @Param include_deleted query bool false "Include deleted records (requires the restore permission)"
 // @Success 200 {object} utils.PaginatedResponse
 // @Router /api/users [get]
 func (c *UserController) List(ctx *gin.Context) {
 	var query models.PaginationQuery
 	if err := ctx.ShouldBindQuery(&query); err != nil {
-		utils.ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		utils.BindError(ctx, err)
 		return
 	}
 
@@ -172,7 +171,7 @@ func (c *UserController) List(ctx *gin.Context) {
 	role := ctx.Query("role")
 	users, total, err := c.userService.List(&query, role)
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -203,7 +202,7 @@ func (c *UserController) GetDashboardStats(ctx *gin.Context) {
 
 	stats, err := c.userService.GetDashboardStats(userID.(string), userRole.(string))
 	if err != nil {
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		utils.HandleError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer appends every message to a local file instead of delivering it.
+// It is intended for local development.
+type FileMailer struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+func NewFileMailer(path, from string) *FileMailer {
+	return &FileMailer{path: path, from: from}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer f.Close()
+
+	header := fmt.Sprintf("==== %s ====\r\n", time.Now().Format(time.RFC3339))
+	if _, err := f.WriteString(header); err != nil {
+		return err
+	}
+	if _, err := f.Write(buildMessage(m.from, msg)); err != nil {
+		return err
+	}
+	_, err = f.WriteString("\r\n\r\n")
+	return err
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/vinodhini/software-api/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as password reset links.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New returns the Mailer selected by MAIL_DRIVER.
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From), nil
+	case "file":
+		return NewFileMailer(cfg.Mail.FilePath, cfg.Mail.From), nil
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+	}
+}
@@ -2,13 +2,24 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware accepts either a session-bound JWT ("Bearer <token>") or a
+// personal API key ("ApiKey <key>"). Both set user_id, user_email and
+// user_role; session_id is only set for JWTs and api_key_id only for API keys.
+// The role is read from the account on every request rather than from the
+// token, so a role change applies to sessions that are already open.
+func AuthMiddleware(keys *utils.KeySet, sessionRepo repositories.SessionRepository, userRepo repositories.UserRepository, apiKeyService services.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -18,27 +29,85 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		}
 
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "ApiKey") {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format")
 			c.Abort()
 			return
 		}
 
-		claims, err := utils.ValidateToken(parts[1], jwtSecret)
-		if err != nil {
+		if parts[0] == "ApiKey" {
+			key, user, err := apiKeyService.Authenticate(parts[1])
+			if err != nil {
+				utils.HandleError(c, err)
+				c.Abort()
+				return
+			}
+
+			scope := models.APIKeyScopeWrite
+			if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+				scope = models.APIKeyScopeRead
+			}
+			if !key.HasScope(scope) {
+				utils.ErrorResponse(c, http.StatusForbidden, "API key is missing the "+scope+" scope")
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", user.UserID)
+			c.Set("user_email", user.Email)
+			c.Set("user_role", string(user.Role))
+			c.Set("api_key_id", key.ID)
+			c.Next()
+			return
+		}
+
+		claims, err := utils.ValidateToken(parts[1], keys)
+		if err != nil || claims.Purpose != "" {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token")
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
+		// Tokens are only honoured while the session they were issued for is still active
+		session, err := sessionRepo.FindByID(claims.SessionID)
+		if err != nil || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) || session.UserID != claims.UserID {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Session has been revoked or expired")
+			c.Abort()
+			return
+		}
+		user, err := userRepo.FindByID(claims.UserID)
+		if err != nil || (user.Status != "" && user.Status != models.UserStatusActive) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Session has been revoked or expired")
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", user.UserID)
+		c.Set("user_email", user.Email)
+		c.Set("user_role", string(user.Role))
+		c.Set("session_id", claims.SessionID)
+		c.Next()
+	}
+}
+
+// SessionOnlyMiddleware rejects requests authenticated with an API key. It
+// guards credential management so a leaked key cannot mint new keys or change
+// two-factor settings.
+func SessionOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("session_id") == "" {
+			utils.ErrorResponse(c, http.StatusForbidden, "This endpoint requires an interactive login")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
 
-func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
+// Authorize admits the request when the caller's role is granted at least one
+// of actions by the policy. Conditions on the resource itself, such as
+// project membership, are checked by the services once it has been loaded.
+func Authorize(engine *policy.Engine, actions ...policy.Action) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
@@ -47,9 +116,9 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 			return
 		}
 
-		role := userRole.(string)
-		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
+		subject := policy.Subject{ID: c.GetString("user_id"), Role: userRole.(string)}
+		for _, action := range actions {
+			if engine.Permits(subject, action) {
 				c.Next()
 				return
 			}
@@ -59,3 +128,23 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// AuthorizeIncludeDeleted refuses ?include_deleted=true on a listing unless
+// the caller's role is granted action, the permission to restore what it
+// would reveal.
+func AuthorizeIncludeDeleted(engine *policy.Engine, action policy.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted")); !includeDeleted {
+			c.Next()
+			return
+		}
+
+		subject := policy.Subject{ID: c.GetString("user_id"), Role: c.GetString("user_role")}
+		if !engine.Permits(subject, action) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
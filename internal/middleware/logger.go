@@ -19,7 +19,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 		statusCode := c.Writer.Status()
 		clientIP := c.ClientIP()
 
-		log.Printf("[%s] %s %s | Status: %d | Latency: %v | IP: %s",
-			method, path, c.Request.Proto, statusCode, latency, clientIP)
+		log.Printf("[%s] %s %s | Status: %d | Latency: %v | IP: %s | Request: %s",
+			method, path, c.Request.Proto, statusCode, latency, clientIP, c.GetString("request_id"))
 	}
 }
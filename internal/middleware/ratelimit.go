@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -46,8 +48,12 @@ func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
 		ip := c.ClientIP()
 		limiter := limiter.GetLimiter(ip)
 
-		if !limiter.Allow() {
-			utils.ErrorResponse(c, http.StatusTooManyRequests, "Rate limit exceeded")
+		// Tell the caller when the next request will be allowed
+		reservation := limiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			utils.ErrorResponseWithCode(c, http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded")
 			c.Abort()
 			return
 		}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// RequestIDHeader carries the ID that ties a request to its log lines and
+// audit events.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs supplied by callers so they cannot bloat the
+// audit log.
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware reuses the caller's X-Request-ID when present and
+// generates one otherwise. The ID is stored as "request_id" and echoed back.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			if token, err := utils.GenerateRandomToken(16); err == nil {
+				requestID = token
+			}
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
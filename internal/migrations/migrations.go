@@ -0,0 +1,258 @@
+// Package migrations keeps the MongoDB schema up to date. Each migration is a
+// numbered Go step; the ones applied are recorded in the schema_migrations
+// collection, so every step runs once per database. A lock document keeps
+// instances that start together from migrating at the same time.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	migrationsCollection = "schema_migrations"
+	lockCollection       = "schema_migrations_lock"
+	lockID               = "lock"
+
+	// The holder renews the lock every lockRenewal while it migrates, so a
+	// lock left by an instance that died mid-migration is taken over after
+	// lockTTL however long the migration itself takes
+	lockTTL      = time.Minute
+	lockRenewal  = lockTTL / 3
+	lockInterval = time.Second
+)
+
+// Migration is one step of the schema. Up applies it and Down reverts it;
+// both should succeed when run again after failing part way.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// Status is a migration and when it was applied, nil while pending.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Migrator applies and reverts the migrations of one database.
+type Migrator struct {
+	db    *mongo.Database
+	steps []Migration
+	owner string
+}
+
+// New returns a migrator for every migration of the API.
+func New(db *mongo.Database) *Migrator {
+	host, _ := os.Hostname()
+	return &Migrator{
+		db:    db,
+		steps: steps,
+		owner: fmt.Sprintf("%s/%d/%s", host, os.Getpid(), primitive.NewObjectID().Hex()),
+	}
+}
+
+// Up applies every pending migration in version order and returns the ones
+// it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, step := range m.steps {
+			if _, ok := applied[step.Version]; ok {
+				continue
+			}
+			if err := step.Up(ctx, m.db); err != nil {
+				return fmt.Errorf("migration %d %s: %w", step.Version, step.Name, err)
+			}
+			_, err := m.db.Collection(migrationsCollection).InsertOne(ctx, record{
+				Version:   step.Version,
+				Name:      step.Name,
+				AppliedAt: time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", step.Version, err)
+			}
+			done = append(done, step)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the latest n applied migrations, newest first, and returns the
+// ones it reverted.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for _, version := range versions {
+			if len(done) == n {
+				break
+			}
+			step, ok := m.find(version)
+			if !ok {
+				return fmt.Errorf("migration %d (%s) is not known to this build", version, applied[version].Name)
+			}
+			if err := step.Down(ctx, m.db); err != nil {
+				return fmt.Errorf("migration %d %s: %w", step.Version, step.Name, err)
+			}
+			if _, err := m.db.Collection(migrationsCollection).DeleteOne(ctx, bson.M{"_id": version}); err != nil {
+				return fmt.Errorf("failed to unrecord migration %d: %w", version, err)
+			}
+			done = append(done, step)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists every migration in version order. Migrations recorded by a
+// newer build are listed too, under the name they were applied with.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.steps))
+	for _, step := range m.steps {
+		status := Status{Version: step.Version, Name: step.Name}
+		if r, ok := applied[step.Version]; ok {
+			status.AppliedAt = &r.AppliedAt
+			delete(applied, step.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, r := range applied {
+		appliedAt := r.AppliedAt
+		statuses = append(statuses, Status{Version: r.Version, Name: r.Name, AppliedAt: &appliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, step := range m.steps {
+		if step.Version == version {
+			return step, true
+		}
+	}
+	return Migration{}, false
+}
+
+// applied returns the recorded migrations keyed by version.
+func (m *Migrator) applied(ctx context.Context) (map[int]record, error) {
+	cursor, err := m.db.Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// locked runs fn holding the migration lock, waiting for it as long as ctx
+// allows, and renews the lock until fn returns.
+func (m *Migrator) locked(ctx context.Context, fn func() error) error {
+	locks := m.db.Collection(lockCollection)
+	for {
+		// The filter only matches a free or expired lock; a held one makes
+		// the upsert insert a second document with the same _id, which fails
+		now := time.Now()
+		_, err := locks.UpdateOne(ctx,
+			bson.M{"_id": lockID, "expires_at": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"owner": m.owner, "locked_at": now, "expires_at": now.Add(lockTTL)}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the migration lock: %w", ctx.Err())
+		case <-time.After(lockInterval):
+		}
+	}
+
+	done := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(lockRenewal):
+				locks.UpdateOne(ctx,
+					bson.M{"_id": lockID, "owner": m.owner},
+					bson.M{"$set": bson.M{"expires_at": time.Now().Add(lockTTL)}},
+				)
+			}
+		}
+	}()
+
+	defer func() {
+		close(done)
+		<-renewed
+
+		// Released with a fresh context so a cancelled run still frees it
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		locks.UpdateOne(releaseCtx,
+			bson.M{"_id": lockID, "owner": m.owner},
+			bson.M{"$set": bson.M{"expires_at": time.Time{}}},
+		)
+	}()
+	return fn()
+}
+
+// ignoreMissing treats a collection or index that is already gone as dropped.
+func ignoreMissing(err error) error {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && (serverErr.HasErrorCode(26) || serverErr.HasErrorCode(27)) {
+		return nil
+	}
+	return err
+}
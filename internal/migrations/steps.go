@@ -0,0 +1,158 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// steps are the migrations of the API, in version order. Add new ones at the
+// end; never renumber or change one that has been released.
+var steps = []Migration{
+	{Version: 1, Name: "create_indexes", Up: createIndexes, Down: dropIndexes},
+	{Version: 2, Name: "seed_id_counters", Up: seedCounters, Down: keepCounters},
+}
+
+type collectionIndexes struct {
+	collection string
+	models     []mongo.IndexModel
+}
+
+// indexes are the indexes created by migration 1. Their names are left to
+// MongoDB's defaults, which is what databases created before migrations have.
+var indexes = []collectionIndexes{
+	{"users", []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}}},
+	{"projects", []mongo.IndexModel{{
+		Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+	}}},
+	{"sessions", []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "refresh_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			// Expired sessions are removed by MongoDB's TTL monitor
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}},
+	{"user_tokens", []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}},
+	{"invitations", []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "status", Value: 1}}},
+	}},
+	{"login_attempts", []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}}},
+	{"lockout_events", []mongo.IndexModel{{
+		Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}},
+	}}},
+	{"api_keys", []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	}},
+	// The purge job looks up soft-deleted records by deletion time
+	{"users", []mongo.IndexModel{deletedAtIndex()}},
+	{"projects", []mongo.IndexModel{deletedAtIndex()}},
+	{"service_requests", []mongo.IndexModel{deletedAtIndex()}},
+	{"messages", []mongo.IndexModel{deletedAtIndex()}},
+	{"audit_events", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "resource_type", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}},
+	{"oidc_states", []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}}},
+	{"service_requests", []mongo.IndexModel{{
+		Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+	}}},
+}
+
+func deletedAtIndex() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	}
+}
+
+func createIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, index := range indexes {
+		if _, err := db.Collection(index.collection).Indexes().CreateMany(ctx, index.models); err != nil {
+			return fmt.Errorf("failed to create %s indexes: %w", index.collection, err)
+		}
+	}
+	return nil
+}
+
+func dropIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, index := range indexes {
+		for _, model := range index.models {
+			_, err := db.Collection(index.collection).Indexes().DropOne(ctx, indexName(model.Keys.(bson.D)))
+			if err := ignoreMissing(err); err != nil {
+				return fmt.Errorf("failed to drop %s indexes: %w", index.collection, err)
+			}
+		}
+	}
+	return nil
+}
+
+// indexName returns the name MongoDB gives an index on keys, such as
+// email_1 or name_text_description_text.
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// seedCounters starts the ID counters above the records numbered before
+// they existed, as an import by the admin CLI does.
+func seedCounters(ctx context.Context, db *mongo.Database) error {
+	data := services.NewDataService(
+		repositories.NewUserRepository(db),
+		repositories.NewProjectRepository(db),
+		repositories.NewServiceRequestRepository(db),
+		repositories.NewMessageRepository(db),
+		repositories.NewServiceTypeRepository(db),
+		repositories.NewCounterRepository(db),
+		services.NewAuditService(repositories.NewAuditEventRepository(db)),
+	)
+	_, err := data.SeedCounters()
+	return err
+}
+
+// keepCounters leaves the counters in place: they are in use, and seeding
+// them again only ever raises them.
+func keepCounters(ctx context.Context, db *mongo.Database) error {
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package oidc implements the relying-party side of the OpenID Connect
+// authorization code flow with PKCE.
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// jwksRefreshInterval limits how often an unknown kid triggers a JWKS refetch
+const jwksRefreshInterval = time.Minute
+
+// Config describes the relying party registration at the identity provider.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Discovery is the subset of the provider metadata the flow needs.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Client talks to a single identity provider. Discovery and the provider keys
+// are fetched lazily and cached, so the API starts even if the provider is down.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	discovery   *Discovery
+	keys        map[string]utils.JWK
+	keysFetched time.Time
+}
+
+func NewClient(cfg Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{cfg: cfg, httpClient: httpClient}
+}
+
+// Discover returns the provider metadata, fetching it on first use.
+func (c *Client) Discover() (*Discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	var discovery Discovery
+	wellKnown := strings.TrimSuffix(c.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(wellKnown, &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+
+	if discovery.Issuer != c.cfg.IssuerURL {
+		return nil, fmt.Errorf("oidc discovery returned issuer %q, expected %q", discovery.Issuer, c.cfg.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("oidc discovery document is missing required endpoints")
+	}
+
+	c.discovery = &discovery
+	return c.discovery, nil
+}
+
+// AuthCodeURL builds the authorization request URL for the code flow with an
+// S256 PKCE challenge derived from verifier.
+func (c *Client) AuthCodeURL(state, nonce, verifier string) (string, error) {
+	discovery, err := c.Discover()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.cfg.ClientID)
+	params.Set("redirect_uri", c.cfg.RedirectURL)
+	params.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint.
+func (c *Client) Exchange(code, verifier string) (*TokenResponse, error) {
+	discovery, err := c.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc token endpoint returned %s", resp.Status)
+	}
+
+	var token TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("oidc token response: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc token response has no id_token")
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken checks the signature against the provider's JWKS as well as
+// issuer, audience, expiry and nonce, and returns the token's claims.
+func (c *Client) VerifyIDToken(rawIDToken, nonce string) (jwt.MapClaims, error) {
+	discovery, err := c.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, c.keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(c.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+
+	// With several audiences the token must name us as the authorized party
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.cfg.ClientID {
+			return nil, errors.New("invalid id_token: azp mismatch")
+		}
+	}
+
+	return claims, nil
+}
+
+func (c *Client) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	jwk, err := c.lookupKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if jwk.Alg != "" && jwk.Alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %q is not valid for %s", kid, token.Method.Alg())
+	}
+
+	return jwk.PublicKey()
+}
+
+// lookupKey finds kid in the cached JWKS and refetches the set when the kid is
+// unknown, which is how provider key rotation is picked up.
+func (c *Client) lookupKey(kid string) (utils.JWK, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if jwk, ok := c.findKey(kid); ok {
+		return jwk, nil
+	}
+
+	if time.Since(c.keysFetched) < jwksRefreshInterval {
+		return utils.JWK{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var jwks utils.JWKS
+	if err := c.getJSON(c.discovery.JWKSURI, &jwks); err != nil {
+		return utils.JWK{}, fmt.Errorf("oidc jwks fetch failed: %w", err)
+	}
+
+	c.keys = make(map[string]utils.JWK, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use == "" || jwk.Use == "sig" {
+			c.keys[jwk.Kid] = jwk
+		}
+	}
+	c.keysFetched = time.Now()
+
+	if jwk, ok := c.findKey(kid); ok {
+		return jwk, nil
+	}
+	return utils.JWK{}, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// findKey matches by kid; a token without kid is accepted only when the
+// provider publishes a single key.
+func (c *Client) findKey(kid string) (utils.JWK, bool) {
+	if jwk, ok := c.keys[kid]; ok {
+		return jwk, true
+	}
+	if kid == "" && len(c.keys) == 1 {
+		for _, jwk := range c.keys {
+			return jwk, true
+		}
+	}
+	return utils.JWK{}, false
+}
+
+func (c *Client) getJSON(url string, v interface{}) error {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// CodeChallenge derives the S256 PKCE challenge for verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,321 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Directories read by Generate, relative to the repository root
+const (
+	mainFile       = "cmd/main.go"
+	controllersDir = "internal/controllers"
+)
+
+// Packages whose types annotations may reference, keyed by package name
+var typeDirs = map[string]string{
+	"models": "pkg/models",
+	"utils":  "pkg/utils",
+}
+
+var (
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(true|false)(?:\s+"([^"]*)")?`)
+	responsePattern = regexp.MustCompile(`^(\d{3})(?:\s+\{(\w+)\}\s+(\S+))?(?:\s+"([^"]*)")?`)
+	routerPattern   = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]`)
+	ginParamPattern = regexp.MustCompile(`[:*](\w+)`)
+)
+
+// OpenAPIPath converts a gin route path such as /api/users/:id to the OpenAPI
+// form /api/users/{id}.
+func OpenAPIPath(path string) string {
+	return ginParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+// Generate builds the document from the sources below root.
+func Generate(root string) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: make(map[string]SecurityScheme),
+		},
+	}
+
+	if err := readGeneralInfo(doc, filepath.Join(root, mainFile)); err != nil {
+		return nil, err
+	}
+
+	schemas, err := newSchemaBuilder(root, typeDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseDir(filepath.Join(root, controllersDir))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			if err := addOperations(doc, schemas, fn, tags); err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.Name.Name, err)
+			}
+		}
+	}
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	// Every error response shares the envelope and its problem+json form
+	for _, name := range []string{"utils.Response", "utils.Problem"} {
+		if _, err := schemas.ref(name); err != nil {
+			return nil, err
+		}
+	}
+	doc.Components.Schemas = schemas.components
+	return doc, nil
+}
+
+// readGeneralInfo reads the API-wide annotations on the main package.
+func readGeneralInfo(doc *Document, path string) error {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	var scheme string
+	for _, group := range file.Comments {
+		for _, line := range group.List {
+			key, value := annotation(line.Text)
+			switch key {
+			case "@title":
+				doc.Info.Title = value
+			case "@version":
+				doc.Info.Version = value
+			case "@description":
+				doc.Info.Description = value
+			case "@securityDefinitions.apikey":
+				scheme = value
+				doc.Components.SecuritySchemes[scheme] = SecurityScheme{Type: "apiKey", Description: "Bearer access token or API key, e.g. \"Bearer <token>\""}
+			case "@in", "@name":
+				if scheme == "" {
+					continue
+				}
+				s := doc.Components.SecuritySchemes[scheme]
+				if key == "@in" {
+					s.In = value
+				} else {
+					s.Name = value
+				}
+				doc.Components.SecuritySchemes[scheme] = s
+			}
+		}
+	}
+
+	if doc.Info.Title == "" || doc.Info.Version == "" {
+		return fmt.Errorf("%s: @title and @version are required", path)
+	}
+	return nil
+}
+
+func addOperations(doc *Document, schemas *schemaBuilder, fn *ast.FuncDecl, tags map[string]bool) error {
+	op := &Operation{Responses: make(map[string]Response)}
+	var routes [][2]string
+	consumesJSON := false
+
+	for _, line := range fn.Doc.List {
+		key, value := annotation(line.Text)
+		switch key {
+		case "@Summary":
+			op.Summary = value
+		case "@Description":
+			op.Description = value
+		case "@Tags":
+			for _, tag := range strings.Split(value, ",") {
+				op.Tags = append(op.Tags, strings.TrimSpace(tag))
+				tags[strings.TrimSpace(tag)] = true
+			}
+		case "@Security":
+			op.Security = append(op.Security, map[string][]string{value: {}})
+		case "@Accept":
+			consumesJSON = value == "json"
+		case "@Param":
+			if err := addParam(op, schemas, value); err != nil {
+				return err
+			}
+		case "@Success", "@Failure":
+			m := responsePattern.FindStringSubmatch(value)
+			if m == nil {
+				return fmt.Errorf("malformed %s %q", key, value)
+			}
+			description := m[4]
+			if description == "" {
+				code, _ := strconv.Atoi(m[1])
+				description = http.StatusText(code)
+			}
+			response := Response{Description: description}
+			// Responses without a {type} are not JSON, e.g. the docs page
+			if m[3] != "" {
+				schema, err := schemas.ref(m[3])
+				if err != nil {
+					return err
+				}
+				if m[2] == "array" {
+					schema = &Schema{Type: "array", Items: schema}
+				}
+				response.Content = map[string]MediaType{"application/json": {Schema: schema}}
+			}
+			op.Responses[m[1]] = response
+		case "@Router":
+			m := routerPattern.FindStringSubmatch(value)
+			if m == nil {
+				return fmt.Errorf("malformed @Router %q", value)
+			}
+			routes = append(routes, [2]string{m[1], strings.ToLower(m[2])})
+		}
+	}
+
+	if len(routes) == 0 {
+		return nil
+	}
+	if op.RequestBody != nil && !consumesJSON {
+		return fmt.Errorf("body parameter without @Accept json")
+	}
+
+	op.Responses["default"] = Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			"application/json":         {Schema: &Schema{Ref: componentRef("utils.Response")}},
+			"application/problem+json": {Schema: &Schema{Ref: componentRef("utils.Problem")}},
+		},
+	}
+
+	for _, route := range routes {
+		path, method := route[0], route[1]
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		if _, exists := item[method]; exists {
+			return fmt.Errorf("%s %s is documented twice", strings.ToUpper(method), path)
+		}
+
+		routeOp := *op
+		routeOp.OperationID = operationID(method, path)
+		item[method] = &routeOp
+	}
+	return nil
+}
+
+func addParam(op *Operation, schemas *schemaBuilder, value string) error {
+	m := paramPattern.FindStringSubmatch(value)
+	if m == nil {
+		return fmt.Errorf("malformed @Param %q", value)
+	}
+	name, in, typ, required, description := m[1], m[2], m[3], m[4] == "true", m[5]
+
+	if in == "body" {
+		schema, err := schemas.ref(typ)
+		if err != nil {
+			return err
+		}
+		op.RequestBody = &RequestBody{
+			Description: description,
+			Required:    required,
+			Content:     map[string]MediaType{"application/json": {Schema: schema}},
+		}
+		return nil
+	}
+
+	schema := primitiveSchema(typ)
+	if schema == nil {
+		return fmt.Errorf("unsupported %s parameter type %q", in, typ)
+	}
+	op.Parameters = append(op.Parameters, Parameter{
+		Name:        name,
+		In:          in,
+		Description: description,
+		Required:    required || in == "path",
+		Schema:      schema,
+	})
+	return nil
+}
+
+// annotation splits a comment line such as "// @Tags auth" into its key and value.
+func annotation(comment string) (string, string) {
+	text := strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	if !strings.HasPrefix(text, "@") {
+		return "", ""
+	}
+	key, value, _ := strings.Cut(text, " ")
+	return key, strings.TrimSpace(value)
+}
+
+// operationID derives a stable ID such as getApiUsersId from the route.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '.' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func parseDir(dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func primitiveSchema(typ string) *Schema {
+	switch typ {
+	case "string":
+		return &Schema{Type: "string"}
+	case "int", "integer", "int64":
+		return &Schema{Type: "integer"}
+	case "number", "float64":
+		return &Schema{Type: "number"}
+	case "bool", "boolean":
+		return &Schema{Type: "boolean"}
+	default:
+		return nil
+	}
+}
+
+func componentRef(name string) string {
+	return "#/components/schemas/" + name
+}
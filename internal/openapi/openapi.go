@@ -0,0 +1,135 @@
+// Package openapi builds the OpenAPI 3 document of the API from the swag-style
+// annotations on the controllers and the request and response types they
+// reference. The document is generated into openapi.json and embedded, so the
+// running server needs no access to the sources.
+package openapi
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:generate go run ../../cmd/openapi-gen -root ../.. -out openapi.json
+
+//go:embed openapi.json
+var specJSON []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+//go:embed docs.js
+var docsJS []byte
+
+// JSON returns the generated specification.
+func JSON() []byte {
+	return specJSON
+}
+
+// DocsPage returns the interactive documentation page; it loads DocsScript
+// from /api/docs/init.js.
+func DocsPage() []byte {
+	return docsHTML
+}
+
+// DocsScript returns the script that points the docs page at the specification.
+func DocsScript() []byte {
+	return docsJS
+}
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// HasOperation reports whether the document describes method on path. path
+// uses gin syntax, e.g. /api/users/:id.
+func (d *Document) HasOperation(method, path string) bool {
+	item, ok := d.Paths[OpenAPIPath(path)]
+	if !ok {
+		return false
+	}
+	_, ok = item[strings.ToLower(method)]
+	return ok
+}
@@ -0,0 +1,275 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// schemaBuilder turns Go type declarations into component schemas. Types are
+// read from the AST so that field names, json/form tags and binding rules are
+// taken exactly as the request binding sees them.
+type schemaBuilder struct {
+	types      map[string]map[string]*ast.TypeSpec
+	components map[string]*Schema
+}
+
+func newSchemaBuilder(root string, dirs map[string]string) (*schemaBuilder, error) {
+	b := &schemaBuilder{
+		types:      make(map[string]map[string]*ast.TypeSpec),
+		components: make(map[string]*Schema),
+	}
+
+	for pkg, dir := range dirs {
+		files, err := parseDir(filepath.Join(root, dir))
+		if err != nil {
+			return nil, err
+		}
+
+		specs := make(map[string]*ast.TypeSpec)
+		for _, file := range files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if spec, ok := n.(*ast.TypeSpec); ok {
+					specs[spec.Name.Name] = spec
+				}
+				return true
+			})
+		}
+		b.types[pkg] = specs
+	}
+	return b, nil
+}
+
+// ref returns a reference to the component for a qualified type name such as
+// models.LoginRequest, building the component on first use.
+func (b *schemaBuilder) ref(qualified string) (*Schema, error) {
+	pkg, name, ok := strings.Cut(qualified, ".")
+	if !ok {
+		if schema := primitiveSchema(qualified); schema != nil {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("type %q must be qualified with its package", qualified)
+	}
+
+	spec, ok := b.types[pkg][name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", qualified)
+	}
+
+	if _, ok := spec.Type.(*ast.StructType); !ok {
+		return b.schema(pkg, spec.Type)
+	}
+
+	if _, done := b.components[qualified]; !done {
+		// Reserve the name first so self-referencing types terminate
+		b.components[qualified] = &Schema{}
+		schema, err := b.schema(pkg, spec.Type)
+		if err != nil {
+			return nil, err
+		}
+		b.components[qualified] = schema
+	}
+	return &Schema{Ref: componentRef(qualified)}, nil
+}
+
+func (b *schemaBuilder) schema(pkg string, expr ast.Expr) (*Schema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if schema := goTypeSchema(t.Name); schema != nil {
+			return schema, nil
+		}
+		return b.ref(pkg + "." + t.Name)
+	case *ast.StarExpr:
+		return b.schema(pkg, t.X)
+	case *ast.SelectorExpr:
+		x, _ := t.X.(*ast.Ident)
+		if x == nil {
+			return &Schema{}, nil
+		}
+		qualified := x.Name + "." + t.Sel.Name
+		switch qualified {
+		case "time.Time":
+			return &Schema{Type: "string", Format: "date-time"}, nil
+		case "time.Duration":
+			return &Schema{Type: "integer", Description: "duration in nanoseconds"}, nil
+		case "primitive.ObjectID":
+			return &Schema{Type: "string"}, nil
+		}
+		if _, known := b.types[x.Name]; known {
+			return b.ref(qualified)
+		}
+		return &Schema{}, nil
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := b.schema(pkg, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := b.schema(pkg, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.InterfaceType:
+		return &Schema{}, nil
+	case *ast.StructType:
+		return b.structSchema(pkg, t)
+	default:
+		return nil, fmt.Errorf("unsupported type expression %T", expr)
+	}
+}
+
+func (b *schemaBuilder) structSchema(pkg string, st *ast.StructType) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(raw)
+		}
+
+		// Embedded structs contribute their fields like encoding/json does
+		if len(field.Names) == 0 {
+			embedded, err := b.schema(pkg, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			if embedded.Ref != "" {
+				embedded = b.components[strings.TrimPrefix(embedded.Ref, componentRef(""))]
+			}
+			for name, prop := range embedded.Properties {
+				schema.Properties[name] = prop
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			name := fieldName(ident.Name, tag)
+			if name == "" {
+				continue
+			}
+
+			prop, err := b.schema(pkg, field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ident.Name, err)
+			}
+			if applyBinding(prop, tag.Get("binding")) {
+				schema.Required = append(schema.Required, name)
+			}
+			if field.Doc != nil {
+				prop.Description = strings.TrimSpace(field.Doc.Text())
+			}
+			schema.Properties[name] = prop
+		}
+	}
+	return schema, nil
+}
+
+// fieldName mirrors the name used by the JSON encoder and the query binding.
+func fieldName(goName string, tag reflect.StructTag) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.Split(tag.Get(key), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return goName
+}
+
+// applyBinding copies the validation rules that OpenAPI can express onto the
+// property and reports whether the field is required.
+func applyBinding(prop *Schema, binding string) bool {
+	if binding == "" {
+		return false
+	}
+
+	target := prop
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = target == prop
+		case "dive":
+			// Later rules apply to the elements of a slice
+			if target.Items != nil {
+				target = target.Items
+			}
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "min", "gte", "max", "lte":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			lower := name == "min" || name == "gte"
+			switch target.Type {
+			case "string":
+				if lower {
+					target.MinLength = &n
+				} else {
+					target.MaxLength = &n
+				}
+			case "array":
+				if lower {
+					target.MinItems = &n
+				} else {
+					target.MaxItems = &n
+				}
+			case "integer", "number":
+				v := float64(n)
+				if lower {
+					target.Minimum = &v
+				} else {
+					target.Maximum = &v
+				}
+			}
+		}
+	}
+	return required
+}
+
+func goTypeSchema(name string) *Schema {
+	switch name {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "uint":
+		return &Schema{Type: "integer"}
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	case "any":
+		return &Schema{}
+	default:
+		return nil
+	}
+}
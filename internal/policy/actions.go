@@ -0,0 +1,139 @@
+package policy
+
+const (
+	ProjectCreate       Action = "project:create"
+	ProjectList         Action = "project:list"
+	ProjectRead         Action = "project:read"
+	ProjectUpdate       Action = "project:update"
+	ProjectUpdateStatus Action = "project:update_status"
+	ProjectProgress     Action = "project:progress"
+	ProjectAssign       Action = "project:assign"
+	ProjectDelete       Action = "project:delete"
+	ProjectRestore      Action = "project:restore"
+
+	MessageCreate  Action = "message:create"
+	MessageList    Action = "message:list"
+	MessageRead    Action = "message:read"
+	MessageDelete  Action = "message:delete"
+	MessageRestore Action = "message:restore"
+
+	ServiceRequestCreate  Action = "service_request:create"
+	ServiceRequestList    Action = "service_request:list"
+	ServiceRequestRead    Action = "service_request:read"
+	ServiceRequestUpdate  Action = "service_request:update"
+	ServiceRequestDelete  Action = "service_request:delete"
+	ServiceRequestApprove Action = "service_request:approve"
+	ServiceRequestReject  Action = "service_request:reject"
+	ServiceRequestRestore Action = "service_request:restore"
+
+	UserList             Action = "user:list"
+	UserRead             Action = "user:read"
+	UserUpdate           Action = "user:update"
+	UserUpdateRole       Action = "user:update_role"
+	UserUpdateEmployment Action = "user:update_employment"
+	UserUpdateCompany    Action = "user:update_company"
+	UserDelete           Action = "user:delete"
+	UserDashboard        Action = "user:dashboard"
+	UserRestore          Action = "user:restore"
+
+	EmployeeCreate Action = "employee:create"
+	EmployeeList   Action = "employee:list"
+	EmployeeRead   Action = "employee:read"
+	EmployeeUpdate Action = "employee:update"
+	EmployeeDelete Action = "employee:delete"
+
+	ClientCreate Action = "client:create"
+	ClientList   Action = "client:list"
+	ClientRead   Action = "client:read"
+	ClientUpdate Action = "client:update"
+	ClientDelete Action = "client:delete"
+
+	ServiceTypeCreate Action = "service_type:create"
+	ServiceTypeRead   Action = "service_type:read"
+	ServiceTypeUpdate Action = "service_type:update"
+	ServiceTypeDelete Action = "service_type:delete"
+
+	InvitationManage   Action = "invitation:manage"
+	RegistrationReview Action = "registration:review"
+	LockoutManage      Action = "lockout:manage"
+	APIKeyRevoke       Action = "api_key:revoke"
+	AuditRead          Action = "audit:read"
+)
+
+var knownActions = map[Action]bool{}
+
+func init() {
+	for _, action := range Actions() {
+		knownActions[action] = true
+	}
+}
+
+// Actions returns every action the API checks.
+func Actions() []Action {
+	return []Action{
+		ProjectCreate, ProjectList, ProjectRead, ProjectUpdate, ProjectUpdateStatus, ProjectProgress, ProjectAssign, ProjectDelete, ProjectRestore,
+		MessageCreate, MessageList, MessageRead, MessageDelete, MessageRestore,
+		ServiceRequestCreate, ServiceRequestList, ServiceRequestRead, ServiceRequestUpdate, ServiceRequestDelete, ServiceRequestApprove, ServiceRequestReject, ServiceRequestRestore,
+		UserList, UserRead, UserUpdate, UserUpdateRole, UserUpdateEmployment, UserUpdateCompany, UserDelete, UserDashboard, UserRestore,
+		EmployeeCreate, EmployeeList, EmployeeRead, EmployeeUpdate, EmployeeDelete,
+		ClientCreate, ClientList, ClientRead, ClientUpdate, ClientDelete,
+		ServiceTypeCreate, ServiceTypeRead, ServiceTypeUpdate, ServiceTypeDelete,
+		InvitationManage, RegistrationReview, LockoutManage, APIKeyRevoke, AuditRead,
+	}
+}
+
+// DefaultRoles returns the built-in admin, employee and client roles.
+func DefaultRoles() []RoleDefinition {
+	return []RoleDefinition{
+		{
+			Name:  "admin",
+			Rules: []Rule{{Actions: []Action{AllActions}}},
+		},
+		{
+			Name: "employee",
+			Rules: []Rule{
+				{
+					Actions: []Action{
+						ServiceRequestList, ServiceRequestRead, ServiceRequestUpdate,
+						UserDashboard,
+						EmployeeList, EmployeeRead,
+						ClientCreate, ClientList, ClientRead, ClientUpdate,
+						ServiceTypeRead,
+					},
+				},
+				{
+					Actions: []Action{
+						ProjectList, ProjectRead, ProjectUpdateStatus, ProjectProgress,
+						MessageCreate, MessageList, MessageRead,
+					},
+					When: []Condition{Member},
+				},
+				{Actions: []Action{MessageDelete}, When: []Condition{Own, Member}},
+				{Actions: []Action{UserRead, UserUpdate, UserUpdateCompany, APIKeyRevoke}, When: []Condition{Own}},
+			},
+		},
+		{
+			Name: "client",
+			Rules: []Rule{
+				{
+					Actions: []Action{
+						ServiceRequestCreate,
+						UserDashboard,
+						EmployeeList, EmployeeRead,
+						ServiceTypeRead,
+					},
+				},
+				{
+					Actions: []Action{
+						ProjectList, ProjectRead, ProjectProgress,
+						MessageCreate, MessageList, MessageRead,
+						ServiceRequestList, ServiceRequestRead,
+					},
+					When: []Condition{Client},
+				},
+				{Actions: []Action{MessageDelete}, When: []Condition{Own, Client}},
+				{Actions: []Action{UserRead, UserUpdate, APIKeyRevoke}, When: []Condition{Own}},
+			},
+		},
+	}
+}
@@ -0,0 +1,308 @@
+// Package policy decides whether a subject may perform an action on a
+// resource. Roles are declared as sets of rules; a rule grants actions either
+// unconditionally or only on resources that match all of its conditions.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/vinodhini/software-api/pkg/apperrors"
+)
+
+// ErrAccessDenied is wrapped by every error returned from Authorize.
+var ErrAccessDenied = errors.New("access denied")
+
+// Action names an operation as "<resource>:<verb>".
+type Action string
+
+// Condition restricts a rule to resources related to the subject.
+type Condition string
+
+const (
+	// Own matches resources whose owner is the subject: their profile, the
+	// messages they sent, their API keys.
+	Own Condition = "own"
+	// Client matches projects and service requests of the subject's company.
+	Client Condition = "client"
+	// Member matches projects the subject is assigned to.
+	Member Condition = "member"
+)
+
+// AllActions in a rule grants every action.
+const AllActions Action = "*"
+
+// Subject is the authenticated caller.
+type Subject struct {
+	ID   string
+	Role string
+}
+
+// Resource carries the attributes conditions are evaluated against. Fields
+// that do not apply to a resource type are left empty.
+type Resource struct {
+	OwnerID   string
+	ClientID  string
+	MemberIDs []string
+}
+
+// Rule grants Actions when every condition in When holds. A rule without
+// conditions applies to all resources.
+type Rule struct {
+	Actions []Action    `json:"actions"`
+	When    []Condition `json:"when,omitempty"`
+}
+
+// RoleDefinition declares a role. Rules of inherited roles are included, so a
+// custom role can extend a built-in one.
+type RoleDefinition struct {
+	Name     string   `json:"name"`
+	Inherits []string `json:"inherits,omitempty"`
+	Rules    []Rule   `json:"rules"`
+}
+
+// Document is the format of POLICY_FILE.
+type Document struct {
+	Roles []RoleDefinition `json:"roles"`
+}
+
+// Engine evaluates rules. It is immutable after construction and safe for
+// concurrent use.
+type Engine struct {
+	// grants maps role -> action -> alternative condition sets
+	grants map[string]map[Action][][]Condition
+}
+
+// New builds an engine from role definitions, resolving inheritance. Unknown
+// actions, conditions or parent roles are rejected so that a typo in a policy
+// file fails at startup instead of silently denying access.
+func New(roles []RoleDefinition) (*Engine, error) {
+	defs := make(map[string]RoleDefinition, len(roles))
+	for _, role := range roles {
+		if role.Name == "" {
+			return nil, errors.New("policy: role without a name")
+		}
+		defs[role.Name] = role
+	}
+
+	engine := &Engine{grants: make(map[string]map[Action][][]Condition, len(defs))}
+	for name := range defs {
+		if err := engine.resolve(name, defs, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return engine, nil
+}
+
+func (e *Engine) resolve(name string, defs map[string]RoleDefinition, visiting map[string]bool) error {
+	if _, done := e.grants[name]; done {
+		return nil
+	}
+	if visiting[name] {
+		return fmt.Errorf("policy: role %q inherits from itself", name)
+	}
+	visiting[name] = true
+
+	def, ok := defs[name]
+	if !ok {
+		return fmt.Errorf("policy: unknown role %q", name)
+	}
+
+	grants := make(map[Action][][]Condition)
+	for _, parent := range def.Inherits {
+		if err := e.resolve(parent, defs, visiting); err != nil {
+			return err
+		}
+		for action, alternatives := range e.grants[parent] {
+			grants[action] = append(grants[action], alternatives...)
+		}
+	}
+
+	for _, rule := range def.Rules {
+		for _, condition := range rule.When {
+			if condition != Own && condition != Client && condition != Member {
+				return fmt.Errorf("policy: role %q uses unknown condition %q", name, condition)
+			}
+		}
+
+		actions := rule.Actions
+		for _, action := range actions {
+			if action == AllActions {
+				actions = Actions()
+				break
+			}
+			if !knownActions[action] {
+				return fmt.Errorf("policy: role %q uses unknown action %q", name, action)
+			}
+		}
+		for _, action := range actions {
+			grants[action] = append(grants[action], rule.When)
+		}
+	}
+
+	e.grants[name] = grants
+	return nil
+}
+
+// Default returns the engine for the built-in roles.
+func Default() *Engine {
+	engine, err := New(DefaultRoles())
+	if err != nil {
+		panic(err)
+	}
+	return engine
+}
+
+// Load returns the built-in roles extended by the roles in path. A role in the
+// file with the same name as a built-in one replaces it. An empty path yields
+// the defaults.
+func Load(path string) (*Engine, error) {
+	if path == "" {
+		return Default(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("policy: %s: %w", path, err)
+	}
+
+	roles := DefaultRoles()
+	for _, custom := range doc.Roles {
+		replaced := false
+		for i := range roles {
+			if roles[i].Name == custom.Name {
+				roles[i] = custom
+				replaced = true
+			}
+		}
+		if !replaced {
+			roles = append(roles, custom)
+		}
+	}
+
+	return New(roles)
+}
+
+// HasRole reports whether role is defined.
+func (e *Engine) HasRole(role string) bool {
+	_, ok := e.grants[role]
+	return ok
+}
+
+// Rank orders roles by privilege: it is the number of actions the role is
+// granted, under any condition, and 0 for an unknown role.
+func (e *Engine) Rank(role string) int {
+	return len(e.grants[role])
+}
+
+// Roles returns the defined role names in sorted order.
+func (e *Engine) Roles() []string {
+	names := make([]string, 0, len(e.grants))
+	for name := range e.grants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Permits reports whether the subject may perform action on at least some
+// resources. Route guards use it before the resource has been loaded.
+func (e *Engine) Permits(sub Subject, action Action) bool {
+	return len(e.grants[sub.Role][action]) > 0
+}
+
+// Can reports whether the subject may perform action on res.
+func (e *Engine) Can(sub Subject, action Action, res Resource) bool {
+	for _, conditions := range e.grants[sub.Role][action] {
+		if matches(sub, res, conditions) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize is Can returning an error that wraps ErrAccessDenied.
+func (e *Engine) Authorize(sub Subject, action Action, res Resource) error {
+	if !e.Can(sub, action, res) {
+		return Denied(sub, action)
+	}
+	return nil
+}
+
+// Denied returns the error for a refused action. It is a forbidden
+// apperrors.Error that also matches ErrAccessDenied.
+func Denied(sub Subject, action Action) error {
+	return apperrors.Forbidden("ACCESS_DENIED", "access denied: %s is not allowed to %s", sub.Role, action).Wrap(ErrAccessDenied)
+}
+
+// Scope summarises which resources a subject may act on, for building list
+// queries.
+type Scope struct {
+	// All is set when a rule grants the action without conditions
+	All bool
+	// Conditions holds the conditions of single-condition rules
+	Conditions []Condition
+}
+
+// Allows reports whether resources matching condition are in scope.
+func (s Scope) Allows(condition Condition) bool {
+	if s.All {
+		return true
+	}
+	for _, c := range s.Conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope returns the scope of action for the subject.
+func (e *Engine) Scope(sub Subject, action Action) Scope {
+	var scope Scope
+	for _, conditions := range e.grants[sub.Role][action] {
+		switch len(conditions) {
+		case 0:
+			scope.All = true
+		case 1:
+			scope.Conditions = append(scope.Conditions, conditions[0])
+		}
+	}
+	return scope
+}
+
+func matches(sub Subject, res Resource, conditions []Condition) bool {
+	for _, condition := range conditions {
+		switch condition {
+		case Own:
+			if res.OwnerID == "" || res.OwnerID != sub.ID {
+				return false
+			}
+		case Client:
+			if res.ClientID == "" || res.ClientID != sub.ID {
+				return false
+			}
+		case Member:
+			member := false
+			for _, id := range res.MemberIDs {
+				if id == sub.ID {
+					member = true
+					break
+				}
+			}
+			if !member {
+				return false
+			}
+		}
+	}
+	return true
+}
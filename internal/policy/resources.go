@@ -0,0 +1,26 @@
+package policy
+
+import "github.com/vinodhini/software-api/pkg/models"
+
+// ProjectResource describes a project: its client and assigned employees.
+func ProjectResource(project *models.Project) Resource {
+	return Resource{ClientID: project.ClientID, MemberIDs: project.EmployeeIDs}
+}
+
+// MessageResource describes a message, which belongs to its sender and is
+// visible to everyone with access to its project.
+func MessageResource(message *models.Message, project *models.Project) Resource {
+	res := ProjectResource(project)
+	res.OwnerID = message.SenderID
+	return res
+}
+
+// ServiceRequestResource describes a service request of a client.
+func ServiceRequestResource(request *models.ServiceRequest) Resource {
+	return Resource{ClientID: request.ClientID}
+}
+
+// UserResource describes a user account, owned by the user themself.
+func UserResource(userID string) Resource {
+	return Resource{OwnerID: userID}
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id string) (*models.APIKey, error)
+	FindByHash(hash string) (*models.APIKey, error)
+	ListByUser(userID string) ([]models.APIKey, error)
+	// Revoke stamps a live key as revoked. It returns mongo.ErrNoDocuments
+	// when there is no such key or it is already revoked.
+	Revoke(id string) error
+	TouchLastUsed(id string, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAPIKeyRepository(db *mongo.Database) APIKeyRepository {
+	return &apiKeyRepository{collection: db.Collection("api_keys")}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r *apiKeyRepository) FindByID(id string) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"key_hash": hash}).Decode(&key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByUser(userID string) ([]models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id string, usedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	return err
+}
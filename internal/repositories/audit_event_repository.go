@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditEventRepository interface {
+	Create(event *models.AuditEvent) error
+	List(query *models.AuditQuery) ([]models.AuditEvent, int64, error)
+}
+
+type auditEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditEventRepository(db *mongo.Database) AuditEventRepository {
+	return &auditEventRepository{collection: db.Collection("audit_events")}
+}
+
+func (r *auditEventRepository) Create(event *models.AuditEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
+
+func (r *auditEventRepository) List(query *models.AuditQuery) ([]models.AuditEvent, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if query.ActorID != "" {
+		filter["actor_id"] = query.ActorID
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.ResourceType != "" {
+		filter["resource_type"] = query.ResourceType
+	}
+	if query.ResourceID != "" {
+		filter["resource_id"] = query.ResourceID
+	}
+	if !query.From.IsZero() || !query.To.IsZero() {
+		createdAt := bson.M{}
+		if !query.From.IsZero() {
+			createdAt["$gte"] = query.From
+		}
+		if !query.To.IsZero() {
+			createdAt["$lt"] = query.To
+		}
+		filter["created_at"] = createdAt
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((query.Page - 1) * query.PageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(int64(query.PageSize)).SetSort(bson.M{"created_at": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []models.AuditEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
@@ -10,22 +10,39 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Counter names, one per sequentially numbered entity
+const (
+	UserCounter           = "user_counter"
+	ProjectCounter        = "project_counter"
+	ServiceRequestCounter = "service_request_counter"
+	MessageCounter        = "message_counter"
+)
+
 type CounterRepository interface {
 	GetNextSequence(counterName string) (int, error)
+	// Raise sets the counter to value unless it is already higher
+	Raise(counterName string, value int) error
 }
 
 type counterRepository struct {
 	collection *mongo.Collection
+	// ctx parents every operation; inside a unit of work it carries the session
+	ctx context.Context
 }
 
 func NewCounterRepository(db *mongo.Database) CounterRepository {
+	return newCounterRepository(context.Background(), db)
+}
+
+func newCounterRepository(ctx context.Context, db *mongo.Database) *counterRepository {
 	return &counterRepository{
 		collection: db.Collection("counters"),
+		ctx:        ctx,
 	}
 }
 
 func (r *counterRepository) GetNextSequence(counterName string) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Second)
 	defer cancel()
 
 	// Use findOneAndUpdate for atomic sequence generation
@@ -54,3 +71,15 @@ func (r *counterRepository) GetNextSequence(counterName string) (int, error) {
 
 	return result.Sequence, nil
 }
+
+func (r *counterRepository) Raise(counterName string, value int) error {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": counterName},
+		bson.M{"$max": bson.M{"sequence": value}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
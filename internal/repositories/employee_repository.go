@@ -1,14 +1,14 @@
 package repositories
 
 import (
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/pkg/models"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type EmployeeRepository interface {
 	Create(employee *models.User) error
 	FindByID(id string) (*models.User, error)
-	List(page, pageSize int, search string) ([]models.User, int64, error)
+	List(page, pageSize int, search string, includeDeleted bool) ([]models.User, int64, error)
 }
 
 type employeeRepository struct {
@@ -30,6 +30,6 @@ func (r *employeeRepository) FindByID(id string) (*models.User, error) {
 	return r.userRepo.FindByID(id)
 }
 
-func (r *employeeRepository) List(page, pageSize int, search string) ([]models.User, int64, error) {
-	return r.userRepo.List(page, pageSize, search, string(models.RoleEmployee))
+func (r *employeeRepository) List(page, pageSize int, search string, includeDeleted bool) ([]models.User, int64, error) {
+	return r.userRepo.List(page, pageSize, search, string(models.RoleEmployee), includeDeleted)
 }
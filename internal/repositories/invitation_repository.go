@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InvitationRepository interface {
+	Create(invitation *models.Invitation) error
+	FindByID(id string) (*models.Invitation, error)
+	FindByTokenHash(hash string) (*models.Invitation, error)
+	FindPendingByEmail(email string) (*models.Invitation, error)
+	Update(invitation *models.Invitation) error
+	MarkAccepted(id string, userID string) error
+	List(page, pageSize int, search string, status string) ([]models.Invitation, int64, error)
+}
+
+type invitationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInvitationRepository(db *mongo.Database) InvitationRepository {
+	return &invitationRepository{collection: db.Collection("invitations")}
+}
+
+func (r *invitationRepository) Create(invitation *models.Invitation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invitation.CreatedAt = time.Now()
+	invitation.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, invitation)
+	return err
+}
+
+func (r *invitationRepository) FindByID(id string) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var invitation models.Invitation
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *invitationRepository) FindByTokenHash(hash string) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var invitation models.Invitation
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *invitationRepository) FindPendingByEmail(email string) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var invitation models.Invitation
+	err := r.collection.FindOne(ctx, bson.M{"email": email, "status": models.InvitationStatusPending}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *invitationRepository) Update(invitation *models.Invitation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invitation.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": invitation.ID}, bson.M{"$set": invitation})
+	return err
+}
+
+// MarkAccepted claims a pending invitation for the given user. It fails if the
+// invitation is no longer pending, so an invitation can only be accepted once.
+func (r *invitationRepository) MarkAccepted(id string, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": models.InvitationStatusPending},
+		bson.M{"$set": bson.M{
+			"status":           models.InvitationStatusAccepted,
+			"accepted_user_id": userID,
+			"accepted_at":      now,
+			"updated_at":       now,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("invitation is no longer pending")
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) List(page, pageSize int, search string, status string) ([]models.Invitation, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if search != "" {
+		filter["$or"] = []bson.M{
+			{"email": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"name": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+		}
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(int64(pageSize)).SetSort(bson.M{"created_at": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var invitations []models.Invitation
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, 0, err
+	}
+
+	return invitations, total, nil
+}
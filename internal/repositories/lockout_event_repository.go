@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LockoutEventRepository interface {
+	Create(event *models.LockoutEvent) error
+	List(page, pageSize int, email string, eventType string) ([]models.LockoutEvent, int64, error)
+}
+
+type lockoutEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLockoutEventRepository(db *mongo.Database) LockoutEventRepository {
+	return &lockoutEventRepository{collection: db.Collection("lockout_events")}
+}
+
+func (r *lockoutEventRepository) Create(event *models.LockoutEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
+
+func (r *lockoutEventRepository) List(page, pageSize int, email string, eventType string) ([]models.LockoutEvent, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if email != "" {
+		filter["email"] = email
+	}
+	if eventType != "" {
+		filter["type"] = eventType
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(int64(pageSize)).SetSort(bson.M{"created_at": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []models.LockoutEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoginAttemptRepository interface {
+	FindByEmail(email string) (*models.LoginAttempt, error)
+	RecordFailure(email string, expiresAt time.Time) (*models.LoginAttempt, error)
+	Lock(email string, until time.Time, expiresAt time.Time) error
+	Delete(email string) error
+	ListLocked() ([]models.LoginAttempt, error)
+}
+
+type loginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLoginAttemptRepository(db *mongo.Database) LoginAttemptRepository {
+	return &loginAttemptRepository{collection: db.Collection("login_attempts")}
+}
+
+func (r *loginAttemptRepository) FindByEmail(email string) (*models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempt models.LoginAttempt
+	err := r.collection.FindOne(ctx, bson.M{"_id": email}).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordFailure atomically increments the failure counter for email, creating
+// the document on the first failure, and returns the updated state.
+func (r *loginAttemptRepository) RecordFailure(email string, expiresAt time.Time) (*models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt models.LoginAttempt
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": email},
+		bson.M{
+			"$inc": bson.M{"failed_count": 1},
+			"$set": bson.M{
+				"last_failed_at": now,
+				"expires_at":     expiresAt,
+				"updated_at":     now,
+			},
+		},
+		opts,
+	).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) Lock(email string, until time.Time, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": email}, bson.M{"$set": bson.M{
+		"locked_until": until,
+		"expires_at":   expiresAt,
+		"updated_at":   time.Now(),
+	}})
+	return err
+}
+
+func (r *loginAttemptRepository) Delete(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": email})
+	return err
+}
+
+func (r *loginAttemptRepository) ListLocked() ([]models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"locked_until": -1})
+	cursor, err := r.collection.Find(ctx, bson.M{"locked_until": bson.M{"$gt": time.Now()}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []models.LoginAttempt
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
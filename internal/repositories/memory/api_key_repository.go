@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type apiKeyRepository struct {
+	access
+}
+
+func NewAPIKeyRepository(store *Store) repositories.APIKeyRepository {
+	return &apiKeyRepository{access{store: store}}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	defer r.lock()()
+
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = time.Now()
+	return r.store.apiKeys.insert(key.ID, key)
+}
+
+func (r *apiKeyRepository) FindByID(id string) (*models.APIKey, error) {
+	defer r.lock()()
+
+	key, ok := r.store.apiKeys.find(id)
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	defer r.lock()()
+
+	keys := r.store.apiKeys.filter(func(k *models.APIKey) bool { return k.KeyHash == hash })
+	if len(keys) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &keys[0], nil
+}
+
+func (r *apiKeyRepository) ListByUser(userID string) ([]models.APIKey, error) {
+	defer r.lock()()
+
+	keys := r.store.apiKeys.filter(func(k *models.APIKey) bool { return k.UserID == userID })
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	newestFirst(keys, func(k *models.APIKey) time.Time { return k.CreatedAt })
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(id string) error {
+	defer r.lock()()
+
+	key, ok := r.store.apiKeys.find(id)
+	if !ok || key.RevokedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	key.UpdatedAt = now
+	return r.store.apiKeys.replace(id, key)
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id string, usedAt time.Time) error {
+	defer r.lock()()
+
+	key, ok := r.store.apiKeys.find(id)
+	if !ok {
+		return nil
+	}
+	key.LastUsedAt = &usedAt
+	return r.store.apiKeys.replace(id, key)
+}
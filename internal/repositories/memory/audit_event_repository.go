@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+type auditEventRepository struct {
+	access
+}
+
+func NewAuditEventRepository(store *Store) repositories.AuditEventRepository {
+	return &auditEventRepository{access{store: store}}
+}
+
+func (r *auditEventRepository) Create(event *models.AuditEvent) error {
+	defer r.lock()()
+
+	event.CreatedAt = time.Now()
+	return r.store.auditEvents.insert(event.ID, event)
+}
+
+func (r *auditEventRepository) List(query *models.AuditQuery) ([]models.AuditEvent, int64, error) {
+	defer r.lock()()
+
+	events := r.store.auditEvents.filter(func(e *models.AuditEvent) bool {
+		return (query.ActorID == "" || e.ActorID == query.ActorID) &&
+			(query.Action == "" || string(e.Action) == query.Action) &&
+			(query.ResourceType == "" || e.ResourceType == query.ResourceType) &&
+			(query.ResourceID == "" || e.ResourceID == query.ResourceID) &&
+			(query.From.IsZero() || !e.CreatedAt.Before(query.From)) &&
+			(query.To.IsZero() || e.CreatedAt.Before(query.To))
+	})
+	newestFirst(events, func(e *models.AuditEvent) time.Time { return e.CreatedAt })
+	return paginate(events, query.Page, query.PageSize), int64(len(events)), nil
+}
@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+type counterRepository struct {
+	access
+}
+
+func NewCounterRepository(store *Store) repositories.CounterRepository {
+	return &counterRepository{access{store: store}}
+}
+
+func (r *counterRepository) GetNextSequence(counterName string) (int, error) {
+	defer r.lock()()
+
+	r.store.counters[counterName]++
+	return r.store.counters[counterName], nil
+}
+
+func (r *counterRepository) Raise(counterName string, value int) error {
+	defer r.lock()()
+
+	if r.store.counters[counterName] < value {
+		r.store.counters[counterName] = value
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type invitationRepository struct {
+	access
+}
+
+func NewInvitationRepository(store *Store) repositories.InvitationRepository {
+	return &invitationRepository{access{store: store}}
+}
+
+func (r *invitationRepository) Create(invitation *models.Invitation) error {
+	defer r.lock()()
+
+	invitation.CreatedAt = time.Now()
+	invitation.UpdatedAt = time.Now()
+	return r.store.invitations.insert(invitation.ID, invitation)
+}
+
+func (r *invitationRepository) FindByID(id string) (*models.Invitation, error) {
+	defer r.lock()()
+
+	invitation, ok := r.store.invitations.find(id)
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepository) FindByTokenHash(hash string) (*models.Invitation, error) {
+	return r.findOne(func(i *models.Invitation) bool { return i.TokenHash == hash })
+}
+
+func (r *invitationRepository) FindPendingByEmail(email string) (*models.Invitation, error) {
+	return r.findOne(func(i *models.Invitation) bool {
+		return i.Email == email && i.Status == models.InvitationStatusPending
+	})
+}
+
+func (r *invitationRepository) findOne(match func(*models.Invitation) bool) (*models.Invitation, error) {
+	defer r.lock()()
+
+	invitations := r.store.invitations.filter(match)
+	if len(invitations) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &invitations[0], nil
+}
+
+func (r *invitationRepository) Update(invitation *models.Invitation) error {
+	defer r.lock()()
+
+	invitation.UpdatedAt = time.Now()
+	if _, ok := r.store.invitations.find(invitation.ID); !ok {
+		return nil
+	}
+	return r.store.invitations.replace(invitation.ID, invitation)
+}
+
+func (r *invitationRepository) MarkAccepted(id string, userID string) error {
+	defer r.lock()()
+
+	invitation, ok := r.store.invitations.find(id)
+	if !ok || invitation.Status != models.InvitationStatusPending {
+		return errors.New("invitation is no longer pending")
+	}
+	now := time.Now()
+	invitation.Status = models.InvitationStatusAccepted
+	invitation.AcceptedUserID = userID
+	invitation.AcceptedAt = &now
+	invitation.UpdatedAt = now
+	return r.store.invitations.replace(id, invitation)
+}
+
+func (r *invitationRepository) List(page, pageSize int, search string, status string) ([]models.Invitation, int64, error) {
+	defer r.lock()()
+
+	matches, err := searcher(search)
+	if err != nil {
+		return nil, 0, err
+	}
+	invitations := r.store.invitations.filter(func(i *models.Invitation) bool {
+		return matches(i.Email, i.Name) && (status == "" || string(i.Status) == status)
+	})
+	newestFirst(invitations, func(i *models.Invitation) time.Time { return i.CreatedAt })
+	return paginate(invitations, page, pageSize), int64(len(invitations)), nil
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+type lockoutEventRepository struct {
+	access
+}
+
+func NewLockoutEventRepository(store *Store) repositories.LockoutEventRepository {
+	return &lockoutEventRepository{access{store: store}}
+}
+
+func (r *lockoutEventRepository) Create(event *models.LockoutEvent) error {
+	defer r.lock()()
+
+	event.CreatedAt = time.Now()
+	return r.store.lockoutEvents.insert(event.ID, event)
+}
+
+func (r *lockoutEventRepository) List(page, pageSize int, email string, eventType string) ([]models.LockoutEvent, int64, error) {
+	defer r.lock()()
+
+	events := r.store.lockoutEvents.filter(func(e *models.LockoutEvent) bool {
+		return (email == "" || e.Email == email) && (eventType == "" || string(e.Type) == eventType)
+	})
+	newestFirst(events, func(e *models.LockoutEvent) time.Time { return e.CreatedAt })
+	return paginate(events, page, pageSize), int64(len(events)), nil
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// loginAttemptRepository drops attempts once they expire, as MongoDB's TTL
+// index does.
+type loginAttemptRepository struct {
+	access
+}
+
+func NewLoginAttemptRepository(store *Store) repositories.LoginAttemptRepository {
+	return &loginAttemptRepository{access{store: store}}
+}
+
+func (r *loginAttemptRepository) FindByEmail(email string) (*models.LoginAttempt, error) {
+	defer r.lock()()
+
+	attempt, ok := r.find(email)
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return attempt, nil
+}
+
+// find returns the live attempt for email, removing it if it has expired.
+func (r *loginAttemptRepository) find(email string) (*models.LoginAttempt, bool) {
+	attempt, ok := r.store.loginAttempts.find(email)
+	if ok && time.Now().After(attempt.ExpiresAt) {
+		r.store.loginAttempts.remove(email)
+		return nil, false
+	}
+	return attempt, ok
+}
+
+func (r *loginAttemptRepository) RecordFailure(email string, expiresAt time.Time) (*models.LoginAttempt, error) {
+	defer r.lock()()
+
+	now := time.Now()
+	attempt, ok := r.find(email)
+	if !ok {
+		attempt = &models.LoginAttempt{Email: email}
+		if err := r.store.loginAttempts.insert(email, attempt); err != nil {
+			return nil, err
+		}
+	}
+	attempt.FailedCount++
+	attempt.LastFailedAt = now
+	attempt.ExpiresAt = expiresAt
+	attempt.UpdatedAt = now
+	if err := r.store.loginAttempts.replace(email, attempt); err != nil {
+		return nil, err
+	}
+
+	// Return the stored state, as FindOneAndUpdate does
+	attempt, _ = r.store.loginAttempts.find(email)
+	return attempt, nil
+}
+
+func (r *loginAttemptRepository) Lock(email string, until time.Time, expiresAt time.Time) error {
+	defer r.lock()()
+
+	attempt, ok := r.find(email)
+	if !ok {
+		return nil
+	}
+	attempt.LockedUntil = &until
+	attempt.ExpiresAt = expiresAt
+	attempt.UpdatedAt = time.Now()
+	return r.store.loginAttempts.replace(email, attempt)
+}
+
+func (r *loginAttemptRepository) Delete(email string) error {
+	defer r.lock()()
+
+	r.store.loginAttempts.remove(email)
+	return nil
+}
+
+func (r *loginAttemptRepository) ListLocked() ([]models.LoginAttempt, error) {
+	defer r.lock()()
+
+	now := time.Now()
+	attempts := r.store.loginAttempts.filter(func(a *models.LoginAttempt) bool {
+		return a.LockedUntil != nil && a.LockedUntil.After(now) && now.Before(a.ExpiresAt)
+	})
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].LockedUntil.After(*attempts[j].LockedUntil)
+	})
+	return attempts, nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type messageRepository struct {
+	access
+}
+
+func NewMessageRepository(store *Store) repositories.MessageRepository {
+	return &messageRepository{access{store: store}}
+}
+
+func (r *messageRepository) Create(message *models.Message) error {
+	message.CreatedAt = time.Now()
+	message.UpdatedAt = time.Now()
+	return r.Import(message)
+}
+
+func (r *messageRepository) Import(message *models.Message) error {
+	defer r.lock()()
+
+	return r.store.messages.insert(message.ID, message)
+}
+
+func (r *messageRepository) FindByID(id string) (*models.Message, error) {
+	defer r.lock()()
+
+	message, ok := r.store.messages.find(id)
+	if !ok || message.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if sender, ok := r.store.users.find(message.SenderID); ok {
+		message.Sender = sender
+	}
+	return message, nil
+}
+
+func (r *messageRepository) Delete(id, deletedBy string) error {
+	defer r.lock()()
+
+	message, ok := r.store.messages.find(id)
+	if !ok || message.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	message.DeletedAt = &now
+	message.DeletedBy = deletedBy
+	return r.store.messages.replace(id, message)
+}
+
+func (r *messageRepository) FindDeleted(id string) (*models.Message, error) {
+	defer r.lock()()
+
+	message, ok := r.store.messages.find(id)
+	if !ok || message.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return message, nil
+}
+
+func (r *messageRepository) Restore(id string) (*models.Message, error) {
+	defer r.lock()()
+
+	message, ok := r.store.messages.find(id)
+	if !ok || message.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	restored := *message
+	restored.DeletedAt = nil
+	restored.DeletedBy = ""
+	if err := r.store.messages.replace(id, &restored); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (r *messageRepository) PurgeDeleted(before time.Time) (int64, error) {
+	defer r.lock()()
+
+	var removed int64
+	for _, message := range r.store.messages.all() {
+		if message.DeletedAt != nil && message.DeletedAt.Before(before) {
+			r.store.messages.remove(message.ID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (r *messageRepository) ListByProject(projectID string, page, pageSize int, includeDeleted bool) ([]models.Message, int64, error) {
+	defer r.lock()()
+
+	messages := r.store.messages.filter(func(m *models.Message) bool {
+		return m.ProjectID == projectID && (includeDeleted || m.DeletedAt == nil)
+	})
+	newestFirst(messages, func(m *models.Message) time.Time { return m.CreatedAt })
+
+	listed := paginate(messages, page, pageSize)
+	for i := range listed {
+		if sender, ok := r.store.users.find(listed[i].SenderID); ok {
+			listed[i].Sender = sender
+		}
+	}
+	return listed, int64(len(messages)), nil
+}
+
+func (r *messageRepository) FindByProject(projectID string) ([]models.Message, error) {
+	defer r.lock()()
+
+	return r.store.messages.filter(func(m *models.Message) bool {
+		return m.ProjectID == projectID && m.DeletedAt == nil
+	}), nil
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type oidcStateRepository struct {
+	access
+}
+
+func NewOIDCStateRepository(store *Store) repositories.OIDCStateRepository {
+	return &oidcStateRepository{access{store: store}}
+}
+
+func (r *oidcStateRepository) Create(state *models.OIDCState) error {
+	defer r.lock()()
+
+	state.CreatedAt = time.Now()
+	return r.store.oidcStates.insert(state.ID, state)
+}
+
+func (r *oidcStateRepository) Consume(id string) (*models.OIDCState, error) {
+	defer r.lock()()
+
+	state, ok := r.store.oidcStates.find(id)
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	r.store.oidcStates.remove(id)
+	return state, nil
+}
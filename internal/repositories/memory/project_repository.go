@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type projectRepository struct {
+	access
+}
+
+func NewProjectRepository(store *Store) repositories.ProjectRepository {
+	return &projectRepository{access{store: store}}
+}
+
+func (r *projectRepository) Create(project *models.Project) error {
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = time.Now()
+	project.Version = 1
+	return r.Import(project)
+}
+
+func (r *projectRepository) Import(project *models.Project) error {
+	defer r.lock()()
+
+	if project.EmployeeIDs == nil {
+		project.EmployeeIDs = []string{}
+	}
+	if project.ID == "" {
+		return fmt.Errorf("project ID cannot be empty")
+	}
+	if err := r.store.projects.insert(project.ID, project); err != nil {
+		return fmt.Errorf("failed to insert project: %w", err)
+	}
+	return nil
+}
+
+func (r *projectRepository) FindByID(id string) (*models.Project, error) {
+	defer r.lock()()
+
+	project, ok := r.store.projects.find(id)
+	if !ok || project.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	r.withClient(project)
+
+	// Deleted employees are still listed, as they are by MongoDB
+	for _, user := range r.store.users.all() {
+		for _, employeeID := range project.EmployeeIDs {
+			if user.UserID == employeeID {
+				project.Employees = append(project.Employees, user)
+			}
+		}
+	}
+	return project, nil
+}
+
+// withClient loads the project's client, deleted or not.
+func (r *projectRepository) withClient(project *models.Project) {
+	if client, ok := r.store.users.find(project.ClientID); ok {
+		project.Client = client
+	} else {
+		project.Client = unknownClient(project.ClientID)
+	}
+}
+
+func (r *projectRepository) Update(project *models.Project) error {
+	defer r.lock()()
+
+	stored, ok := r.store.projects.find(project.ID)
+	if !ok || stored.Version != project.Version {
+		return repositories.ErrStaleVersion
+	}
+
+	project.UpdatedAt = time.Now()
+	project.Version++
+	updated := *project
+	// Unset deletion fields are omitted from the MongoDB $set
+	if updated.DeletedAt == nil {
+		updated.DeletedAt = stored.DeletedAt
+		updated.DeletedBy = stored.DeletedBy
+	}
+	if err := r.store.projects.replace(project.ID, &updated); err != nil {
+		project.Version--
+		return err
+	}
+	return nil
+}
+
+func (r *projectRepository) Delete(id, deletedBy string) error {
+	defer r.lock()()
+
+	project, ok := r.store.projects.find(id)
+	if !ok || project.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	project.DeletedAt = &now
+	project.DeletedBy = deletedBy
+	project.Version++
+	return r.store.projects.replace(id, project)
+}
+
+func (r *projectRepository) FindDeleted(id string) (*models.Project, error) {
+	defer r.lock()()
+
+	project, ok := r.store.projects.find(id)
+	if !ok || project.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return project, nil
+}
+
+func (r *projectRepository) Restore(id string) (*models.Project, error) {
+	defer r.lock()()
+
+	project, ok := r.store.projects.find(id)
+	if !ok || project.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	restored := *project
+	restored.DeletedAt = nil
+	restored.DeletedBy = ""
+	restored.Version++
+	if err := r.store.projects.replace(id, &restored); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r *projectRepository) PurgeDeleted(before time.Time) (int64, error) {
+	defer r.lock()()
+
+	var removed int64
+	for _, project := range r.store.projects.all() {
+		if project.DeletedAt != nil && project.DeletedAt.Before(before) {
+			r.store.projects.remove(project.ID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (r *projectRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.Project, int64, error) {
+	return r.list(page, pageSize, search, status, includeDeleted, func(p *models.Project) bool {
+		return clientID == nil || p.ClientID == *clientID
+	})
+}
+
+func (r *projectRepository) ListByEmployee(page, pageSize int, search string, status string, employeeID string, includeDeleted bool) ([]models.Project, int64, error) {
+	return r.list(page, pageSize, search, status, includeDeleted, func(p *models.Project) bool {
+		return hasEmployee(p, employeeID)
+	})
+}
+
+func (r *projectRepository) list(page, pageSize int, search string, status string, includeDeleted bool, keep func(*models.Project) bool) ([]models.Project, int64, error) {
+	defer r.lock()()
+
+	matches, err := searcher(search)
+	if err != nil {
+		return nil, 0, err
+	}
+	projects := r.store.projects.filter(func(p *models.Project) bool {
+		return keep(p) &&
+			matches(p.Name, p.Description, p.ID) &&
+			(status == "" || string(p.Status) == status) &&
+			(includeDeleted || p.DeletedAt == nil)
+	})
+	newestFirst(projects, func(p *models.Project) time.Time { return p.CreatedAt })
+
+	listed := paginate(projects, page, pageSize)
+	for i := range listed {
+		r.withClient(&listed[i])
+	}
+	return listed, int64(len(projects)), nil
+}
+
+func (r *projectRepository) FindByClient(clientID string) ([]models.Project, error) {
+	defer r.lock()()
+
+	return r.store.projects.filter(func(p *models.Project) bool {
+		return p.ClientID == clientID && p.DeletedAt == nil
+	}), nil
+}
+
+func (r *projectRepository) FindByEmployee(employeeID string) ([]models.Project, error) {
+	defer r.lock()()
+
+	return r.store.projects.filter(func(p *models.Project) bool {
+		return hasEmployee(p, employeeID) && p.DeletedAt == nil
+	}), nil
+}
+
+func (r *projectRepository) AssignEmployees(projectID string, employeeIDs []string) error {
+	defer r.lock()()
+
+	project, ok := r.store.projects.find(projectID)
+	if !ok {
+		// An update matching nothing is not an error
+		return nil
+	}
+	project.EmployeeIDs = employeeIDs
+	project.UpdatedAt = time.Now()
+	project.Version++
+	return r.store.projects.replace(projectID, project)
+}
+
+func hasEmployee(project *models.Project, employeeID string) bool {
+	for _, id := range project.EmployeeIDs {
+		if id == employeeID {
+			return true
+		}
+	}
+	return false
+}
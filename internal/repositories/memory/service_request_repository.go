@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type serviceRequestRepository struct {
+	access
+}
+
+func NewServiceRequestRepository(store *Store) repositories.ServiceRequestRepository {
+	return &serviceRequestRepository{access{store: store}}
+}
+
+func (r *serviceRequestRepository) Create(request *models.ServiceRequest) error {
+	request.CreatedAt = time.Now()
+	request.UpdatedAt = time.Now()
+	request.Version = 1
+	return r.Import(request)
+}
+
+func (r *serviceRequestRepository) Import(request *models.ServiceRequest) error {
+	defer r.lock()()
+
+	return r.store.serviceRequests.insert(request.ID, request)
+}
+
+func (r *serviceRequestRepository) FindByID(id string) (*models.ServiceRequest, error) {
+	defer r.lock()()
+
+	request, ok := r.store.serviceRequests.find(id)
+	if !ok || request.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	r.withClient(request)
+
+	if request.ProjectID != nil {
+		if project, ok := r.store.projects.find(*request.ProjectID); ok {
+			request.Project = project
+		}
+	}
+	return request, nil
+}
+
+// withClient loads the request's client, deleted or not.
+func (r *serviceRequestRepository) withClient(request *models.ServiceRequest) {
+	if client, ok := r.store.users.find(request.ClientID); ok {
+		request.Client = client
+	} else {
+		request.Client = unknownClient(request.ClientID)
+	}
+}
+
+func (r *serviceRequestRepository) Update(request *models.ServiceRequest) error {
+	defer r.lock()()
+
+	stored, ok := r.store.serviceRequests.find(request.ID)
+	if !ok || stored.Version != request.Version {
+		return repositories.ErrStaleVersion
+	}
+
+	request.UpdatedAt = time.Now()
+	request.Version++
+	updated := *request
+	// Unset omitempty fields are left out of the MongoDB $set
+	if updated.ProjectID == nil {
+		updated.ProjectID = stored.ProjectID
+	}
+	if updated.DeletedAt == nil {
+		updated.DeletedAt = stored.DeletedAt
+		updated.DeletedBy = stored.DeletedBy
+	}
+	if err := r.store.serviceRequests.replace(request.ID, &updated); err != nil {
+		request.Version--
+		return err
+	}
+	return nil
+}
+
+func (r *serviceRequestRepository) Delete(id, deletedBy string) error {
+	defer r.lock()()
+
+	request, ok := r.store.serviceRequests.find(id)
+	if !ok || request.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	request.DeletedAt = &now
+	request.DeletedBy = deletedBy
+	request.Version++
+	return r.store.serviceRequests.replace(id, request)
+}
+
+func (r *serviceRequestRepository) FindDeleted(id string) (*models.ServiceRequest, error) {
+	defer r.lock()()
+
+	request, ok := r.store.serviceRequests.find(id)
+	if !ok || request.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return request, nil
+}
+
+func (r *serviceRequestRepository) Restore(id string) (*models.ServiceRequest, error) {
+	defer r.lock()()
+
+	request, ok := r.store.serviceRequests.find(id)
+	if !ok || request.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	restored := *request
+	restored.DeletedAt = nil
+	restored.DeletedBy = ""
+	restored.Version++
+	if err := r.store.serviceRequests.replace(id, &restored); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (r *serviceRequestRepository) PurgeDeleted(before time.Time) (int64, error) {
+	defer r.lock()()
+
+	var removed int64
+	for _, request := range r.store.serviceRequests.all() {
+		if request.DeletedAt != nil && request.DeletedAt.Before(before) {
+			r.store.serviceRequests.remove(request.ID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (r *serviceRequestRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.ServiceRequest, int64, error) {
+	defer r.lock()()
+
+	matches, err := searcher(search)
+	if err != nil {
+		return nil, 0, err
+	}
+	requests := r.store.serviceRequests.filter(func(sr *models.ServiceRequest) bool {
+		return matches(sr.Title, sr.Description, sr.ID) &&
+			(status == "" || string(sr.Status) == status) &&
+			(clientID == nil || sr.ClientID == *clientID) &&
+			(includeDeleted || sr.DeletedAt == nil)
+	})
+	newestFirst(requests, func(sr *models.ServiceRequest) time.Time { return sr.CreatedAt })
+
+	listed := paginate(requests, page, pageSize)
+	for i := range listed {
+		r.withClient(&listed[i])
+	}
+	return listed, int64(len(requests)), nil
+}
+
+func (r *serviceRequestRepository) FindByClient(clientID string) ([]models.ServiceRequest, error) {
+	defer r.lock()()
+
+	return r.store.serviceRequests.filter(func(sr *models.ServiceRequest) bool {
+		return sr.ClientID == clientID && sr.DeletedAt == nil
+	}), nil
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type serviceTypeRepository struct {
+	access
+}
+
+func NewServiceTypeRepository(store *Store) repositories.ServiceTypeRepository {
+	return &serviceTypeRepository{access{store: store}}
+}
+
+func (r *serviceTypeRepository) Create(serviceType *models.ServiceType) error {
+	serviceType.ID = primitive.NewObjectID().Hex()
+	serviceType.CreatedAt = time.Now()
+	serviceType.UpdatedAt = time.Now()
+	return r.Import(serviceType)
+}
+
+func (r *serviceTypeRepository) Import(serviceType *models.ServiceType) error {
+	defer r.lock()()
+
+	return r.store.serviceTypes.insert(serviceType.ID, serviceType)
+}
+
+func (r *serviceTypeRepository) GetByID(id string) (*models.ServiceType, error) {
+	defer r.lock()()
+
+	serviceType, ok := r.store.serviceTypes.find(id)
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return serviceType, nil
+}
+
+func (r *serviceTypeRepository) GetAll(status *string) ([]models.ServiceType, error) {
+	defer r.lock()()
+
+	return r.store.serviceTypes.filter(func(st *models.ServiceType) bool {
+		return status == nil || string(st.Status) == *status
+	}), nil
+}
+
+func (r *serviceTypeRepository) Update(id string, serviceType *models.ServiceType) error {
+	defer r.lock()()
+
+	stored, ok := r.store.serviceTypes.find(id)
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	serviceType.UpdatedAt = time.Now()
+	stored.Name = serviceType.Name
+	stored.Description = serviceType.Description
+	stored.Status = serviceType.Status
+	stored.UpdatedAt = serviceType.UpdatedAt
+	return r.store.serviceTypes.replace(id, stored)
+}
+
+func (r *serviceTypeRepository) Delete(id string) error {
+	defer r.lock()()
+
+	if !r.store.serviceTypes.remove(id) {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
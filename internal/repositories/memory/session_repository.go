@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type sessionRepository struct {
+	access
+}
+
+func NewSessionRepository(store *Store) repositories.SessionRepository {
+	return &sessionRepository{access{store: store}}
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	defer r.lock()()
+
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	return r.store.sessions.insert(session.ID, session)
+}
+
+func (r *sessionRepository) FindByID(id string) (*models.Session, error) {
+	defer r.lock()()
+
+	session, ok := r.store.sessions.find(id)
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) FindByRefreshTokenHash(hash string) (*models.Session, error) {
+	defer r.lock()()
+
+	sessions := r.store.sessions.filter(func(s *models.Session) bool { return s.RefreshTokenHash == hash })
+	if len(sessions) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &sessions[0], nil
+}
+
+func (r *sessionRepository) Revoke(id string, replacedBy string) error {
+	defer r.lock()()
+
+	session, ok := r.store.sessions.find(id)
+	if !ok || session.RevokedAt != nil {
+		return errors.New("session not found or already revoked")
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.UpdatedAt = now
+	if replacedBy != "" {
+		session.ReplacedBy = replacedBy
+	}
+	return r.store.sessions.replace(id, session)
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID string) error {
+	defer r.lock()()
+
+	now := time.Now()
+	for _, session := range r.store.sessions.all() {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			session.UpdatedAt = now
+			if err := r.store.sessions.replace(session.ID, &session); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
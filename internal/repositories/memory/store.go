@@ -0,0 +1,252 @@
+// Package memory implements every repository in process memory. It keeps the
+// behaviour of the MongoDB repositories, down to the errors they return, so
+// the API can run and be tested without a database. Nothing is persisted.
+package memory
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Store holds the collections shared by the repositories of one backend.
+type Store struct {
+	mu sync.Mutex
+
+	users           *table[models.User]
+	projects        *table[models.Project]
+	serviceRequests *table[models.ServiceRequest]
+	messages        *table[models.Message]
+	serviceTypes    *table[models.ServiceType]
+	sessions        *table[models.Session]
+	userTokens      *table[models.UserToken]
+	invitations     *table[models.Invitation]
+	loginAttempts   *table[models.LoginAttempt]
+	lockoutEvents   *table[models.LockoutEvent]
+	apiKeys         *table[models.APIKey]
+	oidcStates      *table[models.OIDCState]
+	auditEvents     *table[models.AuditEvent]
+	counters        map[string]int
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:           newTable[models.User](),
+		projects:        newTable[models.Project](),
+		serviceRequests: newTable[models.ServiceRequest](),
+		messages:        newTable[models.Message](),
+		serviceTypes:    newTable[models.ServiceType](),
+		sessions:        newTable[models.Session](),
+		userTokens:      newTable[models.UserToken](),
+		invitations:     newTable[models.Invitation](),
+		loginAttempts:   newTable[models.LoginAttempt](),
+		lockoutEvents:   newTable[models.LockoutEvent](),
+		apiKeys:         newTable[models.APIKey](),
+		oidcStates:      newTable[models.OIDCState](),
+		auditEvents:     newTable[models.AuditEvent](),
+		counters:        make(map[string]int),
+	}
+}
+
+// NewRepositories returns every repository backed by a new, empty store.
+func NewRepositories() *repositories.Repositories {
+	store := NewStore()
+	users := NewUserRepository(store)
+	return &repositories.Repositories{
+		Users:           users,
+		Employees:       NewEmployeeRepository(users),
+		Projects:        NewProjectRepository(store),
+		ServiceRequests: NewServiceRequestRepository(store),
+		Messages:        NewMessageRepository(store),
+		Counters:        NewCounterRepository(store),
+		ServiceTypes:    NewServiceTypeRepository(store),
+		Sessions:        NewSessionRepository(store),
+		UserTokens:      NewUserTokenRepository(store),
+		Invitations:     NewInvitationRepository(store),
+		LoginAttempts:   NewLoginAttemptRepository(store),
+		LockoutEvents:   NewLockoutEventRepository(store),
+		APIKeys:         NewAPIKeyRepository(store),
+		OIDCStates:      NewOIDCStateRepository(store),
+		AuditEvents:     NewAuditEventRepository(store),
+		UnitOfWork:      NewUnitOfWork(store),
+	}
+}
+
+// access is embedded by every repository. Each call holds the store lock;
+// repositories handed out by a unit of work run while it already holds it.
+type access struct {
+	store *Store
+	held  bool
+}
+
+// lock takes the store lock and returns its release, for use as
+// defer r.lock()().
+func (a access) lock() func() {
+	if a.held {
+		return func() {}
+	}
+	a.store.mu.Lock()
+	return a.store.mu.Unlock
+}
+
+// table is one collection. Documents are kept BSON encoded, keyed by _id, so
+// callers never share memory with the store and records come back exactly as
+// they would from MongoDB: fields tagged bson:"-" are dropped and times are
+// truncated to milliseconds.
+type table[T any] struct {
+	docs map[string]document
+	seq  int
+}
+
+type document struct {
+	raw []byte
+	// seq is the insertion order, MongoDB's natural order for unsorted reads
+	seq int
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{docs: make(map[string]document)}
+}
+
+// insert stores v under id and fails like a duplicate key when id is taken.
+func (t *table[T]) insert(id string, v *T) error {
+	if _, ok := t.docs[id]; ok {
+		return fmt.Errorf("duplicate key: _id %q", id)
+	}
+	t.seq++
+	return t.write(id, v, t.seq)
+}
+
+// replace overwrites the document stored under id.
+func (t *table[T]) replace(id string, v *T) error {
+	return t.write(id, v, t.docs[id].seq)
+}
+
+func (t *table[T]) write(id string, v *T, seq int) error {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.docs[id] = document{raw: raw, seq: seq}
+	return nil
+}
+
+func (t *table[T]) find(id string) (*T, bool) {
+	doc, ok := t.docs[id]
+	if !ok {
+		return nil, false
+	}
+	return decode[T](doc.raw), true
+}
+
+func (t *table[T]) remove(id string) bool {
+	_, ok := t.docs[id]
+	delete(t.docs, id)
+	return ok
+}
+
+// all returns every document in insertion order.
+func (t *table[T]) all() []T {
+	docs := make([]document, 0, len(t.docs))
+	for _, doc := range t.docs {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].seq < docs[j].seq })
+
+	out := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, *decode[T](doc.raw))
+	}
+	return out
+}
+
+// filter returns the documents, in insertion order, for which keep is true.
+func (t *table[T]) filter(keep func(*T) bool) []T {
+	var out []T
+	for _, v := range t.all() {
+		if keep(&v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// clone copies the table; documents are immutable, so they are shared.
+func (t *table[T]) clone() *table[T] {
+	c := &table[T]{docs: make(map[string]document, len(t.docs)), seq: t.seq}
+	for id, doc := range t.docs {
+		c.docs[id] = doc
+	}
+	return c
+}
+
+func decode[T any](raw []byte) *T {
+	var v T
+	if err := bson.Unmarshal(raw, &v); err != nil {
+		// Only values written by Marshal are ever decoded
+		panic(err)
+	}
+	return &v
+}
+
+// newestFirst sorts records by creation time, latest first, as the MongoDB
+// listings do.
+func newestFirst[T any](items []T, createdAt func(*T) time.Time) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return createdAt(&items[i]).After(createdAt(&items[j]))
+	})
+}
+
+// paginate returns one page of items; a zero pageSize means no limit, as with
+// MongoDB's SetLimit.
+func paginate[T any](items []T, page, pageSize int) []T {
+	skip := (page - 1) * pageSize
+	if skip < 0 {
+		skip = 0
+	}
+	if skip >= len(items) {
+		return nil
+	}
+	items = items[skip:]
+	if pageSize > 0 && pageSize < len(items) {
+		items = items[:pageSize]
+	}
+	return items
+}
+
+// searcher matches the case-insensitive $regex filters of the listings, which
+// take the search term literally. An empty search matches everything.
+func searcher(search string) (func(fields ...string) bool, error) {
+	if search == "" {
+		return func(...string) bool { return true }, nil
+	}
+	re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(search))
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	return func(fields ...string) bool {
+		for _, field := range fields {
+			if re.MatchString(field) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// unknownClient stands in for a client that no longer exists, as in the
+// MongoDB repositories.
+func unknownClient(id string) *models.User {
+	return &models.User{
+		UserID: id,
+		Name:   "Unknown Client",
+		Email:  "unknown@example.com",
+		Role:   models.RoleClient,
+	}
+}
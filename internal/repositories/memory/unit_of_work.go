@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+// unitOfWork runs fn with the store locked, so no other write interleaves,
+// and puts the tables it may touch back as they were when fn fails.
+type unitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) repositories.UnitOfWork {
+	return &unitOfWork{store: store}
+}
+
+func (u *unitOfWork) Do(fn func(tx repositories.Tx) error) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	counters := make(map[string]int, len(u.store.counters))
+	for name, sequence := range u.store.counters {
+		counters[name] = sequence
+	}
+	projects := u.store.projects.clone()
+	serviceRequests := u.store.serviceRequests.clone()
+
+	if err := fn(memoryTx{access{store: u.store, held: true}}); err != nil {
+		u.store.counters = counters
+		u.store.projects = projects
+		u.store.serviceRequests = serviceRequests
+		return err
+	}
+	return nil
+}
+
+type memoryTx struct {
+	access access
+}
+
+func (t memoryTx) Counters() repositories.CounterRepository {
+	return &counterRepository{t.access}
+}
+
+func (t memoryTx) Projects() repositories.ProjectRepository {
+	return &projectRepository{t.access}
+}
+
+func (t memoryTx) ServiceRequests() repositories.ServiceRequestRepository {
+	return &serviceRequestRepository{t.access}
+}
@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type userRepository struct {
+	access
+}
+
+func NewUserRepository(store *Store) repositories.UserRepository {
+	return &userRepository{access{store: store}}
+}
+
+func (r *userRepository) Create(user *models.User) error {
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+	user.Version = 1
+	return r.Import(user)
+}
+
+func (r *userRepository) Import(user *models.User) error {
+	defer r.lock()()
+
+	// Stands in for the unique index on email
+	for _, existing := range r.store.users.all() {
+		if existing.Email == user.Email {
+			return fmt.Errorf("duplicate key: email %q", user.Email)
+		}
+	}
+	return r.store.users.insert(user.UserID, user)
+}
+
+func (r *userRepository) FindByID(id string) (*models.User, error) {
+	defer r.lock()()
+
+	user, ok := r.store.users.find(id)
+	if !ok || user.DeletedAt != nil {
+		return &models.User{}, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+func (r *userRepository) FindByUserID(userID string) (*models.User, error) {
+	return r.FindByID(userID)
+}
+
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	defer r.lock()()
+
+	users := r.store.users.filter(func(u *models.User) bool {
+		return u.Email == email && u.DeletedAt == nil
+	})
+	if len(users) == 0 {
+		return &models.User{}, mongo.ErrNoDocuments
+	}
+	return &users[0], nil
+}
+
+func (r *userRepository) EmailExists(email string) (bool, error) {
+	defer r.lock()()
+
+	users := r.store.users.filter(func(u *models.User) bool { return u.Email == email })
+	return len(users) > 0, nil
+}
+
+func (r *userRepository) Update(user *models.User) error {
+	defer r.lock()()
+
+	stored, ok := r.store.users.find(user.UserID)
+	if !ok || stored.Version != user.Version {
+		return repositories.ErrStaleVersion
+	}
+
+	// Every field but the key, creation time and deletion stamp is written
+	user.UpdatedAt = time.Now()
+	updated := *user
+	updated.Version = stored.Version + 1
+	updated.CreatedAt = stored.CreatedAt
+	updated.DeletedAt = stored.DeletedAt
+	updated.DeletedBy = stored.DeletedBy
+	if err := r.store.users.replace(user.UserID, &updated); err != nil {
+		return err
+	}
+	user.Version++
+	return nil
+}
+
+func (r *userRepository) Delete(id, deletedBy string) error {
+	defer r.lock()()
+
+	user, ok := r.store.users.find(id)
+	if !ok || user.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	user.DeletedAt = &now
+	user.DeletedBy = deletedBy
+	user.Version++
+	return r.store.users.replace(id, user)
+}
+
+func (r *userRepository) Restore(id string) (*models.User, error) {
+	defer r.lock()()
+
+	user, ok := r.store.users.find(id)
+	if !ok || user.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	restored := *user
+	restored.DeletedAt = nil
+	restored.DeletedBy = ""
+	restored.Version++
+	if err := r.store.users.replace(id, &restored); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) PurgeDeleted(before time.Time) (int64, error) {
+	defer r.lock()()
+
+	// Purging a client that still owns records would bring back the
+	// "Unknown Client" placeholders
+	referenced := make(map[string]bool)
+	for _, project := range r.store.projects.all() {
+		referenced[project.ClientID] = true
+	}
+	for _, request := range r.store.serviceRequests.all() {
+		referenced[request.ClientID] = true
+	}
+
+	var removed int64
+	for _, user := range r.store.users.all() {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) && !referenced[user.UserID] {
+			r.store.users.remove(user.UserID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (r *userRepository) List(page, pageSize int, search string, role string, includeDeleted bool) ([]models.User, int64, error) {
+	defer r.lock()()
+
+	matches, err := searcher(search)
+	if err != nil {
+		return nil, 0, err
+	}
+	users := r.store.users.filter(func(u *models.User) bool {
+		return matches(u.Name, u.Email, u.Company) &&
+			(role == "" || string(u.Role) == role) &&
+			(includeDeleted || u.DeletedAt == nil)
+	})
+	return paginate(users, page, pageSize), int64(len(users)), nil
+}
+
+func (r *userRepository) ListByStatus(status string, page, pageSize int) ([]models.User, int64, error) {
+	defer r.lock()()
+
+	users := r.store.users.filter(func(u *models.User) bool {
+		return u.Status == status && u.DeletedAt == nil
+	})
+	return paginate(users, page, pageSize), int64(len(users)), nil
+}
+
+// employeeRepository narrows the user repository to employees, as the MongoDB
+// one does.
+type employeeRepository struct {
+	userRepo repositories.UserRepository
+}
+
+func NewEmployeeRepository(userRepo repositories.UserRepository) repositories.EmployeeRepository {
+	return &employeeRepository{userRepo: userRepo}
+}
+
+func (r *employeeRepository) Create(employee *models.User) error {
+	employee.Role = models.RoleEmployee
+	return r.userRepo.Create(employee)
+}
+
+func (r *employeeRepository) FindByID(id string) (*models.User, error) {
+	return r.userRepo.FindByID(id)
+}
+
+func (r *employeeRepository) List(page, pageSize int, search string, includeDeleted bool) ([]models.User, int64, error) {
+	return r.userRepo.List(page, pageSize, search, string(models.RoleEmployee), includeDeleted)
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type userTokenRepository struct {
+	access
+}
+
+func NewUserTokenRepository(store *Store) repositories.UserTokenRepository {
+	return &userTokenRepository{access{store: store}}
+}
+
+func (r *userTokenRepository) Create(token *models.UserToken) error {
+	defer r.lock()()
+
+	token.CreatedAt = time.Now()
+	return r.store.userTokens.insert(token.ID, token)
+}
+
+func (r *userTokenRepository) FindByHash(purpose models.TokenPurpose, hash string) (*models.UserToken, error) {
+	defer r.lock()()
+
+	tokens := r.store.userTokens.filter(func(t *models.UserToken) bool {
+		return t.Purpose == purpose && t.TokenHash == hash
+	})
+	if len(tokens) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &tokens[0], nil
+}
+
+func (r *userTokenRepository) MarkUsed(id string) error {
+	defer r.lock()()
+
+	token, ok := r.store.userTokens.find(id)
+	if !ok || token.UsedAt != nil {
+		return errors.New("token already used")
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return r.store.userTokens.replace(id, token)
+}
+
+func (r *userTokenRepository) DeleteForUser(userID string, purpose models.TokenPurpose) error {
+	defer r.lock()()
+
+	for _, token := range r.store.userTokens.all() {
+		if token.UserID == userID && token.Purpose == purpose {
+			r.store.userTokens.remove(token.ID)
+		}
+	}
+	return nil
+}
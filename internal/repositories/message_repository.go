@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -12,9 +12,19 @@ import (
 
 type MessageRepository interface {
 	Create(message *models.Message) error
+	// Import stores an exported message as it was, keeping its timestamps
+	// and deletion stamp
+	Import(message *models.Message) error
 	FindByID(id string) (*models.Message, error)
-	Delete(id string) error
-	ListByProject(projectID string, page, pageSize int) ([]models.Message, int64, error)
+	Delete(id, deletedBy string) error
+	// FindDeleted returns a deleted message as stored
+	FindDeleted(id string) (*models.Message, error)
+	// Restore undeletes a message and returns the record as it was while deleted
+	Restore(id string) (*models.Message, error)
+	PurgeDeleted(before time.Time) (int64, error)
+	ListByProject(projectID string, page, pageSize int, includeDeleted bool) ([]models.Message, int64, error)
+	// FindByProject returns every live message of a project
+	FindByProject(projectID string) ([]models.Message, error)
 }
 
 type messageRepository struct {
@@ -30,12 +40,15 @@ func NewMessageRepository(db *mongo.Database) MessageRepository {
 }
 
 func (r *messageRepository) Create(message *models.Message) error {
+	message.CreatedAt = time.Now()
+	message.UpdatedAt = time.Now()
+	return r.Import(message)
+}
+
+func (r *messageRepository) Import(message *models.Message) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	message.CreatedAt = time.Now()
-	message.UpdatedAt = time.Now()
-	
 	// Create document with explicit _id to ensure our custom ID is used
 	doc := bson.M{
 		"_id":         message.ID,
@@ -45,6 +58,10 @@ func (r *messageRepository) Create(message *models.Message) error {
 		"created_at":   message.CreatedAt,
 		"updated_at":   message.UpdatedAt,
 	}
+	if message.DeletedAt != nil {
+		doc["deleted_at"] = message.DeletedAt
+		doc["deleted_by"] = message.DeletedBy
+	}
 	
 	_, err := r.collection.InsertOne(ctx, doc)
 	return err
@@ -55,7 +72,7 @@ func (r *messageRepository) FindByID(id string) (*models.Message, error) {
 	defer cancel()
 
 	var message models.Message
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&message)
+	err := r.collection.FindOne(ctx, excludeDeleted(bson.M{"_id": id}, false)).Decode(&message)
 	if err != nil {
 		return nil, err
 	}
@@ -68,20 +85,35 @@ func (r *messageRepository) FindByID(id string) (*models.Message, error) {
 	return &message, nil
 }
 
-func (r *messageRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (r *messageRepository) Delete(id, deletedBy string) error {
+	return softDelete(context.Background(), r.collection, id, deletedBy)
+}
 
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (r *messageRepository) FindDeleted(id string) (*models.Message, error) {
+	var message models.Message
+	if err := findDeleted(context.Background(), r.collection, id, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *messageRepository) Restore(id string) (*models.Message, error) {
+	var message models.Message
+	if err := restoreDeleted(context.Background(), r.collection, id, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
 }
 
+func (r *messageRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted(context.Background(), r.collection, before, bson.M{})
+}
 
-func (r *messageRepository) ListByProject(projectID string, page, pageSize int) ([]models.Message, int64, error) {
+func (r *messageRepository) ListByProject(projectID string, page, pageSize int, includeDeleted bool) ([]models.Message, int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"project_id": projectID}
+	filter := excludeDeleted(bson.M{"project_id": projectID}, includeDeleted)
 
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -111,3 +143,11 @@ func (r *messageRepository) ListByProject(projectID string, page, pageSize int)
 
 	return messages, total, nil
 }
+
+func (r *messageRepository) FindByProject(projectID string) ([]models.Message, error) {
+	var messages []models.Message
+	if err := findLive(context.Background(), r.collection, bson.M{"project_id": projectID}, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
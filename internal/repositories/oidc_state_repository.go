@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type OIDCStateRepository interface {
+	Create(state *models.OIDCState) error
+	Consume(id string) (*models.OIDCState, error)
+}
+
+type oidcStateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOIDCStateRepository(db *mongo.Database) OIDCStateRepository {
+	return &oidcStateRepository{collection: db.Collection("oidc_states")}
+}
+
+func (r *oidcStateRepository) Create(state *models.OIDCState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, state)
+	return err
+}
+
+// Consume deletes and returns the state in one step so a callback can only be
+// completed once.
+func (r *oidcStateRepository) Consume(id string) (*models.OIDCState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var state models.OIDCState
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
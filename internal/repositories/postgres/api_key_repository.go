@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at,
+	updated_at`
+
+func scanAPIKey(row scanner) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, pq.Array(&key.Scopes),
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt, &key.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+type apiKeyRepository struct {
+	db querier
+}
+
+func NewAPIKeyRepository(db *sql.DB) repositories.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO api_keys (`+apiKeyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes),
+		key.ExpiresAt, key.LastUsedAt, key.RevokedAt, key.CreatedAt, key.UpdatedAt)
+	return err
+}
+
+func (r *apiKeyRepository) FindByID(id string) (*models.APIKey, error) {
+	return r.findOne("id = $1", id)
+}
+
+func (r *apiKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	return r.findOne("key_hash = $1", hash)
+}
+
+func (r *apiKeyRepository) findOne(where string, args ...interface{}) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE "+where, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) ListByUser(userID string) ([]models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := queryAll(ctx, r.db, scanAPIKey,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC, id DESC", userID)
+	if keys == nil && err == nil {
+		keys = []models.APIKey{}
+	}
+	return keys, err
+}
+
+func (r *apiKeyRepository) Revoke(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = $2, updated_at = $2 WHERE id = $1 AND revoked_at IS NULL", id, time.Now())
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id string, usedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $2 WHERE id = $1", id, usedAt)
+	return err
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const auditEventColumns = `id, actor_id, actor_role, action, resource_type, resource_id, changes, ip, request_id,
+	created_at`
+
+func scanAuditEvent(row scanner) (*models.AuditEvent, error) {
+	var event models.AuditEvent
+	var changes []byte
+	err := row.Scan(&event.ID, &event.ActorID, &event.ActorRole, &event.Action, &event.ResourceType,
+		&event.ResourceID, &changes, &event.IP, &event.RequestID, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if changes != nil {
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, err
+		}
+	}
+	return &event, nil
+}
+
+type auditEventRepository struct {
+	db querier
+}
+
+func NewAuditEventRepository(db *sql.DB) repositories.AuditEventRepository {
+	return &auditEventRepository{db: db}
+}
+
+func (r *auditEventRepository) Create(event *models.AuditEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event.CreatedAt = time.Now()
+
+	// A nil interface, not an empty []byte, is what stores NULL
+	var changes interface{}
+	if len(event.Changes) > 0 {
+		encoded, err := json.Marshal(event.Changes)
+		if err != nil {
+			return err
+		}
+		changes = string(encoded)
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO audit_events (`+auditEventColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		event.ID, event.ActorID, event.ActorRole, event.Action, event.ResourceType, event.ResourceID,
+		changes, event.IP, event.RequestID, event.CreatedAt)
+	return err
+}
+
+func (r *auditEventRepository) List(query *models.AuditQuery) ([]models.AuditEvent, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	if query.ActorID != "" {
+		c.add("actor_id = ?", query.ActorID)
+	}
+	if query.Action != "" {
+		c.add("action = ?", query.Action)
+	}
+	if query.ResourceType != "" {
+		c.add("resource_type = ?", query.ResourceType)
+	}
+	if query.ResourceID != "" {
+		c.add("resource_id = ?", query.ResourceID)
+	}
+	if !query.From.IsZero() {
+		c.add("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		c.add("created_at < ?", query.To)
+	}
+	return list(ctx, r.db, scanAuditEvent, auditEventColumns+" FROM audit_events", "audit_events", "created_at DESC, id DESC", &c, query.Page, query.PageSize)
+}
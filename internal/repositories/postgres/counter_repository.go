@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+type counterRepository struct {
+	db querier
+}
+
+func NewCounterRepository(db *sql.DB) repositories.CounterRepository {
+	return newCounterRepository(db)
+}
+
+// newCounterRepository returns a counter repository running on db, which may
+// be a transaction.
+func newCounterRepository(db querier) repositories.CounterRepository {
+	return &counterRepository{db: db}
+}
+
+// GetNextSequence increments the counter in one statement, so concurrent
+// callers never get the same value.
+func (r *counterRepository) GetNextSequence(counterName string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var seq int
+	err := r.db.QueryRowContext(ctx, `INSERT INTO counters (name, seq) VALUES ($1, 1)
+		ON CONFLICT (name) DO UPDATE SET seq = counters.seq + 1
+		RETURNING seq`, counterName).Scan(&seq)
+	return seq, err
+}
+
+func (r *counterRepository) Raise(counterName string, value int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO counters (name, seq) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET seq = GREATEST(counters.seq, EXCLUDED.seq)`, counterName, value)
+	return err
+}
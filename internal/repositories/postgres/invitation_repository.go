@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const invitationColumns = `id, email, role, name, phone, department, company, address, salary, token_hash, status,
+	invited_by, accepted_user_id, expires_at, accepted_at, created_at, updated_at`
+
+func scanInvitation(row scanner) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := row.Scan(&invitation.ID, &invitation.Email, &invitation.Role, &invitation.Name, &invitation.Phone,
+		&invitation.Department, &invitation.Company, &invitation.Address, &invitation.Salary,
+		&invitation.TokenHash, &invitation.Status, &invitation.InvitedBy, &invitation.AcceptedUserID,
+		&invitation.ExpiresAt, &invitation.AcceptedAt, &invitation.CreatedAt, &invitation.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+type invitationRepository struct {
+	db querier
+}
+
+func NewInvitationRepository(db *sql.DB) repositories.InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(invitation *models.Invitation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invitation.CreatedAt = time.Now()
+	invitation.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO invitations (`+invitationColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		invitation.ID, invitation.Email, invitation.Role, invitation.Name, invitation.Phone,
+		invitation.Department, invitation.Company, invitation.Address, invitation.Salary,
+		invitation.TokenHash, invitation.Status, invitation.InvitedBy, invitation.AcceptedUserID,
+		invitation.ExpiresAt, invitation.AcceptedAt, invitation.CreatedAt, invitation.UpdatedAt)
+	return err
+}
+
+func (r *invitationRepository) FindByID(id string) (*models.Invitation, error) {
+	return r.findOne("id = $1", id)
+}
+
+func (r *invitationRepository) FindByTokenHash(hash string) (*models.Invitation, error) {
+	return r.findOne("token_hash = $1", hash)
+}
+
+func (r *invitationRepository) FindPendingByEmail(email string) (*models.Invitation, error) {
+	return r.findOne("email = $1 AND status = $2 ORDER BY created_at LIMIT 1", email, models.InvitationStatusPending)
+}
+
+func (r *invitationRepository) findOne(where string, args ...interface{}) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx,
+		"SELECT "+invitationColumns+" FROM invitations WHERE "+where, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepository) Update(invitation *models.Invitation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invitation.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, `UPDATE invitations SET
+		email = $2, role = $3, name = $4, phone = $5, department = $6, company = $7, address = $8, salary = $9,
+		token_hash = $10, status = $11, invited_by = $12, accepted_user_id = $13, expires_at = $14,
+		accepted_at = $15, updated_at = $16
+		WHERE id = $1`,
+		invitation.ID, invitation.Email, invitation.Role, invitation.Name, invitation.Phone,
+		invitation.Department, invitation.Company, invitation.Address, invitation.Salary,
+		invitation.TokenHash, invitation.Status, invitation.InvitedBy, invitation.AcceptedUserID,
+		invitation.ExpiresAt, invitation.AcceptedAt, invitation.UpdatedAt)
+	return err
+}
+
+func (r *invitationRepository) MarkAccepted(id string, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `UPDATE invitations SET status = $3, accepted_user_id = $4, accepted_at = $5, updated_at = $5
+		WHERE id = $1 AND status = $2`,
+		id, models.InvitationStatusPending, models.InvitationStatusAccepted, userID, now)
+	if err != nil {
+		return err
+	}
+	return affected(result, errors.New("invitation is no longer pending"))
+}
+
+func (r *invitationRepository) List(page, pageSize int, search string, status string) ([]models.Invitation, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	c.search(search, "email", "name")
+	if status != "" {
+		c.add("status = ?", status)
+	}
+	return list(ctx, r.db, scanInvitation, invitationColumns+" FROM invitations", "invitations", "created_at DESC, id DESC", &c, page, pageSize)
+}
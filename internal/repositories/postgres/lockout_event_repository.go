@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const lockoutEventColumns = `id, email, type, ip, failed_count, locked_until, actor_id, created_at`
+
+func scanLockoutEvent(row scanner) (*models.LockoutEvent, error) {
+	var event models.LockoutEvent
+	err := row.Scan(&event.ID, &event.Email, &event.Type, &event.IP, &event.FailedCount, &event.LockedUntil,
+		&event.ActorID, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+type lockoutEventRepository struct {
+	db querier
+}
+
+func NewLockoutEventRepository(db *sql.DB) repositories.LockoutEventRepository {
+	return &lockoutEventRepository{db: db}
+}
+
+func (r *lockoutEventRepository) Create(event *models.LockoutEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO lockout_events (`+lockoutEventColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		event.ID, event.Email, event.Type, event.IP, event.FailedCount, event.LockedUntil, event.ActorID, event.CreatedAt)
+	return err
+}
+
+func (r *lockoutEventRepository) List(page, pageSize int, email string, eventType string) ([]models.LockoutEvent, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	if email != "" {
+		c.add("email = ?", email)
+	}
+	if eventType != "" {
+		c.add("type = ?", eventType)
+	}
+	return list(ctx, r.db, scanLockoutEvent, lockoutEventColumns+" FROM lockout_events", "lockout_events", "created_at DESC, id DESC", &c, page, pageSize)
+}
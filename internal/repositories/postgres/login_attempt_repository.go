@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const loginAttemptColumns = `email, failed_count, last_failed_at, locked_until, expires_at, updated_at`
+
+func scanLoginAttempt(row scanner) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := row.Scan(&attempt.Email, &attempt.FailedCount, &attempt.LastFailedAt, &attempt.LockedUntil,
+		&attempt.ExpiresAt, &attempt.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// loginAttemptRepository treats expired attempts as gone, as MongoDB's TTL
+// index makes them; a new failure starts them over.
+type loginAttemptRepository struct {
+	db querier
+}
+
+func NewLoginAttemptRepository(db *sql.DB) repositories.LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) FindByEmail(email string) (*models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	attempt, err := scanLoginAttempt(r.db.QueryRowContext(ctx,
+		"SELECT "+loginAttemptColumns+" FROM login_attempts WHERE email = $1 AND expires_at > $2", email, time.Now()))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return attempt, nil
+}
+
+func (r *loginAttemptRepository) RecordFailure(email string, expiresAt time.Time) (*models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	return scanLoginAttempt(r.db.QueryRowContext(ctx, `INSERT INTO login_attempts AS a
+		(email, failed_count, last_failed_at, expires_at, updated_at) VALUES ($1, 1, $2, $3, $2)
+		ON CONFLICT (email) DO UPDATE SET
+			failed_count = CASE WHEN a.expires_at <= $2 THEN 1 ELSE a.failed_count + 1 END,
+			locked_until = CASE WHEN a.expires_at <= $2 THEN NULL ELSE a.locked_until END,
+			last_failed_at = $2, expires_at = $3, updated_at = $2
+		RETURNING `+loginAttemptColumns, email, now, expiresAt))
+}
+
+func (r *loginAttemptRepository) Lock(email string, until time.Time, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE login_attempts SET locked_until = $2, expires_at = $3, updated_at = $4 WHERE email = $1 AND expires_at > $4",
+		email, until, expiresAt, now)
+	return err
+}
+
+func (r *loginAttemptRepository) Delete(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE email = $1", email)
+	return err
+}
+
+func (r *loginAttemptRepository) ListLocked() ([]models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attempts, err := queryAll(ctx, r.db, scanLoginAttempt,
+		"SELECT "+loginAttemptColumns+" FROM login_attempts WHERE locked_until > $1 AND expires_at > $1 ORDER BY locked_until DESC",
+		time.Now())
+	if attempts == nil && err == nil {
+		attempts = []models.LoginAttempt{}
+	}
+	return attempts, err
+}
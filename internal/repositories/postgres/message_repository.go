@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const messageColumns = `id, content, sender_id, project_id, created_at, updated_at, deleted_at, deleted_by`
+
+func scanMessage(row scanner) (*models.Message, error) {
+	var message models.Message
+	err := row.Scan(&message.ID, &message.Content, &message.SenderID, &message.ProjectID, &message.CreatedAt,
+		&message.UpdatedAt, &message.DeletedAt, &message.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+type messageRepository struct {
+	db querier
+}
+
+func NewMessageRepository(db *sql.DB) repositories.MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) Create(message *models.Message) error {
+	message.CreatedAt = time.Now()
+	message.UpdatedAt = time.Now()
+	return r.Import(message)
+}
+
+func (r *messageRepository) Import(message *models.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO messages (`+messageColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		message.ID, message.Content, message.SenderID, message.ProjectID, message.CreatedAt,
+		message.UpdatedAt, message.DeletedAt, message.DeletedBy)
+	return err
+}
+
+func (r *messageRepository) FindByID(id string) (*models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	message, err := scanMessage(r.db.QueryRowContext(ctx,
+		"SELECT "+messageColumns+" FROM messages WHERE id = $1 AND deleted_at IS NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	senders, err := loadUsers(ctx, r.db, []string{message.SenderID})
+	if err != nil {
+		return nil, err
+	}
+	message.Sender = senders[message.SenderID]
+	return message, nil
+}
+
+func (r *messageRepository) Delete(id, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE messages SET deleted_at = $2, deleted_by = $3 WHERE id = $1 AND deleted_at IS NULL",
+		id, time.Now(), deletedBy)
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
+
+func (r *messageRepository) FindDeleted(id string) (*models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	message, err := scanMessage(r.db.QueryRowContext(ctx,
+		"SELECT "+messageColumns+" FROM messages WHERE id = $1 AND deleted_at IS NOT NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return message, nil
+}
+
+func (r *messageRepository) Restore(id string) (*models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tombstone, err := scanMessage(r.db.QueryRowContext(ctx,
+		"SELECT "+messageColumns+" FROM messages WHERE id = $1 AND deleted_at IS NOT NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE messages SET deleted_at = NULL, deleted_by = '' WHERE id = $1 AND deleted_at = $2",
+		id, tombstone.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := affected(result, mongo.ErrNoDocuments); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+func (r *messageRepository) PurgeDeleted(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM messages WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *messageRepository) ListByProject(projectID string, page, pageSize int, includeDeleted bool) ([]models.Message, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	c.add("project_id = ?", projectID)
+	if !includeDeleted {
+		c.add("deleted_at IS NULL")
+	}
+	messages, total, err := list(ctx, r.db, scanMessage, messageColumns+" FROM messages", "messages", "created_at DESC, id DESC", &c, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	senderIDs := make([]string, len(messages))
+	for i := range messages {
+		senderIDs[i] = messages[i].SenderID
+	}
+	senders, err := loadUsers(ctx, r.db, senderIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range messages {
+		messages[i].Sender = senders[messages[i].SenderID]
+	}
+	return messages, total, nil
+}
+
+func (r *messageRepository) FindByProject(projectID string) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return queryAll(ctx, r.db, scanMessage,
+		"SELECT "+messageColumns+" FROM messages WHERE project_id = $1 AND deleted_at IS NULL ORDER BY created_at, id", projectID)
+}
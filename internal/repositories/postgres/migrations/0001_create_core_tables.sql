@@ -0,0 +1,105 @@
+-- Users, projects, service requests and messages, with the counters that
+-- number them and the catalogue of service types.
+
+CREATE TABLE users (
+    id                        text PRIMARY KEY,
+    email                     text NOT NULL,
+    password                  text NOT NULL DEFAULT '',
+    name                      text NOT NULL DEFAULT '',
+    phone                     text NOT NULL DEFAULT '',
+    role                      text NOT NULL,
+    department                text NOT NULL DEFAULT '',
+    company                   text NOT NULL DEFAULT '',
+    address                   text NOT NULL DEFAULT '',
+    salary                    integer NOT NULL DEFAULT 0,
+    status                    text NOT NULL DEFAULT '',
+    hide                      boolean NOT NULL DEFAULT false,
+    email_verified_at         timestamptz,
+    two_factor_enabled        boolean NOT NULL DEFAULT false,
+    two_factor_secret         text NOT NULL DEFAULT '',
+    two_factor_pending_secret text NOT NULL DEFAULT '',
+    two_factor_last_step      bigint NOT NULL DEFAULT 0,
+    recovery_codes            text[],
+    version                   bigint NOT NULL DEFAULT 1,
+    created_at                timestamptz NOT NULL,
+    updated_at                timestamptz NOT NULL,
+    deleted_at                timestamptz,
+    deleted_by                text NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX users_email_key ON users (email);
+CREATE INDEX users_role_idx ON users (role);
+CREATE INDEX users_deleted_at_idx ON users (deleted_at);
+
+CREATE TABLE projects (
+    id          text PRIMARY KEY,
+    name        text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    client_id   text NOT NULL,
+    status      text NOT NULL DEFAULT '',
+    progress    integer NOT NULL DEFAULT 0,
+    version     bigint NOT NULL DEFAULT 1,
+    created_at  timestamptz NOT NULL,
+    updated_at  timestamptz NOT NULL,
+    deleted_at  timestamptz,
+    deleted_by  text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX projects_client_id_idx ON projects (client_id);
+CREATE INDEX projects_deleted_at_idx ON projects (deleted_at);
+
+-- The employees assigned to a project; position keeps the order they were
+-- assigned in.
+CREATE TABLE project_employees (
+    project_id  text NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    employee_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    position    integer NOT NULL,
+    PRIMARY KEY (project_id, employee_id)
+);
+
+CREATE INDEX project_employees_employee_id_idx ON project_employees (employee_id);
+
+CREATE TABLE service_requests (
+    id          text PRIMARY KEY,
+    title       text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    client_id   text NOT NULL,
+    project_id  text,
+    status      text NOT NULL DEFAULT '',
+    version     bigint NOT NULL DEFAULT 1,
+    created_at  timestamptz NOT NULL,
+    updated_at  timestamptz NOT NULL,
+    deleted_at  timestamptz,
+    deleted_by  text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX service_requests_client_id_idx ON service_requests (client_id);
+CREATE INDEX service_requests_deleted_at_idx ON service_requests (deleted_at);
+
+CREATE TABLE messages (
+    id         text PRIMARY KEY,
+    content    text NOT NULL,
+    sender_id  text NOT NULL,
+    project_id text NOT NULL,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    deleted_by text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX messages_project_id_idx ON messages (project_id, created_at DESC);
+CREATE INDEX messages_deleted_at_idx ON messages (deleted_at);
+
+CREATE TABLE counters (
+    name text PRIMARY KEY,
+    seq  integer NOT NULL
+);
+
+CREATE TABLE service_types (
+    id          text PRIMARY KEY,
+    name        text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    status      text NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL,
+    updated_at  timestamptz NOT NULL
+);
@@ -0,0 +1,102 @@
+-- Sessions, emailed tokens, invitations, login lockouts, API keys and single
+-- sign-on state. Expired rows are ignored by the queries rather than removed
+-- by a TTL index.
+
+CREATE TABLE sessions (
+    id                 text PRIMARY KEY,
+    user_id            text NOT NULL,
+    refresh_token_hash text NOT NULL,
+    user_agent         text NOT NULL DEFAULT '',
+    ip                 text NOT NULL DEFAULT '',
+    expires_at         timestamptz NOT NULL,
+    revoked_at         timestamptz,
+    replaced_by        text NOT NULL DEFAULT '',
+    created_at         timestamptz NOT NULL,
+    updated_at         timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX sessions_refresh_token_hash_key ON sessions (refresh_token_hash);
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+CREATE TABLE user_tokens (
+    id         text PRIMARY KEY,
+    user_id    text NOT NULL,
+    purpose    text NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX user_tokens_token_hash_key ON user_tokens (token_hash);
+CREATE INDEX user_tokens_user_id_purpose_idx ON user_tokens (user_id, purpose);
+
+CREATE TABLE invitations (
+    id               text PRIMARY KEY,
+    email            text NOT NULL,
+    role             text NOT NULL,
+    name             text NOT NULL DEFAULT '',
+    phone            text NOT NULL DEFAULT '',
+    department       text NOT NULL DEFAULT '',
+    company          text NOT NULL DEFAULT '',
+    address          text NOT NULL DEFAULT '',
+    salary           integer NOT NULL DEFAULT 0,
+    token_hash       text NOT NULL,
+    status           text NOT NULL,
+    invited_by       text NOT NULL DEFAULT '',
+    accepted_user_id text NOT NULL DEFAULT '',
+    expires_at       timestamptz NOT NULL,
+    accepted_at      timestamptz,
+    created_at       timestamptz NOT NULL,
+    updated_at       timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX invitations_token_hash_key ON invitations (token_hash);
+CREATE INDEX invitations_email_status_idx ON invitations (email, status);
+
+CREATE TABLE login_attempts (
+    email          text PRIMARY KEY,
+    failed_count   integer NOT NULL,
+    last_failed_at timestamptz NOT NULL,
+    locked_until   timestamptz,
+    expires_at     timestamptz NOT NULL,
+    updated_at     timestamptz NOT NULL
+);
+
+CREATE TABLE lockout_events (
+    id           text PRIMARY KEY,
+    email        text NOT NULL,
+    type         text NOT NULL,
+    ip           text NOT NULL DEFAULT '',
+    failed_count integer NOT NULL DEFAULT 0,
+    locked_until timestamptz,
+    actor_id     text NOT NULL DEFAULT '',
+    created_at   timestamptz NOT NULL
+);
+
+CREATE INDEX lockout_events_email_created_at_idx ON lockout_events (email, created_at DESC);
+
+CREATE TABLE api_keys (
+    id           text PRIMARY KEY,
+    user_id      text NOT NULL,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    key_hash     text NOT NULL,
+    scopes       text[],
+    expires_at   timestamptz NOT NULL,
+    last_used_at timestamptz,
+    revoked_at   timestamptz,
+    created_at   timestamptz NOT NULL,
+    updated_at   timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX api_keys_key_hash_key ON api_keys (key_hash);
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
+
+CREATE TABLE oidc_states (
+    id            text PRIMARY KEY,
+    nonce         text NOT NULL,
+    code_verifier text NOT NULL,
+    expires_at    timestamptz NOT NULL,
+    created_at    timestamptz NOT NULL
+);
@@ -0,0 +1,18 @@
+-- The audit log. changes holds the before and after JSON of each field.
+
+CREATE TABLE audit_events (
+    id            text PRIMARY KEY,
+    actor_id      text NOT NULL DEFAULT '',
+    actor_role    text NOT NULL DEFAULT '',
+    action        text NOT NULL,
+    resource_type text NOT NULL,
+    resource_id   text NOT NULL,
+    changes       jsonb,
+    ip            text NOT NULL DEFAULT '',
+    request_id    text NOT NULL DEFAULT '',
+    created_at    timestamptz NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at DESC);
+CREATE INDEX audit_events_resource_idx ON audit_events (resource_type, resource_id, created_at DESC);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id, created_at DESC);
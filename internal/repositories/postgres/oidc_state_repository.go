@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const oidcStateColumns = `id, nonce, code_verifier, expires_at, created_at`
+
+type oidcStateRepository struct {
+	db querier
+}
+
+func NewOIDCStateRepository(db *sql.DB) repositories.OIDCStateRepository {
+	return &oidcStateRepository{db: db}
+}
+
+func (r *oidcStateRepository) Create(state *models.OIDCState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO oidc_states (`+oidcStateColumns+`) VALUES ($1, $2, $3, $4, $5)`,
+		state.ID, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt)
+	return err
+}
+
+// Consume deletes the state and returns it, so each one is used only once.
+func (r *oidcStateRepository) Consume(id string) (*models.OIDCState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var state models.OIDCState
+	err := r.db.QueryRowContext(ctx, "DELETE FROM oidc_states WHERE id = $1 RETURNING "+oidcStateColumns, id).
+		Scan(&state.ID, &state.Nonce, &state.CodeVerifier, &state.ExpiresAt, &state.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &state, nil
+}
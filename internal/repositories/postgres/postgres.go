@@ -0,0 +1,209 @@
+// Package postgres implements every repository on PostgreSQL. Lookups that
+// find nothing return mongo.ErrNoDocuments and stale updates
+// repositories.ErrStaleVersion, as the MongoDB repositories do, so the
+// services work unchanged over either database. Migrate creates the schema.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewRepositories returns every repository backed by db.
+func NewRepositories(db *sql.DB) *repositories.Repositories {
+	users := NewUserRepository(db)
+	return &repositories.Repositories{
+		Users:           users,
+		Employees:       NewEmployeeRepository(users),
+		Projects:        NewProjectRepository(db),
+		ServiceRequests: NewServiceRequestRepository(db),
+		Messages:        NewMessageRepository(db),
+		Counters:        NewCounterRepository(db),
+		ServiceTypes:    NewServiceTypeRepository(db),
+		Sessions:        NewSessionRepository(db),
+		UserTokens:      NewUserTokenRepository(db),
+		Invitations:     NewInvitationRepository(db),
+		LoginAttempts:   NewLoginAttemptRepository(db),
+		LockoutEvents:   NewLockoutEventRepository(db),
+		APIKeys:         NewAPIKeyRepository(db),
+		OIDCStates:      NewOIDCStateRepository(db),
+		AuditEvents:     NewAuditEventRepository(db),
+		UnitOfWork:      NewUnitOfWork(db),
+	}
+}
+
+// querier is what the repositories run statements on: the database, or a
+// transaction of a unit of work.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// notFound turns sql.ErrNoRows into mongo.ErrNoDocuments, which the services
+// check for.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+// affected returns missing when the statement changed no row.
+func affected(result sql.Result, missing error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return missing
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, or straight on db when it already is one.
+func inTx(ctx context.Context, db querier, fn func(tx querier) error) error {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryAll runs query and scans every row with scan.
+func queryAll[T any](ctx context.Context, db querier, scan func(scanner) (*T, error), query string, args ...interface{}) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []T
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *v)
+	}
+	return out, rows.Err()
+}
+
+// conditions builds the WHERE clause of a listing. Clauses use ? for their
+// arguments; they are numbered $1, $2, ... in the order they are added.
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (c *conditions) add(clause string, args ...interface{}) {
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		clause = strings.Replace(clause, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.clauses = append(c.clauses, clause)
+}
+
+// search matches columns case-insensitively against the search term, taken
+// literally, like the $regex filters of the MongoDB listings. An empty search
+// matches everything.
+func (c *conditions) search(search string, columns ...string) {
+	if search == "" {
+		return
+	}
+	matches := make([]string, len(columns))
+	for i, column := range columns {
+		matches[i] = column + " ~* ?"
+	}
+	args := make([]interface{}, len(columns))
+	for i := range args {
+		args[i] = regexp.QuoteMeta(search)
+	}
+	c.add("("+strings.Join(matches, " OR ")+")", args...)
+}
+
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// page returns the LIMIT and OFFSET of one page, with their arguments
+// appended to args. A zero pageSize means no limit.
+func (c *conditions) page(page, pageSize int) (string, []interface{}) {
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+	n := len(c.args)
+	args := append(append([]interface{}{}, c.args...), pageSize, offset)
+	return fmt.Sprintf(" LIMIT NULLIF($%d, 0) OFFSET $%d", n+1, n+2), args
+}
+
+// list counts the rows matching c and returns one page of them.
+func list[T any](ctx context.Context, db querier, scan func(scanner) (*T, error), selectFrom, countFrom, orderBy string, c *conditions, page, pageSize int) ([]T, int64, error) {
+	var total int64
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM "+countFrom+c.where(), c.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit, args := c.page(page, pageSize)
+	items, err := queryAll(ctx, db, scan, "SELECT "+selectFrom+c.where()+" ORDER BY "+orderBy+limit, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// loadUsers returns the users with the given IDs, deleted ones included,
+// keyed by ID.
+func loadUsers(ctx context.Context, db querier, ids []string) (map[string]*models.User, error) {
+	users := make(map[string]*models.User)
+	if len(ids) == 0 {
+		return users, nil
+	}
+	found, err := queryAll(ctx, db, scanUser, "SELECT "+userColumns+" FROM users WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for i := range found {
+		users[found[i].UserID] = &found[i]
+	}
+	return users, nil
+}
+
+// unknownClient stands in for a client that no longer exists, as in the
+// MongoDB repositories.
+func unknownClient(id string) *models.User {
+	return &models.User{
+		UserID: id,
+		Name:   "Unknown Client",
+		Email:  "unknown@example.com",
+		Role:   models.RoleClient,
+	}
+}
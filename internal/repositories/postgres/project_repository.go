@@ -0,0 +1,293 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// projectColumns selects from projects aliased p. Employee IDs come from the
+// project_employees join table, in the order they were assigned.
+const projectColumns = `p.id, p.name, p.description, p.client_id, p.status, p.progress,
+	ARRAY(SELECT pe.employee_id FROM project_employees pe WHERE pe.project_id = p.id ORDER BY pe.position),
+	p.version, p.created_at, p.updated_at, p.deleted_at, p.deleted_by`
+
+func scanProject(row scanner) (*models.Project, error) {
+	var project models.Project
+	err := row.Scan(&project.ID, &project.Name, &project.Description, &project.ClientID, &project.Status,
+		&project.Progress, pq.Array(&project.EmployeeIDs), &project.Version, &project.CreatedAt,
+		&project.UpdatedAt, &project.DeletedAt, &project.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+type projectRepository struct {
+	db querier
+}
+
+func NewProjectRepository(db *sql.DB) repositories.ProjectRepository {
+	return newProjectRepository(db)
+}
+
+// newProjectRepository returns a project repository running on db, which may
+// be a transaction.
+func newProjectRepository(db querier) repositories.ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+func (r *projectRepository) Create(project *models.Project) error {
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = time.Now()
+	project.Version = 1
+	return r.Import(project)
+}
+
+func (r *projectRepository) Import(project *models.Project) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if project.EmployeeIDs == nil {
+		project.EmployeeIDs = []string{}
+	}
+	if project.ID == "" {
+		return fmt.Errorf("project ID cannot be empty")
+	}
+
+	err := inTx(ctx, r.db, func(tx querier) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO projects
+			(id, name, description, client_id, status, progress, version, created_at, updated_at, deleted_at, deleted_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			project.ID, project.Name, project.Description, project.ClientID, project.Status, project.Progress,
+			project.Version, project.CreatedAt, project.UpdatedAt, project.DeletedAt, project.DeletedBy)
+		if err != nil {
+			return err
+		}
+		return setEmployees(ctx, tx, project.ID, project.EmployeeIDs)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert project: %w", err)
+	}
+	return nil
+}
+
+func (r *projectRepository) FindByID(id string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	project, err := scanProject(r.db.QueryRowContext(ctx,
+		"SELECT "+projectColumns+" FROM projects p WHERE p.id = $1 AND p.deleted_at IS NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	// Deleted clients and employees are still shown, as they are by MongoDB
+	users, err := loadUsers(ctx, r.db, append([]string{project.ClientID}, project.EmployeeIDs...))
+	if err != nil {
+		return nil, err
+	}
+	withClient(project, users)
+	for _, employeeID := range project.EmployeeIDs {
+		if employee, ok := users[employeeID]; ok {
+			project.Employees = append(project.Employees, *employee)
+		}
+	}
+	return project, nil
+}
+
+// withClient sets the project's client from users, or a placeholder when it
+// no longer exists.
+func withClient(project *models.Project, users map[string]*models.User) {
+	if client, ok := users[project.ClientID]; ok {
+		project.Client = client
+	} else {
+		project.Client = unknownClient(project.ClientID)
+	}
+}
+
+func (r *projectRepository) Update(project *models.Project) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	project.UpdatedAt = time.Now()
+	err := inTx(ctx, r.db, func(tx querier) error {
+		result, err := tx.ExecContext(ctx, `UPDATE projects SET
+			name = $3, description = $4, client_id = $5, status = $6, progress = $7, updated_at = $8,
+			version = version + 1
+			WHERE id = $1 AND version = $2`,
+			project.ID, project.Version, project.Name, project.Description, project.ClientID, project.Status,
+			project.Progress, project.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if err := affected(result, repositories.ErrStaleVersion); err != nil {
+			return err
+		}
+		return setEmployees(ctx, tx, project.ID, project.EmployeeIDs)
+	})
+	if err != nil {
+		return err
+	}
+	project.Version++
+	return nil
+}
+
+func (r *projectRepository) Delete(id, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE projects SET deleted_at = $2, deleted_by = $3, version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+		id, time.Now(), deletedBy)
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
+
+func (r *projectRepository) FindDeleted(id string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	project, err := scanProject(r.db.QueryRowContext(ctx,
+		"SELECT "+projectColumns+" FROM projects p WHERE p.id = $1 AND p.deleted_at IS NOT NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return project, nil
+}
+
+func (r *projectRepository) Restore(id string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tombstone, err := scanProject(r.db.QueryRowContext(ctx,
+		"SELECT "+projectColumns+" FROM projects p WHERE p.id = $1 AND p.deleted_at IS NOT NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE projects SET deleted_at = NULL, deleted_by = '', version = version + 1 WHERE id = $1 AND deleted_at = $2",
+		id, tombstone.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := affected(result, mongo.ErrNoDocuments); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+func (r *projectRepository) PurgeDeleted(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Assignments go with the project
+	result, err := r.db.ExecContext(ctx, "DELETE FROM projects WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *projectRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.Project, int64, error) {
+	var c conditions
+	if clientID != nil {
+		c.add("p.client_id = ?", *clientID)
+	}
+	return r.list(&c, page, pageSize, search, status, includeDeleted)
+}
+
+func (r *projectRepository) ListByEmployee(page, pageSize int, search string, status string, employeeID string, includeDeleted bool) ([]models.Project, int64, error) {
+	var c conditions
+	c.add("EXISTS (SELECT 1 FROM project_employees pe WHERE pe.project_id = p.id AND pe.employee_id = ?)", employeeID)
+	return r.list(&c, page, pageSize, search, status, includeDeleted)
+}
+
+func (r *projectRepository) list(c *conditions, page, pageSize int, search string, status string, includeDeleted bool) ([]models.Project, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c.search(search, "p.name", "p.description", "p.id")
+	if status != "" {
+		c.add("p.status = ?", status)
+	}
+	if !includeDeleted {
+		c.add("p.deleted_at IS NULL")
+	}
+	projects, total, err := list(ctx, r.db, scanProject, projectColumns+" FROM projects p", "projects p", "p.created_at DESC, p.id DESC", c, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	clientIDs := make([]string, len(projects))
+	for i := range projects {
+		clientIDs[i] = projects[i].ClientID
+	}
+	clients, err := loadUsers(ctx, r.db, clientIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range projects {
+		withClient(&projects[i], clients)
+	}
+	return projects, total, nil
+}
+
+func (r *projectRepository) FindByClient(clientID string) ([]models.Project, error) {
+	return r.findLive("p.client_id = $1", clientID)
+}
+
+func (r *projectRepository) FindByEmployee(employeeID string) ([]models.Project, error) {
+	return r.findLive("EXISTS (SELECT 1 FROM project_employees pe WHERE pe.project_id = p.id AND pe.employee_id = $1)", employeeID)
+}
+
+// findLive returns every live project matching where, oldest first.
+func (r *projectRepository) findLive(where string, args ...interface{}) ([]models.Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return queryAll(ctx, r.db, scanProject,
+		"SELECT "+projectColumns+" FROM projects p WHERE "+where+" AND p.deleted_at IS NULL ORDER BY p.created_at, p.id", args...)
+}
+
+func (r *projectRepository) AssignEmployees(projectID string, employeeIDs []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return inTx(ctx, r.db, func(tx querier) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE projects SET updated_at = $2, version = version + 1 WHERE id = $1", projectID, time.Now())
+		if err != nil {
+			return err
+		}
+		// An update matching nothing is not an error
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		return setEmployees(ctx, tx, projectID, employeeIDs)
+	})
+}
+
+// setEmployees replaces the employees assigned to a project. An employee
+// listed twice is assigned once, at the first position.
+func setEmployees(ctx context.Context, db querier, projectID string, employeeIDs []string) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM project_employees WHERE project_id = $1", projectID); err != nil {
+		return err
+	}
+	if len(employeeIDs) == 0 {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO project_employees (project_id, employee_id, position)
+		SELECT $1, e.id, min(e.position) FROM unnest($2::text[]) WITH ORDINALITY AS e(id, position) GROUP BY e.id`,
+		projectID, pq.Array(employeeIDs))
+	return err
+}
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const serviceRequestColumns = `id, title, description, client_id, project_id, status, version, created_at,
+	updated_at, deleted_at, deleted_by`
+
+func scanServiceRequest(row scanner) (*models.ServiceRequest, error) {
+	var request models.ServiceRequest
+	err := row.Scan(&request.ID, &request.Title, &request.Description, &request.ClientID, &request.ProjectID,
+		&request.Status, &request.Version, &request.CreatedAt, &request.UpdatedAt, &request.DeletedAt,
+		&request.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+type serviceRequestRepository struct {
+	db querier
+}
+
+func NewServiceRequestRepository(db *sql.DB) repositories.ServiceRequestRepository {
+	return newServiceRequestRepository(db)
+}
+
+// newServiceRequestRepository returns a service request repository running
+// on db, which may be a transaction.
+func newServiceRequestRepository(db querier) repositories.ServiceRequestRepository {
+	return &serviceRequestRepository{db: db}
+}
+
+func (r *serviceRequestRepository) Create(request *models.ServiceRequest) error {
+	request.CreatedAt = time.Now()
+	request.UpdatedAt = time.Now()
+	request.Version = 1
+	return r.Import(request)
+}
+
+func (r *serviceRequestRepository) Import(request *models.ServiceRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO service_requests (`+serviceRequestColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		request.ID, request.Title, request.Description, request.ClientID, request.ProjectID, request.Status,
+		request.Version, request.CreatedAt, request.UpdatedAt, request.DeletedAt, request.DeletedBy)
+	return err
+}
+
+func (r *serviceRequestRepository) FindByID(id string) (*models.ServiceRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := scanServiceRequest(r.db.QueryRowContext(ctx,
+		"SELECT "+serviceRequestColumns+" FROM service_requests WHERE id = $1 AND deleted_at IS NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	clients, err := loadUsers(ctx, r.db, []string{request.ClientID})
+	if err != nil {
+		return nil, err
+	}
+	withRequestClient(request, clients)
+
+	if request.ProjectID != nil {
+		project, err := scanProject(r.db.QueryRowContext(ctx,
+			"SELECT "+projectColumns+" FROM projects p WHERE p.id = $1", *request.ProjectID))
+		if err == nil {
+			request.Project = project
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+// withRequestClient sets the request's client from users, or a placeholder
+// when it no longer exists.
+func withRequestClient(request *models.ServiceRequest, users map[string]*models.User) {
+	if client, ok := users[request.ClientID]; ok {
+		request.Client = client
+	} else {
+		request.Client = unknownClient(request.ClientID)
+	}
+}
+
+func (r *serviceRequestRepository) Update(request *models.ServiceRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request.UpdatedAt = time.Now()
+
+	// An unset project is left as it is, like an omitted field of the
+	// MongoDB $set
+	result, err := r.db.ExecContext(ctx, `UPDATE service_requests SET
+		title = $3, description = $4, client_id = $5, project_id = COALESCE($6, project_id), status = $7,
+		updated_at = $8, version = version + 1
+		WHERE id = $1 AND version = $2`,
+		request.ID, request.Version, request.Title, request.Description, request.ClientID, request.ProjectID,
+		request.Status, request.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := affected(result, repositories.ErrStaleVersion); err != nil {
+		return err
+	}
+	request.Version++
+	return nil
+}
+
+func (r *serviceRequestRepository) Delete(id, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE service_requests SET deleted_at = $2, deleted_by = $3, version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+		id, time.Now(), deletedBy)
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
+
+func (r *serviceRequestRepository) FindDeleted(id string) (*models.ServiceRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := scanServiceRequest(r.db.QueryRowContext(ctx,
+		"SELECT "+serviceRequestColumns+" FROM service_requests WHERE id = $1 AND deleted_at IS NOT NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return request, nil
+}
+
+func (r *serviceRequestRepository) Restore(id string) (*models.ServiceRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tombstone, err := scanServiceRequest(r.db.QueryRowContext(ctx,
+		"SELECT "+serviceRequestColumns+" FROM service_requests WHERE id = $1 AND deleted_at IS NOT NULL", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE service_requests SET deleted_at = NULL, deleted_by = '', version = version + 1 WHERE id = $1 AND deleted_at = $2",
+		id, tombstone.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := affected(result, mongo.ErrNoDocuments); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+func (r *serviceRequestRepository) PurgeDeleted(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM service_requests WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *serviceRequestRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.ServiceRequest, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	c.search(search, "title", "description", "id")
+	if status != "" {
+		c.add("status = ?", status)
+	}
+	if clientID != nil {
+		c.add("client_id = ?", *clientID)
+	}
+	if !includeDeleted {
+		c.add("deleted_at IS NULL")
+	}
+	requests, total, err := list(ctx, r.db, scanServiceRequest, serviceRequestColumns+" FROM service_requests", "service_requests", "created_at DESC, id DESC", &c, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	clientIDs := make([]string, len(requests))
+	for i := range requests {
+		clientIDs[i] = requests[i].ClientID
+	}
+	clients, err := loadUsers(ctx, r.db, clientIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range requests {
+		withRequestClient(&requests[i], clients)
+	}
+	return requests, total, nil
+}
+
+func (r *serviceRequestRepository) FindByClient(clientID string) ([]models.ServiceRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return queryAll(ctx, r.db, scanServiceRequest,
+		"SELECT "+serviceRequestColumns+" FROM service_requests WHERE client_id = $1 AND deleted_at IS NULL ORDER BY created_at, id", clientID)
+}
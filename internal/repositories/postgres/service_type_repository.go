@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const serviceTypeColumns = `id, name, description, status, created_at, updated_at`
+
+func scanServiceType(row scanner) (*models.ServiceType, error) {
+	var serviceType models.ServiceType
+	err := row.Scan(&serviceType.ID, &serviceType.Name, &serviceType.Description, &serviceType.Status,
+		&serviceType.CreatedAt, &serviceType.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &serviceType, nil
+}
+
+type serviceTypeRepository struct {
+	db querier
+}
+
+func NewServiceTypeRepository(db *sql.DB) repositories.ServiceTypeRepository {
+	return &serviceTypeRepository{db: db}
+}
+
+func (r *serviceTypeRepository) Create(serviceType *models.ServiceType) error {
+	// IDs look the same whichever database holds them
+	serviceType.ID = primitive.NewObjectID().Hex()
+	serviceType.CreatedAt = time.Now()
+	serviceType.UpdatedAt = time.Now()
+	return r.Import(serviceType)
+}
+
+func (r *serviceTypeRepository) Import(serviceType *models.ServiceType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO service_types (`+serviceTypeColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		serviceType.ID, serviceType.Name, serviceType.Description, serviceType.Status,
+		serviceType.CreatedAt, serviceType.UpdatedAt)
+	return err
+}
+
+func (r *serviceTypeRepository) GetByID(id string) (*models.ServiceType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	serviceType, err := scanServiceType(r.db.QueryRowContext(ctx,
+		"SELECT "+serviceTypeColumns+" FROM service_types WHERE id = $1", id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return serviceType, nil
+}
+
+func (r *serviceTypeRepository) GetAll(status *string) ([]models.ServiceType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	if status != nil {
+		c.add("status = ?", *status)
+	}
+	return queryAll(ctx, r.db, scanServiceType,
+		"SELECT "+serviceTypeColumns+" FROM service_types"+c.where()+" ORDER BY created_at, id", c.args...)
+}
+
+func (r *serviceTypeRepository) Update(id string, serviceType *models.ServiceType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	serviceType.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE service_types SET name = $2, description = $3, status = $4, updated_at = $5 WHERE id = $1",
+		id, serviceType.Name, serviceType.Description, serviceType.Status, serviceType.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
+
+func (r *serviceTypeRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM service_types WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
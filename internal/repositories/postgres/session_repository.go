@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const sessionColumns = `id, user_id, refresh_token_hash, user_agent, ip, expires_at, revoked_at, replaced_by,
+	created_at, updated_at`
+
+func scanSession(row scanner) (*models.Session, error) {
+	var session models.Session
+	err := row.Scan(&session.ID, &session.UserID, &session.RefreshTokenHash, &session.UserAgent, &session.IP,
+		&session.ExpiresAt, &session.RevokedAt, &session.ReplacedBy, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+type sessionRepository struct {
+	db querier
+}
+
+func NewSessionRepository(db *sql.DB) repositories.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO sessions (`+sessionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		session.ID, session.UserID, session.RefreshTokenHash, session.UserAgent, session.IP,
+		session.ExpiresAt, session.RevokedAt, session.ReplacedBy, session.CreatedAt, session.UpdatedAt)
+	return err
+}
+
+func (r *sessionRepository) FindByID(id string) (*models.Session, error) {
+	return r.findOne("id = $1", id)
+}
+
+func (r *sessionRepository) FindByRefreshTokenHash(hash string) (*models.Session, error) {
+	return r.findOne("refresh_token_hash = $1", hash)
+}
+
+func (r *sessionRepository) findOne(where string, args ...interface{}) (*models.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, "SELECT "+sessionColumns+" FROM sessions WHERE "+where, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) Revoke(id string, replacedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = $2, updated_at = $2,
+		replaced_by = CASE WHEN $3 = '' THEN replaced_by ELSE $3 END
+		WHERE id = $1 AND revoked_at IS NULL`, id, time.Now(), replacedBy)
+	if err != nil {
+		return err
+	}
+	return affected(result, errors.New("session not found or already revoked"))
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = $2, updated_at = $2 WHERE user_id = $1 AND revoked_at IS NULL",
+		userID, time.Now())
+	return err
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+type unitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork returns a unit of work backed by PostgreSQL transactions.
+func NewUnitOfWork(db *sql.DB) repositories.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(fn func(tx repositories.Tx) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return inTx(ctx, u.db, func(tx querier) error {
+		return fn(postgresTx{db: tx})
+	})
+}
+
+type postgresTx struct {
+	db querier
+}
+
+func (t postgresTx) Counters() repositories.CounterRepository {
+	return newCounterRepository(t.db)
+}
+
+func (t postgresTx) Projects() repositories.ProjectRepository {
+	return newProjectRepository(t.db)
+}
+
+func (t postgresTx) ServiceRequests() repositories.ServiceRequestRepository {
+	return newServiceRequestRepository(t.db)
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const userColumns = `id, email, password, name, phone, role, department, company, address, salary, status, hide,
+	email_verified_at, two_factor_enabled, two_factor_secret, two_factor_pending_secret, two_factor_last_step,
+	recovery_codes, version, created_at, updated_at, deleted_at, deleted_by`
+
+func scanUser(row scanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.UserID, &user.Email, &user.Password, &user.Name, &user.Phone, &user.Role,
+		&user.Department, &user.Company, &user.Address, &user.Salary, &user.Status, &user.Hide,
+		&user.EmailVerifiedAt, &user.TwoFactorEnabled, &user.TwoFactorSecret, &user.TwoFactorPendingSecret,
+		&user.TwoFactorLastStep, pq.Array(&user.RecoveryCodes), &user.Version, &user.CreatedAt,
+		&user.UpdatedAt, &user.DeletedAt, &user.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+type userRepository struct {
+	db querier
+}
+
+func NewUserRepository(db *sql.DB) repositories.UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(user *models.User) error {
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+	user.Version = 1
+	return r.Import(user)
+}
+
+func (r *userRepository) Import(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO users (`+userColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`,
+		user.UserID, user.Email, user.Password, user.Name, user.Phone, user.Role,
+		user.Department, user.Company, user.Address, user.Salary, user.Status, user.Hide,
+		user.EmailVerifiedAt, user.TwoFactorEnabled, user.TwoFactorSecret, user.TwoFactorPendingSecret,
+		user.TwoFactorLastStep, pq.Array(user.RecoveryCodes), user.Version, user.CreatedAt,
+		user.UpdatedAt, user.DeletedAt, user.DeletedBy)
+	return err
+}
+
+func (r *userRepository) FindByID(id string) (*models.User, error) {
+	return r.findOne("id = $1 AND deleted_at IS NULL", id)
+}
+
+func (r *userRepository) FindByUserID(userID string) (*models.User, error) {
+	return r.FindByID(userID)
+}
+
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	return r.findOne("email = $1 AND deleted_at IS NULL", email)
+}
+
+func (r *userRepository) findOne(where string, args ...interface{}) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE "+where, args...))
+	if err != nil {
+		return &models.User{}, notFound(err)
+	}
+	return user, nil
+}
+
+func (r *userRepository) EmailExists(email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)", email).Scan(&exists)
+	return exists, err
+}
+
+func (r *userRepository) Update(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user.UpdatedAt = time.Now()
+
+	// Every field but the key, creation time and deletion stamp is written
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET
+		email = $3, password = $4, name = $5, phone = $6, role = $7, department = $8, company = $9,
+		address = $10, salary = $11, status = $12, hide = $13, email_verified_at = $14,
+		two_factor_enabled = $15, two_factor_secret = $16, two_factor_pending_secret = $17,
+		two_factor_last_step = $18, recovery_codes = $19, updated_at = $20, version = version + 1
+		WHERE id = $1 AND version = $2`,
+		user.UserID, user.Version, user.Email, user.Password, user.Name, user.Phone, user.Role,
+		user.Department, user.Company, user.Address, user.Salary, user.Status, user.Hide,
+		user.EmailVerifiedAt, user.TwoFactorEnabled, user.TwoFactorSecret, user.TwoFactorPendingSecret,
+		user.TwoFactorLastStep, pq.Array(user.RecoveryCodes), user.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := affected(result, repositories.ErrStaleVersion); err != nil {
+		return err
+	}
+	user.Version++
+	return nil
+}
+
+func (r *userRepository) Delete(id, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET deleted_at = $2, deleted_by = $3, version = version + 1 WHERE id = $1 AND deleted_at IS NULL",
+		id, time.Now(), deletedBy)
+	if err != nil {
+		return err
+	}
+	return affected(result, mongo.ErrNoDocuments)
+}
+
+func (r *userRepository) Restore(id string) (*models.User, error) {
+	tombstone, err := r.findOne("id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Matching the stamp that was read keeps a concurrent restore from
+	// returning the same tombstone twice
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET deleted_at = NULL, deleted_by = '', version = version + 1 WHERE id = $1 AND deleted_at = $2",
+		id, tombstone.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := affected(result, mongo.ErrNoDocuments); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
+func (r *userRepository) PurgeDeleted(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Purging a client that still owns records would bring back the
+	// "Unknown Client" placeholders
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users u WHERE u.deleted_at < $1
+		AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.client_id = u.id)
+		AND NOT EXISTS (SELECT 1 FROM service_requests sr WHERE sr.client_id = u.id)`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *userRepository) List(page, pageSize int, search string, role string, includeDeleted bool) ([]models.User, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	c.search(search, "name", "email", "company")
+	if role != "" {
+		c.add("role = ?", role)
+	}
+	if !includeDeleted {
+		c.add("deleted_at IS NULL")
+	}
+	return list(ctx, r.db, scanUser, userColumns+" FROM users", "users", "created_at, id", &c, page, pageSize)
+}
+
+func (r *userRepository) ListByStatus(status string, page, pageSize int) ([]models.User, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var c conditions
+	c.add("status = ?", status)
+	c.add("deleted_at IS NULL")
+	return list(ctx, r.db, scanUser, userColumns+" FROM users", "users", "created_at, id", &c, page, pageSize)
+}
+
+// employeeRepository narrows the user repository to employees, as the MongoDB
+// one does.
+type employeeRepository struct {
+	userRepo repositories.UserRepository
+}
+
+func NewEmployeeRepository(userRepo repositories.UserRepository) repositories.EmployeeRepository {
+	return &employeeRepository{userRepo: userRepo}
+}
+
+func (r *employeeRepository) Create(employee *models.User) error {
+	employee.Role = models.RoleEmployee
+	return r.userRepo.Create(employee)
+}
+
+func (r *employeeRepository) FindByID(id string) (*models.User, error) {
+	return r.userRepo.FindByID(id)
+}
+
+func (r *employeeRepository) List(page, pageSize int, search string, includeDeleted bool) ([]models.User, int64, error) {
+	return r.userRepo.List(page, pageSize, search, string(models.RoleEmployee), includeDeleted)
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+const userTokenColumns = `id, user_id, purpose, token_hash, expires_at, used_at, created_at`
+
+func scanUserToken(row scanner) (*models.UserToken, error) {
+	var token models.UserToken
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt,
+		&token.UsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+type userTokenRepository struct {
+	db querier
+}
+
+func NewUserTokenRepository(db *sql.DB) repositories.UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(token *models.UserToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO user_tokens (`+userTokenColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.UsedAt, token.CreatedAt)
+	return err
+}
+
+func (r *userTokenRepository) FindByHash(purpose models.TokenPurpose, hash string) (*models.UserToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token, err := scanUserToken(r.db.QueryRowContext(ctx,
+		"SELECT "+userTokenColumns+" FROM user_tokens WHERE purpose = $1 AND token_hash = $2", purpose, hash))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return token, nil
+}
+
+func (r *userTokenRepository) MarkUsed(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Only one of two concurrent uses of a token succeeds
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE user_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL", id, time.Now())
+	if err != nil {
+		return err
+	}
+	return affected(result, errors.New("token already used"))
+}
+
+func (r *userTokenRepository) DeleteForUser(userID string, purpose models.TokenPurpose) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2", userID, purpose)
+	return err
+}
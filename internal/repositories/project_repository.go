@@ -3,9 +3,10 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -13,32 +14,59 @@ import (
 
 type ProjectRepository interface {
 	Create(project *models.Project) error
+	// Import stores an exported project as it was, keeping its timestamps,
+	// version and deletion stamp
+	Import(project *models.Project) error
 	FindByID(id string) (*models.Project, error)
+	// Update writes the record and bumps its version, or returns
+	// ErrStaleVersion when the stored version has moved on
 	Update(project *models.Project) error
-	Delete(id string) error
-	List(page, pageSize int, search string, status string, clientID *string) ([]models.Project, int64, error)
-	ListByEmployee(page, pageSize int, search string, status string, employeeID string) ([]models.Project, int64, error)
+	Delete(id, deletedBy string) error
+	// FindDeleted returns a deleted project as stored, without its client
+	// and employees
+	FindDeleted(id string) (*models.Project, error)
+	// Restore undeletes a project and returns the record as it was while deleted
+	Restore(id string) (*models.Project, error)
+	PurgeDeleted(before time.Time) (int64, error)
+	List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.Project, int64, error)
+	ListByEmployee(page, pageSize int, search string, status string, employeeID string, includeDeleted bool) ([]models.Project, int64, error)
+	// FindByClient returns every live project of a client
+	FindByClient(clientID string) ([]models.Project, error)
+	// FindByEmployee returns every live project an employee is assigned to
+	FindByEmployee(employeeID string) ([]models.Project, error)
 	AssignEmployees(projectID string, employeeIDs []string) error
 }
 
 type projectRepository struct {
 	collection *mongo.Collection
 	userColl   *mongo.Collection
+	// ctx parents every operation; inside a unit of work it carries the session
+	ctx context.Context
 }
 
 func NewProjectRepository(db *mongo.Database) ProjectRepository {
+	return newProjectRepository(context.Background(), db)
+}
+
+func newProjectRepository(ctx context.Context, db *mongo.Database) *projectRepository {
 	return &projectRepository{
 		collection: db.Collection("projects"),
 		userColl:   db.Collection("users"),
+		ctx:        ctx,
 	}
 }
 
 func (r *projectRepository) Create(project *models.Project) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	project.CreatedAt = time.Now()
 	project.UpdatedAt = time.Now()
+	project.Version = 1
+	return r.Import(project)
+}
+
+func (r *projectRepository) Import(project *models.Project) error {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+	defer cancel()
+
 	if project.EmployeeIDs == nil {
 		project.EmployeeIDs = []string{}
 	}
@@ -60,9 +88,14 @@ func (r *projectRepository) Create(project *models.Project) error {
 		"status":       project.Status,
 		"progress":     project.Progress,
 		"employee_ids": project.EmployeeIDs,
+		"version":      project.Version,
 		"created_at":   project.CreatedAt,
 		"updated_at":   project.UpdatedAt,
 	}
+	if project.DeletedAt != nil {
+		doc["deleted_at"] = project.DeletedAt
+		doc["deleted_by"] = project.DeletedBy
+	}
 	
 	result, err := r.collection.InsertOne(ctx, doc)
 	if err != nil {
@@ -76,11 +109,11 @@ func (r *projectRepository) Create(project *models.Project) error {
 }
 
 func (r *projectRepository) FindByID(id string) (*models.Project, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
 	defer cancel()
 
 	var project models.Project
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&project)
+	err := r.collection.FindOne(ctx, excludeDeleted(bson.M{"_id": id}, false)).Decode(&project)
 	if err != nil {
 		return nil, err
 	}
@@ -112,32 +145,52 @@ func (r *projectRepository) FindByID(id string) (*models.Project, error) {
 }
 
 func (r *projectRepository) Update(project *models.Project) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
 	defer cancel()
 
 	project.UpdatedAt = time.Now()
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": project.ID}, bson.M{"$set": project})
-	return err
+	project.Version++
+	if err := updateVersioned(ctx, r.collection, project.ID, project.Version-1, bson.M{"$set": project}); err != nil {
+		project.Version--
+		return err
+	}
+	return nil
 }
 
-func (r *projectRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (r *projectRepository) Delete(id, deletedBy string) error {
+	return softDelete(r.ctx, r.collection, id, deletedBy)
+}
 
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (r *projectRepository) FindDeleted(id string) (*models.Project, error) {
+	var project models.Project
+	if err := findDeleted(r.ctx, r.collection, id, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
 }
 
-func (r *projectRepository) List(page, pageSize int, search string, status string, clientID *string) ([]models.Project, int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *projectRepository) Restore(id string) (*models.Project, error) {
+	var project models.Project
+	if err := restoreDeleted(r.ctx, r.collection, id, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted(r.ctx, r.collection, before, bson.M{})
+}
+
+func (r *projectRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.Project, int64, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{}
 	if search != "" {
 		filter["$or"] = []bson.M{
-			{"name": bson.M{"$regex": search, "$options": "i"}},
-			{"description": bson.M{"$regex": search, "$options": "i"}},
-			{"_id": bson.M{"$regex": search, "$options": "i"}},
+			{"name": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"description": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"_id": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
 		}
 	}
 	if status != "" {
@@ -146,6 +199,7 @@ func (r *projectRepository) List(page, pageSize int, search string, status strin
 	if clientID != nil {
 		filter["client_id"] = *clientID
 	}
+	excludeDeleted(filter, includeDeleted)
 
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -188,21 +242,22 @@ func (r *projectRepository) List(page, pageSize int, search string, status strin
 }
 
 
-func (r *projectRepository) ListByEmployee(page, pageSize int, search string, status string, employeeID string) ([]models.Project, int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *projectRepository) ListByEmployee(page, pageSize int, search string, status string, employeeID string, includeDeleted bool) ([]models.Project, int64, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"employee_ids": employeeID}
 	if search != "" {
 		filter["$or"] = []bson.M{
-			{"name": bson.M{"$regex": search, "$options": "i"}},
-			{"description": bson.M{"$regex": search, "$options": "i"}},
-			{"_id": bson.M{"$regex": search, "$options": "i"}},
+			{"name": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"description": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"_id": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
 		}
 	}
 	if status != "" {
 		filter["status"] = status
 	}
+	excludeDeleted(filter, includeDeleted)
 
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -244,14 +299,33 @@ func (r *projectRepository) ListByEmployee(page, pageSize int, search string, st
 	return projects, total, nil
 }
 
+func (r *projectRepository) FindByClient(clientID string) ([]models.Project, error) {
+	var projects []models.Project
+	if err := findLive(r.ctx, r.collection, bson.M{"client_id": clientID}, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) FindByEmployee(employeeID string) ([]models.Project, error) {
+	var projects []models.Project
+	if err := findLive(r.ctx, r.collection, bson.M{"employee_ids": employeeID}, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
 func (r *projectRepository) AssignEmployees(projectID string, employeeIDs []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
 	defer cancel()
 
 	_, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": projectID},
-		bson.M{"$set": bson.M{"employee_ids": employeeIDs, "updated_at": time.Now()}},
+		bson.M{
+			"$set": bson.M{"employee_ids": employeeIDs, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		},
 	)
 	return err
 }
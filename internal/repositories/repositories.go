@@ -0,0 +1,51 @@
+package repositories
+
+import "go.mongodb.org/mongo-driver/mongo"
+
+// Repositories holds one implementation of every repository, all backed by
+// the same store. Services are wired from it whatever the storage backend.
+type Repositories struct {
+	Users           UserRepository
+	Employees       EmployeeRepository
+	Projects        ProjectRepository
+	ServiceRequests ServiceRequestRepository
+	Messages        MessageRepository
+	Counters        CounterRepository
+	ServiceTypes    ServiceTypeRepository
+	Sessions        SessionRepository
+	UserTokens      UserTokenRepository
+	Invitations     InvitationRepository
+	LoginAttempts   LoginAttemptRepository
+	LockoutEvents   LockoutEventRepository
+	APIKeys         APIKeyRepository
+	OIDCStates      OIDCStateRepository
+	AuditEvents     AuditEventRepository
+	UnitOfWork      UnitOfWork
+}
+
+// NewMongoRepositories returns the MongoDB-backed repositories. It fails
+// with ErrTransactionsUnsupported as NewUnitOfWork does.
+func NewMongoRepositories(db *mongo.Database, allowNonTransactional bool) (*Repositories, error) {
+	unitOfWork, err := NewUnitOfWork(db, allowNonTransactional)
+	if err != nil {
+		return nil, err
+	}
+	return &Repositories{
+		Users:           NewUserRepository(db),
+		Employees:       NewEmployeeRepository(db),
+		Projects:        NewProjectRepository(db),
+		ServiceRequests: NewServiceRequestRepository(db),
+		Messages:        NewMessageRepository(db),
+		Counters:        NewCounterRepository(db),
+		ServiceTypes:    NewServiceTypeRepository(db),
+		Sessions:        NewSessionRepository(db),
+		UserTokens:      NewUserTokenRepository(db),
+		Invitations:     NewInvitationRepository(db),
+		LoginAttempts:   NewLoginAttemptRepository(db),
+		LockoutEvents:   NewLockoutEventRepository(db),
+		APIKeys:         NewAPIKeyRepository(db),
+		OIDCStates:      NewOIDCStateRepository(db),
+		AuditEvents:     NewAuditEventRepository(db),
+		UnitOfWork:      unitOfWork,
+	}, nil
+}
@@ -3,9 +3,10 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -13,33 +14,58 @@ import (
 
 type ServiceRequestRepository interface {
 	Create(request *models.ServiceRequest) error
+	// Import stores an exported service request as it was, keeping its
+	// timestamps, version and deletion stamp
+	Import(request *models.ServiceRequest) error
 	FindByID(id string) (*models.ServiceRequest, error)
+	// Update writes the record and bumps its version, or returns
+	// ErrStaleVersion when the stored version has moved on
 	Update(request *models.ServiceRequest) error
-	Delete(id string) error
-	List(page, pageSize int, search string, status string, clientID *string) ([]models.ServiceRequest, int64, error)
+	Delete(id, deletedBy string) error
+	// FindDeleted returns a deleted service request as stored, without its
+	// client and project
+	FindDeleted(id string) (*models.ServiceRequest, error)
+	// Restore undeletes a service request and returns the record as it was
+	// while deleted
+	Restore(id string) (*models.ServiceRequest, error)
+	PurgeDeleted(before time.Time) (int64, error)
+	List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.ServiceRequest, int64, error)
+	// FindByClient returns every live service request of a client
+	FindByClient(clientID string) ([]models.ServiceRequest, error)
 }
 
 type serviceRequestRepository struct {
 	collection  *mongo.Collection
 	userColl    *mongo.Collection
 	projectColl *mongo.Collection
+	// ctx parents every operation; inside a unit of work it carries the session
+	ctx context.Context
 }
 
 func NewServiceRequestRepository(db *mongo.Database) ServiceRequestRepository {
+	return newServiceRequestRepository(context.Background(), db)
+}
+
+func newServiceRequestRepository(ctx context.Context, db *mongo.Database) *serviceRequestRepository {
 	return &serviceRequestRepository{
 		collection:  db.Collection("service_requests"),
 		userColl:    db.Collection("users"),
 		projectColl: db.Collection("projects"),
+		ctx:         ctx,
 	}
 }
 
 func (r *serviceRequestRepository) Create(request *models.ServiceRequest) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	request.CreatedAt = time.Now()
 	request.UpdatedAt = time.Now()
-	
+	request.Version = 1
+	return r.Import(request)
+}
+
+func (r *serviceRequestRepository) Import(request *models.ServiceRequest) error {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+	defer cancel()
+
 	// Create document with explicit _id to ensure our custom ID is used
 	doc := bson.M{
 		"_id":         request.ID,
@@ -48,20 +74,25 @@ func (r *serviceRequestRepository) Create(request *models.ServiceRequest) error
 		"client_id":    request.ClientID,
 		"project_id":   request.ProjectID,
 		"status":       request.Status,
+		"version":      request.Version,
 		"created_at":   request.CreatedAt,
 		"updated_at":   request.UpdatedAt,
 	}
+	if request.DeletedAt != nil {
+		doc["deleted_at"] = request.DeletedAt
+		doc["deleted_by"] = request.DeletedBy
+	}
 	
 	_, err := r.collection.InsertOne(ctx, doc)
 	return err
 }
 
 func (r *serviceRequestRepository) FindByID(id string) (*models.ServiceRequest, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
 	defer cancel()
 
 	var request models.ServiceRequest
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&request)
+	err := r.collection.FindOne(ctx, excludeDeleted(bson.M{"_id": id}, false)).Decode(&request)
 	if err != nil {
 		return nil, err
 	}
@@ -93,33 +124,52 @@ func (r *serviceRequestRepository) FindByID(id string) (*models.ServiceRequest,
 }
 
 func (r *serviceRequestRepository) Update(request *models.ServiceRequest) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
 	defer cancel()
 
 	request.UpdatedAt = time.Now()
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": request.ID}, bson.M{"$set": request})
-	return err
+	request.Version++
+	if err := updateVersioned(ctx, r.collection, request.ID, request.Version-1, bson.M{"$set": request}); err != nil {
+		request.Version--
+		return err
+	}
+	return nil
 }
 
-func (r *serviceRequestRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (r *serviceRequestRepository) Delete(id, deletedBy string) error {
+	return softDelete(r.ctx, r.collection, id, deletedBy)
+}
 
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (r *serviceRequestRepository) FindDeleted(id string) (*models.ServiceRequest, error) {
+	var request models.ServiceRequest
+	if err := findDeleted(r.ctx, r.collection, id, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
 }
 
+func (r *serviceRequestRepository) Restore(id string) (*models.ServiceRequest, error) {
+	var request models.ServiceRequest
+	if err := restoreDeleted(r.ctx, r.collection, id, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
 
-func (r *serviceRequestRepository) List(page, pageSize int, search string, status string, clientID *string) ([]models.ServiceRequest, int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *serviceRequestRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return purgeDeleted(r.ctx, r.collection, before, bson.M{})
+}
+
+func (r *serviceRequestRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.ServiceRequest, int64, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{}
 	if search != "" {
 		filter["$or"] = []bson.M{
-			{"title": bson.M{"$regex": search, "$options": "i"}},
-			{"description": bson.M{"$regex": search, "$options": "i"}},
-			{"_id": bson.M{"$regex": search, "$options": "i"}},
+			{"title": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"description": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"_id": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
 		}
 	}
 	if status != "" {
@@ -128,6 +178,7 @@ func (r *serviceRequestRepository) List(page, pageSize int, search string, statu
 	if clientID != nil {
 		filter["client_id"] = *clientID
 	}
+	excludeDeleted(filter, includeDeleted)
 
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -167,3 +218,11 @@ func (r *serviceRequestRepository) List(page, pageSize int, search string, statu
 
 	return requests, total, nil
 }
+
+func (r *serviceRequestRepository) FindByClient(clientID string) ([]models.ServiceRequest, error) {
+	var requests []models.ServiceRequest
+	if err := findLive(r.ctx, r.collection, bson.M{"client_id": clientID}, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -2,54 +2,66 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	"time"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type ServiceTypeRepository struct {
+type ServiceTypeRepository interface {
+	Create(serviceType *models.ServiceType) error
+	// Import stores an exported service type as it was, keeping its ID and
+	// timestamps
+	Import(serviceType *models.ServiceType) error
+	GetByID(id string) (*models.ServiceType, error)
+	// GetAll returns every service type, or only those with status when it
+	// is set
+	GetAll(status *string) ([]models.ServiceType, error)
+	Update(id string, serviceType *models.ServiceType) error
+	Delete(id string) error
+}
+
+type serviceTypeRepository struct {
 	collection *mongo.Collection
 }
 
-func NewServiceTypeRepository(db *mongo.Database) *ServiceTypeRepository {
-	return &ServiceTypeRepository{
+func NewServiceTypeRepository(db *mongo.Database) ServiceTypeRepository {
+	return &serviceTypeRepository{
 		collection: db.Collection("service_types"),
 	}
 }
 
-func (r *ServiceTypeRepository) Create(serviceType *models.ServiceType) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
+func (r *serviceTypeRepository) Create(serviceType *models.ServiceType) error {
 	serviceType.ID = primitive.NewObjectID().Hex()
 	serviceType.CreatedAt = time.Now()
 	serviceType.UpdatedAt = time.Now()
+	return r.Import(serviceType)
+}
+
+func (r *serviceTypeRepository) Import(serviceType *models.ServiceType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
 	_, err := r.collection.InsertOne(ctx, serviceType)
 	return err
 }
 
-func (r *ServiceTypeRepository) GetByID(id string) (*models.ServiceType, error) {
+func (r *serviceTypeRepository) GetByID(id string) (*models.ServiceType, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var serviceType models.ServiceType
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&serviceType)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("service type not found")
-		}
 		return nil, err
 	}
 
 	return &serviceType, nil
 }
 
-func (r *ServiceTypeRepository) GetAll(status *string) ([]models.ServiceType, error) {
+func (r *serviceTypeRepository) GetAll(status *string) ([]models.ServiceType, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -72,7 +84,7 @@ func (r *ServiceTypeRepository) GetAll(status *string) ([]models.ServiceType, er
 	return serviceTypes, nil
 }
 
-func (r *ServiceTypeRepository) Update(id string, serviceType *models.ServiceType) error {
+func (r *serviceTypeRepository) Update(id string, serviceType *models.ServiceType) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -93,13 +105,13 @@ func (r *ServiceTypeRepository) Update(id string, serviceType *models.ServiceTyp
 	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("service type not found")
+		return mongo.ErrNoDocuments
 	}
 
 	return nil
 }
 
-func (r *ServiceTypeRepository) Delete(id string) error {
+func (r *serviceTypeRepository) Delete(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -109,7 +121,7 @@ func (r *ServiceTypeRepository) Delete(id string) error {
 	}
 
 	if result.DeletedCount == 0 {
-		return errors.New("service type not found")
+		return mongo.ErrNoDocuments
 	}
 
 	return nil
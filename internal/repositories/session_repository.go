@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	FindByID(id string) (*models.Session, error)
+	FindByRefreshTokenHash(hash string) (*models.Session, error)
+	Revoke(id string, replacedBy string) error
+	RevokeAllForUser(userID string) error
+}
+
+type sessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSessionRepository(db *mongo.Database) SessionRepository {
+	return &sessionRepository{collection: db.Collection("sessions")}
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *sessionRepository) FindByID(id string) (*models.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) FindByRefreshTokenHash(hash string) (*models.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"refresh_token_hash": hash}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Revoke marks a session as revoked. Only a session that is still active can be
+// revoked, so two concurrent refreshes of the same token cannot both succeed.
+func (r *sessionRepository) Revoke(id string, replacedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{"revoked_at": now, "updated_at": now}
+	if replacedBy != "" {
+		set["replaced_by"] = replacedBy
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "revoked_at": nil}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("session not found or already revoked")
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}},
+	)
+	return err
+}
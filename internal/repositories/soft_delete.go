@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Users, projects, service requests and messages are soft-deleted: Delete
+// stamps deleted_at and deleted_by, lookups skip stamped documents unless
+// asked not to, and PurgeDeleted removes tombstones once they are old enough.
+// The helpers take the repository's parent context so they join its
+// transaction, if any.
+
+// excludeDeleted narrows filter to documents that have not been deleted.
+// A null match also covers documents written before soft deletion existed.
+func excludeDeleted(filter bson.M, includeDeleted bool) bson.M {
+	if !includeDeleted {
+		filter["deleted_at"] = nil
+	}
+	return filter
+}
+
+// findLive decodes every live document matching filter into out, oldest
+// first. It is meant for the few records that reference one parent, not for
+// listings.
+func findLive(parent context.Context, collection *mongo.Collection, filter bson.M, out interface{}) error {
+	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := collection.Find(ctx, excludeDeleted(filter, false), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, out)
+}
+
+// softDelete marks a live document as deleted. It returns
+// mongo.ErrNoDocuments when there is no such document or it is already
+// deleted.
+func softDelete(parent context.Context, collection *mongo.Collection, id, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "deleted_at": nil},
+		bson.M{
+			"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// restoreDeleted clears the deletion stamp of a document and decodes it, as it
+// was while deleted, into tombstone. It returns mongo.ErrNoDocuments when
+// there is no such deleted document.
+func restoreDeleted(parent context.Context, collection *mongo.Collection, id string, tombstone interface{}) error {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	return collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{
+			"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+			"$inc":   bson.M{"version": 1},
+		},
+		opts,
+	).Decode(tombstone)
+}
+
+// findDeleted decodes the deleted document with id into out.
+func findDeleted(parent context.Context, collection *mongo.Collection, id string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	return collection.FindOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}}).Decode(out)
+}
+
+// purgeDeleted permanently removes documents matching filter that were
+// deleted before the cutoff.
+func purgeDeleted(parent context.Context, collection *mongo.Collection, before time.Time, filter bson.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+	defer cancel()
+
+	filter["deleted_at"] = bson.M{"$lt": before}
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
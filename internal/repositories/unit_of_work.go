@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UnitOfWork groups writes to several collections so they commit or fail
+// together.
+type UnitOfWork interface {
+	// Do runs fn in a transaction and commits when fn returns nil. Only the
+	// repositories handed to fn take part; fn may be retried on transient
+	// errors, so it must not have side effects outside them.
+	Do(fn func(tx Tx) error) error
+}
+
+// Tx hands out repositories joined to a running unit of work.
+type Tx interface {
+	Counters() CounterRepository
+	Projects() ProjectRepository
+	ServiceRequests() ServiceRequestRepository
+}
+
+// ErrTransactionsUnsupported is returned for a MongoDB server that cannot run
+// transactions when non-transactional writes were not allowed.
+var ErrTransactionsUnsupported = errors.New("MongoDB is not a replica set and cannot run transactions; set MONGO_ALLOW_NON_TRANSACTIONAL=true to run without them")
+
+type unitOfWork struct {
+	db           *mongo.Database
+	transactions bool
+}
+
+// NewUnitOfWork returns a unit of work backed by MongoDB transactions. They
+// need a replica set or sharded cluster; against a standalone server it
+// returns ErrTransactionsUnsupported, unless allowNonTransactional lets the
+// writes run one after another, without rolling back on failure.
+func NewUnitOfWork(db *mongo.Database, allowNonTransactional bool) (UnitOfWork, error) {
+	transactions := supportsTransactions(db)
+	if !transactions {
+		if !allowNonTransactional {
+			return nil, ErrTransactionsUnsupported
+		}
+		log.Println("WARNING: MongoDB is not a replica set: multi-document writes run without transactions")
+	}
+	return &unitOfWork{db: db, transactions: transactions}, nil
+}
+
+func (u *unitOfWork) Do(fn func(tx Tx) error) error {
+	if !u.transactions {
+		return fn(&mongoTx{ctx: context.Background(), db: u.db})
+	}
+
+	session, err := u.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(&mongoTx{ctx: sc, db: u.db})
+	})
+	return err
+}
+
+// supportsTransactions reports whether the server is a replica set member or
+// a mongos router.
+func supportsTransactions(db *mongo.Database) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+type mongoTx struct {
+	ctx context.Context
+	db  *mongo.Database
+}
+
+func (t *mongoTx) Counters() CounterRepository {
+	return newCounterRepository(t.ctx, t.db)
+}
+
+func (t *mongoTx) Projects() ProjectRepository {
+	return newProjectRepository(t.ctx, t.db)
+}
+
+func (t *mongoTx) ServiceRequests() ServiceRequestRepository {
+	return newServiceRequestRepository(t.ctx, t.db)
+}
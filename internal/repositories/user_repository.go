@@ -2,11 +2,10 @@ package repositories
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"regexp"
 	"time"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -14,30 +13,54 @@ import (
 
 type UserRepository interface {
 	Create(user *models.User) error
+	// Import stores an exported user as it was, keeping its timestamps,
+	// version and deletion stamp
+	Import(user *models.User) error
 	FindByID(id string) (*models.User, error)
 	FindByUserID(userID string) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
+	// EmailExists reports whether any account, deleted ones included, uses
+	// email. Deleted accounts keep their address until they are purged.
+	EmailExists(email string) (bool, error)
+	// Update writes the record and bumps its version, or returns
+	// ErrStaleVersion when the stored version has moved on
 	Update(user *models.User) error
-	Delete(id string) error
-	List(page, pageSize int, search string, role string) ([]models.User, int64, error)
-	GetNextUserID() (string, error)
+	Delete(id, deletedBy string) error
+	// Restore undeletes a user and returns the record as it was while deleted
+	Restore(id string) (*models.User, error)
+	// PurgeDeleted removes users deleted before the cutoff, except clients
+	// still referenced by a project or service request
+	PurgeDeleted(before time.Time) (int64, error)
+	List(page, pageSize int, search string, role string, includeDeleted bool) ([]models.User, int64, error)
+	// ListByStatus returns the live users with status, oldest first
+	ListByStatus(status string, page, pageSize int) ([]models.User, int64, error)
 }
 
 type userRepository struct {
-	collection *mongo.Collection
+	collection         *mongo.Collection
+	projectColl        *mongo.Collection
+	serviceRequestColl *mongo.Collection
 }
 
 func NewUserRepository(db *mongo.Database) UserRepository {
-	return &userRepository{collection: db.Collection("users")}
+	return &userRepository{
+		collection:         db.Collection("users"),
+		projectColl:        db.Collection("projects"),
+		serviceRequestColl: db.Collection("service_requests"),
+	}
 }
 
 func (r *userRepository) Create(user *models.User) error {
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+	user.Version = 1
+	return r.Import(user)
+}
+
+func (r *userRepository) Import(user *models.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
-	
 	// Set the UserID as the MongoDB _id
 	_, err := r.collection.InsertOne(ctx, user)
 	return err
@@ -48,7 +71,7 @@ func (r *userRepository) FindByID(id string) (*models.User, error) {
 	defer cancel()
 
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	err := r.collection.FindOne(ctx, excludeDeleted(bson.M{"_id": id}, false)).Decode(&user)
 	return &user, err
 }
 
@@ -57,48 +80,21 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	defer cancel()
 
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, excludeDeleted(bson.M{"email": email}, false)).Decode(&user)
 	return &user, err
 }
 
-func (r *userRepository) FindByUserID(userID string) (*models.User, error) {
-	// Since UserID is now the _id, we can use FindByID
-	return r.FindByID(userID)
-}
-
-func (r *userRepository) GetNextUserID() (string, error) {
+func (r *userRepository) EmailExists(email string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Find the highest existing user_id
-	opts := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(1)
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
-	if err != nil {
-		return "", err
-	}
-	defer cursor.Close(ctx)
-
-	var lastUser models.User
-	if cursor.Next(ctx) {
-		if err := cursor.Decode(&lastUser); err != nil {
-			return "", err
-		}
-	}
-
-	// If no users exist, start with USER01
-	if lastUser.UserID == "" {
-		return "USER01", nil
-	}
-
-	// Extract the numeric part and increment
-	var lastNum int
-	if _, err := fmt.Sscanf(lastUser.UserID, "USER%d", &lastNum); err != nil {
-		// If parsing fails, start with USER01
-		return "USER01", nil
-	}
+	count, err := r.collection.CountDocuments(ctx, bson.M{"email": email}, options.Count().SetLimit(1))
+	return count > 0, err
+}
 
-	nextNum := lastNum + 1
-	return fmt.Sprintf("USER%02d", nextNum), nil
+func (r *userRepository) FindByUserID(userID string) (*models.User, error) {
+	// Since UserID is now the _id, we can use FindByID
+	return r.FindByID(userID)
 }
 
 func (r *userRepository) Update(user *models.User) error {
@@ -120,44 +116,97 @@ func (r *userRepository) Update(user *models.User) error {
 		"salary":     user.Salary,
 		"status":     user.Status,
 		"hide":       user.Hide,
+		"email_verified_at": user.EmailVerifiedAt,
+		"two_factor_enabled": user.TwoFactorEnabled,
+		"two_factor_secret": user.TwoFactorSecret,
+		"two_factor_pending_secret": user.TwoFactorPendingSecret,
+		"two_factor_last_step": user.TwoFactorLastStep,
+		"recovery_codes": user.RecoveryCodes,
+		"version": user.Version + 1,
 		"updated_at": user.UpdatedAt,
 	}
 	
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": user.UserID}, bson.M{"$set": updateDoc})
-	return err
+	if err := updateVersioned(ctx, r.collection, user.UserID, user.Version, bson.M{"$set": updateDoc}); err != nil {
+		return err
+	}
+	user.Version++
+	return nil
 }
 
-func (r *userRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *userRepository) Delete(id, deletedBy string) error {
+	return softDelete(context.Background(), r.collection, id, deletedBy)
+}
+
+func (r *userRepository) Restore(id string) (*models.User, error) {
+	var user models.User
+	if err := restoreDeleted(context.Background(), r.collection, id, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) PurgeDeleted(before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$lt": before}}, opts)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	var candidates []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return 0, err
 	}
 
-	if result.DeletedCount == 0 {
-		return errors.New("user not found")
+	// Purging a client that still owns records would bring back the
+	// "Unknown Client" placeholders
+	var purgeable []string
+	for _, candidate := range candidates {
+		referenced, err := r.ownsRecords(ctx, candidate.ID)
+		if err != nil {
+			return 0, err
+		}
+		if !referenced {
+			purgeable = append(purgeable, candidate.ID)
+		}
+	}
+	if len(purgeable) == 0 {
+		return 0, nil
 	}
+	return purgeDeleted(ctx, r.collection, before, bson.M{"_id": bson.M{"$in": purgeable}})
+}
 
-	return nil
+// ownsRecords reports whether any project or service request, deleted or not,
+// belongs to the user.
+func (r *userRepository) ownsRecords(ctx context.Context, id string) (bool, error) {
+	for _, collection := range []*mongo.Collection{r.projectColl, r.serviceRequestColl} {
+		count, err := collection.CountDocuments(ctx, bson.M{"client_id": id}, options.Count().SetLimit(1))
+		if err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+	return false, nil
 }
 
-func (r *userRepository) List(page, pageSize int, search string, role string) ([]models.User, int64, error) {
+func (r *userRepository) List(page, pageSize int, search string, role string, includeDeleted bool) ([]models.User, int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{}
 	if search != "" {
 		filter["$or"] = []bson.M{
-			{"name": bson.M{"$regex": search, "$options": "i"}},
-			{"email": bson.M{"$regex": search, "$options": "i"}},
-			{"company": bson.M{"$regex": search, "$options": "i"}},
+			{"name": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"email": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
+			{"company": bson.M{"$regex": regexp.QuoteMeta(search), "$options": "i"}},
 		}
 	}
 	if role != "" {
 		filter["role"] = role
 	}
+	excludeDeleted(filter, includeDeleted)
 
 	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -180,3 +229,32 @@ func (r *userRepository) List(page, pageSize int, search string, role string) ([
 
 	return users, total, nil
 }
+
+func (r *userRepository) ListByStatus(status string, page, pageSize int) ([]models.User, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": status}
+	excludeDeleted(filter, false)
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * pageSize)
+	opts := options.Find().SetSkip(skip).SetLimit(int64(pageSize)).SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UserTokenRepository interface {
+	Create(token *models.UserToken) error
+	FindByHash(purpose models.TokenPurpose, hash string) (*models.UserToken, error)
+	MarkUsed(id string) error
+	DeleteForUser(userID string, purpose models.TokenPurpose) error
+}
+
+type userTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewUserTokenRepository(db *mongo.Database) UserTokenRepository {
+	return &userTokenRepository{collection: db.Collection("user_tokens")}
+}
+
+func (r *userTokenRepository) Create(token *models.UserToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *userTokenRepository) FindByHash(purpose models.TokenPurpose, hash string) (*models.UserToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var token models.UserToken
+	err := r.collection.FindOne(ctx, bson.M{"purpose": purpose, "token_hash": hash}).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a token. It fails if the token was already used so that a
+// token can never be redeemed twice, even by concurrent requests.
+func (r *userTokenRepository) MarkUsed(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("token already used")
+	}
+
+	return nil
+}
+
+func (r *userTokenRepository) DeleteForUser(userID string, purpose models.TokenPurpose) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID, "purpose": purpose})
+	return err
+}
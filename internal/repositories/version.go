@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Users, projects and service requests carry a version that every write bumps.
+// Update only applies when the stored version is still the one the record was
+// read at, so concurrent edits cannot overwrite each other.
+
+// ErrStaleVersion is returned by Update when the record was changed since it
+// was read.
+var ErrStaleVersion = errors.New("stale version")
+
+// versionFilter matches the document at version. Documents written before
+// versioning have no version field and count as version 0.
+func versionFilter(id string, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$in": bson.A{0, nil}}}
+	}
+	return bson.M{"_id": id, "version": version}
+}
+
+// updateVersioned applies update to the document while it is at version.
+func updateVersioned(ctx context.Context, collection *mongo.Collection, id string, version int64, update bson.M) error {
+	result, err := collection.UpdateOne(ctx, versionFilter(id, version), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrStaleVersion
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/vinodhini/software-api/config"
 	"github.com/vinodhini/software-api/internal/controllers"
 	"github.com/vinodhini/software-api/internal/middleware"
+	"github.com/vinodhini/software-api/internal/policy"
 )
 
 func SetupRoutes(
@@ -18,96 +19,192 @@ func SetupRoutes(
 	clientController *controllers.ClientController,
 	serviceTypeController *controllers.ServiceTypeController,
 	employeeController *controllers.EmployeeController,
+	passwordController *controllers.PasswordController,
+	invitationController *controllers.InvitationController,
+	registrationController *controllers.RegistrationController,
+	twoFactorController *controllers.TwoFactorController,
+	lockoutController *controllers.LockoutController,
+	jwksController *controllers.JWKSController,
+	apiKeyController *controllers.APIKeyController,
+	oidcController *controllers.OIDCController,
+	docsController *controllers.DocsController,
+	auditController *controllers.AuditController,
+	authMiddleware gin.HandlerFunc,
+	policyEngine *policy.Engine,
 ) {
+	// can guards a route with the actions that grant access to it
+	can := func(actions ...policy.Action) gin.HandlerFunc {
+		return middleware.Authorize(policyEngine, actions...)
+	}
+	// canSeeDeleted guards ?include_deleted=true on a listing with the
+	// permission to restore the records it reveals
+	canSeeDeleted := func(action policy.Action) gin.HandlerFunc {
+		return middleware.AuthorizeIncludeDeleted(policyEngine, action)
+	}
+
+	// Public signing keys for services that verify our access tokens
+	router.GET("/.well-known/jwks.json", jwksController.Get)
+
 	api := router.Group("/api")
 
 	// Public route for active service types (accessible by clients)
 	api.GET("/service-types", serviceTypeController.GetAll)
 
+	// API documentation (public)
+	api.GET("/openapi.json", docsController.Spec)
+	api.GET("/docs", docsController.Page)
+	api.GET("/docs/init.js", docsController.Script)
+
 	// Auth routes (public)
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", authController.Register)
 		auth.POST("/login", authController.Login)
+		auth.POST("/login/2fa", authController.LoginTwoFactor)
+		auth.POST("/login/2fa/setup", authController.LoginTwoFactorSetup)
+		auth.POST("/refresh", authController.Refresh)
+		auth.POST("/verify-email", authController.VerifyEmail)
+		auth.POST("/resend-verification", authController.ResendVerification)
+		auth.POST("/forgot-password", passwordController.ForgotPassword)
+		auth.POST("/reset-password", passwordController.ResetPassword)
+		auth.POST("/accept-invite", invitationController.Accept)
+		auth.GET("/oidc/login", oidcController.Login)
+		auth.GET("/oidc/callback", oidcController.Callback)
 	}
 
 	// Protected routes
 	protected := api.Group("")
-	protected.Use(middleware.AuthMiddleware(cfg.JWT.Secret))
+	protected.Use(authMiddleware)
 	{
+		protected.POST("/auth/logout", authController.Logout)
+
+		// Two-factor authentication management for the current user
+		twoFactor := protected.Group("/auth/2fa")
+		twoFactor.Use(middleware.SessionOnlyMiddleware())
+		{
+			twoFactor.POST("/setup", twoFactorController.Setup)
+			twoFactor.POST("/enable", twoFactorController.Enable)
+			twoFactor.POST("/disable", twoFactorController.Disable)
+			twoFactor.POST("/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+		}
+
+		// Personal API keys of the current user
+		apiKeys := protected.Group("/api-keys")
+		apiKeys.Use(middleware.SessionOnlyMiddleware())
+		{
+			apiKeys.POST("", apiKeyController.Create)
+			apiKeys.GET("", apiKeyController.List)
+			apiKeys.DELETE("/:id", apiKeyController.Revoke)
+		}
+
 		// Employee routes
 		employees := protected.Group("/employees")
 		{
-			employees.POST("", middleware.RoleMiddleware("admin"), employeeController.Create)
-			employees.GET("", employeeController.List)
-			employees.GET("/:id", employeeController.GetByID)
-			employees.PUT("/:id", middleware.RoleMiddleware("admin"), userController.Update)
-			employees.PATCH("/:id", middleware.RoleMiddleware("admin"), userController.Patch)
-			employees.DELETE("/:id", middleware.RoleMiddleware("admin"), userController.Delete)
+			employees.POST("", can(policy.EmployeeCreate), employeeController.Create)
+			employees.GET("", can(policy.EmployeeList), canSeeDeleted(policy.UserRestore), employeeController.List)
+			employees.GET("/:id", can(policy.EmployeeRead), employeeController.GetByID)
+			employees.PUT("/:id", can(policy.EmployeeUpdate), userController.Update)
+			employees.PATCH("/:id", can(policy.EmployeeUpdate), userController.Patch)
+			employees.DELETE("/:id", can(policy.EmployeeDelete), userController.Delete)
 		}
 
 		// User routes
 		users := protected.Group("/users")
 		{
-			users.GET("", middleware.RoleMiddleware("admin"), userController.List)
-			users.GET("/:id", userController.GetByID)
-			users.PUT("/:id", userController.Update)
-			users.PATCH("/:id", userController.Patch)
-			users.DELETE("/:id", middleware.RoleMiddleware("admin"), userController.Delete)
-			users.GET("/dashboard/stats", middleware.RoleMiddleware("employee", "admin", "client"), userController.GetDashboardStats)
+			users.GET("", can(policy.UserList), canSeeDeleted(policy.UserRestore), userController.List)
+			users.GET("/:id", can(policy.UserRead), userController.GetByID)
+			users.PUT("/:id", can(policy.UserUpdate), userController.Update)
+			users.PATCH("/:id", can(policy.UserUpdate), userController.Patch)
+			users.DELETE("/:id", can(policy.UserDelete), userController.Delete)
+			users.POST("/:id/restore", can(policy.UserRestore), userController.Restore)
+			users.GET("/dashboard/stats", can(policy.UserDashboard), userController.GetDashboardStats)
 		}
 
 		// Client routes
 		clients := protected.Group("/clients")
 		{
-			clients.POST("", clientController.Create)
-			clients.GET("", clientController.List)
-			clients.GET("/:id", clientController.GetByID)
-			clients.PUT("/:id", clientController.Update)
-			clients.DELETE("/:id", middleware.RoleMiddleware("admin"), clientController.Delete)
+			clients.POST("", can(policy.ClientCreate), clientController.Create)
+			clients.GET("", can(policy.ClientList), canSeeDeleted(policy.UserRestore), clientController.List)
+			clients.GET("/:id", can(policy.ClientRead), clientController.GetByID)
+			clients.PUT("/:id", can(policy.ClientUpdate), clientController.Update)
+			clients.DELETE("/:id", can(policy.ClientDelete), clientController.Delete)
 		}
 
 		// Project routes
 		projects := protected.Group("/projects")
 		{
-			projects.POST("", middleware.RoleMiddleware("admin"), projectController.Create)
-			projects.GET("", projectController.List)
-			projects.GET("/:id", projectController.GetByID)
-			projects.PUT("/:id", middleware.RoleMiddleware("admin", "employee"), projectController.Update)
-			projects.DELETE("/:id", middleware.RoleMiddleware("admin"), projectController.Delete)
-			projects.POST("/:id/assign", middleware.RoleMiddleware("admin"), projectController.AssignEmployees)
-			projects.PATCH("/:id/progress", middleware.RoleMiddleware("admin", "employee", "client"), projectController.UpdateProgress)
-			projects.GET("/:id/messages", messageController.ListByProject)
+			projects.POST("", can(policy.ProjectCreate), projectController.Create)
+			projects.GET("", can(policy.ProjectList), canSeeDeleted(policy.ProjectRestore), projectController.List)
+			projects.GET("/:id", can(policy.ProjectRead), projectController.GetByID)
+			projects.PUT("/:id", can(policy.ProjectUpdate, policy.ProjectUpdateStatus), projectController.Update)
+			projects.DELETE("/:id", can(policy.ProjectDelete), projectController.Delete)
+			projects.POST("/:id/restore", can(policy.ProjectRestore), projectController.Restore)
+			projects.POST("/:id/assign", can(policy.ProjectAssign), projectController.AssignEmployees)
+			projects.PATCH("/:id/progress", can(policy.ProjectProgress), projectController.UpdateProgress)
+			projects.GET("/:id/messages", can(policy.MessageList), canSeeDeleted(policy.MessageRestore), messageController.ListByProject)
 		}
 
 		// Service request routes
 		serviceRequests := protected.Group("/service-requests")
 		{
-			serviceRequests.POST("", middleware.RoleMiddleware("client"), serviceRequestController.Create)
-			serviceRequests.GET("", serviceRequestController.List)
-			serviceRequests.GET("/:id", serviceRequestController.GetByID)
-			serviceRequests.PUT("/:id", middleware.RoleMiddleware("admin", "employee"), serviceRequestController.Update)
-			serviceRequests.DELETE("/:id", middleware.RoleMiddleware("admin"), serviceRequestController.Delete)
-			serviceRequests.POST("/:id/approve", middleware.RoleMiddleware("admin"), serviceRequestController.Approve)
-			serviceRequests.POST("/:id/reject", middleware.RoleMiddleware("admin"), serviceRequestController.Reject)
+			serviceRequests.POST("", can(policy.ServiceRequestCreate), serviceRequestController.Create)
+			serviceRequests.GET("", can(policy.ServiceRequestList), canSeeDeleted(policy.ServiceRequestRestore), serviceRequestController.List)
+			serviceRequests.GET("/:id", can(policy.ServiceRequestRead), serviceRequestController.GetByID)
+			serviceRequests.PUT("/:id", can(policy.ServiceRequestUpdate), serviceRequestController.Update)
+			serviceRequests.DELETE("/:id", can(policy.ServiceRequestDelete), serviceRequestController.Delete)
+			serviceRequests.POST("/:id/restore", can(policy.ServiceRequestRestore), serviceRequestController.Restore)
+			serviceRequests.POST("/:id/approve", can(policy.ServiceRequestApprove), serviceRequestController.Approve)
+			serviceRequests.POST("/:id/reject", can(policy.ServiceRequestReject), serviceRequestController.Reject)
+		}
+
+		// Invitation routes
+		invitations := protected.Group("/invitations")
+		invitations.Use(can(policy.InvitationManage))
+		{
+			invitations.POST("", invitationController.Create)
+			invitations.GET("", invitationController.List)
+			invitations.POST("/:id/resend", invitationController.Resend)
+			invitations.DELETE("/:id", invitationController.Revoke)
 		}
 
-		// Service type routes (admin only for management)
+		// Registration approval routes
+		registrations := protected.Group("/registrations")
+		registrations.Use(can(policy.RegistrationReview))
+		{
+			registrations.GET("", registrationController.List)
+			registrations.POST("/:id/approve", registrationController.Approve)
+			registrations.POST("/:id/reject", registrationController.Reject)
+		}
+
+		// Login lockout routes
+		lockouts := protected.Group("/lockouts")
+		lockouts.Use(can(policy.LockoutManage))
+		{
+			lockouts.GET("", lockoutController.ListLocked)
+			lockouts.GET("/events", lockoutController.ListEvents)
+			lockouts.POST("/unlock", lockoutController.Unlock)
+		}
+
+		// Audit log routes
+		protected.GET("/audit", can(policy.AuditRead), auditController.List)
+
+		// Service type management routes
 		serviceTypes := protected.Group("/service-types")
 		{
-			serviceTypes.POST("", middleware.RoleMiddleware("admin"), serviceTypeController.Create)
-			serviceTypes.GET("/:id", serviceTypeController.GetByID)
-			serviceTypes.PUT("/:id", middleware.RoleMiddleware("admin"), serviceTypeController.Update)
-			serviceTypes.DELETE("/:id", middleware.RoleMiddleware("admin"), serviceTypeController.Delete)
+			serviceTypes.POST("", can(policy.ServiceTypeCreate), serviceTypeController.Create)
+			serviceTypes.GET("/:id", can(policy.ServiceTypeRead), serviceTypeController.GetByID)
+			serviceTypes.PUT("/:id", can(policy.ServiceTypeUpdate), serviceTypeController.Update)
+			serviceTypes.DELETE("/:id", can(policy.ServiceTypeDelete), serviceTypeController.Delete)
 		}
 
 		// Message routes
 		messages := protected.Group("/messages")
 		{
-			messages.GET("", messageController.List)
-			messages.POST("", messageController.Create)
-			messages.GET("/:id", messageController.GetByID)
-			messages.DELETE("/:id", messageController.Delete)
+			messages.GET("", can(policy.MessageList), messageController.List)
+			messages.POST("", can(policy.MessageCreate), messageController.Create)
+			messages.GET("/:id", can(policy.MessageRead), messageController.GetByID)
+			messages.DELETE("/:id", can(policy.MessageDelete), messageController.Delete)
+			messages.POST("/:id/restore", can(policy.MessageRestore), messageController.Restore)
 		}
 	}
 }
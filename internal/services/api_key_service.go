@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// apiKeyPrefix marks our keys so they are easy to recognise in logs and secret scanners
+const apiKeyPrefix = "vsk_"
+
+// lastUsedResolution limits how often a busy key's last_used_at is written
+const lastUsedResolution = time.Minute
+
+var (
+	ErrInvalidAPIKey = apperrors.Unauthorized("INVALID_API_KEY", "invalid, expired or revoked API key")
+	ErrAPIKeyRevoked = apperrors.Conflict("API_KEY_REVOKED", "API key is already revoked")
+)
+
+type APIKeyService interface {
+	// Create issues a key owned by actor
+	Create(req *models.CreateAPIKeyRequest, actor models.Actor) (*models.CreateAPIKeyResponse, error)
+	List(userID string) ([]models.APIKey, error)
+	Revoke(id string, actor models.Actor) error
+	Authenticate(rawKey string) (*models.APIKey, *models.User, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+	policy     *policy.Engine
+	cfg        *config.Config
+	audit      AuditService
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository, policyEngine *policy.Engine, cfg *config.Config, audit AuditService) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		policy:     policyEngine,
+		cfg:        cfg,
+		audit:      audit,
+	}
+}
+
+func (s *apiKeyService) Create(req *models.CreateAPIKeyRequest, actor models.Actor) (*models.CreateAPIKeyResponse, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	rawKey := apiKeyPrefix + token
+
+	expiry := s.cfg.Auth.APIKeyDefaultExpiry
+	if req.ExpiresInDays > 0 {
+		expiry = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	key := &models.APIKey{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    actor.ID,
+		Name:      req.Name,
+		Prefix:    rawKey[:len(apiKeyPrefix)+8],
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    req.Scopes,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionCreate, auditAPIKey, key.ID, nil, snapshot(key))
+	return &models.CreateAPIKeyResponse{Key: rawKey, APIKey: *key}, nil
+}
+
+func (s *apiKeyService) List(userID string) ([]models.APIKey, error) {
+	return s.apiKeyRepo.ListByUser(userID)
+}
+
+// Revoke disables a key. Keys the caller may not revoke are reported as not
+// found so their IDs cannot be probed.
+func (s *apiKeyService) Revoke(id string, actor models.Actor) error {
+	key, err := s.apiKeyRepo.FindByID(id)
+	if err != nil || !s.policy.Can(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.APIKeyRevoke, policy.Resource{OwnerID: key.UserID}) {
+		return apperrors.NotFound("API_KEY_NOT_FOUND", "API key not found")
+	}
+	if key.RevokedAt != nil {
+		return ErrAPIKeyRevoked
+	}
+
+	// A concurrent revoke may win between the check above and the update
+	if err := s.apiKeyRepo.Revoke(id); errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrAPIKeyRevoked
+	} else if err != nil {
+		return err
+	}
+
+	before := snapshot(key)
+	now := time.Now()
+	key.RevokedAt = &now
+	s.audit.Record(actor, models.AuditActionRevoke, auditAPIKey, id, before, snapshot(key))
+	return nil
+}
+
+// Authenticate resolves a raw key to the key record and its owner. The owner is
+// loaded on every call so role changes and deactivation apply immediately.
+func (s *apiKeyService) Authenticate(rawKey string) (*models.APIKey, *models.User, error) {
+	key, err := s.apiKeyRepo.FindByHash(utils.HashToken(rawKey))
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || now.After(key.ExpiresAt) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(key.UserID)
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if user.Status != "" && user.Status != models.UserStatusActive {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("Failed to record use of API key %s: %v", key.ID, err)
+		}
+	}
+
+	return key, user, nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MockAPIKeyRepository for testing
+type MockAPIKeyRepository struct {
+	keys map[string]*models.APIKey
+}
+
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{
+		keys: make(map[string]*models.APIKey),
+	}
+}
+
+func (m *MockAPIKeyRepository) Create(key *models.APIKey) error {
+	key.CreatedAt = time.Now()
+	key.UpdatedAt = time.Now()
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *MockAPIKeyRepository) FindByID(id string) (*models.APIKey, error) {
+	key, exists := m.keys[id]
+	if !exists {
+		return nil, errors.New("API key not found")
+	}
+	return key, nil
+}
+
+func (m *MockAPIKeyRepository) FindByHash(hash string) (*models.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == hash {
+			return key, nil
+		}
+	}
+	return nil, errors.New("API key not found")
+}
+
+func (m *MockAPIKeyRepository) ListByUser(userID string) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) Revoke(id string) error {
+	key, exists := m.keys[id]
+	if !exists || key.RevokedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return nil
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(id string, usedAt time.Time) error {
+	if key, exists := m.keys[id]; exists {
+		key.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+func TestAPIKey_AuthenticateAndRevoke(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	apiKeyRepo := NewMockAPIKeyRepository()
+	cfg := &config.Config{Auth: config.AuthConfig{APIKeyDefaultExpiry: 24 * time.Hour}}
+	apiKeyService := NewAPIKeyService(apiKeyRepo, userRepo, policy.Default(), cfg, newTestAuditService())
+
+	userRepo.Create(&models.User{UserID: "USER01", Email: "bot@example.com", Role: models.RoleEmployee, Status: models.UserStatusActive})
+
+	created, err := apiKeyService.Create(&models.CreateAPIKeyRequest{Name: "CI", Scopes: []string{models.APIKeyScopeRead}}, models.Actor{ID: "USER01"})
+	if err != nil {
+		t.Fatalf("Expected no error creating API key, got: %v", err)
+	}
+
+	if !strings.HasPrefix(created.Key, created.APIKey.Prefix) || apiKeyRepo.keys[created.APIKey.ID].KeyHash == created.Key {
+		t.Error("Expected only a hash of the key to be stored, with a displayable prefix")
+	}
+
+	key, user, err := apiKeyService.Authenticate(created.Key)
+	if err != nil {
+		t.Fatalf("Expected key to authenticate, got: %v", err)
+	}
+
+	if user.UserID != "USER01" || !key.HasScope(models.APIKeyScopeRead) || key.HasScope(models.APIKeyScopeWrite) {
+		t.Errorf("Expected read-only key of USER01, got user %s scopes %v", user.UserID, key.Scopes)
+	}
+
+	if key.LastUsedAt == nil {
+		t.Error("Expected last_used_at to be recorded")
+	}
+
+	if err := apiKeyService.Revoke(created.APIKey.ID, models.Actor{ID: "USER02", Role: string(models.RoleClient)}); err == nil {
+		t.Error("Expected other users to be unable to revoke the key")
+	}
+
+	if err := apiKeyService.Revoke(created.APIKey.ID, models.Actor{ID: "USER01", Role: string(models.RoleEmployee)}); err != nil {
+		t.Fatalf("Expected owner to revoke the key, got: %v", err)
+	}
+
+	if _, _, err := apiKeyService.Authenticate(created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected revoked key to be rejected, got: %v", err)
+	}
+
+	if err := apiKeyService.Revoke(created.APIKey.ID, models.Actor{ID: "USER01", Role: string(models.RoleEmployee)}); err != ErrAPIKeyRevoked {
+		t.Errorf("Expected ErrAPIKeyRevoked revoking twice, got: %v", err)
+	}
+}
+
+func TestAPIKey_RejectsExpiredKeyAndInactiveOwner(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	apiKeyRepo := NewMockAPIKeyRepository()
+	cfg := &config.Config{Auth: config.AuthConfig{APIKeyDefaultExpiry: 24 * time.Hour}}
+	apiKeyService := NewAPIKeyService(apiKeyRepo, userRepo, policy.Default(), cfg, newTestAuditService())
+
+	userRepo.Create(&models.User{UserID: "USER01", Email: "bot@example.com", Role: models.RoleEmployee, Status: models.UserStatusActive})
+
+	expiring, _ := apiKeyService.Create(&models.CreateAPIKeyRequest{Name: "Old", Scopes: []string{models.APIKeyScopeWrite}}, models.Actor{ID: "USER01"})
+	apiKeyRepo.keys[expiring.APIKey.ID].ExpiresAt = time.Now().Add(-time.Minute)
+	if _, _, err := apiKeyService.Authenticate(expiring.Key); err == nil {
+		t.Error("Expected expired key to be rejected")
+	}
+
+	active, _ := apiKeyService.Create(&models.CreateAPIKeyRequest{Name: "New", Scopes: []string{models.APIKeyScopeWrite}, ExpiresInDays: 30}, models.Actor{ID: "USER01"})
+	if days := time.Until(active.APIKey.ExpiresAt).Hours() / 24; days < 29 || days > 30 {
+		t.Errorf("Expected key to expire in 30 days, got %.1f", days)
+	}
+
+	userRepo.users["USER01"].Status = models.UserStatusInactive
+	if _, _, err := apiKeyService.Authenticate(active.Key); err == nil {
+		t.Error("Expected keys of a deactivated user to be rejected")
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Resource types recorded in the audit log
+const (
+	auditUser           = "user"
+	auditClient         = "client"
+	auditEmployee       = "employee"
+	auditProject        = "project"
+	auditServiceRequest = "service_request"
+	auditServiceType    = "service_type"
+	auditMessage        = "message"
+	auditInvitation     = "invitation"
+	auditAPIKey         = "api_key"
+)
+
+// Fields left out of audit diffs: timestamps and versions change on every
+// write and the expanded relations are not stored with the resource
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+	"client":     true,
+	"employees":  true,
+	"project":    true,
+	"sender":     true,
+}
+
+type AuditService interface {
+	// Record stores an event for a change. before and after are snapshots
+	// taken with snapshot; before is nil for creations and after for
+	// deletions. Failures are logged rather than returned because the change
+	// itself has already been made.
+	Record(actor models.Actor, action models.AuditAction, resourceType, resourceID string, before, after map[string]interface{})
+	List(query *models.AuditQuery) ([]models.AuditEvent, int64, error)
+}
+
+type auditService struct {
+	eventRepo repositories.AuditEventRepository
+}
+
+func NewAuditService(eventRepo repositories.AuditEventRepository) AuditService {
+	return &auditService{eventRepo: eventRepo}
+}
+
+func (s *auditService) Record(actor models.Actor, action models.AuditAction, resourceType, resourceID string, before, after map[string]interface{}) {
+	event := &models.AuditEvent{
+		ID:           primitive.NewObjectID().Hex(),
+		ActorID:      actor.ID,
+		ActorRole:    actor.Role,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      diff(before, after),
+		IP:           actor.IP,
+		RequestID:    actor.RequestID,
+	}
+
+	if err := s.eventRepo.Create(event); err != nil {
+		log.Printf("Failed to record audit event %s %s %s: %v", action, resourceType, resourceID, err)
+	}
+}
+
+func (s *auditService) List(query *models.AuditQuery) ([]models.AuditEvent, int64, error) {
+	return s.eventRepo.List(query)
+}
+
+// snapshot captures the JSON view of a resource, so fields hidden from API
+// responses such as password hashes never reach the audit log.
+func snapshot(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// diff returns the fields whose values differ between two snapshots.
+func diff(before, after map[string]interface{}) map[string]models.AuditChange {
+	changes := make(map[string]models.AuditChange)
+	for field, old := range before {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if value, ok := after[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = models.AuditChange{Before: old, After: after[field]}
+		}
+	}
+	for field, value := range after {
+		if _, seen := before[field]; seen || auditIgnoredFields[field] {
+			continue
+		}
+		changes[field] = models.AuditChange{After: value}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// MockAuditEventRepository for testing
+type MockAuditEventRepository struct {
+	events []models.AuditEvent
+}
+
+func NewMockAuditEventRepository() *MockAuditEventRepository {
+	return &MockAuditEventRepository{}
+}
+
+func (m *MockAuditEventRepository) Create(event *models.AuditEvent) error {
+	event.CreatedAt = time.Now()
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *MockAuditEventRepository) List(query *models.AuditQuery) ([]models.AuditEvent, int64, error) {
+	var events []models.AuditEvent
+	for _, event := range m.events {
+		if (query.ResourceType == "" || event.ResourceType == query.ResourceType) && (query.ResourceID == "" || event.ResourceID == query.ResourceID) {
+			events = append(events, event)
+		}
+	}
+	return events, int64(len(events)), nil
+}
+
+func newTestAuditService() AuditService {
+	return NewAuditService(NewMockAuditEventRepository())
+}
+
+func TestAudit_RecordsProjectStatusChange(t *testing.T) {
+	// Setup
+	projectRepo := NewMockProjectRepository()
+	eventRepo := NewMockAuditEventRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), newTestIntegrityService(NewMockUserRepository(), projectRepo), NewAuditService(eventRepo))
+
+	projectRepo.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01", EmployeeIDs: []string{"EMP01"}, Status: models.StatusPending})
+
+	employee := models.Actor{ID: "EMP01", Role: "employee", IP: "10.0.0.1", RequestID: "req-1"}
+	if _, err := projectService.Update("PROJECT01", &models.UpdateProjectRequest{Status: models.StatusInProgress}, MatchVersion(0), employee); err != nil {
+		t.Fatalf("Expected no error updating the status, got: %v", err)
+	}
+
+	if len(eventRepo.events) != 1 {
+		t.Fatalf("Expected one audit event, got: %+v", eventRepo.events)
+	}
+	event := eventRepo.events[0]
+	if event.ActorID != "EMP01" || event.ActorRole != "employee" || event.IP != "10.0.0.1" || event.RequestID != "req-1" {
+		t.Errorf("Expected the event to record the caller, got: %+v", event)
+	}
+	if event.Action != models.AuditActionUpdate || event.ResourceType != "project" || event.ResourceID != "PROJECT01" {
+		t.Errorf("Expected a project update event, got: %+v", event)
+	}
+
+	// Only the changed field is recorded; timestamps are left out
+	change, ok := event.Changes["status"]
+	if len(event.Changes) != 1 || !ok || change.Before != string(models.StatusPending) || change.After != string(models.StatusInProgress) {
+		t.Errorf("Expected only the status change, got: %+v", event.Changes)
+	}
+
+	// A rejected change is not recorded
+	if _, err := projectService.Update("PROJECT01", &models.UpdateProjectRequest{Name: "Renamed"}, MatchVersion(1), employee); err == nil {
+		t.Fatal("Expected access denied renaming the project")
+	}
+	if len(eventRepo.events) != 1 {
+		t.Errorf("Expected no event for a denied change, got: %+v", eventRepo.events)
+	}
+}
+
+func TestAudit_DeleteKeepsSnapshotWithoutSecrets(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	eventRepo := NewMockAuditEventRepository()
+	userService := NewUserService(userRepo, NewMockProjectRepository(), NewMockSessionRepository(), policy.Default(), newTestIntegrityService(userRepo, NewMockProjectRepository()), NewAuditService(eventRepo))
+
+	userRepo.Create(&models.User{UserID: "USER02", Name: "Leaving", Email: "leaving@example.com", Password: "hash", Role: models.RoleEmployee})
+
+	if err := userService.Delete("USER02", models.Actor{ID: "ADMIN01", Role: "admin"}); err != nil {
+		t.Fatalf("Expected no error deleting the user, got: %v", err)
+	}
+
+	events, total, _ := NewAuditService(eventRepo).List(&models.AuditQuery{ResourceType: "user", ResourceID: "USER02"})
+	if total != 1 || events[0].Action != models.AuditActionDelete || events[0].ActorID != "ADMIN01" {
+		t.Fatalf("Expected one delete event by the admin, got: %+v", events)
+	}
+	if change := events[0].Changes["email"]; change.Before != "leaving@example.com" || change.After != nil {
+		t.Errorf("Expected the deleted email in the before snapshot, got: %+v", change)
+	}
+	if _, ok := events[0].Changes["password"]; ok {
+		t.Error("Expected the password hash to be left out of the audit log")
+	}
+}
+
+func TestAudit_RecordsTwoFactorChanges(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	eventRepo := NewMockAuditEventRepository()
+	cfg := &config.Config{Auth: config.AuthConfig{TOTPIssuer: "Test"}}
+	twoFactorService := NewTwoFactorService(userRepo, newTestLockoutService(cfg), cfg, NewAuditService(eventRepo))
+
+	userRepo.Create(&models.User{UserID: "USER01", Email: "2fa@example.com", Role: models.RoleEmployee, Status: models.UserStatusActive})
+	user := models.Actor{ID: "USER01", Role: "employee", IP: "10.0.0.1"}
+
+	setup, err := twoFactorService.Setup("USER01")
+	if err != nil {
+		t.Fatalf("Expected no error starting setup, got: %v", err)
+	}
+	code, _ := utils.TOTPCode(setup.Secret, time.Now())
+	recoveryCodes, err := twoFactorService.Enable(&models.TwoFactorCodeRequest{Code: code}, user)
+	if err != nil {
+		t.Fatalf("Expected no error enabling 2FA, got: %v", err)
+	}
+	if err := twoFactorService.Disable(&models.TwoFactorCodeRequest{Code: recoveryCodes[0]}, user); err != nil {
+		t.Fatalf("Expected no error disabling 2FA, got: %v", err)
+	}
+
+	if len(eventRepo.events) != 2 {
+		t.Fatalf("Expected two audit events, got: %+v", eventRepo.events)
+	}
+	enabled, disabled := eventRepo.events[0], eventRepo.events[1]
+	if enabled.Action != models.AuditActionEnableTwoFactor || enabled.ActorID != "USER01" || enabled.IP != "10.0.0.1" || enabled.ResourceID != "USER01" {
+		t.Errorf("Expected an enable event by the user, got: %+v", enabled)
+	}
+	if change := enabled.Changes["two_factor_enabled"]; change.Before != nil || change.After != true {
+		t.Errorf("Expected the enable event to show the flag turning on, got: %+v", enabled.Changes)
+	}
+	if disabled.Action != models.AuditActionDisableTwoFactor {
+		t.Errorf("Expected a disable event, got: %+v", disabled)
+	}
+	for _, event := range eventRepo.events {
+		if _, ok := event.Changes["two_factor_secret"]; ok {
+			t.Errorf("Expected the 2FA secret to be left out of the audit log, got: %+v", event.Changes)
+		}
+	}
+}
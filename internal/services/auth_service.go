@@ -2,35 +2,90 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/vinodhini/software-api/config"
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/mailer"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AuthService interface {
-	Register(req *models.RegisterRequest) (*models.User, error)
-	Login(req *models.LoginRequest) (*models.LoginResponse, error)
+	// Register creates an unverified account when the registration policy
+	// allows it. actor only carries the request details; the new user becomes
+	// the actor of the recorded event.
+	Register(req *models.RegisterRequest, actor models.Actor) (*models.User, error)
+	// CreateAdmin creates an active, verified admin. Only the admin CLI calls
+	// it, as registration refuses the admin role.
+	CreateAdmin(req *models.CreateAdminRequest, actor models.Actor) (*models.User, error)
+	Login(req *models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	Refresh(req *models.RefreshTokenRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	Logout(sessionID string) error
+	VerifyEmail(req *models.VerifyEmailRequest) error
+	ResendVerification(req *models.ResendVerificationRequest) error
+	LoginTwoFactor(req *models.TwoFactorLoginRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	LoginTwoFactorSetup(req *models.TwoFactorChallengeRequest) (*models.TwoFactorSetupResponse, error)
+	CreateSession(user *models.User, client models.ClientInfo) (*models.LoginResponse, error)
 }
 
+// ErrEmailNotVerified is returned by Login for accounts that have not confirmed their email address yet.
+var ErrEmailNotVerified = apperrors.Forbidden("EMAIL_NOT_VERIFIED", "email address has not been verified. Please check your inbox for the verification link")
+
+// ErrInvalidTwoFactorLogin rejects the second step of a login. Unlike
+// ErrInvalidTwoFactorCode it is an authentication failure.
+var ErrInvalidTwoFactorLogin = apperrors.Unauthorized("INVALID_TWO_FACTOR_CODE", "invalid two-factor code")
+
+// ErrAdminRegistration refuses self-registration as an admin. Admins are
+// invited, or created with the admin CLI.
+var ErrAdminRegistration = apperrors.Forbidden("ADMIN_REGISTRATION_DISABLED", "admin accounts cannot be registered; ask an administrator for an invitation")
+
+var errInvalidVerificationToken = apperrors.Validation("INVALID_VERIFICATION_TOKEN", "invalid or expired verification token")
+
 type authService struct {
-	userRepo repositories.UserRepository
-	cfg      *config.Config
+	userRepo       repositories.UserRepository
+	ids            IDGenerator
+	sessionRepo    repositories.SessionRepository
+	tokenRepo      repositories.UserTokenRepository
+	lockoutService LockoutService
+	mailer         mailer.Mailer
+	keys           *utils.KeySet
+	cfg            *config.Config
+	audit          AuditService
 }
 
-func NewAuthService(userRepo repositories.UserRepository, cfg *config.Config) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, ids IDGenerator, sessionRepo repositories.SessionRepository, tokenRepo repositories.UserTokenRepository, lockoutService LockoutService, mailer mailer.Mailer, keys *utils.KeySet, cfg *config.Config, audit AuditService) AuthService {
 	return &authService{
-		userRepo: userRepo,
-		cfg:      cfg,
+		userRepo:       userRepo,
+		ids:            ids,
+		sessionRepo:    sessionRepo,
+		tokenRepo:      tokenRepo,
+		lockoutService: lockoutService,
+		mailer:         mailer,
+		keys:           keys,
+		cfg:            cfg,
+		audit:          audit,
 	}
 }
 
-func (s *authService) Register(req *models.RegisterRequest) (*models.User, error) {
-	_, err := s.userRepo.FindByEmail(req.Email)
-	if err == nil {
-		return nil, errors.New("email already exists")
+func (s *authService) Register(req *models.RegisterRequest, actor models.Actor) (*models.User, error) {
+	if req.Role == "" {
+		req.Role = models.RoleClient
+	}
+	if err := checkRegistration(s.cfg.Registration, req); err != nil {
+		return nil, err
+	}
+
+	// Deleted accounts keep their email until they are purged
+	if taken, err := s.userRepo.EmailExists(req.Email); err != nil {
+		return nil, apperrors.Internal(err)
+	} else if taken {
+		return nil, ErrEmailTaken
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
@@ -39,7 +94,7 @@ func (s *authService) Register(req *models.RegisterRequest) (*models.User, error
 	}
 
 	// Generate next user ID like USER01, USER02, etc.
-	userID, err := s.userRepo.GetNextUserID()
+	userID, err := s.ids.NextUserID()
 	if err != nil {
 		return nil, err
 	}
@@ -50,42 +105,406 @@ func (s *authService) Register(req *models.RegisterRequest) (*models.User, error
 		Password: hashedPassword,
 		Name:     req.Name,
 		Role:     req.Role,
-		Status:   "active", // Set default status to active for new users
+		Status:   models.UserStatusUnverified, // Activated once the email address is confirmed
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	actor.ID, actor.Role = user.UserID, string(user.Role)
+	s.audit.Record(actor, models.AuditActionRegister, auditUser, user.UserID, nil, snapshot(user))
+
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
+	return user, nil
+}
+
+func (s *authService) CreateAdmin(req *models.CreateAdminRequest, actor models.Actor) (*models.User, error) {
+	if taken, err := s.userRepo.EmailExists(req.Email); err != nil {
+		return nil, apperrors.Internal(err)
+	} else if taken {
+		return nil, ErrEmailTaken
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := s.ids.NextUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &models.User{
+		UserID:          userID,
+		Email:           req.Email,
+		Password:        hashedPassword,
+		Name:            req.Name,
+		Role:            models.RoleAdmin,
+		Status:          models.UserStatusActive,
+		EmailVerifiedAt: &now,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, err
 	}
+	s.audit.Record(actor, models.AuditActionCreate, auditUser, user.UserID, nil, snapshot(user))
 
 	return user, nil
 }
 
-func (s *authService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+func (s *authService) Login(req *models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	// Checked before the password so a locked account cannot be probed
+	if err := s.lockoutService.Check(req.Email); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("invalid credentials")
+			// Unknown emails are tracked too so they behave like real accounts
+			s.recordLoginFailure(req.Email, client)
+			return nil, ErrInvalidCredentials
 		}
 		return nil, err
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid credentials")
+		s.recordLoginFailure(req.Email, client)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Status == models.UserStatusUnverified {
+		return nil, ErrEmailNotVerified
+	}
+	if user.Status == models.UserStatusPendingApproval {
+		return nil, ErrAccountPendingApproval
 	}
 
 	// Check if user is active
 	if user.Status != "" && user.Status != "active" {
-		return nil, errors.New("account is inactive. Please contact your system administrator to activate your account")
+		return nil, ErrAccountInactive
 	}
 
-	// Use UserID instead of MongoDB ObjectID for token generation
-	token, err := utils.GenerateToken(user.UserID, user.Email, string(user.Role), s.cfg.JWT.Secret, s.cfg.JWT.Expiry)
+	return s.completeLogin(user, client)
+}
+
+// completeLogin finishes the first step of a login: users with 2FA, and
+// admins who must enrol in it, get a challenge; everyone else gets tokens.
+func (s *authService) completeLogin(user *models.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	// The first factor alone is not enough; hand out a challenge for the second step
+	if user.TwoFactorEnabled {
+		return s.twoFactorChallenge(user, challengeTwoFactor)
+	}
+
+	if s.cfg.Auth.RequireAdmin2FA && user.Role == models.RoleAdmin {
+		return s.twoFactorChallenge(user, challengeTwoFactorSetup)
+	}
+
+	s.recordLoginSuccess(user.Email)
+	response, _, err := s.issueTokens(user, client)
+	return response, err
+}
+
+// LoginTwoFactor completes a login that was answered with a challenge token. For
+// a regular challenge the code may be a TOTP code or a recovery code; for a
+// setup challenge it must confirm the secret from LoginTwoFactorSetup.
+func (s *authService) LoginTwoFactor(req *models.TwoFactorLoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	claims, err := utils.ValidateToken(req.ChallengeToken, s.keys)
+	if err != nil || (claims.Purpose != challengeTwoFactor && claims.Purpose != challengeTwoFactorSetup) {
+		return nil, ErrInvalidChallengeToken
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallengeToken
+	}
+
+	// Guessing second-factor codes counts against the same limit as passwords
+	if err := s.lockoutService.Check(user.Email); err != nil {
+		return nil, err
+	}
+
+	// The account may have been deactivated since the password was checked
+	if user.Status != "" && user.Status != models.UserStatusActive {
+		return nil, ErrAccountInactive
+	}
+
+	before := snapshot(user)
+	var recoveryCodes []string
+	if claims.Purpose == challengeTwoFactorSetup {
+		if user.TwoFactorEnabled {
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		if recoveryCodes, err = completeTwoFactorSetup(user, req.Code); err != nil {
+			s.recordLoginFailure(user.Email, client)
+			return nil, ErrInvalidTwoFactorLogin.Wrap(err)
+		}
+	} else {
+		if !user.TwoFactorEnabled || !verifySecondFactor(user, req.Code) {
+			s.recordLoginFailure(user.Email, client)
+			return nil, ErrInvalidTwoFactorLogin
+		}
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	if claims.Purpose == challengeTwoFactorSetup {
+		actor := models.Actor{ID: user.UserID, Role: string(user.Role), IP: client.IP}
+		s.audit.Record(actor, models.AuditActionEnableTwoFactor, auditUser, user.UserID, before, snapshot(user))
+	}
+
+	s.recordLoginSuccess(user.Email)
+
+	response, _, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// LoginTwoFactorSetup generates the secret for an account that must enrol in
+// 2FA before its first login completes.
+func (s *authService) LoginTwoFactorSetup(req *models.TwoFactorChallengeRequest) (*models.TwoFactorSetupResponse, error) {
+	claims, err := utils.ValidateToken(req.ChallengeToken, s.keys)
+	if err != nil || claims.Purpose != challengeTwoFactorSetup {
+		return nil, ErrInvalidChallengeToken
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallengeToken
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	setup, err := beginTwoFactorSetup(user, s.cfg.Auth.TOTPIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return setup, nil
+}
+
+func (s *authService) Refresh(req *models.RefreshTokenRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	session, err := s.sessionRepo.FindByRefreshTokenHash(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil {
+		// A rotated token being presented again means it leaked; end every session of the user
+		if session.ReplacedBy != "" {
+			if err := s.sessionRepo.RevokeAllForUser(session.UserID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if user.Status != "" && user.Status != "active" {
+		return nil, ErrAccountInactive
+	}
+
+	response, newSessionID, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.Revoke(session.ID, newSessionID); err != nil {
+		// Lost a race with another refresh of the same token; drop the session we just created
+		s.sessionRepo.Revoke(newSessionID, "")
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	return response, nil
+}
+
+func (s *authService) Logout(sessionID string) error {
+	if sessionID == "" {
+		return apperrors.Unauthorized("SESSION_NOT_FOUND", "session not found")
+	}
+
+	return s.sessionRepo.Revoke(sessionID, "")
+}
+
+func (s *authService) VerifyEmail(req *models.VerifyEmailRequest) error {
+	token, err := s.tokenRepo.FindByHash(models.TokenPurposeEmailVerification, utils.HashToken(req.Token))
+	if err != nil {
+		return errInvalidVerificationToken
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return errInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return errInvalidVerificationToken
+	}
+
+	if err := s.tokenRepo.MarkUsed(token.ID); err != nil {
+		return errInvalidVerificationToken
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	// Do not re-enable an account an administrator deactivated in the meantime
+	if user.Status == models.UserStatusUnverified {
+		user.Status = verifiedStatus(s.cfg.Registration)
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	return s.tokenRepo.DeleteForUser(user.UserID, models.TokenPurposeEmailVerification)
+}
+
+// ResendVerification issues a fresh verification link. Like ForgotPassword it
+// does not reveal whether the email belongs to an account.
+func (s *authService) ResendVerification(req *models.ResendVerificationRequest) error {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil || user.Status != models.UserStatusUnverified {
+		return nil
+	}
+
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
+	return nil
+}
+
+func (s *authService) sendVerificationEmail(user *models.User) error {
+	// Only the most recently sent link stays valid
+	if err := s.tokenRepo.DeleteForUser(user.UserID, models.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	verificationToken := &models.UserToken{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    user.UserID,
+		Purpose:   models.TokenPurposeEmailVerification,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.Auth.EmailVerificationExpiry),
+	}
+
+	if err := s.tokenRepo.Create(verificationToken); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.cfg.Mail.AppURL, token)
+	return s.mailer.Send(mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nThanks for registering. Please confirm your email address using the link below:\n\n%s\n\nThis link expires in %s.\n",
+			user.Name, link, s.cfg.Auth.EmailVerificationExpiry,
+		),
+	})
+}
+
+// CreateSession signs in a user that was authenticated elsewhere, such as by
+// single sign-on. Lockouts and two-factor authentication apply as they do to
+// a password login. The caller is responsible for checking the account status.
+func (s *authService) CreateSession(user *models.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	if err := s.lockoutService.Check(user.Email); err != nil {
+		return nil, err
+	}
+	return s.completeLogin(user, client)
+}
+
+// recordLoginFailure and recordLoginSuccess only log errors: failing to update
+// the counters must not change the outcome of the login itself.
+func (s *authService) recordLoginFailure(email string, client models.ClientInfo) {
+	if err := s.lockoutService.RecordFailure(email, client.IP); err != nil {
+		log.Printf("Failed to record failed login for %s: %v", email, err)
+	}
+}
+
+func (s *authService) recordLoginSuccess(email string) {
+	if err := s.lockoutService.RecordSuccess(email); err != nil {
+		log.Printf("Failed to reset failed logins for %s: %v", email, err)
+	}
+}
+
+func (s *authService) twoFactorChallenge(user *models.User, purpose string) (*models.LoginResponse, error) {
+	token, err := utils.GenerateChallengeToken(user.UserID, purpose, s.keys, s.cfg.Auth.TwoFactorChallengeExpiry)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.LoginResponse{
-		Token: token,
-		User:  *user,
+		TwoFactorRequired:      purpose == challengeTwoFactor,
+		TwoFactorSetupRequired: purpose == challengeTwoFactorSetup,
+		ChallengeToken:         token,
+		ExpiresIn:              int64(s.cfg.Auth.TwoFactorChallengeExpiry.Seconds()),
 	}, nil
 }
+
+// issueTokens creates a new server-side session for the user and returns the
+// access/refresh token pair bound to it together with the new session ID.
+func (s *authService) issueTokens(user *models.User, client models.ClientInfo) (*models.LoginResponse, string, error) {
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := &models.Session{
+		ID:               primitive.NewObjectID().Hex(),
+		UserID:           user.UserID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        client.UserAgent,
+		IP:               client.IP,
+		ExpiresAt:        time.Now().Add(s.cfg.JWT.RefreshExpiry),
+	}
+
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, "", err
+	}
+
+	// Use UserID instead of MongoDB ObjectID for token generation
+	token, err := utils.GenerateToken(user.UserID, user.Email, string(user.Role), session.ID, s.keys, s.cfg.JWT.Expiry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &models.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.cfg.JWT.Expiry.Seconds()),
+		User:         *user,
+	}, session.ID, nil
+}
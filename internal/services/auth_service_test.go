@@ -2,14 +2,20 @@ package services
 
 import (
 	"errors"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/vinodhini/software-api/config"
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/pkg/models"
 	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var testKeys = utils.NewHMACKeySet("test-secret")
+
 // MockUserRepository for testing
 type MockUserRepository struct {
 	users map[string]*models.User
@@ -28,19 +34,33 @@ func (m *MockUserRepository) Create(user *models.User) error {
 	return nil
 }
 
+func (m *MockUserRepository) Import(user *models.User) error {
+	m.users[user.UserID] = user
+	return nil
+}
+
 func (m *MockUserRepository) FindByEmail(email string) (*models.User, error) {
 	for _, user := range m.users {
-		if user.Email == email {
+		if user.Email == email && user.DeletedAt == nil {
 			return user, nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *MockUserRepository) EmailExists(email string) (bool, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func (m *MockUserRepository) FindByID(id string) (*models.User, error) {
 	user, exists := m.users[id]
-	if !exists {
-		return nil, errors.New("user not found")
+	if !exists || user.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
 	}
 	return user, nil
 }
@@ -51,25 +71,119 @@ func (m *MockUserRepository) FindByUserID(userID string) (*models.User, error) {
 
 func (m *MockUserRepository) Update(user *models.User) error {
 	user.UpdatedAt = time.Now()
+	user.Version++
 	m.users[user.UserID] = user
 	return nil
 }
 
-func (m *MockUserRepository) Delete(id string) error {
-	delete(m.users, id)
+func (m *MockUserRepository) Delete(id, deletedBy string) error {
+	user, exists := m.users[id]
+	if !exists || user.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	user.DeletedAt = &now
+	user.DeletedBy = deletedBy
 	return nil
 }
 
-func (m *MockUserRepository) List(page, pageSize int, search string, role string) ([]models.User, int64, error) {
+func (m *MockUserRepository) Restore(id string) (*models.User, error) {
+	user, exists := m.users[id]
+	if !exists || user.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	tombstone := *user
+	user.DeletedAt = nil
+	user.DeletedBy = ""
+	return &tombstone, nil
+}
+
+func (m *MockUserRepository) PurgeDeleted(before time.Time) (int64, error) {
+	var removed int64
+	for id, user := range m.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) {
+			delete(m.users, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (m *MockUserRepository) List(page, pageSize int, search string, role string, includeDeleted bool) ([]models.User, int64, error) {
+	var users []models.User
+	for _, user := range m.users {
+		if includeDeleted || user.DeletedAt == nil {
+			users = append(users, *user)
+		}
+	}
+	return users, int64(len(users)), nil
+}
+
+func (m *MockUserRepository) ListByStatus(status string, page, pageSize int) ([]models.User, int64, error) {
 	var users []models.User
 	for _, user := range m.users {
-		users = append(users, *user)
+		if user.Status == status && user.DeletedAt == nil {
+			users = append(users, *user)
+		}
 	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
 	return users, int64(len(users)), nil
 }
 
-func (m *MockUserRepository) GetNextUserID() (string, error) {
-	return "USER01", nil
+// MockSessionRepository for testing
+type MockSessionRepository struct {
+	sessions map[string]*models.Session
+}
+
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		sessions: make(map[string]*models.Session),
+	}
+}
+
+func (m *MockSessionRepository) Create(session *models.Session) error {
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *MockSessionRepository) FindByID(id string) (*models.Session, error) {
+	session, exists := m.sessions[id]
+	if !exists {
+		return nil, errors.New("session not found")
+	}
+	return session, nil
+}
+
+func (m *MockSessionRepository) FindByRefreshTokenHash(hash string) (*models.Session, error) {
+	for _, session := range m.sessions {
+		if session.RefreshTokenHash == hash {
+			return session, nil
+		}
+	}
+	return nil, errors.New("session not found")
+}
+
+func (m *MockSessionRepository) Revoke(id string, replacedBy string) error {
+	session, exists := m.sessions[id]
+	if !exists || session.RevokedAt != nil {
+		return errors.New("session not found or already revoked")
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.ReplacedBy = replacedBy
+	return nil
+}
+
+func (m *MockSessionRepository) RevokeAllForUser(userID string) error {
+	now := time.Now()
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
 }
 
 func TestLogin_ActiveUser(t *testing.T) {
@@ -83,7 +197,7 @@ func TestLogin_ActiveUser(t *testing.T) {
 		},
 	}
 	
-	authService := NewAuthService(mockRepo, cfg)
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
 	
 	// Create a test user with active status
 	hashedPassword, _ := utils.HashPassword("password123")
@@ -105,7 +219,7 @@ func TestLogin_ActiveUser(t *testing.T) {
 		Password: "password123",
 	}
 	
-	response, err := authService.Login(loginReq)
+	response, err := authService.Login(loginReq, models.ClientInfo{})
 	
 	// Assertions
 	if err != nil {
@@ -136,7 +250,7 @@ func TestLogin_InactiveUser(t *testing.T) {
 		},
 	}
 	
-	authService := NewAuthService(mockRepo, cfg)
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
 	
 	// Create a test user with inactive status
 	hashedPassword, _ := utils.HashPassword("password123")
@@ -158,7 +272,7 @@ func TestLogin_InactiveUser(t *testing.T) {
 		Password: "password123",
 	}
 	
-	response, err := authService.Login(loginReq)
+	response, err := authService.Login(loginReq, models.ClientInfo{})
 	
 	// Assertions
 	if err == nil {
@@ -186,7 +300,7 @@ func TestLogin_UserWithoutStatus(t *testing.T) {
 		},
 	}
 	
-	authService := NewAuthService(mockRepo, cfg)
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
 	
 	// Create a test user without status (should be treated as active)
 	hashedPassword, _ := utils.HashPassword("password123")
@@ -208,7 +322,7 @@ func TestLogin_UserWithoutStatus(t *testing.T) {
 		Password: "password123",
 	}
 	
-	response, err := authService.Login(loginReq)
+	response, err := authService.Login(loginReq, models.ClientInfo{})
 	
 	// Assertions
 	if err != nil {
@@ -224,19 +338,22 @@ func TestLogin_UserWithoutStatus(t *testing.T) {
 	}
 }
 
-func TestRegister_DefaultActiveStatus(t *testing.T) {
+func TestRegister_RequiresEmailVerification(t *testing.T) {
 	// Setup
 	mockRepo := NewMockUserRepository()
-	jwtExpiry, _ := time.ParseDuration("24h")
+	mail := mailer.NewMemoryMailer()
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
-			Secret: "test-secret",
-			Expiry: jwtExpiry,
+			Secret:        "test-secret",
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: time.Hour,
 		},
+		Auth: config.AuthConfig{EmailVerificationExpiry: time.Hour},
+		Mail: config.MailConfig{AppURL: "http://localhost:3000"},
 	}
-	
-	authService := NewAuthService(mockRepo, cfg)
-	
+
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mail, testKeys, cfg, newTestAuditService())
+
 	// Test user registration
 	registerReq := &models.RegisterRequest{
 		Email:    "newuser@example.com",
@@ -244,19 +361,169 @@ func TestRegister_DefaultActiveStatus(t *testing.T) {
 		Name:     "New User",
 		Role:     models.RoleClient,
 	}
-	
-	user, err := authService.Register(registerReq)
-	
+
+	user, err := authService.Register(registerReq, models.Actor{})
+
 	// Assertions
 	if err != nil {
-		t.Errorf("Expected no error during registration, got: %v", err)
+		t.Fatalf("Expected no error during registration, got: %v", err)
 	}
-	
-	if user == nil {
-		t.Error("Expected user, got nil")
+
+	if user.Status != models.UserStatusUnverified {
+		t.Errorf("Expected status '%s', got '%s'", models.UserStatusUnverified, user.Status)
 	}
-	
-	if user.Status != "active" {
-		t.Errorf("Expected status 'active', got '%s'", user.Status)
+
+	loginReq := &models.LoginRequest{Email: "newuser@example.com", Password: "password123"}
+	if _, err := authService.Login(loginReq, models.ClientInfo{}); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("Expected ErrEmailNotVerified before verification, got: %v", err)
+	}
+
+	messages := mail.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 verification email, got %d", len(messages))
+	}
+	body := messages[0].Body
+	token := strings.Fields(body[strings.Index(body, "token=")+len("token="):])[0]
+
+	if err := authService.VerifyEmail(&models.VerifyEmailRequest{Token: token}); err != nil {
+		t.Fatalf("Expected no error verifying email, got: %v", err)
+	}
+
+	if user.Status != models.UserStatusActive || user.EmailVerifiedAt == nil {
+		t.Errorf("Expected verified active user, got status '%s'", user.Status)
+	}
+
+	if _, err := authService.Login(loginReq, models.ClientInfo{}); err != nil {
+		t.Errorf("Expected login to succeed after verification, got: %v", err)
+	}
+}
+
+func TestRegister_RefusesAdminRole(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", Expiry: 15 * time.Minute, RefreshExpiry: time.Hour}}
+	mockRepo := NewMockUserRepository()
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+
+	req := &models.RegisterRequest{Email: "mallory@example.com", Password: "password123", Name: "Mallory", Role: models.RoleAdmin}
+	if _, err := authService.Register(req, models.Actor{}); !errors.Is(err, ErrAdminRegistration) {
+		t.Fatalf("Expected ErrAdminRegistration, got: %v", err)
+	}
+	if exists, _ := mockRepo.EmailExists("mallory@example.com"); exists {
+		t.Error("Expected no account to be created")
+	}
+}
+
+func TestCreateAdmin_CanLogInStraightAway(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", Expiry: 15 * time.Minute, RefreshExpiry: time.Hour}}
+	mail := mailer.NewMemoryMailer()
+	authService := NewAuthService(NewMockUserRepository(), newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mail, testKeys, cfg, newTestAuditService())
+
+	req := &models.CreateAdminRequest{Email: "root@example.com", Password: "password123", Name: "Root"}
+	admin, err := authService.CreateAdmin(req, models.Actor{ID: "cli", Role: "admin"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if admin.Role != models.RoleAdmin || admin.Status != models.UserStatusActive || admin.EmailVerifiedAt == nil {
+		t.Errorf("Expected an active, verified admin, got %+v", admin)
+	}
+	if len(mail.Messages()) != 0 {
+		t.Errorf("Expected no verification email, got %d", len(mail.Messages()))
+	}
+
+	if _, err := authService.Login(&models.LoginRequest{Email: "root@example.com", Password: "password123"}, models.ClientInfo{}); err != nil {
+		t.Errorf("Expected login to succeed, got: %v", err)
+	}
+	if _, err := authService.CreateAdmin(req, models.Actor{}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken for a second admin with the email, got: %v", err)
+	}
+}
+
+func TestRefresh_RotatesSession(t *testing.T) {
+	// Setup
+	mockRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:        "test-secret",
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: time.Hour,
+		},
+	}
+
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), sessionRepo, NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo.Create(&models.User{
+		UserID:   "USER01",
+		Email:    "refresh@example.com",
+		Password: hashedPassword,
+		Role:     models.RoleClient,
+		Status:   "active",
+	})
+
+	login, err := authService.Login(&models.LoginRequest{Email: "refresh@example.com", Password: "password123"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error on login, got: %v", err)
+	}
+
+	if login.RefreshToken == "" {
+		t.Fatal("Expected refresh token in login response")
+	}
+
+	refreshed, err := authService.Refresh(&models.RefreshTokenRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error on refresh, got: %v", err)
+	}
+
+	if refreshed.RefreshToken == login.RefreshToken {
+		t.Error("Expected refresh token to be rotated")
+	}
+
+	oldClaims, _ := utils.ValidateToken(login.Token, testKeys)
+	if session, _ := sessionRepo.FindByID(oldClaims.SessionID); session.RevokedAt == nil {
+		t.Error("Expected previous session to be revoked after rotation")
+	}
+
+	// Replaying the rotated token must fail and revoke the whole session family
+	if _, err := authService.Refresh(&models.RefreshTokenRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{}); err == nil {
+		t.Error("Expected error when reusing a rotated refresh token")
+	}
+
+	newClaims, _ := utils.ValidateToken(refreshed.Token, testKeys)
+	if session, _ := sessionRepo.FindByID(newClaims.SessionID); session.RevokedAt == nil {
+		t.Error("Expected all sessions to be revoked after refresh token reuse")
+	}
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	// Setup
+	mockRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:        "test-secret",
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: time.Hour,
+		},
+	}
+
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), sessionRepo, NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo.Create(&models.User{
+		UserID:   "USER01",
+		Email:    "logout@example.com",
+		Password: hashedPassword,
+		Role:     models.RoleClient,
+	})
+
+	login, _ := authService.Login(&models.LoginRequest{Email: "logout@example.com", Password: "password123"}, models.ClientInfo{})
+	claims, _ := utils.ValidateToken(login.Token, testKeys)
+
+	if err := authService.Logout(claims.SessionID); err != nil {
+		t.Fatalf("Expected no error on logout, got: %v", err)
+	}
+
+	if _, err := authService.Refresh(&models.RefreshTokenRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{}); err == nil {
+		t.Error("Expected refresh to fail after logout")
 	}
 }
@@ -1,51 +1,71 @@
 package services
 
 import (
-	"errors"
-	"fmt"
-
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/policy"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type ClientService interface {
-	Create(req *models.CreateClientRequest) (*models.User, error)
+	Create(req *models.CreateClientRequest, actor models.Actor) (*models.User, error)
 	GetByID(id string) (*models.User, error)
-	Update(id string, req *models.UpdateUserRequest) (*models.User, error)
-	Delete(id string) error
+	// Update applies req when the client is still at a version match allows
+	Update(id string, req *models.UpdateUserRequest, match VersionMatch, actor models.Actor) (*models.User, error)
+	Delete(id string, actor models.Actor) error
 	List(query *models.PaginationQuery) ([]models.User, int64, error)
 }
 
 type clientService struct {
-	userRepo repositories.UserRepository
+	userRepo    repositories.UserRepository
+	ids         IDGenerator
+	sessionRepo repositories.SessionRepository
+	policy      *policy.Engine
+	integrity   IntegrityService
+	audit       AuditService
 }
 
-func NewClientService(userRepo repositories.UserRepository) ClientService {
-	return &clientService{userRepo: userRepo}
+func NewClientService(userRepo repositories.UserRepository, ids IDGenerator, sessionRepo repositories.SessionRepository, policyEngine *policy.Engine, integrity IntegrityService, audit AuditService) ClientService {
+	return &clientService{
+		userRepo:    userRepo,
+		ids:         ids,
+		sessionRepo: sessionRepo,
+		policy:      policyEngine,
+		integrity:   integrity,
+		audit:       audit,
+	}
 }
 
-func (s *clientService) Create(req *models.CreateClientRequest) (*models.User, error) {
-	fmt.Printf("ClientService.Create called with Request: %+v\n", req)
+func (s *clientService) Create(req *models.CreateClientRequest, actor models.Actor) (*models.User, error) {
+	// Any other role needs the permission to set roles, as on /api/users
+	if req.Role != "" && req.Role != string(models.RoleClient) {
+		subject := policy.Subject{ID: actor.ID, Role: actor.Role}
+		if err := s.policy.Authorize(subject, policy.UserUpdateRole, policy.UserResource("")); err != nil {
+			return nil, err
+		}
+		if !s.policy.HasRole(req.Role) {
+			return nil, apperrors.Validation("UNKNOWN_ROLE", "unknown role %q", req.Role)
+		}
+	}
 
-	// Check if user with this email already exists
-	existingUser, err := s.userRepo.FindByEmail(req.Email)
-	if err == nil && existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+	// Deleted accounts keep their email until they are purged
+	if taken, err := s.userRepo.EmailExists(req.Email); err != nil {
+		return nil, apperrors.Internal(err)
+	} else if taken {
+		return nil, ErrEmailTaken
 	}
 
 	// Generate next user ID like USER01, USER02, etc.
-	userID, err := s.userRepo.GetNextUserID()
+	userID, err := s.ids.NextUserID()
 	if err != nil {
-		fmt.Printf("Error generating user ID: %v\n", err)
 		return nil, err
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		fmt.Printf("Password hashing error: %v\n", err)
-		return nil, errors.New("failed to hash password")
+		return nil, apperrors.Internal(err)
 	}
 
 	// Create user object
@@ -67,55 +87,79 @@ func (s *clientService) Create(req *models.CreateClientRequest) (*models.User, e
 		user.Role = models.RoleClient
 	}
 
-	fmt.Printf("Creating user: %+v\n", user)
-
 	// Save to database
 	if err := s.userRepo.Create(user); err != nil {
-		fmt.Printf("Repository create error: %v\n", err)
 		return nil, err
 	}
 
-	fmt.Printf("Client created successfully\n")
+	s.audit.Record(actor, models.AuditActionCreate, auditClient, user.UserID, nil, snapshot(user))
 	return user, nil
 }
 
 func (s *clientService) GetByID(id string) (*models.User, error) {
-	return s.userRepo.FindByID(id)
+	return s.findClient(id)
 }
 
-func (s *clientService) Update(id string, req *models.UpdateUserRequest) (*models.User, error) {
-	fmt.Printf("ClientService.Update called with ID: %s, Request: %+v\n", id, req)
+// findClient returns the client with id. Other accounts are not found here,
+// so the client routes cannot be used to change employees or admins.
+func (s *clientService) findClient(id string) (*models.User, error) {
+	client, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, lookupError(err, ErrClientNotFound)
+	}
+	if client.Role != models.RoleClient {
+		return nil, ErrClientNotFound
+	}
+
+	return client, nil
+}
 
-	user, err := s.userRepo.FindByID(id)
+func (s *clientService) Update(id string, req *models.UpdateUserRequest, match VersionMatch, actor models.Actor) (*models.User, error) {
+	user, err := s.findClient(id)
 	if err != nil {
-		fmt.Printf("Client not found: %v\n", err)
-		return nil, errors.New("client not found")
+		return nil, err
+	}
+
+	// Credentials, status and role are account changes: they need the same
+	// permissions as on /api/users
+	subject := policy.Subject{ID: actor.ID, Role: actor.Role}
+	resource := policy.UserResource(user.UserID)
+	if req.Password != "" || req.Status != "" {
+		if err := s.policy.Authorize(subject, policy.UserUpdate, resource); err != nil {
+			return nil, err
+		}
+	}
+	if req.Role != "" {
+		if err := s.policy.Authorize(subject, policy.UserUpdateRole, resource); err != nil {
+			return nil, err
+		}
+		if !s.policy.HasRole(req.Role) {
+			return nil, apperrors.Validation("UNKNOWN_ROLE", "unknown role %q", req.Role)
+		}
 	}
 
-	fmt.Printf("Found client: %+v\n", user)
+	if !match.Matches(user.Version) {
+		return nil, ErrVersionMismatch
+	}
+	before := snapshot(user)
 
 	if req.Name != "" {
 		user.Name = req.Name
-		fmt.Printf("Updated name to: %s\n", req.Name)
 	}
 	if req.Email != "" {
 		user.Email = req.Email
-		fmt.Printf("Updated email to: %s\n", req.Email)
 	}
 	if req.Phone != "" {
 		user.Phone = req.Phone
-		fmt.Printf("Updated phone to: %s\n", req.Phone)
 	}
 	if req.Role != "" {
 		user.Role = models.Role(req.Role)
 	}
 	if req.Company != "" {
 		user.Company = req.Company
-		fmt.Printf("Updated company to: %s\n", req.Company)
 	}
 	if req.Address != "" {
 		user.Address = req.Address
-		fmt.Printf("Updated address to: %s\n", req.Address)
 	}
 	if req.Salary > 0 {
 		user.Salary = req.Salary
@@ -124,40 +168,46 @@ func (s *clientService) Update(id string, req *models.UpdateUserRequest) (*model
 		// Hash password if provided
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
-			fmt.Printf("Password hashing error: %v\n", err)
-			return nil, errors.New("failed to hash password")
+			return nil, apperrors.Internal(err)
 		}
 		user.Password = string(hashedPassword)
 	}
 	if req.Status != "" {
 		user.Status = req.Status
-		fmt.Printf("Updated status to: %s\n", req.Status)
 	}
 	if req.Hide != nil {
 		user.Hide = *req.Hide
-		fmt.Printf("Updated hide to: %t\n", *req.Hide)
 	}
 
-	fmt.Printf("Updated client before save: %+v\n", user)
-
 	if err := s.userRepo.Update(user); err != nil {
-		fmt.Printf("Repository update error: %v\n", err)
+		return nil, updateError(err)
+	}
+
+	if err := revokeSessionsIfNeeded(s.sessionRepo, user, req); err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("Client updated successfully\n")
+	s.audit.Record(actor, models.AuditActionUpdate, auditClient, user.UserID, before, snapshot(user))
 	return user, nil
 }
 
-func (s *clientService) Delete(id string) error {
-	_, err := s.userRepo.FindByID(id)
+func (s *clientService) Delete(id string, actor models.Actor) error {
+	client, err := s.findClient(id)
 	if err != nil {
-		return errors.New("client not found")
+		return err
+	}
+
+	if err := s.integrity.ReleaseUser(client, actor); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(id, actor.ID); err != nil {
+		return err
 	}
+	s.audit.Record(actor, models.AuditActionDelete, auditClient, id, snapshot(client), nil)
 
-	return s.userRepo.Delete(id)
+	return s.sessionRepo.RevokeAllForUser(id)
 }
 
 func (s *clientService) List(query *models.PaginationQuery) ([]models.User, int64, error) {
-	return s.userRepo.List(query.Page, query.PageSize, query.Search, "client")
+	return s.userRepo.List(query.Page, query.PageSize, query.Search, "client", query.IncludeDeleted)
 }
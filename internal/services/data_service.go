@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+// DataService moves records in and out of storage in bulk, for backups and
+// for moving between storage backends. The admin CLI uses it, and migration
+// 2 seeds the ID counters through it.
+type DataService interface {
+	// Export returns every user, project, service request, message and
+	// service type, deleted ones included
+	Export() (*models.DataExport, error)
+	// Import creates the records of an export, oldest first, records each in
+	// the audit log as imported by actor and seeds the ID counters past them.
+	// Records keep their IDs, timestamps, versions and deletion stamps.
+	Import(data *models.DataExport, actor models.Actor) error
+	// SeedCounters raises every ID counter to the highest number among the
+	// IDs in use and returns those numbers by counter
+	SeedCounters() (map[string]int, error)
+}
+
+type dataService struct {
+	userRepo           repositories.UserRepository
+	projectRepo        repositories.ProjectRepository
+	serviceRequestRepo repositories.ServiceRequestRepository
+	messageRepo        repositories.MessageRepository
+	serviceTypeRepo    repositories.ServiceTypeRepository
+	counterRepo        repositories.CounterRepository
+	audit              AuditService
+}
+
+func NewDataService(userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository, serviceRequestRepo repositories.ServiceRequestRepository, messageRepo repositories.MessageRepository, serviceTypeRepo repositories.ServiceTypeRepository, counterRepo repositories.CounterRepository, audit AuditService) DataService {
+	return &dataService{
+		userRepo:           userRepo,
+		projectRepo:        projectRepo,
+		serviceRequestRepo: serviceRequestRepo,
+		messageRepo:        messageRepo,
+		serviceTypeRepo:    serviceTypeRepo,
+		counterRepo:        counterRepo,
+		audit:              audit,
+	}
+}
+
+func (s *dataService) Export() (*models.DataExport, error) {
+	data := &models.DataExport{ExportedAt: time.Now()}
+
+	// A page size of 0 lists everything
+	var err error
+	if data.Users, _, err = s.userRepo.List(1, 0, "", "", true); err != nil {
+		return nil, fmt.Errorf("failed to export users: %w", err)
+	}
+	if data.Projects, _, err = s.projectRepo.List(1, 0, "", "", nil, true); err != nil {
+		return nil, fmt.Errorf("failed to export projects: %w", err)
+	}
+	if data.ServiceRequests, _, err = s.serviceRequestRepo.List(1, 0, "", "", nil, true); err != nil {
+		return nil, fmt.Errorf("failed to export service requests: %w", err)
+	}
+	for _, project := range data.Projects {
+		messages, _, err := s.messageRepo.ListByProject(project.ID, 1, 0, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export messages of %s: %w", project.ID, err)
+		}
+		data.Messages = append(data.Messages, messages...)
+	}
+	if data.ServiceTypes, err = s.serviceTypeRepo.GetAll(nil); err != nil {
+		return nil, fmt.Errorf("failed to export service types: %w", err)
+	}
+
+	sortByCreation(data)
+	return data, nil
+}
+
+func (s *dataService) Import(data *models.DataExport, actor models.Actor) error {
+	sortByCreation(data)
+
+	// Users go first: projects refer to their clients and employees
+	for i := range data.Users {
+		if err := s.userRepo.Import(&data.Users[i]); err != nil {
+			return fmt.Errorf("failed to import user %s: %w", data.Users[i].UserID, err)
+		}
+		s.audit.Record(actor, models.AuditActionImport, auditUser, data.Users[i].UserID, nil, snapshot(&data.Users[i]))
+	}
+	for i := range data.Projects {
+		if err := s.projectRepo.Import(&data.Projects[i]); err != nil {
+			return fmt.Errorf("failed to import project %s: %w", data.Projects[i].ID, err)
+		}
+		s.audit.Record(actor, models.AuditActionImport, auditProject, data.Projects[i].ID, nil, snapshot(&data.Projects[i]))
+	}
+	for i := range data.ServiceRequests {
+		if err := s.serviceRequestRepo.Import(&data.ServiceRequests[i]); err != nil {
+			return fmt.Errorf("failed to import service request %s: %w", data.ServiceRequests[i].ID, err)
+		}
+		s.audit.Record(actor, models.AuditActionImport, auditServiceRequest, data.ServiceRequests[i].ID, nil, snapshot(&data.ServiceRequests[i]))
+	}
+	for i := range data.Messages {
+		if err := s.messageRepo.Import(&data.Messages[i]); err != nil {
+			return fmt.Errorf("failed to import message %s: %w", data.Messages[i].ID, err)
+		}
+		s.audit.Record(actor, models.AuditActionImport, auditMessage, data.Messages[i].ID, nil, snapshot(&data.Messages[i]))
+	}
+	for i := range data.ServiceTypes {
+		if err := s.serviceTypeRepo.Import(&data.ServiceTypes[i]); err != nil {
+			return fmt.Errorf("failed to import service type %s: %w", data.ServiceTypes[i].Name, err)
+		}
+		s.audit.Record(actor, models.AuditActionImport, auditServiceType, data.ServiceTypes[i].ID, nil, snapshot(&data.ServiceTypes[i]))
+	}
+
+	_, err := s.SeedCounters()
+	return err
+}
+
+func (s *dataService) SeedCounters() (map[string]int, error) {
+	data, err := s.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	highest := map[string]int{
+		repositories.UserCounter:           0,
+		repositories.ProjectCounter:        0,
+		repositories.ServiceRequestCounter: 0,
+		repositories.MessageCounter:        0,
+	}
+	for _, user := range data.Users {
+		raise(highest, repositories.UserCounter, user.UserID)
+	}
+	for _, project := range data.Projects {
+		raise(highest, repositories.ProjectCounter, project.ID)
+	}
+	for _, request := range data.ServiceRequests {
+		raise(highest, repositories.ServiceRequestCounter, request.ID)
+	}
+	for _, message := range data.Messages {
+		raise(highest, repositories.MessageCounter, message.ID)
+	}
+
+	for counter, value := range highest {
+		if err := s.counterRepo.Raise(counter, value); err != nil {
+			return nil, fmt.Errorf("failed to seed %s: %w", counter, err)
+		}
+	}
+	return highest, nil
+}
+
+var trailingNumber = regexp.MustCompile(`\d+$`)
+
+// raise records the trailing number of id, if higher than the one seen so
+// far. IDs are compared as numbers, so USER100 ranks above USER99.
+func raise(highest map[string]int, counter, id string) {
+	n, err := strconv.Atoi(trailingNumber.FindString(id))
+	if err == nil && n > highest[counter] {
+		highest[counter] = n
+	}
+}
+
+// sortByCreation orders every kind of record oldest first, so an import
+// creates them in their original order.
+func sortByCreation(data *models.DataExport) {
+	sort.SliceStable(data.Users, func(i, j int) bool { return data.Users[i].CreatedAt.Before(data.Users[j].CreatedAt) })
+	sort.SliceStable(data.Projects, func(i, j int) bool { return data.Projects[i].CreatedAt.Before(data.Projects[j].CreatedAt) })
+	sort.SliceStable(data.ServiceRequests, func(i, j int) bool {
+		return data.ServiceRequests[i].CreatedAt.Before(data.ServiceRequests[j].CreatedAt)
+	})
+	sort.SliceStable(data.Messages, func(i, j int) bool { return data.Messages[i].CreatedAt.Before(data.Messages[j].CreatedAt) })
+	sort.SliceStable(data.ServiceTypes, func(i, j int) bool {
+		return data.ServiceTypes[i].CreatedAt.Before(data.ServiceTypes[j].CreatedAt)
+	})
+}
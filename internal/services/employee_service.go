@@ -1,15 +1,14 @@
 package services
 
 import (
-	"errors"
-
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type EmployeeService interface {
-	Create(req *models.CreateEmployeeRequest) (*models.User, error)
+	Create(req *models.CreateEmployeeRequest, actor models.Actor) (*models.User, error)
 	GetByID(id string) (*models.User, error)
 	List(query *models.PaginationQuery) ([]models.User, int64, error)
 }
@@ -17,32 +16,37 @@ type EmployeeService interface {
 type employeeService struct {
 	employeeRepo repositories.EmployeeRepository
 	userRepo     repositories.UserRepository
+	ids          IDGenerator
+	audit        AuditService
 }
 
-func NewEmployeeService(employeeRepo repositories.EmployeeRepository, userRepo repositories.UserRepository) EmployeeService {
+func NewEmployeeService(employeeRepo repositories.EmployeeRepository, userRepo repositories.UserRepository, ids IDGenerator, audit AuditService) EmployeeService {
 	return &employeeService{
 		employeeRepo: employeeRepo,
 		userRepo:     userRepo,
+		ids:          ids,
+		audit:        audit,
 	}
 }
 
-func (s *employeeService) Create(req *models.CreateEmployeeRequest) (*models.User, error) {
-	// Check if email already exists
-	existingUser, err := s.userRepo.FindByEmail(req.Email)
-	if err == nil && existingUser != nil {
-		return nil, errors.New("email already exists")
+func (s *employeeService) Create(req *models.CreateEmployeeRequest, actor models.Actor) (*models.User, error) {
+	// Deleted accounts keep their email until they are purged
+	if taken, err := s.userRepo.EmailExists(req.Email); err != nil {
+		return nil, apperrors.Internal(err)
+	} else if taken {
+		return nil, ErrEmailTaken
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, errors.New("failed to hash password")
+		return nil, apperrors.Internal(err)
 	}
 
 	// Get next user ID
-	userID, err := s.userRepo.GetNextUserID()
+	userID, err := s.ids.NextUserID()
 	if err != nil {
-		return nil, errors.New("failed to generate user ID")
+		return nil, apperrors.Internal(err)
 	}
 
 	// Create employee
@@ -59,8 +63,9 @@ func (s *employeeService) Create(req *models.CreateEmployeeRequest) (*models.Use
 	}
 
 	if err := s.employeeRepo.Create(employee); err != nil {
-		return nil, errors.New("failed to create employee")
+		return nil, apperrors.Internal(err)
 	}
+	s.audit.Record(actor, models.AuditActionCreate, auditEmployee, employee.UserID, nil, snapshot(employee))
 
 	// Clear password before returning
 	employee.Password = ""
@@ -68,9 +73,14 @@ func (s *employeeService) Create(req *models.CreateEmployeeRequest) (*models.Use
 }
 
 func (s *employeeService) GetByID(id string) (*models.User, error) {
-	return s.employeeRepo.FindByID(id)
+	employee, err := s.employeeRepo.FindByID(id)
+	if err != nil {
+		return nil, lookupError(err, ErrEmployeeNotFound)
+	}
+
+	return employee, nil
 }
 
 func (s *employeeService) List(query *models.PaginationQuery) ([]models.User, int64, error) {
-	return s.employeeRepo.List(query.Page, query.PageSize, query.Search)
+	return s.employeeRepo.List(query.Page, query.PageSize, query.Search, query.IncludeDeleted)
 }
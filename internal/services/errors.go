@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrUserNotFound           = apperrors.NotFound("USER_NOT_FOUND", "user not found")
+	ErrClientNotFound         = apperrors.NotFound("CLIENT_NOT_FOUND", "client not found")
+	ErrEmployeeNotFound       = apperrors.NotFound("EMPLOYEE_NOT_FOUND", "employee not found")
+	ErrProjectNotFound        = apperrors.NotFound("PROJECT_NOT_FOUND", "project not found")
+	ErrMessageNotFound        = apperrors.NotFound("MESSAGE_NOT_FOUND", "message not found")
+	ErrServiceRequestNotFound = apperrors.NotFound("SERVICE_REQUEST_NOT_FOUND", "service request not found")
+	ErrServiceTypeNotFound    = apperrors.NotFound("SERVICE_TYPE_NOT_FOUND", "service type not found")
+	ErrInvalidClient          = apperrors.Unprocessable("INVALID_CLIENT", "client ID does not refer to an existing client")
+	ErrInvalidProject         = apperrors.Unprocessable("INVALID_PROJECT", "project ID does not refer to an existing project of the client")
+	ErrParentDeleted          = apperrors.Conflict("PARENT_DELETED", "the client or project this record belongs to is deleted; restore it first")
+	ErrVersionMismatch        = apperrors.PreconditionFailed("VERSION_MISMATCH", "the resource has changed since it was read; fetch it again and retry")
+	ErrEmailTaken             = apperrors.Conflict("EMAIL_TAKEN", "email already exists")
+	ErrAccountInactive        = apperrors.Forbidden("ACCOUNT_INACTIVE", "account is inactive. Please contact your system administrator to activate your account")
+
+	ErrInvalidCredentials      = apperrors.Unauthorized("INVALID_CREDENTIALS", "invalid credentials")
+	ErrInvalidChallengeToken   = apperrors.Unauthorized("INVALID_CHALLENGE_TOKEN", "invalid or expired challenge token")
+	ErrInvalidRefreshToken     = apperrors.Unauthorized("INVALID_REFRESH_TOKEN", "invalid refresh token")
+	ErrRefreshTokenRevoked     = apperrors.Unauthorized("REFRESH_TOKEN_REVOKED", "refresh token has been revoked")
+	ErrRefreshTokenExpired     = apperrors.Unauthorized("REFRESH_TOKEN_EXPIRED", "refresh token has expired")
+	ErrTwoFactorAlreadyEnabled = apperrors.Conflict("TWO_FACTOR_ENABLED", "two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = apperrors.Conflict("TWO_FACTOR_NOT_ENABLED", "two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = apperrors.Validation("INVALID_TWO_FACTOR_CODE", "invalid two-factor code")
+)
+
+// lookupError maps the error of a repository lookup: a missing document
+// becomes notFound, anything else is an internal error.
+func lookupError(err error, notFound *apperrors.Error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFound
+	}
+	return apperrors.Internal(err)
+}
+
+// updateError maps the error of a versioned repository update: a stale
+// version becomes ErrVersionMismatch, anything else is returned as is.
+func updateError(err error) error {
+	if errors.Is(err, repositories.ErrStaleVersion) {
+		return ErrVersionMismatch
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+// IDGenerator hands out sequential record IDs such as USER07. Numbers come
+// from the atomic counters, so concurrent creates never share one, and are
+// written with the configured prefix and padding.
+type IDGenerator interface {
+	NextUserID() (string, error)
+	NextProjectID() (string, error)
+	NextServiceRequestID() (string, error)
+	NextMessageID() (string, error)
+	// WithCounters returns a generator drawing from counters, such as those
+	// of a unit of work
+	WithCounters(counters repositories.CounterRepository) IDGenerator
+}
+
+type idGenerator struct {
+	counterRepo repositories.CounterRepository
+	formats     config.IDConfig
+}
+
+func NewIDGenerator(counterRepo repositories.CounterRepository, cfg *config.Config) IDGenerator {
+	return &idGenerator{
+		counterRepo: counterRepo,
+		formats:     cfg.IDs,
+	}
+}
+
+func (g *idGenerator) NextUserID() (string, error) {
+	return g.next(repositories.UserCounter, g.formats.User, "user")
+}
+
+func (g *idGenerator) NextProjectID() (string, error) {
+	return g.next(repositories.ProjectCounter, g.formats.Project, "project")
+}
+
+func (g *idGenerator) NextServiceRequestID() (string, error) {
+	return g.next(repositories.ServiceRequestCounter, g.formats.ServiceRequest, "service request")
+}
+
+func (g *idGenerator) NextMessageID() (string, error) {
+	return g.next(repositories.MessageCounter, g.formats.Message, "message")
+}
+
+func (g *idGenerator) WithCounters(counters repositories.CounterRepository) IDGenerator {
+	return &idGenerator{
+		counterRepo: counters,
+		formats:     g.formats,
+	}
+}
+
+func (g *idGenerator) next(counter string, format config.IDFormat, entity string) (string, error) {
+	sequence, err := g.counterRepo.GetNextSequence(counter)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s ID: %w", entity, err)
+	}
+	return format.Format(sequence), nil
+}
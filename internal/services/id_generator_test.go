@@ -0,0 +1,85 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+// newTestIDGenerator draws from counters with the default ID formats
+func newTestIDGenerator(counters repositories.CounterRepository) IDGenerator {
+	return NewIDGenerator(counters, &config.Config{IDs: config.IDConfig{
+		User:           config.IDFormat{Prefix: "USER", Width: 2},
+		Project:        config.IDFormat{Prefix: "PROJECT", Width: 2},
+		ServiceRequest: config.IDFormat{Prefix: "SERVICE", Width: 2},
+		Message:        config.IDFormat{Prefix: "MESSAGE", Width: 2},
+	}})
+}
+
+// lockedCounterRepository serialises a MockCounterRepository the way
+// MongoDB's atomic $inc does
+type lockedCounterRepository struct {
+	mu sync.Mutex
+	*MockCounterRepository
+}
+
+func (m *lockedCounterRepository) GetNextSequence(counterName string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.MockCounterRepository.GetNextSequence(counterName)
+}
+
+func (m *lockedCounterRepository) Raise(counterName string, value int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.MockCounterRepository.Raise(counterName, value)
+}
+
+func TestIDGenerator_UserIDsAreUniqueUnderConcurrency(t *testing.T) {
+	counters := &lockedCounterRepository{MockCounterRepository: NewMockCounterRepository()}
+	counters.counters[repositories.UserCounter] = 98
+	ids := newTestIDGenerator(counters)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := ids.NextUserID()
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[id] {
+				t.Errorf("Expected unique IDs, got %s twice", id)
+			}
+			seen[id] = true
+		}()
+	}
+	wg.Wait()
+
+	// Numbering carries on past USER99 instead of sorting as strings
+	if !seen["USER99"] || !seen["USER100"] || !seen["USER118"] {
+		t.Errorf("Expected USER99 through USER118, got: %v", seen)
+	}
+}
+
+func TestIDGenerator_UsesConfiguredFormat(t *testing.T) {
+	ids := NewIDGenerator(NewMockCounterRepository(), &config.Config{IDs: config.IDConfig{
+		User:    config.IDFormat{Prefix: "U-", Width: 5},
+		Project: config.IDFormat{Prefix: "PRJ", Width: 0},
+	}})
+
+	if id, _ := ids.NextUserID(); id != "U-00001" {
+		t.Errorf("Expected U-00001, got %s", id)
+	}
+	if id, _ := ids.NextProjectID(); id != "PRJ1" {
+		t.Errorf("Expected PRJ1, got %s", id)
+	}
+}
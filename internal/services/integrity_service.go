@@ -0,0 +1,228 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IntegrityService keeps the references between users, projects, service
+// requests and messages valid. It checks the IDs a request refers to and
+// applies the configured delete rules to the records that reference something
+// about to be deleted.
+type IntegrityService interface {
+	// CheckClient verifies that id names a live client
+	CheckClient(id string) error
+	// CheckEmployees verifies that every id names a live employee
+	CheckEmployees(ids []string) error
+	// CheckClientProject verifies that projectID names a live project of the client
+	CheckClientProject(projectID, clientID string) error
+	// CheckRestorable verifies that the client and project a deleted record
+	// belongs to are live, so restoring it does not bring back an orphan.
+	// Empty IDs are not checked.
+	CheckRestorable(clientID, projectID string) error
+	// ReleaseUser applies the delete rule of the user's role. It returns a
+	// conflict when the rule is restrict and references remain.
+	ReleaseUser(user *models.User, actor models.Actor) error
+	// ReleaseProject applies the project delete rule to its messages
+	ReleaseProject(project *models.Project, actor models.Actor) error
+}
+
+type integrityService struct {
+	userRepo           repositories.UserRepository
+	projectRepo        repositories.ProjectRepository
+	serviceRequestRepo repositories.ServiceRequestRepository
+	messageRepo        repositories.MessageRepository
+	rules              config.IntegrityConfig
+	audit              AuditService
+}
+
+func NewIntegrityService(userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository, serviceRequestRepo repositories.ServiceRequestRepository, messageRepo repositories.MessageRepository, cfg *config.Config, audit AuditService) IntegrityService {
+	return &integrityService{
+		userRepo:           userRepo,
+		projectRepo:        projectRepo,
+		serviceRequestRepo: serviceRequestRepo,
+		messageRepo:        messageRepo,
+		rules:              cfg.Integrity,
+		audit:              audit,
+	}
+}
+
+func (s *integrityService) CheckClient(id string) error {
+	ok, err := s.hasRole(id, models.RoleClient)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidClient
+	}
+	return nil
+}
+
+func (s *integrityService) CheckEmployees(ids []string) error {
+	for _, id := range ids {
+		if id == "" {
+			return apperrors.Validation("INVALID_EMPLOYEE_ID", "invalid employee ID provided")
+		}
+		ok, err := s.hasRole(id, models.RoleEmployee)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperrors.Unprocessable("INVALID_EMPLOYEE", "%s is not an existing employee", id)
+		}
+	}
+	return nil
+}
+
+// hasRole reports whether id names a live user with the role. Deleted users
+// are treated as missing.
+func (s *integrityService) hasRole(id string, role models.Role) (bool, error) {
+	user, err := s.userRepo.FindByID(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+	return user.Role == role, nil
+}
+
+func (s *integrityService) CheckClientProject(projectID, clientID string) error {
+	project, err := s.projectRepo.FindByID(projectID)
+	if err != nil {
+		return lookupError(err, ErrInvalidProject)
+	}
+	if project.ClientID != clientID {
+		return ErrInvalidProject
+	}
+	return nil
+}
+
+func (s *integrityService) CheckRestorable(clientID, projectID string) error {
+	if clientID != "" {
+		if _, err := s.userRepo.FindByID(clientID); err != nil {
+			return lookupError(err, ErrParentDeleted)
+		}
+	}
+	if projectID != "" {
+		if _, err := s.projectRepo.FindByID(projectID); err != nil {
+			return lookupError(err, ErrParentDeleted)
+		}
+	}
+	return nil
+}
+
+func (s *integrityService) ReleaseUser(user *models.User, actor models.Actor) error {
+	switch user.Role {
+	case models.RoleClient:
+		return s.releaseClient(user.UserID, actor)
+	case models.RoleEmployee:
+		return s.releaseEmployee(user.UserID, actor)
+	}
+	return nil
+}
+
+// releaseClient deletes the client's projects, with their messages, and
+// service requests, or refuses when the rule is restrict.
+func (s *integrityService) releaseClient(clientID string, actor models.Actor) error {
+	projects, err := s.projectRepo.FindByClient(clientID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	serviceRequests, err := s.serviceRequestRepo.FindByClient(clientID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if len(projects) == 0 && len(serviceRequests) == 0 {
+		return nil
+	}
+	if s.rules.OnDeleteClient != config.DeleteCascade {
+		return apperrors.Conflict("CLIENT_IN_USE", "client still has %d project(s) and %d service request(s)", len(projects), len(serviceRequests))
+	}
+
+	for i := range projects {
+		// A cascaded project takes its messages along whatever the project rule
+		messages, err := s.messageRepo.FindByProject(projects[i].ID)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		if err := s.deleteMessages(messages, actor); err != nil {
+			return err
+		}
+		if err := s.projectRepo.Delete(projects[i].ID, actor.ID); err != nil {
+			return err
+		}
+		s.audit.Record(actor, models.AuditActionDelete, auditProject, projects[i].ID, snapshot(&projects[i]), nil)
+	}
+
+	for i := range serviceRequests {
+		if err := s.serviceRequestRepo.Delete(serviceRequests[i].ID, actor.ID); err != nil {
+			return err
+		}
+		s.audit.Record(actor, models.AuditActionDelete, auditServiceRequest, serviceRequests[i].ID, snapshot(&serviceRequests[i]), nil)
+	}
+	return nil
+}
+
+// releaseEmployee removes the employee from every project they are assigned
+// to, or refuses when the rule is restrict.
+func (s *integrityService) releaseEmployee(employeeID string, actor models.Actor) error {
+	projects, err := s.projectRepo.FindByEmployee(employeeID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+	if s.rules.OnDeleteEmployee != config.DeleteCascade {
+		return apperrors.Conflict("EMPLOYEE_ASSIGNED", "employee is still assigned to %d project(s)", len(projects))
+	}
+
+	for i := range projects {
+		project := &projects[i]
+		before := snapshot(project)
+
+		remaining := make([]string, 0, len(project.EmployeeIDs))
+		for _, id := range project.EmployeeIDs {
+			if id != employeeID {
+				remaining = append(remaining, id)
+			}
+		}
+		if err := s.projectRepo.AssignEmployees(project.ID, remaining); err != nil {
+			return err
+		}
+
+		project.EmployeeIDs = remaining
+		s.audit.Record(actor, models.AuditActionAssign, auditProject, project.ID, before, snapshot(project))
+	}
+	return nil
+}
+
+func (s *integrityService) ReleaseProject(project *models.Project, actor models.Actor) error {
+	messages, err := s.messageRepo.FindByProject(project.ID)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	if s.rules.OnDeleteProject != config.DeleteCascade {
+		return apperrors.Conflict("PROJECT_HAS_MESSAGES", "project still has %d message(s)", len(messages))
+	}
+	return s.deleteMessages(messages, actor)
+}
+
+func (s *integrityService) deleteMessages(messages []models.Message, actor models.Actor) error {
+	for i := range messages {
+		if err := s.messageRepo.Delete(messages[i].ID, actor.ID); err != nil {
+			return err
+		}
+		s.audit.Record(actor, models.AuditActionDelete, auditMessage, messages[i].ID, snapshot(&messages[i]), nil)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MockServiceRequestRepository for testing
+type MockServiceRequestRepository struct {
+	requests map[string]*models.ServiceRequest
+	// updateErr makes Update fail, to exercise rollbacks
+	updateErr error
+}
+
+func NewMockServiceRequestRepository() *MockServiceRequestRepository {
+	return &MockServiceRequestRepository{
+		requests: make(map[string]*models.ServiceRequest),
+	}
+}
+
+func (m *MockServiceRequestRepository) Create(request *models.ServiceRequest) error {
+	m.requests[request.ID] = request
+	return nil
+}
+
+func (m *MockServiceRequestRepository) Import(request *models.ServiceRequest) error {
+	return m.Create(request)
+}
+
+func (m *MockServiceRequestRepository) FindByID(id string) (*models.ServiceRequest, error) {
+	request, exists := m.requests[id]
+	if !exists || request.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return request, nil
+}
+
+func (m *MockServiceRequestRepository) Update(request *models.ServiceRequest) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	request.Version++
+	m.requests[request.ID] = request
+	return nil
+}
+
+func (m *MockServiceRequestRepository) Delete(id, deletedBy string) error {
+	request, err := m.FindByID(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	request.DeletedAt = &now
+	request.DeletedBy = deletedBy
+	return nil
+}
+
+func (m *MockServiceRequestRepository) FindDeleted(id string) (*models.ServiceRequest, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *MockServiceRequestRepository) Restore(id string) (*models.ServiceRequest, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *MockServiceRequestRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockServiceRequestRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.ServiceRequest, int64, error) {
+	var requests []models.ServiceRequest
+	for _, request := range m.requests {
+		if (includeDeleted || request.DeletedAt == nil) && (clientID == nil || request.ClientID == *clientID) {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, int64(len(requests)), nil
+}
+
+func (m *MockServiceRequestRepository) FindByClient(clientID string) ([]models.ServiceRequest, error) {
+	requests, _, err := m.List(1, 0, "", "", &clientID, false)
+	return requests, err
+}
+
+// MockMessageRepository for testing
+type MockMessageRepository struct {
+	messages map[string]*models.Message
+}
+
+func NewMockMessageRepository() *MockMessageRepository {
+	return &MockMessageRepository{
+		messages: make(map[string]*models.Message),
+	}
+}
+
+func (m *MockMessageRepository) Create(message *models.Message) error {
+	m.messages[message.ID] = message
+	return nil
+}
+
+func (m *MockMessageRepository) Import(message *models.Message) error {
+	return m.Create(message)
+}
+
+func (m *MockMessageRepository) FindByID(id string) (*models.Message, error) {
+	message, exists := m.messages[id]
+	if !exists || message.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return message, nil
+}
+
+func (m *MockMessageRepository) Delete(id, deletedBy string) error {
+	message, err := m.FindByID(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	message.DeletedAt = &now
+	message.DeletedBy = deletedBy
+	return nil
+}
+
+func (m *MockMessageRepository) FindDeleted(id string) (*models.Message, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *MockMessageRepository) Restore(id string) (*models.Message, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *MockMessageRepository) PurgeDeleted(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockMessageRepository) ListByProject(projectID string, page, pageSize int, includeDeleted bool) ([]models.Message, int64, error) {
+	var messages []models.Message
+	for _, message := range m.messages {
+		if message.ProjectID == projectID && (includeDeleted || message.DeletedAt == nil) {
+			messages = append(messages, *message)
+		}
+	}
+	return messages, int64(len(messages)), nil
+}
+
+func (m *MockMessageRepository) FindByProject(projectID string) ([]models.Message, error) {
+	messages, _, err := m.ListByProject(projectID, 1, 0, false)
+	return messages, err
+}
+
+func newTestIntegrityService(userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository) IntegrityService {
+	cfg := &config.Config{Integrity: config.IntegrityConfig{
+		OnDeleteClient:   config.DeleteRestrict,
+		OnDeleteEmployee: config.DeleteCascade,
+		OnDeleteProject:  config.DeleteCascade,
+	}}
+	return NewIntegrityService(userRepo, projectRepo, NewMockServiceRequestRepository(), NewMockMessageRepository(), cfg, newTestAuditService())
+}
+
+func TestIntegrity_ChecksReferencedIDs(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	projectRepo := NewMockProjectRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), newTestIntegrityService(userRepo, projectRepo), newTestAuditService())
+	admin := models.Actor{ID: "ADMIN01", Role: "admin"}
+
+	userRepo.Create(&models.User{UserID: "CLIENT01", Role: models.RoleClient})
+	userRepo.Create(&models.User{UserID: "EMP01", Role: models.RoleEmployee})
+
+	_, err := projectService.Create(&models.CreateProjectRequest{Name: "Portal", ClientID: "CLIENT99"}, admin)
+	if err != ErrInvalidClient || !errors.Is(err, apperrors.KindUnprocessable) {
+		t.Errorf("Expected an unprocessable ErrInvalidClient for an unknown client, got: %v", err)
+	}
+	if _, err := projectService.Create(&models.CreateProjectRequest{Name: "Portal", ClientID: "EMP01"}, admin); err != ErrInvalidClient {
+		t.Errorf("Expected ErrInvalidClient for an employee ID, got: %v", err)
+	}
+
+	project, err := projectService.Create(&models.CreateProjectRequest{Name: "Portal", ClientID: "CLIENT01", EmployeeIDs: []string{"EMP01"}}, admin)
+	if err != nil {
+		t.Fatalf("Expected the project to be created, got: %v", err)
+	}
+
+	err = projectService.AssignEmployees(project.ID, &models.AssignEmployeesRequest{EmployeeIDs: []string{"EMP01", "CLIENT01"}}, admin)
+	if !errors.Is(err, apperrors.KindUnprocessable) || apperrors.From(err).Code != "INVALID_EMPLOYEE" {
+		t.Errorf("Expected INVALID_EMPLOYEE assigning a client, got: %v", err)
+	}
+
+	// Deleted employees can no longer be assigned
+	userRepo.Delete("EMP01", "ADMIN01")
+	if err := projectService.AssignEmployees(project.ID, &models.AssignEmployeesRequest{EmployeeIDs: []string{"EMP01"}}, admin); !errors.Is(err, apperrors.KindUnprocessable) {
+		t.Errorf("Expected a deleted employee to be rejected, got: %v", err)
+	}
+}
+
+func TestIntegrity_AppliesDeleteRules(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	projectRepo := NewMockProjectRepository()
+	messageRepo := NewMockMessageRepository()
+	cfg := &config.Config{Integrity: config.IntegrityConfig{
+		OnDeleteClient:   config.DeleteRestrict,
+		OnDeleteEmployee: config.DeleteCascade,
+		OnDeleteProject:  config.DeleteRestrict,
+	}}
+	integrity := NewIntegrityService(userRepo, projectRepo, NewMockServiceRequestRepository(), messageRepo, cfg, newTestAuditService())
+	userService := NewUserService(userRepo, projectRepo, NewMockSessionRepository(), policy.Default(), integrity, newTestAuditService())
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), integrity, newTestAuditService())
+	admin := models.Actor{ID: "ADMIN01", Role: "admin"}
+
+	userRepo.Create(&models.User{UserID: "CLIENT01", Role: models.RoleClient})
+	userRepo.Create(&models.User{UserID: "EMP01", Role: models.RoleEmployee})
+	userRepo.Create(&models.User{UserID: "EMP02", Role: models.RoleEmployee})
+	projectRepo.Create(&models.Project{ID: "PROJECT01", ClientID: "CLIENT01", EmployeeIDs: []string{"EMP01", "EMP02"}})
+	messageRepo.Create(&models.Message{ID: "MESSAGE01", ProjectID: "PROJECT01", SenderID: "CLIENT01"})
+
+	// Restrict: the client keeps its account while it has projects
+	err := userService.Delete("CLIENT01", admin)
+	if !errors.Is(err, apperrors.KindConflict) || apperrors.From(err).Code != "CLIENT_IN_USE" {
+		t.Errorf("Expected CLIENT_IN_USE deleting a client with projects, got: %v", err)
+	}
+	if err := projectService.Delete("PROJECT01", admin); !errors.Is(err, apperrors.KindConflict) {
+		t.Errorf("Expected a conflict deleting a project with messages, got: %v", err)
+	}
+
+	// Cascade: the employee is taken off the project
+	if err := userService.Delete("EMP01", admin); err != nil {
+		t.Fatalf("Expected the employee to be deleted, got: %v", err)
+	}
+	if project, _ := projectRepo.FindByID("PROJECT01"); len(project.EmployeeIDs) != 1 || project.EmployeeIDs[0] != "EMP02" {
+		t.Errorf("Expected EMP01 to be unassigned, got: %v", project.EmployeeIDs)
+	}
+
+	// Cascading a client takes its projects and their messages along
+	cfg.Integrity.OnDeleteClient = config.DeleteCascade
+	integrity = NewIntegrityService(userRepo, projectRepo, NewMockServiceRequestRepository(), messageRepo, cfg, newTestAuditService())
+	userService = NewUserService(userRepo, projectRepo, NewMockSessionRepository(), policy.Default(), integrity, newTestAuditService())
+	if err := userService.Delete("CLIENT01", admin); err != nil {
+		t.Fatalf("Expected the client to be deleted, got: %v", err)
+	}
+	if _, err := projectRepo.FindByID("PROJECT01"); err != mongo.ErrNoDocuments {
+		t.Errorf("Expected the client's project to be deleted, got: %v", err)
+	}
+	if _, err := messageRepo.FindByID("MESSAGE01"); err != mongo.ErrNoDocuments {
+		t.Errorf("Expected the project's message to be deleted, got: %v", err)
+	}
+}
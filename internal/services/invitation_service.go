@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InvitationService interface {
+	// Create invites a user on behalf of actor, who is recorded as the inviter
+	Create(req *models.CreateInvitationRequest, actor models.Actor) (*models.Invitation, error)
+	List(query *models.InvitationQuery) ([]models.Invitation, int64, error)
+	Resend(id string, actor models.Actor) (*models.Invitation, error)
+	Revoke(id string, actor models.Actor) error
+	// Accept creates the invited account. actor only carries the request
+	// details; the new user becomes the actor of the recorded events.
+	Accept(req *models.AcceptInvitationRequest, actor models.Actor) (*models.User, error)
+}
+
+var (
+	errInvitationNotFound   = apperrors.NotFound("INVITATION_NOT_FOUND", "invitation not found")
+	errInvitationNotPending = apperrors.Conflict("INVITATION_NOT_PENDING", "invitation is no longer pending")
+	errInvalidInvitation    = apperrors.Validation("INVALID_INVITATION", "invalid or expired invitation")
+)
+
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	userRepo       repositories.UserRepository
+	ids            IDGenerator
+	mailer         mailer.Mailer
+	cfg            *config.Config
+	audit          AuditService
+}
+
+func NewInvitationService(invitationRepo repositories.InvitationRepository, userRepo repositories.UserRepository, ids IDGenerator, mailer mailer.Mailer, cfg *config.Config, audit AuditService) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		ids:            ids,
+		mailer:         mailer,
+		cfg:            cfg,
+		audit:          audit,
+	}
+}
+
+func (s *invitationService) Create(req *models.CreateInvitationRequest, actor models.Actor) (*models.Invitation, error) {
+	// Deleted accounts keep their email until they are purged
+	if taken, err := s.userRepo.EmailExists(req.Email); err != nil {
+		return nil, apperrors.Internal(err)
+	} else if taken {
+		return nil, ErrEmailTaken
+	}
+
+	if _, err := s.invitationRepo.FindPendingByEmail(req.Email); err == nil {
+		return nil, apperrors.Conflict("INVITATION_PENDING", "a pending invitation already exists for this email")
+	}
+
+	invitation := &models.Invitation{
+		ID:         primitive.NewObjectID().Hex(),
+		Email:      req.Email,
+		Role:       req.Role,
+		Name:       req.Name,
+		Phone:      req.Phone,
+		Department: req.Department,
+		Company:    req.Company,
+		Address:    req.Address,
+		Salary:     req.Salary,
+		Status:     models.InvitationStatusPending,
+		InvitedBy:  actor.ID,
+	}
+
+	token, err := s.refreshToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	s.audit.Record(actor, models.AuditActionCreate, auditInvitation, invitation.ID, nil, snapshot(invitation))
+	s.sendInvitationEmail(invitation, token)
+	return invitation, nil
+}
+
+func (s *invitationService) List(query *models.InvitationQuery) ([]models.Invitation, int64, error) {
+	return s.invitationRepo.List(query.Page, query.PageSize, query.Search, query.Status)
+}
+
+// Resend issues a new token and expiry for a pending invitation; the previously sent link stops working.
+func (s *invitationService) Resend(id string, actor models.Actor) (*models.Invitation, error) {
+	invitation, err := s.invitationRepo.FindByID(id)
+	if err != nil {
+		return nil, lookupError(err, errInvitationNotFound)
+	}
+
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, errInvitationNotPending
+	}
+
+	before := snapshot(invitation)
+	token, err := s.refreshToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.Update(invitation); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionResend, auditInvitation, invitation.ID, before, snapshot(invitation))
+	s.sendInvitationEmail(invitation, token)
+	return invitation, nil
+}
+
+func (s *invitationService) Revoke(id string, actor models.Actor) error {
+	invitation, err := s.invitationRepo.FindByID(id)
+	if err != nil {
+		return lookupError(err, errInvitationNotFound)
+	}
+
+	if invitation.Status != models.InvitationStatusPending {
+		return errInvitationNotPending
+	}
+
+	before := snapshot(invitation)
+	invitation.Status = models.InvitationStatusRevoked
+	if err := s.invitationRepo.Update(invitation); err != nil {
+		return err
+	}
+
+	s.audit.Record(actor, models.AuditActionRevoke, auditInvitation, invitation.ID, before, snapshot(invitation))
+	return nil
+}
+
+func (s *invitationService) Accept(req *models.AcceptInvitationRequest, actor models.Actor) (*models.User, error) {
+	invitation, err := s.invitationRepo.FindByTokenHash(utils.HashToken(req.Token))
+	if err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	if invitation.Status != models.InvitationStatusPending || time.Now().After(invitation.ExpiresAt) {
+		return nil, errInvalidInvitation
+	}
+
+	// Deleted accounts keep their email until they are purged
+	if taken, err := s.userRepo.EmailExists(invitation.Email); err != nil {
+		return nil, apperrors.Internal(err)
+	} else if taken {
+		return nil, ErrEmailTaken
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	userID, err := s.ids.NextUserID()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	// Claim the invitation first so the same link cannot create two accounts
+	if err := s.invitationRepo.MarkAccepted(invitation.ID, userID); err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	// The invitation link proves ownership of the email address
+	now := time.Now()
+	user := &models.User{
+		UserID:          userID,
+		Email:           invitation.Email,
+		Password:        hashedPassword,
+		Name:            invitation.Name,
+		Phone:           invitation.Phone,
+		Role:            invitation.Role,
+		Department:      invitation.Department,
+		Company:         invitation.Company,
+		Address:         invitation.Address,
+		Salary:          invitation.Salary,
+		Status:          models.UserStatusActive,
+		EmailVerifiedAt: &now,
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		// Release the invitation so it can be accepted again
+		invitation.Status = models.InvitationStatusPending
+		s.invitationRepo.Update(invitation)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	before := snapshot(invitation)
+	invitation.Status = models.InvitationStatusAccepted
+	invitation.AcceptedUserID = user.UserID
+
+	actor.ID, actor.Role = user.UserID, string(user.Role)
+	s.audit.Record(actor, models.AuditActionAccept, auditInvitation, invitation.ID, before, snapshot(invitation))
+	s.audit.Record(actor, models.AuditActionCreate, auditUser, user.UserID, nil, snapshot(user))
+	return user, nil
+}
+
+// refreshToken sets a new token hash and expiry on the invitation and returns the raw token.
+func (s *invitationService) refreshToken(invitation *models.Invitation) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	invitation.TokenHash = utils.HashToken(token)
+	invitation.ExpiresAt = time.Now().Add(s.cfg.Auth.InvitationExpiry)
+	return token, nil
+}
+
+func (s *invitationService) sendInvitationEmail(invitation *models.Invitation, token string) {
+	link := fmt.Sprintf("%s/accept-invite?token=%s", s.cfg.Mail.AppURL, token)
+	msg := mailer.Message{
+		To:      []string{invitation.Email},
+		Subject: "You have been invited to Vinodhini Software",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nYou have been invited to join Vinodhini Software as %s. Use the link below to set your password and activate your account:\n\n%s\n\nThis invitation expires in %s.\n",
+			invitation.Name, invitation.Role, link, s.cfg.Auth.InvitationExpiry,
+		),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Failed to send invitation email to %s: %v", invitation.Email, err)
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// MockInvitationRepository for testing
+type MockInvitationRepository struct {
+	invitations map[string]*models.Invitation
+}
+
+func NewMockInvitationRepository() *MockInvitationRepository {
+	return &MockInvitationRepository{
+		invitations: make(map[string]*models.Invitation),
+	}
+}
+
+func (m *MockInvitationRepository) Create(invitation *models.Invitation) error {
+	invitation.CreatedAt = time.Now()
+	invitation.UpdatedAt = time.Now()
+	m.invitations[invitation.ID] = invitation
+	return nil
+}
+
+func (m *MockInvitationRepository) FindByID(id string) (*models.Invitation, error) {
+	invitation, exists := m.invitations[id]
+	if !exists {
+		return nil, errors.New("invitation not found")
+	}
+	return invitation, nil
+}
+
+func (m *MockInvitationRepository) FindByTokenHash(hash string) (*models.Invitation, error) {
+	for _, invitation := range m.invitations {
+		if invitation.TokenHash == hash {
+			return invitation, nil
+		}
+	}
+	return nil, errors.New("invitation not found")
+}
+
+func (m *MockInvitationRepository) FindPendingByEmail(email string) (*models.Invitation, error) {
+	for _, invitation := range m.invitations {
+		if invitation.Email == email && invitation.Status == models.InvitationStatusPending {
+			return invitation, nil
+		}
+	}
+	return nil, errors.New("invitation not found")
+}
+
+func (m *MockInvitationRepository) Update(invitation *models.Invitation) error {
+	invitation.UpdatedAt = time.Now()
+	m.invitations[invitation.ID] = invitation
+	return nil
+}
+
+func (m *MockInvitationRepository) MarkAccepted(id string, userID string) error {
+	invitation, exists := m.invitations[id]
+	if !exists || invitation.Status != models.InvitationStatusPending {
+		return errors.New("invitation is no longer pending")
+	}
+	now := time.Now()
+	invitation.Status = models.InvitationStatusAccepted
+	invitation.AcceptedUserID = userID
+	invitation.AcceptedAt = &now
+	return nil
+}
+
+func (m *MockInvitationRepository) List(page, pageSize int, search string, status string) ([]models.Invitation, int64, error) {
+	var invitations []models.Invitation
+	for _, invitation := range m.invitations {
+		if status == "" || string(invitation.Status) == status {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	return invitations, int64(len(invitations)), nil
+}
+
+func TestAcceptInvitation_CreatesUser(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	mail := mailer.NewMemoryMailer()
+	cfg := &config.Config{
+		Auth: config.AuthConfig{InvitationExpiry: time.Hour},
+		Mail: config.MailConfig{AppURL: "http://localhost:3000"},
+	}
+	invitationService := NewInvitationService(NewMockInvitationRepository(), userRepo, newTestIDGenerator(NewMockCounterRepository()), mail, cfg, newTestAuditService())
+
+	invitation, err := invitationService.Create(&models.CreateInvitationRequest{
+		Email:      "invitee@example.com",
+		Role:       models.RoleEmployee,
+		Name:       "Invited Employee",
+		Department: "Engineering",
+	}, models.Actor{ID: "USER01"})
+	if err != nil {
+		t.Fatalf("Expected no error creating invitation, got: %v", err)
+	}
+
+	if _, err := invitationService.Create(&models.CreateInvitationRequest{Email: "invitee@example.com", Role: models.RoleEmployee, Name: "Again"}, models.Actor{ID: "USER01"}); err == nil {
+		t.Error("Expected error for a second pending invitation to the same email")
+	}
+
+	// Resending invalidates the first link
+	firstBody := mail.Messages()[0].Body
+	firstToken := strings.Fields(firstBody[strings.Index(firstBody, "token=")+len("token="):])[0]
+	if _, err := invitationService.Resend(invitation.ID, models.Actor{ID: "USER01"}); err != nil {
+		t.Fatalf("Expected no error resending invitation, got: %v", err)
+	}
+	if _, err := invitationService.Accept(&models.AcceptInvitationRequest{Token: firstToken, Password: "secret123"}, models.Actor{}); err == nil {
+		t.Error("Expected the superseded invitation link to be rejected")
+	}
+
+	body := mail.Messages()[1].Body
+	token := strings.Fields(body[strings.Index(body, "token=")+len("token="):])[0]
+
+	user, err := invitationService.Accept(&models.AcceptInvitationRequest{Token: token, Password: "secret123"}, models.Actor{})
+	if err != nil {
+		t.Fatalf("Expected no error accepting invitation, got: %v", err)
+	}
+
+	if user.Role != models.RoleEmployee || user.Department != "Engineering" || user.Status != models.UserStatusActive {
+		t.Errorf("Expected active employee with invitation profile, got: %+v", user)
+	}
+
+	if !utils.CheckPassword("secret123", user.Password) {
+		t.Error("Expected invitee's chosen password to be set")
+	}
+
+	if invitation.Status != models.InvitationStatusAccepted {
+		t.Errorf("Expected invitation status accepted, got %s", invitation.Status)
+	}
+
+	if _, err := invitationService.Accept(&models.AcceptInvitationRequest{Token: token, Password: "secret123"}, models.Actor{}); err == nil {
+		t.Error("Expected error when accepting an invitation twice")
+	}
+}
+
+func TestRevokeInvitation_BlocksAccept(t *testing.T) {
+	mail := mailer.NewMemoryMailer()
+	cfg := &config.Config{Auth: config.AuthConfig{InvitationExpiry: time.Hour}}
+	invitationService := NewInvitationService(NewMockInvitationRepository(), NewMockUserRepository(), newTestIDGenerator(NewMockCounterRepository()), mail, cfg, newTestAuditService())
+
+	invitation, _ := invitationService.Create(&models.CreateInvitationRequest{Email: "client@example.com", Role: models.RoleClient, Name: "Client"}, models.Actor{ID: "USER01"})
+	if err := invitationService.Revoke(invitation.ID, models.Actor{ID: "USER01"}); err != nil {
+		t.Fatalf("Expected no error revoking invitation, got: %v", err)
+	}
+
+	body := mail.Messages()[0].Body
+	token := strings.Fields(body[strings.Index(body, "token=")+len("token="):])[0]
+	if _, err := invitationService.Accept(&models.AcceptInvitationRequest{Token: token, Password: "secret123"}, models.Actor{}); err == nil {
+		t.Error("Expected revoked invitation to be rejected")
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LoginBlockedError is returned when an email has too many recent failed logins.
+// Locked distinguishes a temporary lockout from the progressive delay.
+type LoginBlockedError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return "account is temporarily locked after too many failed login attempts. Please try again later"
+	}
+	return "too many failed login attempts. Please wait before trying again"
+}
+
+type LockoutService interface {
+	Check(email string) error
+	RecordFailure(email, ip string) error
+	RecordSuccess(email string) error
+	Unlock(email, actorID string) error
+	ListLocked() ([]models.LoginAttempt, error)
+	ListEvents(query *models.LockoutEventQuery) ([]models.LockoutEvent, int64, error)
+}
+
+type lockoutService struct {
+	attemptRepo repositories.LoginAttemptRepository
+	eventRepo   repositories.LockoutEventRepository
+	cfg         *config.Config
+}
+
+func NewLockoutService(attemptRepo repositories.LoginAttemptRepository, eventRepo repositories.LockoutEventRepository, cfg *config.Config) LockoutService {
+	return &lockoutService{
+		attemptRepo: attemptRepo,
+		eventRepo:   eventRepo,
+		cfg:         cfg,
+	}
+}
+
+// Check returns a *LoginBlockedError if a login for email must not be attempted yet.
+func (s *lockoutService) Check(email string) error {
+	attempt, err := s.attemptRepo.FindByEmail(normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return &LoginBlockedError{Locked: true, RetryAfter: attempt.LockedUntil.Sub(now)}
+	}
+
+	if delay := s.delayFor(attempt.FailedCount); delay > 0 {
+		if next := attempt.LastFailedAt.Add(delay); now.Before(next) {
+			return &LoginBlockedError{RetryAfter: next.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login and locks the email once the lockout
+// threshold is reached. The counter is kept after a lockout expires, so every
+// further failure within the attempt window locks the account again.
+func (s *lockoutService) RecordFailure(email, ip string) error {
+	email = normalizeEmail(email)
+	now := time.Now()
+
+	attempt, err := s.attemptRepo.RecordFailure(email, now.Add(s.cfg.Auth.LoginAttemptWindow))
+	if err != nil {
+		return err
+	}
+
+	threshold := s.cfg.Auth.LoginLockoutThreshold
+	if threshold <= 0 || attempt.FailedCount < threshold {
+		return nil
+	}
+
+	until := now.Add(s.cfg.Auth.LoginLockoutDuration)
+	if err := s.attemptRepo.Lock(email, until, until.Add(s.cfg.Auth.LoginAttemptWindow)); err != nil {
+		return err
+	}
+
+	return s.eventRepo.Create(&models.LockoutEvent{
+		ID:          primitive.NewObjectID().Hex(),
+		Email:       email,
+		Type:        models.LockoutEventLocked,
+		IP:          ip,
+		FailedCount: attempt.FailedCount,
+		LockedUntil: &until,
+	})
+}
+
+func (s *lockoutService) RecordSuccess(email string) error {
+	return s.attemptRepo.Delete(normalizeEmail(email))
+}
+
+// Unlock clears the failure history of an email on behalf of an administrator.
+func (s *lockoutService) Unlock(email, actorID string) error {
+	email = normalizeEmail(email)
+
+	if _, err := s.attemptRepo.FindByEmail(email); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("LOGIN_ATTEMPTS_NOT_FOUND", "no failed login attempts recorded for this email")
+		}
+		return err
+	}
+
+	if err := s.attemptRepo.Delete(email); err != nil {
+		return err
+	}
+
+	return s.eventRepo.Create(&models.LockoutEvent{
+		ID:      primitive.NewObjectID().Hex(),
+		Email:   email,
+		Type:    models.LockoutEventUnlocked,
+		ActorID: actorID,
+	})
+}
+
+func (s *lockoutService) ListLocked() ([]models.LoginAttempt, error) {
+	return s.attemptRepo.ListLocked()
+}
+
+func (s *lockoutService) ListEvents(query *models.LockoutEventQuery) ([]models.LockoutEvent, int64, error) {
+	email := ""
+	if query.Email != "" {
+		email = normalizeEmail(query.Email)
+	}
+	return s.eventRepo.List(query.Page, query.PageSize, email, query.Type)
+}
+
+// delayFor returns how long to wait after the last failure, doubling with
+// every failure past the delay threshold and capped at the lockout duration.
+func (s *lockoutService) delayFor(failures int) time.Duration {
+	threshold := s.cfg.Auth.LoginDelayThreshold
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+
+	delay := s.cfg.Auth.LoginDelayBase
+	for i := threshold; i < failures && delay < s.cfg.Auth.LoginLockoutDuration; i++ {
+		delay *= 2
+	}
+
+	if delay > s.cfg.Auth.LoginLockoutDuration {
+		delay = s.cfg.Auth.LoginLockoutDuration
+	}
+	return delay
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MockLoginAttemptRepository for testing
+type MockLoginAttemptRepository struct {
+	attempts map[string]*models.LoginAttempt
+}
+
+func NewMockLoginAttemptRepository() *MockLoginAttemptRepository {
+	return &MockLoginAttemptRepository{
+		attempts: make(map[string]*models.LoginAttempt),
+	}
+}
+
+func (m *MockLoginAttemptRepository) FindByEmail(email string) (*models.LoginAttempt, error) {
+	attempt, exists := m.attempts[email]
+	if !exists {
+		return nil, mongo.ErrNoDocuments
+	}
+	return attempt, nil
+}
+
+func (m *MockLoginAttemptRepository) RecordFailure(email string, expiresAt time.Time) (*models.LoginAttempt, error) {
+	attempt, exists := m.attempts[email]
+	if !exists {
+		attempt = &models.LoginAttempt{Email: email}
+		m.attempts[email] = attempt
+	}
+	attempt.FailedCount++
+	attempt.LastFailedAt = time.Now()
+	attempt.ExpiresAt = expiresAt
+	return attempt, nil
+}
+
+func (m *MockLoginAttemptRepository) Lock(email string, until time.Time, expiresAt time.Time) error {
+	attempt, exists := m.attempts[email]
+	if !exists {
+		return errors.New("login attempt not found")
+	}
+	attempt.LockedUntil = &until
+	attempt.ExpiresAt = expiresAt
+	return nil
+}
+
+func (m *MockLoginAttemptRepository) Delete(email string) error {
+	delete(m.attempts, email)
+	return nil
+}
+
+func (m *MockLoginAttemptRepository) ListLocked() ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	for _, attempt := range m.attempts {
+		if attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+			attempts = append(attempts, *attempt)
+		}
+	}
+	return attempts, nil
+}
+
+// MockLockoutEventRepository for testing
+type MockLockoutEventRepository struct {
+	events []models.LockoutEvent
+}
+
+func NewMockLockoutEventRepository() *MockLockoutEventRepository {
+	return &MockLockoutEventRepository{}
+}
+
+func (m *MockLockoutEventRepository) Create(event *models.LockoutEvent) error {
+	event.CreatedAt = time.Now()
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *MockLockoutEventRepository) List(page, pageSize int, email string, eventType string) ([]models.LockoutEvent, int64, error) {
+	var events []models.LockoutEvent
+	for _, event := range m.events {
+		if (email == "" || event.Email == email) && (eventType == "" || string(event.Type) == eventType) {
+			events = append(events, event)
+		}
+	}
+	return events, int64(len(events)), nil
+}
+
+func newTestLockoutService(cfg *config.Config) LockoutService {
+	return NewLockoutService(NewMockLoginAttemptRepository(), NewMockLockoutEventRepository(), cfg)
+}
+
+func TestLogin_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	// Setup
+	mockRepo := NewMockUserRepository()
+	attemptRepo := NewMockLoginAttemptRepository()
+	eventRepo := NewMockLockoutEventRepository()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:        "test-secret",
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: time.Hour,
+		},
+		Auth: config.AuthConfig{
+			LoginLockoutThreshold: 3,
+			LoginLockoutDuration:  15 * time.Minute,
+			LoginAttemptWindow:    time.Hour,
+		},
+	}
+
+	lockoutService := NewLockoutService(attemptRepo, eventRepo, cfg)
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), lockoutService, mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo.Create(&models.User{
+		UserID:   "USER01",
+		Email:    "locked@example.com",
+		Password: hashedPassword,
+		Role:     models.RoleClient,
+		Status:   models.UserStatusActive,
+	})
+
+	wrong := &models.LoginRequest{Email: "locked@example.com", Password: "wrong"}
+	for i := 0; i < 3; i++ {
+		if _, err := authService.Login(wrong, models.ClientInfo{IP: "10.0.0.1"}); err == nil {
+			t.Fatal("Expected error for wrong password")
+		}
+	}
+
+	// Even the correct password is refused while locked
+	_, err := authService.Login(&models.LoginRequest{Email: "Locked@Example.com", Password: "password123"}, models.ClientInfo{})
+	var blocked *LoginBlockedError
+	if !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("Expected account lockout error, got: %v", err)
+	}
+
+	if len(eventRepo.events) != 1 || eventRepo.events[0].Type != models.LockoutEventLocked || eventRepo.events[0].IP != "10.0.0.1" {
+		t.Errorf("Expected one lock event with the client IP, got: %+v", eventRepo.events)
+	}
+
+	if err := lockoutService.Unlock("locked@example.com", "USER99"); err != nil {
+		t.Fatalf("Expected no error unlocking, got: %v", err)
+	}
+
+	if _, err := authService.Login(&models.LoginRequest{Email: "locked@example.com", Password: "password123"}, models.ClientInfo{}); err != nil {
+		t.Errorf("Expected login to succeed after unlock, got: %v", err)
+	}
+
+	if len(eventRepo.events) != 2 || eventRepo.events[1].ActorID != "USER99" {
+		t.Errorf("Expected unlock event recording the admin, got: %+v", eventRepo.events)
+	}
+}
+
+func TestLogin_ProgressiveDelay(t *testing.T) {
+	// Setup
+	attemptRepo := NewMockLoginAttemptRepository()
+	cfg := &config.Config{
+		Auth: config.AuthConfig{
+			LoginDelayThreshold:   2,
+			LoginDelayBase:        time.Second,
+			LoginLockoutThreshold: 10,
+			LoginLockoutDuration:  15 * time.Minute,
+			LoginAttemptWindow:    time.Hour,
+		},
+	}
+	lockoutService := NewLockoutService(attemptRepo, NewMockLockoutEventRepository(), cfg)
+
+	lockoutService.RecordFailure("slow@example.com", "")
+	if err := lockoutService.Check("slow@example.com"); err != nil {
+		t.Errorf("Expected no delay below the threshold, got: %v", err)
+	}
+
+	lockoutService.RecordFailure("slow@example.com", "")
+	var blocked *LoginBlockedError
+	if err := lockoutService.Check("slow@example.com"); !errors.As(err, &blocked) || blocked.Locked {
+		t.Fatalf("Expected a throttling error at the threshold, got: %v", err)
+	}
+
+	// Each further failure doubles the wait
+	attemptRepo.attempts["slow@example.com"].FailedCount = 5
+	attemptRepo.attempts["slow@example.com"].LastFailedAt = time.Now().Add(-5 * time.Second)
+	if err := lockoutService.Check("slow@example.com"); !errors.As(err, &blocked) || blocked.RetryAfter <= 2*time.Second {
+		t.Errorf("Expected roughly 3s left of an 8s delay, got: %v", err)
+	}
+
+	attemptRepo.attempts["slow@example.com"].LastFailedAt = time.Now().Add(-9 * time.Second)
+	if err := lockoutService.Check("slow@example.com"); err != nil {
+		t.Errorf("Expected login to be allowed once the delay has passed, got: %v", err)
+	}
+}
+
+func TestTwoFactor_WrongCodesCountTowardsLockout(t *testing.T) {
+	// Setup
+	mockRepo := NewMockUserRepository()
+	cfg := &config.Config{
+		Auth: config.AuthConfig{
+			LoginLockoutThreshold: 3,
+			LoginLockoutDuration:  15 * time.Minute,
+			LoginAttemptWindow:    time.Hour,
+			TOTPIssuer:            "Test",
+		},
+	}
+	twoFactorService := NewTwoFactorService(mockRepo, NewLockoutService(NewMockLoginAttemptRepository(), NewMockLockoutEventRepository(), cfg), cfg, newTestAuditService())
+
+	mockRepo.Create(&models.User{UserID: "USER01", Email: "2fa@example.com", Role: models.RoleEmployee, Status: models.UserStatusActive})
+	setup, err := twoFactorService.Setup("USER01")
+	if err != nil {
+		t.Fatalf("Expected no error starting setup, got: %v", err)
+	}
+	enableCode, _ := utils.TOTPCode(setup.Secret, time.Now().Add(-30*time.Second))
+	recoveryCodes, err := twoFactorService.Enable(&models.TwoFactorCodeRequest{Code: enableCode}, models.Actor{ID: "USER01"})
+	if err != nil {
+		t.Fatalf("Expected no error enabling 2FA, got: %v", err)
+	}
+
+	actor := models.Actor{ID: "USER01", IP: "10.0.0.1"}
+	if err := twoFactorService.Disable(&models.TwoFactorCodeRequest{Code: "000000"}, actor); err != ErrInvalidTwoFactorCode {
+		t.Fatalf("Expected an invalid code error, got: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := twoFactorService.RegenerateRecoveryCodes(&models.TwoFactorCodeRequest{Code: "000000"}, actor); err != ErrInvalidTwoFactorCode {
+			t.Fatalf("Expected an invalid code error, got: %v", err)
+		}
+	}
+
+	// Even a valid recovery code is refused while locked
+	var blocked *LoginBlockedError
+	if err := twoFactorService.Disable(&models.TwoFactorCodeRequest{Code: recoveryCodes[0]}, actor); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Errorf("Expected account lockout error, got: %v", err)
+	}
+}
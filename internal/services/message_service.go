@@ -3,76 +3,68 @@ package services
 import (
 	"fmt"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/policy"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 )
 
 type MessageService interface {
-	Create(senderID string, req *models.CreateMessageRequest, userID string, userRole string) (*models.Message, error)
+	// Create posts a message sent by actor
+	Create(req *models.CreateMessageRequest, actor models.Actor) (*models.Message, error)
 	GetByID(id string, userID string, userRole string) (*models.Message, error)
-	Delete(id string, userID string, userRole string) error
-	ListByProject(projectID string, page, pageSize int, userID string, userRole string) ([]models.Message, int64, error)
+	Delete(id string, actor models.Actor) error
+	Restore(id string, actor models.Actor) (*models.Message, error)
+	ListByProject(projectID string, page, pageSize int, includeDeleted bool, userID string, userRole string) ([]models.Message, int64, error)
 }
 
 type messageService struct {
 	messageRepo  repositories.MessageRepository
 	projectRepo  repositories.ProjectRepository
-	counterRepo  repositories.CounterRepository
+	ids          IDGenerator
+	policy       *policy.Engine
+	audit        AuditService
 }
 
-func NewMessageService(messageRepo repositories.MessageRepository, counterRepo repositories.CounterRepository, projectRepo repositories.ProjectRepository) MessageService {
+func NewMessageService(messageRepo repositories.MessageRepository, ids IDGenerator, projectRepo repositories.ProjectRepository, policyEngine *policy.Engine, audit AuditService) MessageService {
 	return &messageService{
 		messageRepo: messageRepo,
-		counterRepo: counterRepo,
+		ids:         ids,
 		projectRepo: projectRepo,
+		policy:      policyEngine,
+		audit:       audit,
 	}
 }
 
-func (s *messageService) Create(senderID string, req *models.CreateMessageRequest, userID string, userRole string) (*models.Message, error) {
+func (s *messageService) Create(req *models.CreateMessageRequest, actor models.Actor) (*models.Message, error) {
 	// Validate request
 	if req.Content == "" {
-		return nil, fmt.Errorf("message content is required")
+		return nil, apperrors.Validation("MESSAGE_CONTENT_REQUIRED", "message content is required")
 	}
 	if req.ProjectID == "" {
-		return nil, fmt.Errorf("project ID is required")
+		return nil, apperrors.Validation("PROJECT_ID_REQUIRED", "project ID is required")
 	}
+	senderID := actor.ID
 	if senderID == "" {
-		return nil, fmt.Errorf("sender ID is required")
+		return nil, apperrors.Validation("SENDER_ID_REQUIRED", "sender ID is required")
 	}
 
 	// Verify user has access to the project
 	project, err := s.projectRepo.FindByID(req.ProjectID)
 	if err != nil {
-		return nil, fmt.Errorf("project not found")
-	}
-
-	// Apply role-based access control for messaging
-	if userRole == "employee" {
-		// Employees can only message projects they're assigned to
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return nil, fmt.Errorf("access denied: employee not assigned to this project")
-		}
-	} else if userRole == "client" {
-		// Clients can only message their own projects
-		if project.ClientID != userID {
-			return nil, fmt.Errorf("access denied: client can only message their own projects")
-		}
-	}
-
-	// Generate next message ID sequence
-	sequence, err := s.counterRepo.GetNextSequence("message_counter")
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate message ID: %w", err)
+		return nil, lookupError(err, ErrProjectNotFound)
 	}
 
-	messageID := fmt.Sprintf("MESSAGE%02d", sequence)
+	// The new message will belong to the sender
+	resource := policy.MessageResource(&models.Message{SenderID: senderID}, project)
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.MessageCreate, resource); err != nil {
+		return nil, err
+	}
+
+	messageID, err := s.ids.NextMessageID()
+	if err != nil {
+		return nil, err
+	}
 
 	message := &models.Message{
 		ID:        messageID,
@@ -85,86 +77,83 @@ func (s *messageService) Create(senderID string, req *models.CreateMessageReques
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
-	return s.messageRepo.FindByID(message.ID)
+	created, err := s.messageRepo.FindByID(message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionCreate, auditMessage, created.ID, nil, snapshot(created))
+	return created, nil
 }
 
 func (s *messageService) GetByID(id string, userID string, userRole string) (*models.Message, error) {
 	message, err := s.messageRepo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, lookupError(err, ErrMessageNotFound)
 	}
 
 	// Verify user has access to the project
 	project, err := s.projectRepo.FindByID(message.ProjectID)
 	if err != nil {
-		return nil, fmt.Errorf("project not found")
-	}
-
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only view messages from projects they're assigned to
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return nil, fmt.Errorf("access denied: employee not assigned to this project")
-		}
-	} else if userRole == "client" {
-		// Clients can only view messages from their own projects
-		if project.ClientID != userID {
-			return nil, fmt.Errorf("access denied: client can only view their own projects")
-		}
+		return nil, lookupError(err, ErrProjectNotFound)
+	}
+
+	if err := s.policy.Authorize(policy.Subject{ID: userID, Role: userRole}, policy.MessageRead, policy.MessageResource(message, project)); err != nil {
+		return nil, err
 	}
 
 	return message, nil
 }
 
-func (s *messageService) Delete(id string, userID string, userRole string) error {
+func (s *messageService) Delete(id string, actor models.Actor) error {
 	message, err := s.messageRepo.FindByID(id)
 	if err != nil {
-		return err
+		return lookupError(err, ErrMessageNotFound)
 	}
 
 	// Verify user has access to the project
 	project, err := s.projectRepo.FindByID(message.ProjectID)
 	if err != nil {
-		return fmt.Errorf("project not found")
-	}
-
-	// Apply role-based access control for deletion
-	if userRole == "employee" {
-		// Employees can only delete their own messages from projects they're assigned to
-		if message.SenderID != userID {
-			return fmt.Errorf("access denied: can only delete own messages")
-		}
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return fmt.Errorf("access denied: employee not assigned to this project")
-		}
-	} else if userRole == "client" {
-		// Clients can only delete their own messages from their own projects
-		if message.SenderID != userID {
-			return fmt.Errorf("access denied: can only delete own messages")
-		}
-		if project.ClientID != userID {
-			return fmt.Errorf("access denied: client can only delete messages from their own projects")
-		}
-	}
-
-	return s.messageRepo.Delete(id)
+		return lookupError(err, ErrProjectNotFound)
+	}
+
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.MessageDelete, policy.MessageResource(message, project)); err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.Delete(id, actor.ID); err != nil {
+		return err
+	}
+
+	s.audit.Record(actor, models.AuditActionDelete, auditMessage, id, snapshot(message), nil)
+	return nil
 }
 
-func (s *messageService) ListByProject(projectID string, page, pageSize int, userID string, userRole string) ([]models.Message, int64, error) {
+func (s *messageService) Restore(id string, actor models.Actor) (*models.Message, error) {
+	deleted, err := s.messageRepo.FindDeleted(id)
+	if err != nil {
+		return nil, lookupError(err, ErrMessageNotFound)
+	}
+	// A message is not brought back onto a deleted project
+	if _, err := s.projectRepo.FindByID(deleted.ProjectID); err != nil {
+		return nil, lookupError(err, ErrParentDeleted)
+	}
+
+	tombstone, err := s.messageRepo.Restore(id)
+	if err != nil {
+		return nil, lookupError(err, ErrMessageNotFound)
+	}
+
+	message, err := s.messageRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionRestore, auditMessage, id, snapshot(tombstone), snapshot(message))
+	return message, nil
+}
+
+func (s *messageService) ListByProject(projectID string, page, pageSize int, includeDeleted bool, userID string, userRole string) ([]models.Message, int64, error) {
 	// If projectID is empty, return all messages the user has access to
 	if projectID == "" {
 		// For now, return empty list as general message listing isn't implemented
@@ -175,28 +164,12 @@ func (s *messageService) ListByProject(projectID string, page, pageSize int, use
 	// Verify user has access to the project
 	project, err := s.projectRepo.FindByID(projectID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("project not found")
-	}
-
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only view messages from projects they're assigned to
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return nil, 0, fmt.Errorf("access denied: employee not assigned to this project")
-		}
-	} else if userRole == "client" {
-		// Clients can only view messages from their own projects
-		if project.ClientID != userID {
-			return nil, 0, fmt.Errorf("access denied: client can only view their own projects")
-		}
-	}
-
-	return s.messageRepo.ListByProject(projectID, page, pageSize)
+		return nil, 0, lookupError(err, ErrProjectNotFound)
+	}
+
+	if err := s.policy.Authorize(policy.Subject{ID: userID, Role: userRole}, policy.MessageList, policy.ProjectResource(project)); err != nil {
+		return nil, 0, err
+	}
+
+	return s.messageRepo.ListByProject(projectID, page, pageSize, includeDeleted)
 }
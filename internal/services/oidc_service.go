@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/oidc"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrOIDCDisabled = apperrors.NotFound("OIDC_DISABLED", "single sign-on is not configured")
+
+type OIDCService interface {
+	Begin() (*models.OIDCLoginResponse, error)
+	Callback(req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.LoginResponse, error)
+}
+
+type oidcService struct {
+	oidcClient  *oidc.Client
+	stateRepo   repositories.OIDCStateRepository
+	userRepo    repositories.UserRepository
+	ids         IDGenerator
+	authService AuthService
+	policy      *policy.Engine
+	cfg         *config.Config
+	audit       AuditService
+}
+
+// NewOIDCService wires the SSO flow; oidcClient is nil when SSO is disabled.
+func NewOIDCService(oidcClient *oidc.Client, stateRepo repositories.OIDCStateRepository, userRepo repositories.UserRepository, ids IDGenerator, authService AuthService, policyEngine *policy.Engine, cfg *config.Config, audit AuditService) OIDCService {
+	return &oidcService{
+		oidcClient:  oidcClient,
+		stateRepo:   stateRepo,
+		userRepo:    userRepo,
+		ids:         ids,
+		authService: authService,
+		policy:      policyEngine,
+		cfg:         cfg,
+		audit:       audit,
+	}
+}
+
+// Begin starts a login and returns the provider URL the browser must visit.
+func (s *oidcService) Begin() (*models.OIDCLoginResponse, error) {
+	if s.oidcClient == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	values := make([]string, 3)
+	for i := range values {
+		value, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := s.oidcClient.AuthCodeURL(state, nonce, verifier)
+	if err != nil {
+		return nil, apperrors.Upstream("OIDC_DISCOVERY_FAILED", err)
+	}
+
+	if err := s.stateRepo.Create(&models.OIDCState{
+		ID:           utils.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.cfg.OIDC.StateExpiry),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCLoginResponse{AuthorizationURL: authURL}, nil
+}
+
+// Callback completes a login: it redeems the code, verifies the ID token and
+// signs in the user with the token's email, provisioning the account if allowed.
+func (s *oidcService) Callback(req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	if s.oidcClient == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	// Consume the state first so it cannot be replayed even if the login fails
+	state, err := s.stateRepo.Consume(utils.HashToken(req.State))
+	if err != nil || time.Now().After(state.ExpiresAt) {
+		return nil, apperrors.Unauthorized("INVALID_OIDC_STATE", "invalid or expired login state")
+	}
+
+	if req.Error != "" {
+		return nil, apperrors.Unauthorized("OIDC_PROVIDER_ERROR", "identity provider returned an error: %s %s", req.Error, req.ErrorDescription)
+	}
+	if req.Code == "" {
+		return nil, apperrors.Validation("OIDC_CODE_MISSING", "authorization code is missing")
+	}
+
+	token, err := s.oidcClient.Exchange(req.Code, state.CodeVerifier)
+	if err != nil {
+		return nil, apperrors.Unauthorized("OIDC_EXCHANGE_FAILED", "%v", err).Wrap(err)
+	}
+
+	claims, err := s.oidcClient.VerifyIDToken(token.IDToken, state.Nonce)
+	if err != nil {
+		return nil, apperrors.Unauthorized("INVALID_ID_TOKEN", "%v", err).Wrap(err)
+	}
+
+	// Providers do not promise to keep the case of an address stable
+	email, _ := claims["email"].(string)
+	email = normalizeEmail(email)
+	if email == "" {
+		return nil, apperrors.Unauthorized("OIDC_EMAIL_MISSING", "identity provider did not return an email address")
+	}
+
+	// Providers that omit email_verified are trusted; an explicit false is not
+	if verified, ok := claims["email_verified"]; ok && verified != true && verified != "true" {
+		return nil, apperrors.Forbidden("OIDC_EMAIL_NOT_VERIFIED", "email address is not verified by the identity provider")
+	}
+
+	user, err := s.findOrProvision(email, claims, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == models.UserStatusPendingApproval {
+		return nil, ErrAccountPendingApproval
+	}
+	if user.Status != "" && user.Status != models.UserStatusActive {
+		return nil, ErrAccountInactive
+	}
+
+	return s.authService.CreateSession(user, client)
+}
+
+// findOrProvision returns the account of email, updated or created from the
+// claims. Changes are recorded with the user as the actor.
+func (s *oidcService) findOrProvision(email string, claims jwt.MapClaims, client models.ClientInfo) (*models.User, error) {
+	role := s.mapRole(claims)
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	if err == nil {
+		before := snapshot(user)
+		changed := false
+		if s.cfg.OIDC.SyncRoles && role != "" && user.Role != role {
+			user.Role = role
+			changed = true
+		}
+		// The provider vouches for the address, which is what verification proves
+		if user.Status == models.UserStatusUnverified {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			user.Status = verifiedStatus(s.cfg.Registration)
+			changed = true
+		}
+		if changed {
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, err
+			}
+			s.audit.Record(ssoActor(user, client), models.AuditActionUpdate, auditUser, user.UserID, before, snapshot(user))
+		}
+		return user, nil
+	}
+
+	// A deleted account keeps its address until it is restored or purged
+	if deleted, err := s.userRepo.EmailExists(email); err != nil {
+		return nil, err
+	} else if deleted {
+		return nil, ErrAccountInactive
+	}
+
+	if !s.cfg.OIDC.AutoProvision {
+		return nil, apperrors.Forbidden("OIDC_NO_ACCOUNT", "no account exists for this email address")
+	}
+	if role == "" {
+		return nil, apperrors.Forbidden("OIDC_NO_ROLE", "your identity provider account is not assigned a role in this application")
+	}
+	// Provisioning registers the account, so the registration policy applies;
+	// the role mapping stands in for the allowlist
+	switch s.cfg.Registration.Mode {
+	case config.RegistrationClosed:
+		return nil, ErrRegistrationClosed
+	case config.RegistrationInvitation:
+		return nil, ErrRegistrationInvitation
+	}
+
+	// SSO accounts get an unguessable password; a reset can set a real one later
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := s.ids.NextUserID()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	name, _ := claims["name"].(string)
+	if name == "" {
+		name = email
+	}
+
+	now := time.Now()
+	user = &models.User{
+		UserID:          userID,
+		Email:           email,
+		Password:        hashedPassword,
+		Name:            name,
+		Role:            role,
+		Status:          verifiedStatus(s.cfg.Registration),
+		EmailVerifiedAt: &now,
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	s.audit.Record(ssoActor(user, client), models.AuditActionCreate, auditUser, user.UserID, nil, snapshot(user))
+
+	return user, nil
+}
+
+// ssoActor records changes made by a single sign-on as made by the user.
+func ssoActor(user *models.User, client models.ClientInfo) models.Actor {
+	return models.Actor{ID: user.UserID, Role: string(user.Role), IP: client.IP}
+}
+
+// mapRole translates the configured role claim, which may be a string or a
+// list of strings, into the most privileged mapped role. Mapped names the
+// policy does not define are ignored.
+func (s *oidcService) mapRole(claims jwt.MapClaims) models.Role {
+	var values []string
+	switch claim := claims[s.cfg.OIDC.RoleClaim].(type) {
+	case string:
+		values = []string{claim}
+	case []interface{}:
+		for _, v := range claim {
+			if str, ok := v.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	var role models.Role
+	for _, value := range values {
+		mapped := s.cfg.OIDC.RoleMapping[value]
+		if s.policy.Rank(mapped) > s.policy.Rank(string(role)) {
+			role = models.Role(mapped)
+		}
+	}
+
+	if role == "" && s.policy.HasRole(s.cfg.OIDC.DefaultRole) {
+		role = models.Role(s.cfg.OIDC.DefaultRole)
+	}
+	return role
+}
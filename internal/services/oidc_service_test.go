@@ -0,0 +1,374 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/oidc"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// MockOIDCStateRepository for testing
+type MockOIDCStateRepository struct {
+	states map[string]*models.OIDCState
+}
+
+func NewMockOIDCStateRepository() *MockOIDCStateRepository {
+	return &MockOIDCStateRepository{
+		states: make(map[string]*models.OIDCState),
+	}
+}
+
+func (m *MockOIDCStateRepository) Create(state *models.OIDCState) error {
+	state.CreatedAt = time.Now()
+	m.states[state.ID] = state
+	return nil
+}
+
+func (m *MockOIDCStateRepository) Consume(id string) (*models.OIDCState, error) {
+	state, exists := m.states[id]
+	if !exists {
+		return nil, errors.New("state not found")
+	}
+	delete(m.states, id)
+	return state, nil
+}
+
+// stubIdP is a minimal OpenID provider: discovery, JWKS and a token endpoint
+// that enforces PKCE for codes registered with authorize.
+type stubIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]stubGrant
+}
+
+type stubGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate IdP key: %v", err)
+	}
+
+	idp := &stubIdP{key: key, grants: make(map[string]stubGrant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidc.Discovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(utils.JWKS{Keys: []utils.JWK{{
+			Kty: "RSA",
+			Kid: "stub",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		grant, ok := idp.grants[r.PostForm.Get("code")]
+		delete(idp.grants, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+
+		if !ok || oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != grant.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(oidc.TokenResponse{IDToken: idp.sign(grant.claims), TokenType: "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *stubIdP) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "stub"
+	signed, _ := token.SignedString(idp.key)
+	return signed
+}
+
+// authorize plays the user's visit to the authorization URL and returns the
+// code and state the provider would redirect back with.
+func (idp *stubIdP) authorize(t *testing.T, authURL string, claims jwt.MapClaims) (string, string) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	query := parsed.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("Expected a PKCE S256 challenge, got: %s", parsed.RawQuery)
+	}
+
+	base := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   "api-client",
+		"sub":   "subject-1",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for k, v := range claims {
+		base[k] = v
+	}
+
+	code, _ := utils.GenerateRandomToken(16)
+	idp.mu.Lock()
+	idp.grants[code] = stubGrant{challenge: query.Get("code_challenge"), claims: base}
+	idp.mu.Unlock()
+
+	return code, query.Get("state")
+}
+
+// newTestOIDCService signs in through idp with the OIDC, auth and
+// registration settings of cfg.
+func newTestOIDCService(idp *stubIdP, userRepo *MockUserRepository, settings config.Config) OIDCService {
+	cfg := &settings
+	cfg.JWT = config.JWTConfig{Expiry: 15 * time.Minute, RefreshExpiry: time.Hour}
+	cfg.OIDC.StateExpiry = time.Minute
+
+	client := oidc.NewClient(oidc.Config{
+		IssuerURL:   idp.server.URL,
+		ClientID:    "api-client",
+		RedirectURL: "http://localhost:3000/oidc/callback",
+		Scopes:      []string{"openid", "email"},
+	}, idp.server.Client())
+
+	authService := NewAuthService(userRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+	return NewOIDCService(client, NewMockOIDCStateRepository(), userRepo, newTestIDGenerator(NewMockCounterRepository()), authService, policy.Default(), cfg, newTestAuditService())
+}
+
+func TestOIDCLogin_ProvisionsUserWithMappedRole(t *testing.T) {
+	idp := newStubIdP(t)
+	userRepo := NewMockUserRepository()
+	oidcService := newTestOIDCService(idp, userRepo, config.Config{OIDC: config.OIDCConfig{
+		RoleClaim:     "groups",
+		RoleMapping:   map[string]string{"it-admins": "admin", "staff": "employee"},
+		AutoProvision: true,
+	}})
+
+	begin, err := oidcService.Begin()
+	if err != nil {
+		t.Fatalf("Expected no error starting login, got: %v", err)
+	}
+
+	code, state := idp.authorize(t, begin.AuthorizationURL, jwt.MapClaims{
+		"email":          "sso@example.com",
+		"email_verified": true,
+		"name":           "SSO User",
+		"groups":         []string{"staff", "it-admins"},
+	})
+
+	response, err := oidcService.Callback(&models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error completing login, got: %v", err)
+	}
+
+	if response.Token == "" || response.User.Role != models.RoleAdmin || response.User.Name != "SSO User" {
+		t.Errorf("Expected tokens for a provisioned admin, got: %+v", response)
+	}
+
+	if user, err := userRepo.FindByEmail("sso@example.com"); err != nil || user.Status != models.UserStatusActive || user.EmailVerifiedAt == nil {
+		t.Errorf("Expected an active, verified account to be provisioned, got: %+v", user)
+	}
+
+	// The state is single-use
+	if _, err := oidcService.Callback(&models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{}); err == nil {
+		t.Error("Expected error when replaying the callback")
+	}
+}
+
+func TestOIDCLogin_RejectsInvalidIDTokens(t *testing.T) {
+	idp := newStubIdP(t)
+	userRepo := NewMockUserRepository()
+	oidcService := newTestOIDCService(idp, userRepo, config.Config{OIDC: config.OIDCConfig{
+		RoleClaim:     "groups",
+		DefaultRole:   "employee",
+		AutoProvision: true,
+	}})
+
+	cases := map[string]jwt.MapClaims{
+		"wrong audience": {"email": "a@example.com", "aud": "other-client"},
+		"wrong nonce":    {"email": "a@example.com", "nonce": "forged"},
+		"wrong issuer":   {"email": "a@example.com", "iss": "https://evil.example.com"},
+		"expired":        {"email": "a@example.com", "exp": time.Now().Add(-time.Hour).Unix()},
+		"unverified":     {"email": "a@example.com", "email_verified": false},
+	}
+
+	for name, claims := range cases {
+		begin, _ := oidcService.Begin()
+		code, state := idp.authorize(t, begin.AuthorizationURL, claims)
+		if _, err := oidcService.Callback(&models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{}); err == nil {
+			t.Errorf("%s: expected the ID token to be rejected", name)
+		}
+	}
+
+	if len(userRepo.users) != 0 {
+		t.Errorf("Expected no users to be provisioned, got %d", len(userRepo.users))
+	}
+}
+
+func TestOIDCLogin_ExistingUserWithoutProvisioning(t *testing.T) {
+	idp := newStubIdP(t)
+	userRepo := NewMockUserRepository()
+	oidcService := newTestOIDCService(idp, userRepo, config.Config{OIDC: config.OIDCConfig{RoleClaim: "groups"}})
+
+	userRepo.Create(&models.User{UserID: "USER01", Email: "known@example.com", Role: models.RoleClient, Status: models.UserStatusActive})
+
+	begin, _ := oidcService.Begin()
+	code, state := idp.authorize(t, begin.AuthorizationURL, jwt.MapClaims{"email": "Known@Example.com"})
+	response, err := oidcService.Callback(&models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected existing user to sign in, got: %v", err)
+	}
+
+	if response.User.UserID != "USER01" || response.User.Role != models.RoleClient {
+		t.Errorf("Expected the existing account to be matched by email, got: %+v", response.User)
+	}
+
+	begin, _ = oidcService.Begin()
+	code, state = idp.authorize(t, begin.AuthorizationURL, jwt.MapClaims{"email": "unknown@example.com"})
+	if _, err := oidcService.Callback(&models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{}); err == nil {
+		t.Error("Expected unknown users to be refused when auto-provisioning is off")
+	}
+}
+
+func TestOIDCLogin_MapsPolicyRoles(t *testing.T) {
+	engine, err := policy.New(append(policy.DefaultRoles(), policy.RoleDefinition{
+		Name:     "project_manager",
+		Inherits: []string{"employee"},
+		Rules:    []policy.Rule{{Actions: []policy.Action{policy.ProjectCreate, policy.ProjectAssign}}},
+	}))
+	if err != nil {
+		t.Fatalf("Failed to build policy: %v", err)
+	}
+	oidcService := &oidcService{policy: engine, cfg: &config.Config{OIDC: config.OIDCConfig{
+		RoleClaim:   "groups",
+		RoleMapping: map[string]string{"staff": "employee", "leads": "project_manager", "auditors": "auditor"},
+		DefaultRole: "client",
+	}}}
+
+	if role := oidcService.mapRole(jwt.MapClaims{"groups": []interface{}{"staff", "leads"}}); role != "project_manager" {
+		t.Errorf("Expected the custom role to outrank employee, got %q", role)
+	}
+	if role := oidcService.mapRole(jwt.MapClaims{"groups": []interface{}{"auditors"}}); role != models.RoleClient {
+		t.Errorf("Expected a role the policy does not define to fall back to the default, got %q", role)
+	}
+}
+
+// ssoLogin signs in email through idp and returns the response.
+func ssoLogin(t *testing.T, idp *stubIdP, oidcService OIDCService, email string) (*models.LoginResponse, error) {
+	t.Helper()
+	begin, err := oidcService.Begin()
+	if err != nil {
+		t.Fatalf("Expected no error starting login, got: %v", err)
+	}
+	code, state := idp.authorize(t, begin.AuthorizationURL, jwt.MapClaims{"email": email})
+	return oidcService.Callback(&models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{})
+}
+
+func TestOIDCLogin_ChallengesTwoFactorUsers(t *testing.T) {
+	idp := newStubIdP(t)
+	userRepo := NewMockUserRepository()
+	oidcService := newTestOIDCService(idp, userRepo, config.Config{
+		OIDC: config.OIDCConfig{RoleClaim: "groups"},
+		Auth: config.AuthConfig{TwoFactorChallengeExpiry: time.Minute},
+	})
+
+	userRepo.Create(&models.User{UserID: "USER01", Email: "totp@example.com", Role: models.RoleEmployee, Status: models.UserStatusActive, TwoFactorEnabled: true})
+
+	response, err := ssoLogin(t, idp, oidcService, "totp@example.com")
+	if err != nil {
+		t.Fatalf("Expected a challenge, got: %v", err)
+	}
+	if !response.TwoFactorRequired || response.ChallengeToken == "" || response.Token != "" {
+		t.Errorf("Expected a 2FA challenge instead of tokens, got: %+v", response)
+	}
+}
+
+func TestOIDCLogin_RequiresAdminTwoFactorEnrolment(t *testing.T) {
+	idp := newStubIdP(t)
+	userRepo := NewMockUserRepository()
+	oidcService := newTestOIDCService(idp, userRepo, config.Config{
+		OIDC: config.OIDCConfig{RoleClaim: "groups"},
+		Auth: config.AuthConfig{RequireAdmin2FA: true, TwoFactorChallengeExpiry: time.Minute},
+	})
+
+	userRepo.Create(&models.User{UserID: "USER01", Email: "admin@example.com", Role: models.RoleAdmin, Status: models.UserStatusActive})
+
+	response, err := ssoLogin(t, idp, oidcService, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Expected a setup challenge, got: %v", err)
+	}
+	if !response.TwoFactorSetupRequired || response.ChallengeToken == "" || response.Token != "" {
+		t.Errorf("Expected a 2FA setup challenge instead of tokens, got: %+v", response)
+	}
+}
+
+func TestOIDCLogin_FollowsTheRegistrationPolicy(t *testing.T) {
+	provision := config.OIDCConfig{RoleClaim: "groups", DefaultRole: "client", AutoProvision: true}
+
+	for _, mode := range []struct {
+		mode config.RegistrationMode
+		want error
+	}{
+		{config.RegistrationClosed, ErrRegistrationClosed},
+		{config.RegistrationInvitation, ErrRegistrationInvitation},
+	} {
+		idp := newStubIdP(t)
+		userRepo := NewMockUserRepository()
+		oidcService := newTestOIDCService(idp, userRepo, config.Config{OIDC: provision, Registration: config.RegistrationConfig{Mode: mode.mode}})
+
+		if _, err := ssoLogin(t, idp, oidcService, "new@example.com"); !errors.Is(err, mode.want) {
+			t.Errorf("%s: expected %v, got: %v", mode.mode, mode.want, err)
+		}
+		if len(userRepo.users) != 0 {
+			t.Errorf("%s: expected no account to be provisioned, got %d", mode.mode, len(userRepo.users))
+		}
+	}
+
+	// With approval required, new and newly verified accounts join the queue
+	idp := newStubIdP(t)
+	userRepo := NewMockUserRepository()
+	oidcService := newTestOIDCService(idp, userRepo, config.Config{OIDC: provision, Registration: config.RegistrationConfig{RequireApproval: true}})
+	userRepo.Create(&models.User{UserID: "USER01", Email: "unverified@example.com", Role: models.RoleClient, Status: models.UserStatusUnverified})
+
+	for _, email := range []string{"new@example.com", "unverified@example.com"} {
+		if _, err := ssoLogin(t, idp, oidcService, email); !errors.Is(err, ErrAccountPendingApproval) {
+			t.Errorf("%s: expected ErrAccountPendingApproval, got: %v", email, err)
+		}
+		if user, err := userRepo.FindByEmail(email); err != nil || user.Status != models.UserStatusPendingApproval {
+			t.Errorf("%s: expected the account to wait for approval, got: %+v (%v)", email, user, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PasswordService interface {
+	ForgotPassword(req *models.ForgotPasswordRequest) error
+	// ResetPassword sets the password of the token's owner. actor only
+	// carries the request details; the owner becomes the actor of the
+	// recorded event.
+	ResetPassword(req *models.ResetPasswordRequest, actor models.Actor) error
+}
+
+var errInvalidResetToken = apperrors.Validation("INVALID_RESET_TOKEN", "invalid or expired reset token")
+
+type passwordService struct {
+	userRepo    repositories.UserRepository
+	tokenRepo   repositories.UserTokenRepository
+	sessionRepo repositories.SessionRepository
+	mailer      mailer.Mailer
+	cfg         *config.Config
+	audit       AuditService
+}
+
+func NewPasswordService(userRepo repositories.UserRepository, tokenRepo repositories.UserTokenRepository, sessionRepo repositories.SessionRepository, mailer mailer.Mailer, cfg *config.Config, audit AuditService) PasswordService {
+	return &passwordService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mailer,
+		cfg:         cfg,
+		audit:       audit,
+	}
+}
+
+// ForgotPassword emails a reset link to the account owner. It never reports
+// whether the email belongs to an account, so callers cannot probe for users.
+func (s *passwordService) ForgotPassword(req *models.ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		return nil
+	}
+
+	// Deactivated accounts have to be re-enabled by an administrator first
+	if user.Status != "" && user.Status != "active" {
+		return nil
+	}
+
+	// Only the most recently requested link stays valid
+	if err := s.tokenRepo.DeleteForUser(user.UserID, models.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	resetToken := &models.UserToken{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    user.UserID,
+		Purpose:   models.TokenPurposePasswordReset,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.Auth.PasswordResetExpiry),
+	}
+
+	if err := s.tokenRepo.Create(resetToken); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.Mail.AppURL, token)
+	msg := mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nWe received a request to reset your password. Use the link below to choose a new one:\n\n%s\n\nThis link expires in %s. If you did not request a reset, you can ignore this email.\n",
+			user.Name, link, s.cfg.Auth.PasswordResetExpiry,
+		),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+	}
+
+	return nil
+}
+
+func (s *passwordService) ResetPassword(req *models.ResetPasswordRequest, actor models.Actor) error {
+	token, err := s.tokenRepo.FindByHash(models.TokenPurposePasswordReset, utils.HashToken(req.Token))
+	if err != nil {
+		return errInvalidResetToken
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return errInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return errInvalidResetToken
+	}
+
+	if err := s.tokenRepo.MarkUsed(token.ID); err != nil {
+		return errInvalidResetToken
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	before := snapshot(user)
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	actor.ID, actor.Role = user.UserID, string(user.Role)
+	s.audit.Record(actor, models.AuditActionResetPassword, auditUser, user.UserID, before, snapshot(user))
+
+	if err := s.tokenRepo.DeleteForUser(user.UserID, models.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password must not stay signed in
+	return s.sessionRepo.RevokeAllForUser(user.UserID)
+}
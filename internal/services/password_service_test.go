@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// MockUserTokenRepository for testing
+type MockUserTokenRepository struct {
+	tokens map[string]*models.UserToken
+}
+
+func NewMockUserTokenRepository() *MockUserTokenRepository {
+	return &MockUserTokenRepository{
+		tokens: make(map[string]*models.UserToken),
+	}
+}
+
+func (m *MockUserTokenRepository) Create(token *models.UserToken) error {
+	token.CreatedAt = time.Now()
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *MockUserTokenRepository) FindByHash(purpose models.TokenPurpose, hash string) (*models.UserToken, error) {
+	for _, token := range m.tokens {
+		if token.Purpose == purpose && token.TokenHash == hash {
+			return token, nil
+		}
+	}
+	return nil, errors.New("token not found")
+}
+
+func (m *MockUserTokenRepository) MarkUsed(id string) error {
+	token, exists := m.tokens[id]
+	if !exists || token.UsedAt != nil {
+		return errors.New("token already used")
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return nil
+}
+
+func (m *MockUserTokenRepository) DeleteForUser(userID string, purpose models.TokenPurpose) error {
+	for id, token := range m.tokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(m.tokens, id)
+		}
+	}
+	return nil
+}
+
+func newTestPasswordService() (PasswordService, *MockUserRepository, *MockSessionRepository, *mailer.MemoryMailer) {
+	userRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	mail := mailer.NewMemoryMailer()
+	cfg := &config.Config{
+		Auth: config.AuthConfig{PasswordResetExpiry: time.Hour},
+		Mail: config.MailConfig{AppURL: "http://localhost:3000"},
+	}
+
+	hashedPassword, _ := utils.HashPassword("old-password")
+	userRepo.Create(&models.User{
+		UserID:   "USER01",
+		Email:    "forgot@example.com",
+		Password: hashedPassword,
+		Name:     "Forgetful User",
+		Role:     models.RoleClient,
+		Status:   "active",
+	})
+
+	return NewPasswordService(userRepo, NewMockUserTokenRepository(), sessionRepo, mail, cfg, newTestAuditService()), userRepo, sessionRepo, mail
+}
+
+func resetTokenFromMail(t *testing.T, msg mailer.Message) string {
+	idx := strings.Index(msg.Body, "token=")
+	if idx < 0 {
+		t.Fatalf("Expected reset link in email body, got: %s", msg.Body)
+	}
+	return strings.Fields(msg.Body[idx+len("token="):])[0]
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	passwordService, userRepo, sessionRepo, mail := newTestPasswordService()
+	sessionRepo.Create(&models.Session{ID: "SESSION01", UserID: "USER01", ExpiresAt: time.Now().Add(time.Hour)})
+
+	if err := passwordService.ForgotPassword(&models.ForgotPasswordRequest{Email: "forgot@example.com"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	messages := mail.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(messages))
+	}
+	token := resetTokenFromMail(t, messages[0])
+
+	if err := passwordService.ResetPassword(&models.ResetPasswordRequest{Token: token, Password: "new-password"}, models.Actor{}); err != nil {
+		t.Fatalf("Expected no error on reset, got: %v", err)
+	}
+
+	user, _ := userRepo.FindByID("USER01")
+	if !utils.CheckPassword("new-password", user.Password) {
+		t.Error("Expected password to be updated")
+	}
+
+	if session, _ := sessionRepo.FindByID("SESSION01"); session.RevokedAt == nil {
+		t.Error("Expected existing sessions to be revoked after reset")
+	}
+
+	// Tokens are single-use
+	if err := passwordService.ResetPassword(&models.ResetPasswordRequest{Token: token, Password: "another-password"}, models.Actor{}); err == nil {
+		t.Error("Expected error when reusing a reset token")
+	}
+}
+
+func TestForgotPassword_UnknownEmail(t *testing.T) {
+	passwordService, _, _, mail := newTestPasswordService()
+
+	if err := passwordService.ForgotPassword(&models.ForgotPasswordRequest{Email: "nobody@example.com"}); err != nil {
+		t.Errorf("Expected no error for unknown email, got: %v", err)
+	}
+
+	if len(mail.Messages()) != 0 {
+		t.Error("Expected no email for unknown address")
+	}
+}
+
+func TestForgotPassword_OnlyLatestTokenValid(t *testing.T) {
+	passwordService, _, _, mail := newTestPasswordService()
+
+	passwordService.ForgotPassword(&models.ForgotPasswordRequest{Email: "forgot@example.com"})
+	passwordService.ForgotPassword(&models.ForgotPasswordRequest{Email: "forgot@example.com"})
+
+	messages := mail.Messages()
+	first := resetTokenFromMail(t, messages[0])
+	second := resetTokenFromMail(t, messages[1])
+
+	if err := passwordService.ResetPassword(&models.ResetPasswordRequest{Token: first, Password: "new-password"}, models.Actor{}); err == nil {
+		t.Error("Expected superseded reset token to be rejected")
+	}
+
+	if err := passwordService.ResetPassword(&models.ResetPasswordRequest{Token: second, Password: "new-password"}, models.Actor{}); err != nil {
+		t.Errorf("Expected latest reset token to work, got: %v", err)
+	}
+}
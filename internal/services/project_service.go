@@ -1,56 +1,64 @@
 package services
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/policy"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 )
 
 type ProjectService interface {
-	Create(req *models.CreateProjectRequest) (*models.Project, error)
+	Create(req *models.CreateProjectRequest, actor models.Actor) (*models.Project, error)
 	GetByID(id string, userID string, userRole string) (*models.Project, error)
-	Update(id string, req *models.UpdateProjectRequest, userID string, userRole string) (*models.Project, error)
-	Delete(id string) error
-	List(query *models.PaginationQuery, clientID *string, userID string, userRole string) ([]models.Project, int64, error)
-	AssignEmployees(projectID string, req *models.AssignEmployeesRequest, userID string, userRole string) error
-	UpdateProjectProgress(projectID string, req *models.UpdateProjectProgressRequest, userID string, userRole string) (*models.Project, error)
+	// Update and UpdateProjectProgress apply req when the project is still at
+	// a version match allows
+	Update(id string, req *models.UpdateProjectRequest, match VersionMatch, actor models.Actor) (*models.Project, error)
+	Delete(id string, actor models.Actor) error
+	Restore(id string, actor models.Actor) (*models.Project, error)
+	List(query *models.PaginationQuery, userID string, userRole string) ([]models.Project, int64, error)
+	AssignEmployees(projectID string, req *models.AssignEmployeesRequest, actor models.Actor) error
+	UpdateProjectProgress(projectID string, req *models.UpdateProjectProgressRequest, match VersionMatch, actor models.Actor) (*models.Project, error)
 }
 
 type projectService struct {
 	projectRepo repositories.ProjectRepository
-	counterRepo repositories.CounterRepository
+	ids         IDGenerator
+	policy      *policy.Engine
+	integrity   IntegrityService
+	audit       AuditService
 }
 
-func NewProjectService(projectRepo repositories.ProjectRepository, counterRepo repositories.CounterRepository) ProjectService {
+func NewProjectService(projectRepo repositories.ProjectRepository, ids IDGenerator, policyEngine *policy.Engine, integrity IntegrityService, audit AuditService) ProjectService {
 	return &projectService{
 		projectRepo: projectRepo,
-		counterRepo: counterRepo,
+		ids:         ids,
+		policy:      policyEngine,
+		integrity:   integrity,
+		audit:       audit,
 	}
 }
 
-func (s *projectService) Create(req *models.CreateProjectRequest) (*models.Project, error) {
+func (s *projectService) Create(req *models.CreateProjectRequest, actor models.Actor) (*models.Project, error) {
 	// Validate request
 	if req.Name == "" {
-		return nil, fmt.Errorf("project name is required")
+		return nil, apperrors.Validation("PROJECT_NAME_REQUIRED", "project name is required")
 	}
 	if req.ClientID == "" {
-		return nil, fmt.Errorf("client ID is required")
+		return nil, apperrors.Validation("CLIENT_ID_REQUIRED", "client ID is required")
 	}
-
-	// Generate next project ID sequence
-	sequence, err := s.counterRepo.GetNextSequence("project_counter")
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate project ID: %w", err)
+	if err := s.integrity.CheckClient(req.ClientID); err != nil {
+		return nil, err
+	}
+	if err := s.integrity.CheckEmployees(req.EmployeeIDs); err != nil {
+		return nil, err
 	}
 
-	projectID := fmt.Sprintf("PROJECT%02d", sequence)
-	
-	// Debug: Ensure projectID is not empty
-	if projectID == "" {
-		return nil, fmt.Errorf("generated project ID is empty")
+	projectID, err := s.ids.NextProjectID()
+	if err != nil {
+		return nil, err
 	}
 
 	project := &models.Project{
@@ -67,9 +75,6 @@ func (s *projectService) Create(req *models.CreateProjectRequest) (*models.Proje
 		project.Status = req.Status
 	}
 
-	// Debug: Log the project before creation
-	fmt.Printf("Creating project with ID: %s\n", project.ID)
-
 	if err := s.projectRepo.Create(project); err != nil {
 		return nil, fmt.Errorf("failed to create project: %w", err)
 	}
@@ -79,74 +84,44 @@ func (s *projectService) Create(req *models.CreateProjectRequest) (*models.Proje
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve created project: %w", err)
 	}
-	
-	// Debug: Log the retrieved project
-	fmt.Printf("Retrieved project with ID: %s\n", createdProject.ID)
 
+	s.audit.Record(actor, models.AuditActionCreate, auditProject, createdProject.ID, nil, snapshot(createdProject))
 	return createdProject, nil
 }
 
 func (s *projectService) GetByID(id string, userID string, userRole string) (*models.Project, error) {
 	project, err := s.projectRepo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, lookupError(err, ErrProjectNotFound)
 	}
 
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only view projects they're assigned to
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return nil, errors.New("access denied: employee not assigned to this project")
-		}
-	} else if userRole == "client" {
-		// Clients can only view their own projects
-		if project.ClientID != userID {
-			return nil, errors.New("access denied: client can only view their own projects")
-		}
+	if err := s.policy.Authorize(policy.Subject{ID: userID, Role: userRole}, policy.ProjectRead, policy.ProjectResource(project)); err != nil {
+		return nil, err
 	}
 
 	return project, nil
 }
 
-func (s *projectService) Update(id string, req *models.UpdateProjectRequest, userID string, userRole string) (*models.Project, error) {
+func (s *projectService) Update(id string, req *models.UpdateProjectRequest, match VersionMatch, actor models.Actor) (*models.Project, error) {
 	project, err := s.projectRepo.FindByID(id)
 	if err != nil {
-		return nil, errors.New("project not found")
-	}
-
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only update projects they're assigned to
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return nil, errors.New("access denied: employee not assigned to this project")
-		}
-		// Employees can only update status, not other fields
-		if req.Name != "" || req.Description != "" {
-			return nil, errors.New("access denied: employees can only update project status")
-		}
-	} else if userRole == "client" {
-		// Clients can only update their own projects
-		if project.ClientID != userID {
-			return nil, errors.New("access denied: client can only update their own projects")
-		}
-		// Clients can only update description, not status or assignment
-		if req.Status != "" {
-			return nil, errors.New("access denied: clients cannot update project status")
-		}
+		return nil, lookupError(err, ErrProjectNotFound)
+	}
+	before := snapshot(project)
+
+	// Renaming or describing a project needs the full update permission; some
+	// roles may only move a project through its statuses
+	subject := policy.Subject{ID: actor.ID, Role: actor.Role}
+	resource := policy.ProjectResource(project)
+	action := policy.ProjectUpdate
+	if req.Name == "" && req.Description == "" && s.policy.Can(subject, policy.ProjectUpdateStatus, resource) {
+		action = policy.ProjectUpdateStatus
+	}
+	if err := s.policy.Authorize(subject, action, resource); err != nil {
+		return nil, err
+	}
+	if !match.Matches(project.Version) {
+		return nil, ErrVersionMismatch
 	}
 
 	if req.Name != "" {
@@ -160,98 +135,129 @@ func (s *projectService) Update(id string, req *models.UpdateProjectRequest, use
 	}
 
 	if err := s.projectRepo.Update(project); err != nil {
-		return nil, err
+		return nil, updateError(err)
 	}
 
+	s.audit.Record(actor, models.AuditActionUpdate, auditProject, project.ID, before, snapshot(project))
 	return project, nil
 }
 
-func (s *projectService) Delete(id string) error {
-	_, err := s.projectRepo.FindByID(id)
+func (s *projectService) Delete(id string, actor models.Actor) error {
+	project, err := s.projectRepo.FindByID(id)
 	if err != nil {
-		return errors.New("project not found")
+		return lookupError(err, ErrProjectNotFound)
+	}
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ProjectDelete, policy.ProjectResource(project)); err != nil {
+		return err
+	}
+
+	if err := s.integrity.ReleaseProject(project, actor); err != nil {
+		return err
+	}
+	if err := s.projectRepo.Delete(id, actor.ID); err != nil {
+		return err
 	}
 
-	return s.projectRepo.Delete(id)
+	s.audit.Record(actor, models.AuditActionDelete, auditProject, id, snapshot(project), nil)
+	return nil
 }
 
-func (s *projectService) List(query *models.PaginationQuery, clientID *string, userID string, userRole string) ([]models.Project, int64, error) {
-	// Apply role-based filtering
-	if userRole == "employee" {
-		// Employees can only see projects they're assigned to
-		return s.projectRepo.ListByEmployee(query.Page, query.PageSize, query.Search, string(query.Status), userID)
-	} else if userRole == "client" {
-		// Clients can only see their own projects
-		return s.projectRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), &userID)
-	}
-	
-	// Admins can see all projects
-	return s.projectRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), clientID)
+func (s *projectService) Restore(id string, actor models.Actor) (*models.Project, error) {
+	deleted, err := s.projectRepo.FindDeleted(id)
+	if err != nil {
+		return nil, lookupError(err, ErrProjectNotFound)
+	}
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ProjectRestore, policy.ProjectResource(deleted)); err != nil {
+		return nil, err
+	}
+	if err := s.integrity.CheckRestorable(deleted.ClientID, ""); err != nil {
+		return nil, err
+	}
+
+	tombstone, err := s.projectRepo.Restore(id)
+	if err != nil {
+		return nil, lookupError(err, ErrProjectNotFound)
+	}
+
+	project, err := s.projectRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionRestore, auditProject, id, snapshot(tombstone), snapshot(project))
+	return project, nil
 }
 
-func (s *projectService) AssignEmployees(projectID string, req *models.AssignEmployeesRequest, userID string, userRole string) error {
-	// Only admins can assign employees
-	if userRole != "admin" {
-		return errors.New("access denied: only admins can assign employees to projects")
+func (s *projectService) List(query *models.PaginationQuery, userID string, userRole string) ([]models.Project, int64, error) {
+	// Narrow the listing to the projects the role may see
+	subject := policy.Subject{ID: userID, Role: userRole}
+	scope := s.policy.Scope(subject, policy.ProjectList)
+	switch {
+	case scope.All:
+		return s.projectRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), nil, query.IncludeDeleted)
+	case scope.Allows(policy.Member):
+		return s.projectRepo.ListByEmployee(query.Page, query.PageSize, query.Search, string(query.Status), userID, query.IncludeDeleted)
+	case scope.Allows(policy.Client):
+		return s.projectRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), &userID, query.IncludeDeleted)
 	}
 
+	return nil, 0, policy.Denied(subject, policy.ProjectList)
+}
+
+func (s *projectService) AssignEmployees(projectID string, req *models.AssignEmployeesRequest, actor models.Actor) error {
 	// Get the current project to check existing assignments
-	_, err := s.projectRepo.FindByID(projectID)
+	project, err := s.projectRepo.FindByID(projectID)
 	if err != nil {
-		return errors.New("project not found")
+		return lookupError(err, ErrProjectNotFound)
+	}
+
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ProjectAssign, policy.ProjectResource(project)); err != nil {
+		return err
 	}
 
 	// Validate that all employee IDs exist and are employees
-	for _, empID := range req.EmployeeIDs {
-		// Additional validation can be added here to verify each employee exists
-		// and has the "employee" role
-		if empID == "" {
-			return errors.New("invalid employee ID provided")
-		}
+	if err := s.integrity.CheckEmployees(req.EmployeeIDs); err != nil {
+		return err
+	}
+
+	if err := s.projectRepo.AssignEmployees(projectID, req.EmployeeIDs); err != nil {
+		return err
 	}
 
-	return s.projectRepo.AssignEmployees(projectID, req.EmployeeIDs)
+	before := snapshot(project)
+	project.EmployeeIDs = req.EmployeeIDs
+	s.audit.Record(actor, models.AuditActionAssign, auditProject, projectID, before, snapshot(project))
+	return nil
 }
 
-func (s *projectService) UpdateProjectProgress(projectID string, req *models.UpdateProjectProgressRequest, userID string, userRole string) (*models.Project, error) {
+func (s *projectService) UpdateProjectProgress(projectID string, req *models.UpdateProjectProgressRequest, match VersionMatch, actor models.Actor) (*models.Project, error) {
 	// Get the project to check access
 	project, err := s.projectRepo.FindByID(projectID)
 	if err != nil {
-		return nil, errors.New("project not found")
-	}
-
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only update projects they're assigned to
-		isAssigned := false
-		for _, empID := range project.EmployeeIDs {
-			if empID == userID {
-				isAssigned = true
-				break
-			}
-		}
-		if !isAssigned {
-			return nil, errors.New("access denied: employee not assigned to this project")
-		}
-	} else if userRole == "client" {
-		// Clients can only update their own projects
-		if project.ClientID != userID {
-			return nil, errors.New("access denied: client can only update their own projects")
-		}
+		return nil, lookupError(err, ErrProjectNotFound)
+	}
+
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ProjectProgress, policy.ProjectResource(project)); err != nil {
+		return nil, err
 	}
 
 	// Validate progress value
 	if req.Progress < 0 || req.Progress > 100 {
-		return nil, errors.New("progress must be between 0 and 100")
+		return nil, apperrors.Validation("INVALID_PROGRESS", "progress must be between 0 and 100")
+	}
+	if !match.Matches(project.Version) {
+		return nil, ErrVersionMismatch
 	}
 
 	// Update project progress
+	before := snapshot(project)
 	project.Progress = req.Progress
 	project.UpdatedAt = time.Now()
 
 	if err := s.projectRepo.Update(project); err != nil {
-		return nil, err
+		return nil, updateError(err)
 	}
 
+	s.audit.Record(actor, models.AuditActionUpdate, auditProject, project.ID, before, snapshot(project))
 	return project, nil
 }
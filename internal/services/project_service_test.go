@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MockProjectRepository for testing
+type MockProjectRepository struct {
+	projects map[string]*models.Project
+}
+
+func NewMockProjectRepository() *MockProjectRepository {
+	return &MockProjectRepository{
+		projects: make(map[string]*models.Project),
+	}
+}
+
+func (m *MockProjectRepository) Create(project *models.Project) error {
+	m.projects[project.ID] = project
+	return nil
+}
+
+func (m *MockProjectRepository) Import(project *models.Project) error {
+	return m.Create(project)
+}
+
+func (m *MockProjectRepository) FindByID(id string) (*models.Project, error) {
+	project, exists := m.projects[id]
+	if !exists || project.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return project, nil
+}
+
+func (m *MockProjectRepository) Update(project *models.Project) error {
+	project.Version++
+	m.projects[project.ID] = project
+	return nil
+}
+
+func (m *MockProjectRepository) Delete(id, deletedBy string) error {
+	project, exists := m.projects[id]
+	if !exists || project.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	project.DeletedAt = &now
+	project.DeletedBy = deletedBy
+	return nil
+}
+
+func (m *MockProjectRepository) FindDeleted(id string) (*models.Project, error) {
+	project, exists := m.projects[id]
+	if !exists || project.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	tombstone := *project
+	return &tombstone, nil
+}
+
+func (m *MockProjectRepository) Restore(id string) (*models.Project, error) {
+	project, exists := m.projects[id]
+	if !exists || project.DeletedAt == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	tombstone := *project
+	project.DeletedAt = nil
+	project.DeletedBy = ""
+	return &tombstone, nil
+}
+
+func (m *MockProjectRepository) PurgeDeleted(before time.Time) (int64, error) {
+	var removed int64
+	for id, project := range m.projects {
+		if project.DeletedAt != nil && project.DeletedAt.Before(before) {
+			delete(m.projects, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (m *MockProjectRepository) List(page, pageSize int, search string, status string, clientID *string, includeDeleted bool) ([]models.Project, int64, error) {
+	var projects []models.Project
+	for _, project := range m.projects {
+		if project.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+		if clientID == nil || project.ClientID == *clientID {
+			projects = append(projects, *project)
+		}
+	}
+	return projects, int64(len(projects)), nil
+}
+
+func (m *MockProjectRepository) ListByEmployee(page, pageSize int, search string, status string, employeeID string, includeDeleted bool) ([]models.Project, int64, error) {
+	var projects []models.Project
+	for _, project := range m.projects {
+		if project.DeletedAt != nil && !includeDeleted {
+			continue
+		}
+		for _, id := range project.EmployeeIDs {
+			if id == employeeID {
+				projects = append(projects, *project)
+				break
+			}
+		}
+	}
+	return projects, int64(len(projects)), nil
+}
+
+func (m *MockProjectRepository) FindByClient(clientID string) ([]models.Project, error) {
+	projects, _, err := m.List(1, 0, "", "", &clientID, false)
+	return projects, err
+}
+
+func (m *MockProjectRepository) FindByEmployee(employeeID string) ([]models.Project, error) {
+	projects, _, err := m.ListByEmployee(1, 0, "", "", employeeID, false)
+	return projects, err
+}
+
+func (m *MockProjectRepository) AssignEmployees(projectID string, employeeIDs []string) error {
+	project, exists := m.projects[projectID]
+	if !exists {
+		return errors.New("project not found")
+	}
+	project.EmployeeIDs = employeeIDs
+	return nil
+}
+
+// MockCounterRepository for testing
+type MockCounterRepository struct {
+	counters map[string]int
+}
+
+func NewMockCounterRepository() *MockCounterRepository {
+	return &MockCounterRepository{
+		counters: make(map[string]int),
+	}
+}
+
+func (m *MockCounterRepository) GetNextSequence(counterName string) (int, error) {
+	m.counters[counterName]++
+	return m.counters[counterName], nil
+}
+
+func (m *MockCounterRepository) Raise(counterName string, value int) error {
+	if m.counters[counterName] < value {
+		m.counters[counterName] = value
+	}
+	return nil
+}
+
+func TestProjectService_AppliesPolicy(t *testing.T) {
+	// Setup
+	projectRepo := NewMockProjectRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), newTestIntegrityService(NewMockUserRepository(), projectRepo), newTestAuditService())
+
+	projectRepo.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01", EmployeeIDs: []string{"EMP01"}})
+	projectRepo.Create(&models.Project{ID: "PROJECT02", Name: "Billing", ClientID: "CLIENT02", EmployeeIDs: []string{"EMP02"}})
+
+	if _, err := projectService.GetByID("PROJECT01", "EMP01", "employee"); err != nil {
+		t.Errorf("Expected assigned employee to read the project, got: %v", err)
+	}
+
+	if _, err := projectService.GetByID("PROJECT02", "EMP01", "employee"); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied for an unassigned employee, got: %v", err)
+	}
+
+	// Employees may move the status but not rename the project
+	if _, err := projectService.Update("PROJECT01", &models.UpdateProjectRequest{Status: models.StatusInProgress}, MatchVersion(0), models.Actor{ID: "EMP01", Role: "employee"}); err != nil {
+		t.Errorf("Expected employee to update the status, got: %v", err)
+	}
+	if _, err := projectService.Update("PROJECT01", &models.UpdateProjectRequest{Name: "Renamed"}, MatchVersion(1), models.Actor{ID: "EMP01", Role: "employee"}); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied renaming the project, got: %v", err)
+	}
+
+	if err := projectService.AssignEmployees("PROJECT01", &models.AssignEmployeesRequest{EmployeeIDs: []string{"EMP02"}}, models.Actor{ID: "CLIENT01", Role: "client"}); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied assigning as a client, got: %v", err)
+	}
+
+	projects, total, err := projectService.List(&models.PaginationQuery{Page: 1, PageSize: 10}, "CLIENT02", "client")
+	if err != nil || total != 1 || projects[0].ID != "PROJECT02" {
+		t.Errorf("Expected clients to list only their own project, got %d projects: %v", total, err)
+	}
+
+	if _, _, err := projectService.List(&models.PaginationQuery{Page: 1, PageSize: 10}, "X01", "unknown"); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied for a role without rules, got: %v", err)
+	}
+}
+
+func TestProjectService_ReturnsTypedErrors(t *testing.T) {
+	projectRepo := NewMockProjectRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), newTestIntegrityService(NewMockUserRepository(), projectRepo), newTestAuditService())
+
+	projectRepo.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01"})
+
+	if _, err := projectService.GetByID("PROJECT99", "ADMIN01", "admin"); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound for a missing project, got: %v", err)
+	}
+
+	if _, err := projectService.Create(&models.CreateProjectRequest{ClientID: "CLIENT01"}, models.Actor{ID: "ADMIN01", Role: "admin"}); !errors.Is(err, apperrors.KindValidation) {
+		t.Errorf("Expected a validation error without a name, got: %v", err)
+	}
+
+	if _, err := projectService.UpdateProjectProgress("PROJECT01", &models.UpdateProjectProgressRequest{Progress: 150}, MatchVersion(0), models.Actor{ID: "ADMIN01", Role: "admin"}); !errors.Is(err, apperrors.KindValidation) {
+		t.Errorf("Expected a validation error for progress above 100, got: %v", err)
+	}
+
+	_, err := projectService.GetByID("PROJECT01", "CLIENT02", "client")
+	if !errors.Is(err, apperrors.KindForbidden) || apperrors.From(err).Code != "ACCESS_DENIED" {
+		t.Errorf("Expected a forbidden ACCESS_DENIED error for another client, got: %v", err)
+	}
+}
+
+func TestProjectService_SoftDeleteAndRestore(t *testing.T) {
+	// Setup
+	projectRepo := NewMockProjectRepository()
+	userRepo := NewMockUserRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), newTestIntegrityService(userRepo, projectRepo), newTestAuditService())
+	admin := models.Actor{ID: "ADMIN01", Role: "admin"}
+
+	userRepo.Create(&models.User{UserID: "CLIENT01", Role: models.RoleClient})
+	projectRepo.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01"})
+
+	if err := projectService.Delete("PROJECT01", admin); err != nil {
+		t.Fatalf("Expected no error deleting the project, got: %v", err)
+	}
+	if _, err := projectService.GetByID("PROJECT01", "ADMIN01", "admin"); err != ErrProjectNotFound {
+		t.Errorf("Expected a deleted project to be hidden, got: %v", err)
+	}
+	if err := projectService.Delete("PROJECT01", admin); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound deleting twice, got: %v", err)
+	}
+
+	// Admins can still list the tombstone
+	if _, total, _ := projectService.List(&models.PaginationQuery{Page: 1, PageSize: 10}, "ADMIN01", "admin"); total != 0 {
+		t.Errorf("Expected deleted projects to be left out by default, got %d", total)
+	}
+	projects, total, _ := projectService.List(&models.PaginationQuery{Page: 1, PageSize: 10, IncludeDeleted: true}, "ADMIN01", "admin")
+	if total != 1 || projects[0].DeletedBy != "ADMIN01" {
+		t.Errorf("Expected the tombstone with include_deleted, got: %+v", projects)
+	}
+
+	project, err := projectService.Restore("PROJECT01", admin)
+	if err != nil || project.DeletedAt != nil {
+		t.Fatalf("Expected the project to be restored, got %+v: %v", project, err)
+	}
+	if _, err := projectService.Restore("PROJECT01", admin); err != ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound restoring a live project, got: %v", err)
+	}
+}
+
+func TestProjectService_DeleteAndRestoreCheckTheProject(t *testing.T) {
+	// A role that may only delete and restore the projects it works on
+	engine, err := policy.New(append(policy.DefaultRoles(), policy.RoleDefinition{
+		Name:  "lead",
+		Rules: []policy.Rule{{Actions: []policy.Action{policy.ProjectDelete, policy.ProjectRestore}, When: []policy.Condition{policy.Member}}},
+	}))
+	if err != nil {
+		t.Fatalf("Failed to build policy: %v", err)
+	}
+	projectRepo := NewMockProjectRepository()
+	userRepo := NewMockUserRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), engine, newTestIntegrityService(userRepo, projectRepo), newTestAuditService())
+	lead := models.Actor{ID: "EMP01", Role: "lead"}
+
+	userRepo.Create(&models.User{UserID: "CLIENT01", Role: models.RoleClient})
+
+	projectRepo.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01", EmployeeIDs: []string{"EMP01"}})
+	projectRepo.Create(&models.Project{ID: "PROJECT02", Name: "Billing", ClientID: "CLIENT02", EmployeeIDs: []string{"EMP02"}})
+
+	if err := projectService.Delete("PROJECT02", lead); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied deleting another team's project, got: %v", err)
+	}
+	if err := projectService.Delete("PROJECT01", lead); err != nil {
+		t.Fatalf("Expected the lead to delete their project, got: %v", err)
+	}
+
+	projectRepo.Delete("PROJECT02", "ADMIN01")
+	if _, err := projectService.Restore("PROJECT02", lead); !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied restoring another team's project, got: %v", err)
+	}
+	if _, err := projectRepo.FindDeleted("PROJECT02"); err != nil {
+		t.Errorf("Expected the denied restore to leave the project deleted, got: %v", err)
+	}
+	if _, err := projectService.Restore("PROJECT01", lead); err != nil {
+		t.Errorf("Expected the lead to restore their project, got: %v", err)
+	}
+}
+
+func TestProjectService_UpdateRequiresCurrentVersion(t *testing.T) {
+	// Setup
+	projectRepo := NewMockProjectRepository()
+	projectService := NewProjectService(projectRepo, newTestIDGenerator(NewMockCounterRepository()), policy.Default(), newTestIntegrityService(NewMockUserRepository(), projectRepo), newTestAuditService())
+	admin := models.Actor{ID: "ADMIN01", Role: "admin"}
+
+	projectRepo.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01", Version: 1})
+
+	project, err := projectService.Update("PROJECT01", &models.UpdateProjectRequest{Status: models.StatusInProgress}, MatchVersion(1), admin)
+	if err != nil || project.Version != 2 {
+		t.Fatalf("Expected the update to bump the version to 2, got %+v: %v", project, err)
+	}
+
+	// A second writer still holding version 1 must not overwrite the change
+	_, err = projectService.UpdateProjectProgress("PROJECT01", &models.UpdateProjectProgressRequest{Progress: 50}, MatchVersion(1), admin)
+	if err != ErrVersionMismatch || !errors.Is(err, apperrors.KindPreconditionFailed) {
+		t.Errorf("Expected ErrVersionMismatch for a stale version, got: %v", err)
+	}
+	if project, _ := projectRepo.FindByID("PROJECT01"); project.Progress != 0 || project.Status != models.StatusInProgress {
+		t.Errorf("Expected the stale write to be dropped, got: %+v", project)
+	}
+}
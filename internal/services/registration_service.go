@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+var (
+	ErrRegistrationClosed           = apperrors.Forbidden("REGISTRATION_CLOSED", "registration is closed")
+	ErrRegistrationInvitation       = apperrors.Forbidden("INVITATION_REQUIRED", "registration is by invitation only")
+	ErrRegistrationRoleNotAllowed   = apperrors.Forbidden("REGISTRATION_ROLE_NOT_ALLOWED", "this role cannot be registered; ask an administrator for an invitation")
+	ErrRegistrationDomainNotAllowed = apperrors.Forbidden("REGISTRATION_DOMAIN_NOT_ALLOWED", "this email domain may not register with this role")
+	ErrAccountPendingApproval       = apperrors.Forbidden("ACCOUNT_PENDING_APPROVAL", "account is waiting for an administrator to approve it")
+	ErrRegistrationNotPending       = apperrors.Conflict("REGISTRATION_NOT_PENDING", "the account is not waiting for approval")
+)
+
+// checkRegistration applies the registration policy to req, whose role is
+// already defaulted.
+func checkRegistration(cfg config.RegistrationConfig, req *models.RegisterRequest) error {
+	// No mode lets anyone make themselves an admin
+	if req.Role == models.RoleAdmin {
+		return ErrAdminRegistration
+	}
+
+	switch cfg.Mode {
+	case config.RegistrationClosed:
+		return ErrRegistrationClosed
+	case config.RegistrationInvitation:
+		return ErrRegistrationInvitation
+	case config.RegistrationAllowlist:
+		domains, ok := cfg.AllowedDomains[string(req.Role)]
+		if !ok {
+			return ErrRegistrationRoleNotAllowed
+		}
+		_, domain, _ := strings.Cut(strings.ToLower(req.Email), "@")
+		for _, allowed := range domains {
+			if allowed == "*" || allowed == domain {
+				return nil
+			}
+		}
+		return ErrRegistrationDomainNotAllowed
+	default:
+		// Client-only, also when no mode is configured
+		if req.Role != models.RoleClient {
+			return ErrRegistrationRoleNotAllowed
+		}
+		return nil
+	}
+}
+
+// verifiedStatus is the status an account takes once its email is verified:
+// active, or waiting for approval when REGISTRATION_REQUIRE_APPROVAL is set.
+func verifiedStatus(cfg config.RegistrationConfig) string {
+	if cfg.RequireApproval {
+		return models.UserStatusPendingApproval
+	}
+	return models.UserStatusActive
+}
+
+// RegistrationService is the queue of registrations waiting for an admin,
+// when REGISTRATION_REQUIRE_APPROVAL holds verified accounts back.
+type RegistrationService interface {
+	// ListPending returns the accounts waiting for approval, oldest first
+	ListPending(query *models.RegistrationQuery) ([]models.User, int64, error)
+	// Approve activates a pending account and tells its owner
+	Approve(id string, actor models.Actor) (*models.User, error)
+	// Reject deletes a pending account and tells its owner. Like any deleted
+	// user it can be restored, back into the queue.
+	Reject(id string, actor models.Actor) error
+}
+
+type registrationService struct {
+	userRepo repositories.UserRepository
+	mailer   mailer.Mailer
+	cfg      *config.Config
+	audit    AuditService
+}
+
+func NewRegistrationService(userRepo repositories.UserRepository, mailer mailer.Mailer, cfg *config.Config, audit AuditService) RegistrationService {
+	return &registrationService{
+		userRepo: userRepo,
+		mailer:   mailer,
+		cfg:      cfg,
+		audit:    audit,
+	}
+}
+
+func (s *registrationService) ListPending(query *models.RegistrationQuery) ([]models.User, int64, error) {
+	return s.userRepo.ListByStatus(models.UserStatusPendingApproval, query.Page, query.PageSize)
+}
+
+func (s *registrationService) Approve(id string, actor models.Actor) (*models.User, error) {
+	user, err := s.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(user)
+
+	user.Status = models.UserStatusActive
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, updateError(err)
+	}
+	s.audit.Record(actor, models.AuditActionApprove, auditUser, user.UserID, before, snapshot(user))
+
+	s.notify(user, "Your account has been approved",
+		fmt.Sprintf("Hello %s,\n\nYour account has been approved. You can now sign in at %s.\n", user.Name, s.cfg.Mail.AppURL))
+	return user, nil
+}
+
+func (s *registrationService) Reject(id string, actor models.Actor) error {
+	user, err := s.pending(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(user.UserID, actor.ID); err != nil {
+		return lookupError(err, ErrUserNotFound)
+	}
+	s.audit.Record(actor, models.AuditActionReject, auditUser, user.UserID, snapshot(user), nil)
+
+	s.notify(user, "Your registration was not approved",
+		fmt.Sprintf("Hello %s,\n\nYour registration was not approved. Please contact us if you think this is a mistake.\n", user.Name))
+	return nil
+}
+
+// pending returns the user with id if it is waiting for approval.
+func (s *registrationService) pending(id string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, lookupError(err, ErrUserNotFound)
+	}
+	if user.Status != models.UserStatusPendingApproval {
+		return nil, ErrRegistrationNotPending
+	}
+	return user, nil
+}
+
+// notify emails the owner of an account; the decision stands if it fails.
+func (s *registrationService) notify(user *models.User, subject, body string) {
+	err := s.mailer.Send(mailer.Message{To: []string{user.Email}, Subject: subject, Body: body})
+	if err != nil {
+		log.Printf("Failed to notify %s of their registration: %v", user.Email, err)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+func TestCheckRegistration_Modes(t *testing.T) {
+	allowlist := config.RegistrationConfig{
+		Mode: config.RegistrationAllowlist,
+		AllowedDomains: map[string][]string{
+			"employee": {"vinodhini.example"},
+			"client":   {"*"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.RegistrationConfig
+		email string
+		role  models.Role
+		want  error
+	}{
+		{"unset mode allows clients", config.RegistrationConfig{}, "ann@example.com", models.RoleClient, nil},
+		{"unset mode refuses employees", config.RegistrationConfig{}, "ann@example.com", models.RoleEmployee, ErrRegistrationRoleNotAllowed},
+		{"client-only refuses employees", config.RegistrationConfig{Mode: config.RegistrationClientOnly}, "ann@example.com", models.RoleEmployee, ErrRegistrationRoleNotAllowed},
+		{"closed refuses clients", config.RegistrationConfig{Mode: config.RegistrationClosed}, "ann@example.com", models.RoleClient, ErrRegistrationClosed},
+		{"invitation refuses clients", config.RegistrationConfig{Mode: config.RegistrationInvitation}, "ann@example.com", models.RoleClient, ErrRegistrationInvitation},
+		{"allowlist matches the domain", allowlist, "ann@Vinodhini.Example", models.RoleEmployee, nil},
+		{"allowlist refuses other domains", allowlist, "ann@example.com", models.RoleEmployee, ErrRegistrationDomainNotAllowed},
+		{"allowlist refuses subdomains", allowlist, "ann@mail.vinodhini.example", models.RoleEmployee, ErrRegistrationDomainNotAllowed},
+		{"allowlist wildcard", allowlist, "ann@example.com", models.RoleClient, nil},
+		{"allowlist never allows admins", config.RegistrationConfig{Mode: config.RegistrationAllowlist, AllowedDomains: map[string][]string{"admin": {"*"}}}, "ann@example.com", models.RoleAdmin, ErrAdminRegistration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.RegisterRequest{Email: tt.email, Role: tt.role}
+			if err := checkRegistration(tt.cfg, req); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRegister_DefaultsToClient(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", Expiry: 15 * time.Minute, RefreshExpiry: time.Hour}}
+	authService := NewAuthService(NewMockUserRepository(), newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+
+	user, err := authService.Register(&models.RegisterRequest{Email: "ann@example.com", Password: "password123", Name: "Ann"}, models.Actor{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if user.Role != models.RoleClient {
+		t.Errorf("Expected role %s, got %s", models.RoleClient, user.Role)
+	}
+}
+
+func TestRegistration_ApprovalQueue(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	mail := mailer.NewMemoryMailer()
+	cfg := &config.Config{
+		JWT:          config.JWTConfig{Secret: "test-secret", Expiry: 15 * time.Minute, RefreshExpiry: time.Hour},
+		Auth:         config.AuthConfig{EmailVerificationExpiry: time.Hour},
+		Mail:         config.MailConfig{AppURL: "http://localhost:3000"},
+		Registration: config.RegistrationConfig{Mode: config.RegistrationClientOnly, RequireApproval: true},
+	}
+	authService := NewAuthService(userRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mail, testKeys, cfg, newTestAuditService())
+	registrationService := NewRegistrationService(userRepo, mail, cfg, newTestAuditService())
+	admin := models.Actor{ID: "USER01", Role: string(models.RoleAdmin)}
+
+	// register verifies a new client, leaving it waiting for approval
+	register := func(email string) *models.User {
+		t.Helper()
+		req := &models.RegisterRequest{Email: email, Password: "password123", Name: "New Client"}
+		user, err := authService.Register(req, models.Actor{})
+		if err != nil {
+			t.Fatalf("Expected no error registering %s, got: %v", email, err)
+		}
+		body := mail.Messages()[len(mail.Messages())-1].Body
+		token := strings.Fields(body[strings.Index(body, "token=")+len("token="):])[0]
+		if err := authService.VerifyEmail(&models.VerifyEmailRequest{Token: token}); err != nil {
+			t.Fatalf("Expected no error verifying %s, got: %v", email, err)
+		}
+		return user
+	}
+	approved := register("approved@example.com")
+	rejected := register("rejected@example.com")
+
+	if approved.Status != models.UserStatusPendingApproval {
+		t.Fatalf("Expected status %s after verification, got %s", models.UserStatusPendingApproval, approved.Status)
+	}
+	login := &models.LoginRequest{Email: "approved@example.com", Password: "password123"}
+	if _, err := authService.Login(login, models.ClientInfo{}); !errors.Is(err, ErrAccountPendingApproval) {
+		t.Errorf("Expected ErrAccountPendingApproval before approval, got: %v", err)
+	}
+
+	pending, total, err := registrationService.ListPending(&models.RegistrationQuery{Page: 1, PageSize: 10})
+	if err != nil || total != 2 || len(pending) != 2 {
+		t.Fatalf("Expected 2 pending registrations, got %d (total %d, err %v)", len(pending), total, err)
+	}
+
+	// Approve
+	sent := len(mail.Messages())
+	if _, err := registrationService.Approve(approved.UserID, admin); err != nil {
+		t.Fatalf("Expected no error approving, got: %v", err)
+	}
+	if _, err := authService.Login(login, models.ClientInfo{}); err != nil {
+		t.Errorf("Expected login to succeed after approval, got: %v", err)
+	}
+	if _, err := registrationService.Approve(approved.UserID, admin); !errors.Is(err, ErrRegistrationNotPending) {
+		t.Errorf("Expected ErrRegistrationNotPending approving twice, got: %v", err)
+	}
+
+	// Reject
+	if err := registrationService.Reject(rejected.UserID, admin); err != nil {
+		t.Fatalf("Expected no error rejecting, got: %v", err)
+	}
+	if _, err := userRepo.FindByID(rejected.UserID); err == nil {
+		t.Error("Expected the rejected account to be deleted")
+	}
+
+	if got := len(mail.Messages()) - sent; got != 2 {
+		t.Errorf("Expected an email for each decision, got %d", got)
+	}
+	if _, total, _ := registrationService.ListPending(&models.RegistrationQuery{Page: 1, PageSize: 10}); total != 0 {
+		t.Errorf("Expected an empty queue, got %d", total)
+	}
+}
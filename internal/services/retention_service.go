@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+)
+
+// RetentionService permanently removes soft-deleted records once
+// RetentionConfig.DeletedRecords has passed since their deletion.
+type RetentionService interface {
+	// PurgeDeleted removes expired tombstones and returns how many were removed
+	PurgeDeleted() (int64, error)
+	// Run purges every PurgeInterval until ctx is done. It returns at once
+	// when retention is disabled.
+	Run(ctx context.Context)
+}
+
+type retentionService struct {
+	userRepo           repositories.UserRepository
+	projectRepo        repositories.ProjectRepository
+	serviceRequestRepo repositories.ServiceRequestRepository
+	messageRepo        repositories.MessageRepository
+	cfg                *config.Config
+}
+
+func NewRetentionService(userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository, serviceRequestRepo repositories.ServiceRequestRepository, messageRepo repositories.MessageRepository, cfg *config.Config) RetentionService {
+	return &retentionService{
+		userRepo:           userRepo,
+		projectRepo:        projectRepo,
+		serviceRequestRepo: serviceRequestRepo,
+		messageRepo:        messageRepo,
+		cfg:                cfg,
+	}
+}
+
+func (s *retentionService) PurgeDeleted() (int64, error) {
+	before := time.Now().Add(-s.cfg.Retention.DeletedRecords)
+
+	// Users go last so that clients whose projects and service requests are
+	// purged in this run are no longer considered referenced
+	purges := []func(time.Time) (int64, error){
+		s.messageRepo.PurgeDeleted,
+		s.serviceRequestRepo.PurgeDeleted,
+		s.projectRepo.PurgeDeleted,
+		s.userRepo.PurgeDeleted,
+	}
+
+	var total int64
+	for _, purge := range purges {
+		removed, err := purge(before)
+		total += removed
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (s *retentionService) Run(ctx context.Context) {
+	if s.cfg.Retention.DeletedRecords <= 0 || s.cfg.Retention.PurgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Retention.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := s.PurgeDeleted()
+		if err != nil {
+			log.Printf("Failed to purge deleted records: %v", err)
+		} else if removed > 0 {
+			log.Printf("Purged %d deleted records", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,54 +1,69 @@
 package services
 
 import (
-	"errors"
 	"fmt"
 
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/policy"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 )
 
+var errServiceRequestNotPending = apperrors.Conflict("SERVICE_REQUEST_NOT_PENDING", "service request is not pending")
+
 type ServiceRequestService interface {
-	Create(clientID string, req *models.CreateServiceRequestRequest) (*models.ServiceRequest, error)
-	GetByID(id string) (*models.ServiceRequest, error)
-	Update(id string, req *models.UpdateServiceRequestRequest) (*models.ServiceRequest, error)
-	Delete(id string) error
-	List(query *models.PaginationQuery, clientID *string) ([]models.ServiceRequest, int64, error)
-	Approve(id string, employeeIDs *[]string) (*models.Project, error)
-	Reject(id string) error
+	// Create files a request on behalf of actor, who becomes its client
+	Create(req *models.CreateServiceRequestRequest, actor models.Actor) (*models.ServiceRequest, error)
+	GetByID(id string, userID string, userRole string) (*models.ServiceRequest, error)
+	// Update applies req when the request is still at a version match allows
+	Update(id string, req *models.UpdateServiceRequestRequest, match VersionMatch, actor models.Actor) (*models.ServiceRequest, error)
+	Delete(id string, actor models.Actor) error
+	Restore(id string, actor models.Actor) (*models.ServiceRequest, error)
+	List(query *models.PaginationQuery, userID string, userRole string) ([]models.ServiceRequest, int64, error)
+	Approve(id string, employeeIDs *[]string, actor models.Actor) (*models.Project, error)
+	Reject(id string, actor models.Actor) error
 }
 
 type serviceRequestService struct {
 	serviceRequestRepo repositories.ServiceRequestRepository
-	projectRepo         repositories.ProjectRepository
-	counterRepo         repositories.CounterRepository
+	ids                IDGenerator
+	uow                repositories.UnitOfWork
+	policy             *policy.Engine
+	integrity          IntegrityService
+	audit              AuditService
 }
 
-func NewServiceRequestService(serviceRequestRepo repositories.ServiceRequestRepository, projectRepo repositories.ProjectRepository, counterRepo repositories.CounterRepository) ServiceRequestService {
+func NewServiceRequestService(serviceRequestRepo repositories.ServiceRequestRepository, ids IDGenerator, uow repositories.UnitOfWork, policyEngine *policy.Engine, integrity IntegrityService, audit AuditService) ServiceRequestService {
 	return &serviceRequestService{
 		serviceRequestRepo: serviceRequestRepo,
-		projectRepo:         projectRepo,
-		counterRepo:         counterRepo,
+		ids:                ids,
+		uow:                uow,
+		policy:             policyEngine,
+		integrity:          integrity,
+		audit:              audit,
 	}
 }
 
-func (s *serviceRequestService) Create(clientID string, req *models.CreateServiceRequestRequest) (*models.ServiceRequest, error) {
+func (s *serviceRequestService) Create(req *models.CreateServiceRequestRequest, actor models.Actor) (*models.ServiceRequest, error) {
 	// Validate request
 	if req.Title == "" {
-		return nil, fmt.Errorf("service request title is required")
+		return nil, apperrors.Validation("SERVICE_REQUEST_TITLE_REQUIRED", "service request title is required")
 	}
+	clientID := actor.ID
 	if clientID == "" {
-		return nil, fmt.Errorf("client ID is required")
+		return nil, apperrors.Validation("CLIENT_ID_REQUIRED", "client ID is required")
+	}
+	if req.ProjectID != nil {
+		if err := s.integrity.CheckClientProject(*req.ProjectID, clientID); err != nil {
+			return nil, err
+		}
 	}
 
-	// Generate next service ID sequence
-	sequence, err := s.counterRepo.GetNextSequence("service_request_counter")
+	serviceID, err := s.ids.NextServiceRequestID()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate service ID: %w", err)
+		return nil, err
 	}
 
-	serviceID := fmt.Sprintf("SERVICE%02d", sequence)
-
 	serviceRequest := &models.ServiceRequest{
 		ID:          serviceID,
 		Title:       req.Title,
@@ -65,18 +80,40 @@ func (s *serviceRequestService) Create(clientID string, req *models.CreateServic
 		return nil, fmt.Errorf("failed to create service request: %w", err)
 	}
 
-	return s.serviceRequestRepo.FindByID(serviceRequest.ID)
+	created, err := s.serviceRequestRepo.FindByID(serviceRequest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionCreate, auditServiceRequest, created.ID, nil, snapshot(created))
+	return created, nil
 }
 
-func (s *serviceRequestService) GetByID(id string) (*models.ServiceRequest, error) {
-	return s.serviceRequestRepo.FindByID(id)
+func (s *serviceRequestService) GetByID(id string, userID string, userRole string) (*models.ServiceRequest, error) {
+	serviceRequest, err := s.serviceRequestRepo.FindByID(id)
+	if err != nil {
+		return nil, lookupError(err, ErrServiceRequestNotFound)
+	}
+
+	if err := s.policy.Authorize(policy.Subject{ID: userID, Role: userRole}, policy.ServiceRequestRead, policy.ServiceRequestResource(serviceRequest)); err != nil {
+		return nil, err
+	}
+
+	return serviceRequest, nil
 }
 
-func (s *serviceRequestService) Update(id string, req *models.UpdateServiceRequestRequest) (*models.ServiceRequest, error) {
+func (s *serviceRequestService) Update(id string, req *models.UpdateServiceRequestRequest, match VersionMatch, actor models.Actor) (*models.ServiceRequest, error) {
 	serviceRequest, err := s.serviceRequestRepo.FindByID(id)
 	if err != nil {
-		return nil, errors.New("service request not found")
+		return nil, lookupError(err, ErrServiceRequestNotFound)
+	}
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ServiceRequestUpdate, policy.ServiceRequestResource(serviceRequest)); err != nil {
+		return nil, err
+	}
+	if !match.Matches(serviceRequest.Version) {
+		return nil, ErrVersionMismatch
 	}
+	before := snapshot(serviceRequest)
 
 	if req.Title != "" {
 		serviceRequest.Title = req.Title
@@ -88,81 +125,174 @@ func (s *serviceRequestService) Update(id string, req *models.UpdateServiceReque
 		serviceRequest.Status = req.Status
 	}
 	if req.ProjectID != nil {
+		if err := s.integrity.CheckClientProject(*req.ProjectID, serviceRequest.ClientID); err != nil {
+			return nil, err
+		}
 		serviceRequest.ProjectID = req.ProjectID
 	}
 
 	if err := s.serviceRequestRepo.Update(serviceRequest); err != nil {
-		return nil, err
+		return nil, updateError(err)
 	}
 
+	s.audit.Record(actor, models.AuditActionUpdate, auditServiceRequest, serviceRequest.ID, before, snapshot(serviceRequest))
 	return serviceRequest, nil
 }
 
-func (s *serviceRequestService) Delete(id string) error {
-	_, err := s.serviceRequestRepo.FindByID(id)
+func (s *serviceRequestService) Delete(id string, actor models.Actor) error {
+	serviceRequest, err := s.serviceRequestRepo.FindByID(id)
 	if err != nil {
-		return errors.New("service request not found")
+		return lookupError(err, ErrServiceRequestNotFound)
+	}
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ServiceRequestDelete, policy.ServiceRequestResource(serviceRequest)); err != nil {
+		return err
 	}
 
-	return s.serviceRequestRepo.Delete(id)
-}
+	if err := s.serviceRequestRepo.Delete(id, actor.ID); err != nil {
+		return err
+	}
 
-func (s *serviceRequestService) List(query *models.PaginationQuery, clientID *string) ([]models.ServiceRequest, int64, error) {
-	return s.serviceRequestRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), clientID)
+	s.audit.Record(actor, models.AuditActionDelete, auditServiceRequest, id, snapshot(serviceRequest), nil)
+	return nil
 }
 
-func (s *serviceRequestService) Approve(id string, employeeIDs *[]string) (*models.Project, error) {
-	serviceRequest, err := s.serviceRequestRepo.FindByID(id)
+func (s *serviceRequestService) Restore(id string, actor models.Actor) (*models.ServiceRequest, error) {
+	deleted, err := s.serviceRequestRepo.FindDeleted(id)
 	if err != nil {
-		return nil, errors.New("service request not found")
+		return nil, lookupError(err, ErrServiceRequestNotFound)
+	}
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ServiceRequestRestore, policy.ServiceRequestResource(deleted)); err != nil {
+		return nil, err
+	}
+	projectID := ""
+	if deleted.ProjectID != nil {
+		projectID = *deleted.ProjectID
+	}
+	if err := s.integrity.CheckRestorable(deleted.ClientID, projectID); err != nil {
+		return nil, err
 	}
 
-	if serviceRequest.Status != models.StatusPending {
-		return nil, errors.New("service request is not pending")
+	tombstone, err := s.serviceRequestRepo.Restore(id)
+	if err != nil {
+		return nil, lookupError(err, ErrServiceRequestNotFound)
 	}
 
-	// Generate next project ID sequence for the new project
-	sequence, err := s.counterRepo.GetNextSequence("project_counter")
+	serviceRequest, err := s.serviceRequestRepo.FindByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate project ID: %w", err)
+		return nil, err
 	}
 
-	projectID := fmt.Sprintf("PROJECT%02d", sequence)
+	s.audit.Record(actor, models.AuditActionRestore, auditServiceRequest, id, snapshot(tombstone), snapshot(serviceRequest))
+	return serviceRequest, nil
+}
 
-	// Create project from service request
-	project := &models.Project{
-		ID:          projectID,
-		Name:        serviceRequest.Title,
-		Description: serviceRequest.Description,
-		ClientID:    serviceRequest.ClientID,
-		Status:      models.StatusActive,
-		EmployeeIDs: *employeeIDs,
+func (s *serviceRequestService) List(query *models.PaginationQuery, userID string, userRole string) ([]models.ServiceRequest, int64, error) {
+	// Narrow the listing to the requests the role may see
+	subject := policy.Subject{ID: userID, Role: userRole}
+	scope := s.policy.Scope(subject, policy.ServiceRequestList)
+	switch {
+	case scope.All:
+		return s.serviceRequestRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), nil, query.IncludeDeleted)
+	case scope.Allows(policy.Client):
+		return s.serviceRequestRepo.List(query.Page, query.PageSize, query.Search, string(query.Status), &userID, query.IncludeDeleted)
 	}
 
-	if err := s.projectRepo.Create(project); err != nil {
-		return nil, fmt.Errorf("failed to create project: %w", err)
-	}
+	return nil, 0, policy.Denied(subject, policy.ServiceRequestList)
+}
 
-	// Update service request status and link to project
-	serviceRequest.Status = models.StatusActive
-	serviceRequest.ProjectID = &project.ID
-	if err := s.serviceRequestRepo.Update(serviceRequest); err != nil {
-		return nil, fmt.Errorf("failed to update service request: %w", err)
+func (s *serviceRequestService) Approve(id string, employeeIDs *[]string, actor models.Actor) (*models.Project, error) {
+	if err := s.integrity.CheckEmployees(*employeeIDs); err != nil {
+		return nil, err
 	}
 
-	return s.projectRepo.FindByID(project.ID)
-}
+	// The project number, the project and the request's new status are
+	// written together so a failure cannot leave an orphan project or burn a
+	// number
+	var before, after map[string]interface{}
+	var project *models.Project
+	err := s.uow.Do(func(tx repositories.Tx) error {
+		serviceRequest, err := tx.ServiceRequests().FindByID(id)
+		if err != nil {
+			return lookupError(err, ErrServiceRequestNotFound)
+		}
+		if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ServiceRequestApprove, policy.ServiceRequestResource(serviceRequest)); err != nil {
+			return err
+		}
+		before = snapshot(serviceRequest)
 
-func (s *serviceRequestService) Reject(id string) error {
-	serviceRequest, err := s.serviceRequestRepo.FindByID(id)
+		if serviceRequest.Status != models.StatusPending {
+			return errServiceRequestNotPending
+		}
+
+		// The project ID is drawn inside the transaction so a rollback
+		// gives it back
+		projectID, err := s.ids.WithCounters(tx.Counters()).NextProjectID()
+		if err != nil {
+			return err
+		}
+
+		// Create project from service request
+		project = &models.Project{
+			ID:          projectID,
+			Name:        serviceRequest.Title,
+			Description: serviceRequest.Description,
+			ClientID:    serviceRequest.ClientID,
+			Status:      models.StatusActive,
+			EmployeeIDs: *employeeIDs,
+		}
+		if err := tx.Projects().Create(project); err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+
+		// Update service request status and link to project
+		serviceRequest.Status = models.StatusActive
+		serviceRequest.ProjectID = &project.ID
+		if err := tx.ServiceRequests().Update(serviceRequest); err != nil {
+			return updateError(err)
+		}
+		after = snapshot(serviceRequest)
+
+		project, err = tx.Projects().FindByID(project.ID)
+		return err
+	})
 	if err != nil {
-		return errors.New("service request not found")
+		return nil, err
 	}
 
-	if serviceRequest.Status != models.StatusPending {
-		return errors.New("service request is not pending")
+	s.audit.Record(actor, models.AuditActionApprove, auditServiceRequest, id, before, after)
+	s.audit.Record(actor, models.AuditActionCreate, auditProject, project.ID, nil, snapshot(project))
+	return project, nil
+}
+
+func (s *serviceRequestService) Reject(id string, actor models.Actor) error {
+	// Reading and writing in one transaction keeps a concurrent approval
+	// from slipping in between the status check and the update
+	var before, after map[string]interface{}
+	err := s.uow.Do(func(tx repositories.Tx) error {
+		serviceRequest, err := tx.ServiceRequests().FindByID(id)
+		if err != nil {
+			return lookupError(err, ErrServiceRequestNotFound)
+		}
+		if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.ServiceRequestReject, policy.ServiceRequestResource(serviceRequest)); err != nil {
+			return err
+		}
+
+		if serviceRequest.Status != models.StatusPending {
+			return errServiceRequestNotPending
+		}
+
+		before = snapshot(serviceRequest)
+		serviceRequest.Status = models.StatusRejected
+		if err := tx.ServiceRequests().Update(serviceRequest); err != nil {
+			return updateError(err)
+		}
+		after = snapshot(serviceRequest)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	serviceRequest.Status = models.StatusRejected
-	return s.serviceRequestRepo.Update(serviceRequest)
+	s.audit.Record(actor, models.AuditActionReject, auditServiceRequest, id, before, after)
+	return nil
 }
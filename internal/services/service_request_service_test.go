@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+// MockUnitOfWork is an in-memory unit of work: it hands out the mock
+// repositories and puts their contents back when fn fails.
+type MockUnitOfWork struct {
+	counters        *MockCounterRepository
+	projects        *MockProjectRepository
+	serviceRequests *MockServiceRequestRepository
+}
+
+func (m *MockUnitOfWork) Do(fn func(tx repositories.Tx) error) error {
+	counters := make(map[string]int)
+	for name, sequence := range m.counters.counters {
+		counters[name] = sequence
+	}
+	projects := make(map[string]*models.Project)
+	for id, project := range m.projects.projects {
+		copied := *project
+		projects[id] = &copied
+	}
+	requests := make(map[string]*models.ServiceRequest)
+	for id, request := range m.serviceRequests.requests {
+		copied := *request
+		requests[id] = &copied
+	}
+
+	if err := fn(m); err != nil {
+		m.counters.counters = counters
+		m.projects.projects = projects
+		m.serviceRequests.requests = requests
+		return err
+	}
+	return nil
+}
+
+func (m *MockUnitOfWork) Counters() repositories.CounterRepository { return m.counters }
+
+func (m *MockUnitOfWork) Projects() repositories.ProjectRepository { return m.projects }
+
+func (m *MockUnitOfWork) ServiceRequests() repositories.ServiceRequestRepository {
+	return m.serviceRequests
+}
+
+func TestServiceRequestService_ApproveIsAtomic(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	uow := &MockUnitOfWork{
+		counters:        NewMockCounterRepository(),
+		projects:        NewMockProjectRepository(),
+		serviceRequests: NewMockServiceRequestRepository(),
+	}
+	serviceRequestService := NewServiceRequestService(uow.serviceRequests, newTestIDGenerator(uow.counters), uow, policy.Default(), newTestIntegrityService(userRepo, uow.projects), newTestAuditService())
+	admin := models.Actor{ID: "ADMIN01", Role: "admin"}
+
+	userRepo.Create(&models.User{UserID: "EMP01", Role: models.RoleEmployee})
+	uow.serviceRequests.Create(&models.ServiceRequest{ID: "SERVICE01", Title: "Portal", ClientID: "CLIENT01", Status: models.StatusPending})
+
+	// A failure updating the request undoes the project and its number
+	uow.serviceRequests.updateErr = errors.New("connection reset")
+	if _, err := serviceRequestService.Approve("SERVICE01", &[]string{"EMP01"}, admin); err == nil {
+		t.Fatal("Expected the approval to fail")
+	}
+	if len(uow.projects.projects) != 0 || uow.counters.counters["project_counter"] != 0 {
+		t.Errorf("Expected no project and no burned number, got %d projects and counter %d", len(uow.projects.projects), uow.counters.counters["project_counter"])
+	}
+	if request, _ := uow.serviceRequests.FindByID("SERVICE01"); request.Status != models.StatusPending || request.ProjectID != nil {
+		t.Errorf("Expected the request to stay pending, got: %+v", request)
+	}
+
+	uow.serviceRequests.updateErr = nil
+	project, err := serviceRequestService.Approve("SERVICE01", &[]string{"EMP01"}, admin)
+	if err != nil || project.ID != "PROJECT01" {
+		t.Fatalf("Expected PROJECT01 once the approval succeeds, got %+v: %v", project, err)
+	}
+	if request, _ := uow.serviceRequests.FindByID("SERVICE01"); request.Status != models.StatusActive || request.ProjectID == nil || *request.ProjectID != "PROJECT01" {
+		t.Errorf("Expected the request to be active and linked, got: %+v", request)
+	}
+
+	if err := serviceRequestService.Reject("SERVICE01", admin); err != errServiceRequestNotPending {
+		t.Errorf("Expected errServiceRequestNotPending rejecting an approved request, got: %v", err)
+	}
+}
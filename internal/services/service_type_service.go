@@ -1,25 +1,36 @@
 package services
 
 import (
-	"errors"
-
-	"github.com/vinodhini/software-api/internal/models"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 )
 
-type ServiceTypeService struct {
-	repository *repositories.ServiceTypeRepository
+type ServiceTypeService interface {
+	Create(req *models.CreateServiceTypeRequest, actor models.Actor) (*models.ServiceType, error)
+	GetByID(id string) (*models.ServiceType, error)
+	GetAll(status *string) ([]models.ServiceType, error)
+	// GetActive returns the service types clients can request
+	GetActive() ([]models.ServiceType, error)
+	Update(id string, req *models.UpdateServiceTypeRequest, actor models.Actor) (*models.ServiceType, error)
+	Delete(id string, actor models.Actor) error
+}
+
+type serviceTypeService struct {
+	repository repositories.ServiceTypeRepository
+	audit      AuditService
 }
 
-func NewServiceTypeService(repository *repositories.ServiceTypeRepository) *ServiceTypeService {
-	return &ServiceTypeService{
+func NewServiceTypeService(repository repositories.ServiceTypeRepository, audit AuditService) ServiceTypeService {
+	return &serviceTypeService{
 		repository: repository,
+		audit:      audit,
 	}
 }
 
-func (s *ServiceTypeService) Create(req *models.CreateServiceTypeRequest) (*models.ServiceType, error) {
+func (s *serviceTypeService) Create(req *models.CreateServiceTypeRequest, actor models.Actor) (*models.ServiceType, error) {
 	if req.Name == "" {
-		return nil, errors.New("name is required")
+		return nil, apperrors.Validation("SERVICE_TYPE_NAME_REQUIRED", "name is required")
 	}
 
 	serviceType := &models.ServiceType{
@@ -33,27 +44,34 @@ func (s *ServiceTypeService) Create(req *models.CreateServiceTypeRequest) (*mode
 		return nil, err
 	}
 
+	s.audit.Record(actor, models.AuditActionCreate, auditServiceType, serviceType.ID, nil, snapshot(serviceType))
 	return serviceType, nil
 }
 
-func (s *ServiceTypeService) GetByID(id string) (*models.ServiceType, error) {
-	return s.repository.GetByID(id)
+func (s *serviceTypeService) GetByID(id string) (*models.ServiceType, error) {
+	serviceType, err := s.repository.GetByID(id)
+	if err != nil {
+		return nil, lookupError(err, ErrServiceTypeNotFound)
+	}
+
+	return serviceType, nil
 }
 
-func (s *ServiceTypeService) GetAll(status *string) ([]models.ServiceType, error) {
+func (s *serviceTypeService) GetAll(status *string) ([]models.ServiceType, error) {
 	return s.repository.GetAll(status)
 }
 
-func (s *ServiceTypeService) GetActive() ([]models.ServiceType, error) {
+func (s *serviceTypeService) GetActive() ([]models.ServiceType, error) {
 	activeStatus := string(models.StatusActive)
 	return s.repository.GetAll(&activeStatus)
 }
 
-func (s *ServiceTypeService) Update(id string, req *models.UpdateServiceTypeRequest) (*models.ServiceType, error) {
+func (s *serviceTypeService) Update(id string, req *models.UpdateServiceTypeRequest, actor models.Actor) (*models.ServiceType, error) {
 	existingServiceType, err := s.repository.GetByID(id)
 	if err != nil {
-		return nil, err
+		return nil, lookupError(err, ErrServiceTypeNotFound)
 	}
+	before := snapshot(existingServiceType)
 
 	if req.Name != "" {
 		existingServiceType.Name = req.Name
@@ -67,17 +85,23 @@ func (s *ServiceTypeService) Update(id string, req *models.UpdateServiceTypeRequ
 
 	err = s.repository.Update(id, existingServiceType)
 	if err != nil {
-		return nil, err
+		return nil, lookupError(err, ErrServiceTypeNotFound)
 	}
 
+	s.audit.Record(actor, models.AuditActionUpdate, auditServiceType, id, before, snapshot(existingServiceType))
 	return existingServiceType, nil
 }
 
-func (s *ServiceTypeService) Delete(id string) error {
-	_, err := s.repository.GetByID(id)
+func (s *serviceTypeService) Delete(id string, actor models.Actor) error {
+	serviceType, err := s.repository.GetByID(id)
 	if err != nil {
-		return err
+		return lookupError(err, ErrServiceTypeNotFound)
+	}
+
+	if err := s.repository.Delete(id); err != nil {
+		return lookupError(err, ErrServiceTypeNotFound)
 	}
 
-	return s.repository.Delete(id)
+	s.audit.Record(actor, models.AuditActionDelete, auditServiceType, id, snapshot(serviceType), nil)
+	return nil
 }
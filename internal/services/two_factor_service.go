@@ -0,0 +1,245 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// Purposes of the challenge tokens handed out by a two-step login
+const (
+	challengeTwoFactor      = "2fa"
+	challengeTwoFactorSetup = "2fa_setup"
+)
+
+const recoveryCodeCount = 10
+
+// TwoFactorService manages the second factor of the calling user: actor is
+// always the account being changed.
+type TwoFactorService interface {
+	Setup(userID string) (*models.TwoFactorSetupResponse, error)
+	Enable(req *models.TwoFactorCodeRequest, actor models.Actor) ([]string, error)
+	Disable(req *models.TwoFactorCodeRequest, actor models.Actor) error
+	RegenerateRecoveryCodes(req *models.TwoFactorCodeRequest, actor models.Actor) ([]string, error)
+}
+
+type twoFactorService struct {
+	userRepo       repositories.UserRepository
+	lockoutService LockoutService
+	cfg            *config.Config
+	audit          AuditService
+}
+
+func NewTwoFactorService(userRepo repositories.UserRepository, lockoutService LockoutService, cfg *config.Config, audit AuditService) TwoFactorService {
+	return &twoFactorService{
+		userRepo:       userRepo,
+		lockoutService: lockoutService,
+		cfg:            cfg,
+		audit:          audit,
+	}
+}
+
+func (s *twoFactorService) Setup(userID string) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, lookupError(err, ErrUserNotFound)
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	setup, err := beginTwoFactorSetup(user, s.cfg.Auth.TOTPIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return setup, nil
+}
+
+func (s *twoFactorService) Enable(req *models.TwoFactorCodeRequest, actor models.Actor) ([]string, error) {
+	user, err := s.userRepo.FindByID(actor.ID)
+	if err != nil {
+		return nil, lookupError(err, ErrUserNotFound)
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	before := snapshot(user)
+	codes, err := completeTwoFactorSetup(user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(actor, models.AuditActionEnableTwoFactor, auditUser, user.UserID, before, snapshot(user))
+
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(req *models.TwoFactorCodeRequest, actor models.Actor) error {
+	user, err := s.userRepo.FindByID(actor.ID)
+	if err != nil {
+		return lookupError(err, ErrUserNotFound)
+	}
+
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if s.cfg.Auth.RequireAdmin2FA && user.Role == models.RoleAdmin {
+		return apperrors.Forbidden("TWO_FACTOR_REQUIRED", "two-factor authentication is mandatory for admin accounts")
+	}
+
+	if err := s.verifyCode(user, req.Code, actor); err != nil {
+		return err
+	}
+
+	before := snapshot(user)
+	user.TwoFactorEnabled = false
+	user.TwoFactorSecret = ""
+	user.TwoFactorPendingSecret = ""
+	user.TwoFactorLastStep = 0
+	user.RecoveryCodes = nil
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	s.audit.Record(actor, models.AuditActionDisableTwoFactor, auditUser, user.UserID, before, snapshot(user))
+	return nil
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(req *models.TwoFactorCodeRequest, actor models.Actor) ([]string, error) {
+	user, err := s.userRepo.FindByID(actor.ID)
+	if err != nil {
+		return nil, lookupError(err, ErrUserNotFound)
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if err := s.verifyCode(user, req.Code, actor); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.RecoveryCodes = hashes
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(actor, models.AuditActionRegenerateRecoveryCodes, auditUser, user.UserID, nil, nil)
+
+	return codes, nil
+}
+
+// verifyCode checks a second-factor code of the signed-in user. Wrong codes
+// count against the same lockout as a login, so a stolen session cannot be
+// used to guess them.
+func (s *twoFactorService) verifyCode(user *models.User, code string, actor models.Actor) error {
+	if err := s.lockoutService.Check(user.Email); err != nil {
+		return err
+	}
+	if !verifySecondFactor(user, code) {
+		if err := s.lockoutService.RecordFailure(user.Email, actor.IP); err != nil {
+			log.Printf("Failed to record failed two-factor code for %s: %v", user.Email, err)
+		}
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// beginTwoFactorSetup stores a new pending secret on the user. It only becomes
+// active once a code generated from it is confirmed.
+func beginTwoFactorSetup(user *models.User, issuer string) (*models.TwoFactorSetupResponse, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorPendingSecret = secret
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// completeTwoFactorSetup activates the pending secret if code matches it and
+// returns freshly generated recovery codes.
+func completeTwoFactorSetup(user *models.User, code string) ([]string, error) {
+	if user.TwoFactorPendingSecret == "" {
+		return nil, apperrors.Conflict("TWO_FACTOR_SETUP_NOT_STARTED", "two-factor setup has not been started")
+	}
+
+	step, ok := utils.ValidateTOTP(user.TwoFactorPendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	user.TwoFactorSecret = user.TwoFactorPendingSecret
+	user.TwoFactorPendingSecret = ""
+	user.TwoFactorLastStep = step
+	user.RecoveryCodes = hashes
+	return codes, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code. It
+// records the used TOTP step or consumes the recovery code on the user, so the
+// caller must persist the user after a successful check.
+func verifySecondFactor(user *models.User, code string) bool {
+	if step, ok := utils.ValidateTOTP(user.TwoFactorSecret, code, time.Now()); ok {
+		// Each code may only be used once within its validity window
+		if step <= user.TwoFactorLastStep {
+			return false
+		}
+		user.TwoFactorLastStep = step
+		return true
+	}
+
+	hash := utils.HashToken(code)
+	for i, stored := range user.RecoveryCodes {
+		if stored == hash {
+			user.RecoveryCodes = append(user.RecoveryCodes[:i:i], user.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+func TestLogin_TwoFactorChallenge(t *testing.T) {
+	// Setup
+	mockRepo := NewMockUserRepository()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:        "test-secret",
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: time.Hour,
+		},
+		Auth: config.AuthConfig{
+			TwoFactorChallengeExpiry: 5 * time.Minute,
+			TOTPIssuer:               "Test",
+		},
+	}
+
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+	twoFactorService := NewTwoFactorService(mockRepo, newTestLockoutService(cfg), cfg, newTestAuditService())
+
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo.Create(&models.User{
+		UserID:   "USER01",
+		Email:    "2fa@example.com",
+		Password: hashedPassword,
+		Role:     models.RoleEmployee,
+		Status:   models.UserStatusActive,
+	})
+
+	setup, err := twoFactorService.Setup("USER01")
+	if err != nil {
+		t.Fatalf("Expected no error starting setup, got: %v", err)
+	}
+
+	// Confirm with the code of the previous step so the login below uses a fresh one
+	enableCode, _ := utils.TOTPCode(setup.Secret, time.Now().Add(-30*time.Second))
+	recoveryCodes, err := twoFactorService.Enable(&models.TwoFactorCodeRequest{Code: enableCode}, models.Actor{ID: "USER01"})
+	if err != nil {
+		t.Fatalf("Expected no error enabling 2FA, got: %v", err)
+	}
+
+	login, err := authService.Login(&models.LoginRequest{Email: "2fa@example.com", Password: "password123"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error on login, got: %v", err)
+	}
+
+	if !login.TwoFactorRequired || login.ChallengeToken == "" || login.Token != "" {
+		t.Fatalf("Expected a 2FA challenge instead of tokens, got: %+v", login)
+	}
+
+	if _, err := authService.LoginTwoFactor(&models.TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: "000000"}, models.ClientInfo{}); err == nil {
+		t.Error("Expected error for an invalid code")
+	}
+
+	code, _ := utils.TOTPCode(setup.Secret, time.Now())
+	response, err := authService.LoginTwoFactor(&models.TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: code}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error completing login, got: %v", err)
+	}
+
+	if response.Token == "" || response.RefreshToken == "" {
+		t.Error("Expected tokens after the second factor")
+	}
+
+	// The same code cannot be replayed
+	if _, err := authService.LoginTwoFactor(&models.TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: code}, models.ClientInfo{}); err == nil {
+		t.Error("Expected error when reusing a TOTP code")
+	}
+
+	// Recovery codes work exactly once
+	recovery := &models.TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: recoveryCodes[0]}
+	if _, err := authService.LoginTwoFactor(recovery, models.ClientInfo{}); err != nil {
+		t.Errorf("Expected recovery code to be accepted, got: %v", err)
+	}
+	if _, err := authService.LoginTwoFactor(recovery, models.ClientInfo{}); err == nil {
+		t.Error("Expected error when reusing a recovery code")
+	}
+
+	// A challenge token must not be usable as an access token
+	claims, _ := utils.ValidateToken(login.ChallengeToken, testKeys)
+	if claims.Purpose == "" {
+		t.Error("Expected challenge token to carry a purpose")
+	}
+}
+
+func TestLogin_AdminMustEnrolTwoFactor(t *testing.T) {
+	// Setup
+	mockRepo := NewMockUserRepository()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:        "test-secret",
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: time.Hour,
+		},
+		Auth: config.AuthConfig{
+			RequireAdmin2FA:          true,
+			TwoFactorChallengeExpiry: 5 * time.Minute,
+			TOTPIssuer:               "Test",
+		},
+	}
+
+	authService := NewAuthService(mockRepo, newTestIDGenerator(NewMockCounterRepository()), NewMockSessionRepository(), NewMockUserTokenRepository(), newTestLockoutService(cfg), mailer.NewMemoryMailer(), testKeys, cfg, newTestAuditService())
+	twoFactorService := NewTwoFactorService(mockRepo, newTestLockoutService(cfg), cfg, newTestAuditService())
+
+	hashedPassword, _ := utils.HashPassword("password123")
+	mockRepo.Create(&models.User{
+		UserID:   "USER01",
+		Email:    "admin@example.com",
+		Password: hashedPassword,
+		Role:     models.RoleAdmin,
+		Status:   models.UserStatusActive,
+	})
+
+	login, err := authService.Login(&models.LoginRequest{Email: "admin@example.com", Password: "password123"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error on login, got: %v", err)
+	}
+
+	if !login.TwoFactorSetupRequired || login.Token != "" {
+		t.Fatalf("Expected admin to be asked to enrol in 2FA, got: %+v", login)
+	}
+
+	setup, err := authService.LoginTwoFactorSetup(&models.TwoFactorChallengeRequest{ChallengeToken: login.ChallengeToken})
+	if err != nil {
+		t.Fatalf("Expected no error starting enrolment, got: %v", err)
+	}
+
+	code, _ := utils.TOTPCode(setup.Secret, time.Now())
+	response, err := authService.LoginTwoFactor(&models.TwoFactorLoginRequest{ChallengeToken: login.ChallengeToken, Code: code}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Expected no error completing enrolment, got: %v", err)
+	}
+
+	if response.Token == "" || len(response.RecoveryCodes) == 0 {
+		t.Error("Expected tokens and recovery codes after enrolment")
+	}
+
+	if err := twoFactorService.Disable(&models.TwoFactorCodeRequest{Code: response.RecoveryCodes[0]}, models.Actor{ID: "USER01"}); err == nil {
+		t.Error("Expected admins to be unable to disable mandatory 2FA")
+	}
+}
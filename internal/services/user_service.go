@@ -1,18 +1,21 @@
 package services
 
 import (
-	"errors"
-	"fmt"
-
-	"github.com/vinodhini/software-api/internal/models"
+	"github.com/vinodhini/software-api/internal/policy"
 	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
 	GetByID(id string, userID string, userRole string) (*models.User, error)
-	Update(id string, req *models.UpdateUserRequest, userID string, userRole string) (*models.User, error)
-	Delete(id string) error
+	// Update applies req when the user is still at a version match allows
+	Update(id string, req *models.UpdateUserRequest, match VersionMatch, actor models.Actor) (*models.User, error)
+	Delete(id string, actor models.Actor) error
+	// Restore undeletes an account. Its sessions stay revoked, so the user
+	// signs in again.
+	Restore(id string, actor models.Actor) (*models.User, error)
 	List(query *models.PaginationQuery, role string) ([]models.User, int64, error)
 	GetDashboardStats(userID string, userRole string) (map[string]interface{}, error)
 }
@@ -20,69 +23,73 @@ type UserService interface {
 type userService struct {
 	userRepo    repositories.UserRepository
 	projectRepo repositories.ProjectRepository
+	sessionRepo repositories.SessionRepository
+	policy      *policy.Engine
+	integrity   IntegrityService
+	audit       AuditService
 }
 
-func NewUserService(userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository) UserService {
+func NewUserService(userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository, sessionRepo repositories.SessionRepository, policyEngine *policy.Engine, integrity IntegrityService, audit AuditService) UserService {
 	return &userService{
 		userRepo:    userRepo,
 		projectRepo: projectRepo,
+		sessionRepo: sessionRepo,
+		policy:      policyEngine,
+		integrity:   integrity,
+		audit:       audit,
 	}
 }
 
 func (s *userService) GetByID(id string, userID string, userRole string) (*models.User, error) {
 	user, err := s.userRepo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, lookupError(err, ErrUserNotFound)
 	}
 
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only view their own profile
-		if id != userID {
-			return nil, errors.New("access denied: employees can only view their own profile")
-		}
-	} else if userRole == "client" {
-		// Clients can only view their own profile
-		if id != userID {
-			return nil, errors.New("access denied: clients can only view their own profile")
-		}
+	if err := s.policy.Authorize(policy.Subject{ID: userID, Role: userRole}, policy.UserRead, policy.UserResource(user.UserID)); err != nil {
+		return nil, err
 	}
 
 	return user, nil
 }
 
-func (s *userService) Update(id string, req *models.UpdateUserRequest, userID string, userRole string) (*models.User, error) {
-	fmt.Printf("UserService.Update called with ID: %s, Request: %+v\n", id, req)
-	
+func (s *userService) Update(id string, req *models.UpdateUserRequest, match VersionMatch, actor models.Actor) (*models.User, error) {
 	user, err := s.userRepo.FindByID(id)
 	if err != nil {
-		fmt.Printf("User not found: %v\n", err)
-		return nil, errors.New("user not found")
+		return nil, lookupError(err, ErrUserNotFound)
 	}
-	
-	fmt.Printf("Found user: %+v\n", user)
+	before := snapshot(user)
 
-	// Apply role-based access control
-	if userRole == "employee" {
-		// Employees can only update their own profile
-		if id != userID {
-			return nil, errors.New("access denied: employees can only update their own profile")
+	subject := policy.Subject{ID: actor.ID, Role: actor.Role}
+	resource := policy.UserResource(user.UserID)
+	if err := s.policy.Authorize(subject, policy.UserUpdate, resource); err != nil {
+		return nil, err
+	}
+
+	// Role, employment details and company need their own permission on top of user:update
+	if req.Role != "" {
+		if err := s.policy.Authorize(subject, policy.UserUpdateRole, resource); err != nil {
+			return nil, err
 		}
-		// Employees cannot change their role, department, or salary
-		if req.Role != "" || req.Department != "" || req.Salary > 0 {
-			return nil, errors.New("access denied: employees cannot modify role, department, or salary")
+		if !s.policy.HasRole(req.Role) {
+			return nil, apperrors.Validation("UNKNOWN_ROLE", "unknown role %q", req.Role)
 		}
-	} else if userRole == "client" {
-		// Clients can only update their own profile
-		if id != userID {
-			return nil, errors.New("access denied: clients can only update their own profile")
+	}
+	if req.Department != "" || req.Salary > 0 {
+		if err := s.policy.Authorize(subject, policy.UserUpdateEmployment, resource); err != nil {
+			return nil, err
 		}
-		// Clients cannot change their role or company
-		if req.Role != "" || req.Company != "" {
-			return nil, errors.New("access denied: clients cannot modify role or company")
+	}
+	if req.Company != "" {
+		if err := s.policy.Authorize(subject, policy.UserUpdateCompany, resource); err != nil {
+			return nil, err
 		}
 	}
 
+	if !match.Matches(user.Version) {
+		return nil, ErrVersionMismatch
+	}
+
 	if req.Name != "" {
 		user.Name = req.Name
 	}
@@ -91,14 +98,12 @@ func (s *userService) Update(id string, req *models.UpdateUserRequest, userID st
 	}
 	if req.Phone != "" {
 		user.Phone = req.Phone
-		fmt.Printf("Updated phone to: %s\n", req.Phone)
 	}
 	if req.Role != "" {
 		user.Role = models.Role(req.Role)
 	}
 	if req.Department != "" {
 		user.Department = req.Department
-		fmt.Printf("Updated department to: %s\n", req.Department)
 	}
 	if req.Salary > 0 {
 		user.Salary = req.Salary
@@ -107,8 +112,7 @@ func (s *userService) Update(id string, req *models.UpdateUserRequest, userID st
 		// Hash password if provided
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
-			fmt.Printf("Password hashing error: %v\n", err)
-			return nil, errors.New("failed to hash password")
+			return nil, apperrors.Internal(err)
 		}
 		user.Password = string(hashedPassword)
 	}
@@ -121,137 +125,124 @@ func (s *userService) Update(id string, req *models.UpdateUserRequest, userID st
 	if req.Company != "" {
 		user.Company = req.Company
 	}
-	
-	fmt.Printf("Updated user before save: %+v\n", user)
 
 	if err := s.userRepo.Update(user); err != nil {
-		fmt.Printf("Repository update error: %v\n", err)
+		return nil, updateError(err)
+	}
+
+	if err := revokeSessionsIfNeeded(s.sessionRepo, user, req); err != nil {
 		return nil, err
 	}
-	
-	fmt.Printf("User updated successfully\n")
 
+	s.audit.Record(actor, models.AuditActionUpdate, auditUser, user.UserID, before, snapshot(user))
 	return user, nil
 }
 
-func (s *userService) Delete(id string) error {
-	_, err := s.userRepo.FindByID(id)
+func (s *userService) Delete(id string, actor models.Actor) error {
+	user, err := s.userRepo.FindByID(id)
 	if err != nil {
-		return errors.New("user not found")
+		return lookupError(err, ErrUserNotFound)
+	}
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.UserDelete, policy.UserResource(user.UserID)); err != nil {
+		return err
 	}
 
-	return s.userRepo.Delete(id)
+	// Projects and service requests must not be left pointing at the user
+	if err := s.integrity.ReleaseUser(user, actor); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(id, actor.ID); err != nil {
+		return err
+	}
+	s.audit.Record(actor, models.AuditActionDelete, auditUser, id, snapshot(user), nil)
+
+	return s.sessionRepo.RevokeAllForUser(id)
+}
+
+func (s *userService) Restore(id string, actor models.Actor) (*models.User, error) {
+	// A user resource is its ID alone, so the tombstone need not be read first
+	if err := s.policy.Authorize(policy.Subject{ID: actor.ID, Role: actor.Role}, policy.UserRestore, policy.UserResource(id)); err != nil {
+		return nil, err
+	}
+
+	tombstone, err := s.userRepo.Restore(id)
+	if err != nil {
+		return nil, lookupError(err, ErrUserNotFound)
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(actor, models.AuditActionRestore, auditUser, id, snapshot(tombstone), snapshot(user))
+	return user, nil
 }
 
 func (s *userService) List(query *models.PaginationQuery, role string) ([]models.User, int64, error) {
-	return s.userRepo.List(query.Page, query.PageSize, query.Search, role)
+	return s.userRepo.List(query.Page, query.PageSize, query.Search, role, query.IncludeDeleted)
 }
 
 func (s *userService) GetDashboardStats(userID string, userRole string) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
-	
-	if userRole == "employee" {
-		// Get employee's assigned projects
-		projects, _, err := s.projectRepo.ListByEmployee(1, 1000, "", "", userID)
-		if err != nil {
-			return nil, err
-		}
-		
-		totalProjects := len(projects)
-		activeProjects := 0
-		pendingProjects := 0
-		completedProjects := 0
-		inProgressProjects := 0
-		
-		for _, project := range projects {
-			switch project.Status {
-			case models.StatusActive:
-				activeProjects++
-			case models.StatusPending:
-				pendingProjects++
-			case models.StatusCompleted:
-				completedProjects++
-			case models.StatusInProgress:
-				inProgressProjects++
-			}
-		}
-		
-		stats["assigned_projects"] = totalProjects
-		stats["active_projects"] = activeProjects
-		stats["pending_projects"] = pendingProjects
-		stats["completed_projects"] = completedProjects
-		stats["in_progress_projects"] = inProgressProjects
-		stats["projects"] = projects
-		
-	} else if userRole == "client" {
-		// Get client's projects
-		projects, _, err := s.projectRepo.List(1, 1000, "", "", &userID)
-		if err != nil {
-			return nil, err
-		}
-		
-		totalProjects := len(projects)
-		activeProjects := 0
-		pendingProjects := 0
-		completedProjects := 0
-		inProgressProjects := 0
-		
-		for _, project := range projects {
-			switch project.Status {
-			case models.StatusActive:
-				activeProjects++
-			case models.StatusPending:
-				pendingProjects++
-			case models.StatusCompleted:
-				completedProjects++
-			case models.StatusInProgress:
-				inProgressProjects++
-			}
-		}
-		
-		stats["total_projects"] = totalProjects
-		stats["active_projects"] = activeProjects
-		stats["pending_projects"] = pendingProjects
-		stats["completed_projects"] = completedProjects
-		stats["in_progress_projects"] = inProgressProjects
-		stats["projects"] = projects
-		
-	} else if userRole == "admin" {
-		// Get all projects for admin overview
-		projects, _, err := s.projectRepo.List(1, 1000, "", "", nil)
-		if err != nil {
-			return nil, err
-		}
-		
-		totalProjects := len(projects)
-		activeProjects := 0
-		pendingProjects := 0
-		completedProjects := 0
-		inProgressProjects := 0
-		
-		for _, project := range projects {
-			switch project.Status {
-			case models.StatusActive:
-				activeProjects++
-			case models.StatusPending:
-				pendingProjects++
-			case models.StatusCompleted:
-				completedProjects++
-			case models.StatusInProgress:
-				inProgressProjects++
-			}
+	subject := policy.Subject{ID: userID, Role: userRole}
+
+	// Project figures cover the projects the role may list
+	var projects []models.Project
+	var err error
+	totalKey := "total_projects"
+	scope := s.policy.Scope(subject, policy.ProjectList)
+	switch {
+	case scope.All:
+		projects, _, err = s.projectRepo.List(1, 1000, "", "", nil, false)
+	case scope.Allows(policy.Member):
+		projects, _, err = s.projectRepo.ListByEmployee(1, 1000, "", "", userID, false)
+		totalKey = "assigned_projects"
+	case scope.Allows(policy.Client):
+		projects, _, err = s.projectRepo.List(1, 1000, "", "", &userID, false)
+	default:
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	activeProjects := 0
+	pendingProjects := 0
+	completedProjects := 0
+	inProgressProjects := 0
+
+	for _, project := range projects {
+		switch project.Status {
+		case models.StatusActive:
+			activeProjects++
+		case models.StatusPending:
+			pendingProjects++
+		case models.StatusCompleted:
+			completedProjects++
+		case models.StatusInProgress:
+			inProgressProjects++
 		}
-		
-		// Get user counts
-		totalUsers, _, err := s.userRepo.List(1, 1000, "", "")
+	}
+
+	stats[totalKey] = len(projects)
+	stats["active_projects"] = activeProjects
+	stats["pending_projects"] = pendingProjects
+	stats["completed_projects"] = completedProjects
+	stats["in_progress_projects"] = inProgressProjects
+	stats["projects"] = projects
+
+	// User counts are only shown to roles that may list all users
+	if s.policy.Scope(subject, policy.UserList).All {
+		totalUsers, _, err := s.userRepo.List(1, 1000, "", "", false)
 		if err != nil {
 			return nil, err
 		}
-		
+
 		adminUsers := 0
 		employeeUsers := 0
 		clientUsers := 0
-		
+
 		for _, user := range totalUsers {
 			switch user.Role {
 			case models.RoleAdmin:
@@ -262,18 +253,22 @@ func (s *userService) GetDashboardStats(userID string, userRole string) (map[str
 				clientUsers++
 			}
 		}
-		
-		stats["total_projects"] = totalProjects
-		stats["active_projects"] = activeProjects
-		stats["pending_projects"] = pendingProjects
-		stats["completed_projects"] = completedProjects
-		stats["in_progress_projects"] = inProgressProjects
+
 		stats["total_users"] = len(totalUsers)
 		stats["admin_users"] = adminUsers
 		stats["employee_users"] = employeeUsers
 		stats["client_users"] = clientUsers
-		stats["projects"] = projects
 	}
-	
+
 	return stats, nil
 }
+
+// revokeSessionsIfNeeded signs user out everywhere after an update that
+// changed the password or deactivated the account: neither may keep
+// existing sessions alive.
+func revokeSessionsIfNeeded(sessionRepo repositories.SessionRepository, user *models.User, req *models.UpdateUserRequest) error {
+	if req.Password == "" && (req.Status == "" || req.Status == models.UserStatusActive) {
+		return nil
+	}
+	return sessionRepo.RevokeAllForUser(user.UserID)
+}
@@ -0,0 +1,30 @@
+package services
+
+// VersionMatch is the precondition of an update to a versioned record: the
+// versions named by If-Match, or any version for "If-Match: *". The update
+// only applies while the record is at one of them.
+type VersionMatch struct {
+	Any      bool
+	Versions []int64
+}
+
+// AnyVersion matches whatever version the record is at.
+var AnyVersion = VersionMatch{Any: true}
+
+// MatchVersion matches the record only at one of versions.
+func MatchVersion(versions ...int64) VersionMatch {
+	return VersionMatch{Versions: versions}
+}
+
+// Matches reports whether a record at version satisfies the precondition.
+func (m VersionMatch) Matches(version int64) bool {
+	if m.Any {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
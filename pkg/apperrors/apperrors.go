@@ -0,0 +1,140 @@
+// Package apperrors defines the typed errors services return. Each error has a
+// kind, which decides the HTTP status, and a stable machine-readable code that
+// clients can branch on instead of the human-readable message.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error. A Kind is itself an error so callers can test for
+// it with errors.Is(err, apperrors.KindNotFound).
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindPreconditionFailed
+	KindUpstream
+)
+
+func (k Kind) Error() string {
+	switch k {
+	case KindValidation:
+		return "validation failed"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindNotFound:
+		return "not found"
+	case KindConflict:
+		return "conflict"
+	case KindUnprocessable:
+		return "unprocessable entity"
+	case KindPreconditionFailed:
+		return "precondition failed"
+	case KindUpstream:
+		return "upstream service failed"
+	default:
+		return "internal server error"
+	}
+}
+
+// Error is a typed application error. Message is safe to show to clients; Err
+// keeps the underlying cause for logging.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the error's kind, so errors.Is(err, KindForbidden) holds for every
+// forbidden error regardless of its code.
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+// Wrap returns a copy of e with cause attached.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Err = cause
+	return &wrapped
+}
+
+func newError(kind Kind, code, format string, args []interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation reports input the service cannot accept.
+func Validation(code, format string, args ...interface{}) *Error {
+	return newError(KindValidation, code, format, args)
+}
+
+// Unauthorized reports missing or invalid credentials.
+func Unauthorized(code, format string, args ...interface{}) *Error {
+	return newError(KindUnauthorized, code, format, args)
+}
+
+// Forbidden reports a caller that is known but not allowed to do something.
+func Forbidden(code, format string, args ...interface{}) *Error {
+	return newError(KindForbidden, code, format, args)
+}
+
+// NotFound reports a missing resource.
+func NotFound(code, format string, args ...interface{}) *Error {
+	return newError(KindNotFound, code, format, args)
+}
+
+// Conflict reports a request that clashes with the current state.
+func Conflict(code, format string, args ...interface{}) *Error {
+	return newError(KindConflict, code, format, args)
+}
+
+// Unprocessable reports a well-formed request that refers to something that
+// does not exist or cannot be used, such as a client ID naming an employee.
+func Unprocessable(code, format string, args ...interface{}) *Error {
+	return newError(KindUnprocessable, code, format, args)
+}
+
+// PreconditionFailed reports a conditional request whose precondition, such
+// as the version in If-Match, no longer holds.
+func PreconditionFailed(code, format string, args ...interface{}) *Error {
+	return newError(KindPreconditionFailed, code, format, args)
+}
+
+// Upstream reports a failure of an external service the request depends on,
+// such as the identity provider. Like Internal, the cause is not shown.
+func Upstream(code string, cause error) *Error {
+	return &Error{Kind: KindUpstream, Code: code, Message: KindUpstream.Error(), Err: cause}
+}
+
+// Internal hides cause behind a generic message.
+func Internal(cause error) *Error {
+	return &Error{Kind: KindInternal, Code: "INTERNAL_ERROR", Message: KindInternal.Error(), Err: cause}
+}
+
+// From returns err as an *Error. Untyped errors become internal errors.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+// The two-factor and API key routes only accept session tokens, not API keys.
+
+func (c *Client) SetupTwoFactor(ctx context.Context) (*models.TwoFactorSetupResponse, error) {
+	return callData[models.TwoFactorSetupResponse](ctx, c, request{method: http.MethodPost, path: "/api/auth/2fa/setup"})
+}
+
+// EnableTwoFactor confirms the setup with a code and returns the recovery
+// codes.
+func (c *Client) EnableTwoFactor(ctx context.Context, req *models.TwoFactorCodeRequest) (*models.RecoveryCodesResponse, error) {
+	return callData[models.RecoveryCodesResponse](ctx, c, request{method: http.MethodPost, path: "/api/auth/2fa/enable", body: req})
+}
+
+func (c *Client) DisableTwoFactor(ctx context.Context, req *models.TwoFactorCodeRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/auth/2fa/disable", body: req}, nil)
+}
+
+func (c *Client) RegenerateRecoveryCodes(ctx context.Context, req *models.TwoFactorCodeRequest) (*models.RecoveryCodesResponse, error) {
+	return callData[models.RecoveryCodesResponse](ctx, c, request{method: http.MethodPost, path: "/api/auth/2fa/recovery-codes", body: req})
+}
+
+// CreateAPIKey returns the new key; its secret is only included in this
+// response.
+func (c *Client) CreateAPIKey(ctx context.Context, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	return callData[models.CreateAPIKeyResponse](ctx, c, request{method: http.MethodPost, path: "/api/api-keys", body: req})
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return callList[models.APIKey](ctx, c, request{method: http.MethodGet, path: "/api/api-keys"})
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/api-keys/%s", id)}, nil)
+}
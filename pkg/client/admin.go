@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+func (c *Client) CreateInvitation(ctx context.Context, req *models.CreateInvitationRequest) (*models.Invitation, error) {
+	return callData[models.Invitation](ctx, c, request{method: http.MethodPost, path: "/api/invitations", body: req})
+}
+
+func (c *Client) ListInvitations(ctx context.Context, query models.InvitationQuery) (*Page[models.Invitation], error) {
+	values := pageValues(query.Page, query.PageSize)
+	setValue(values, "search", query.Search)
+	setValue(values, "status", query.Status)
+	return callPage[models.Invitation](ctx, c, request{method: http.MethodGet, path: "/api/invitations", query: values})
+}
+
+// IterateInvitations walks every invitation matching query, starting at
+// query.Page.
+func (c *Client) IterateInvitations(query models.InvitationQuery) *Iterator[models.Invitation] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.Invitation], error) {
+		query.Page = page
+		return c.ListInvitations(ctx, query)
+	})
+}
+
+func (c *Client) ResendInvitation(ctx context.Context, id string) (*models.Invitation, error) {
+	return callData[models.Invitation](ctx, c, request{method: http.MethodPost, path: pathf("/api/invitations/%s/resend", id)})
+}
+
+func (c *Client) RevokeInvitation(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/invitations/%s", id)}, nil)
+}
+
+// ListPendingRegistrations returns the accounts waiting for an admin to
+// approve them, oldest first.
+func (c *Client) ListPendingRegistrations(ctx context.Context, query models.RegistrationQuery) (*Page[models.User], error) {
+	values := pageValues(query.Page, query.PageSize)
+	return callPage[models.User](ctx, c, request{method: http.MethodGet, path: "/api/registrations", query: values})
+}
+
+// IteratePendingRegistrations walks every account waiting for approval,
+// starting at query.Page.
+func (c *Client) IteratePendingRegistrations(query models.RegistrationQuery) *Iterator[models.User] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.User], error) {
+		query.Page = page
+		return c.ListPendingRegistrations(ctx, query)
+	})
+}
+
+func (c *Client) ApproveRegistration(ctx context.Context, id string) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPost, path: pathf("/api/registrations/%s/approve", id)})
+}
+
+func (c *Client) RejectRegistration(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodPost, path: pathf("/api/registrations/%s/reject", id)}, nil)
+}
+
+// ListLockedAccounts returns the accounts that are currently locked out.
+func (c *Client) ListLockedAccounts(ctx context.Context) ([]models.LoginAttempt, error) {
+	return callList[models.LoginAttempt](ctx, c, request{method: http.MethodGet, path: "/api/lockouts"})
+}
+
+func (c *Client) ListLockoutEvents(ctx context.Context, query models.LockoutEventQuery) (*Page[models.LockoutEvent], error) {
+	values := pageValues(query.Page, query.PageSize)
+	setValue(values, "email", query.Email)
+	setValue(values, "type", query.Type)
+	return callPage[models.LockoutEvent](ctx, c, request{method: http.MethodGet, path: "/api/lockouts/events", query: values})
+}
+
+// IterateLockoutEvents walks every lockout event matching query, starting at
+// query.Page.
+func (c *Client) IterateLockoutEvents(query models.LockoutEventQuery) *Iterator[models.LockoutEvent] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.LockoutEvent], error) {
+		query.Page = page
+		return c.ListLockoutEvents(ctx, query)
+	})
+}
+
+func (c *Client) UnlockAccount(ctx context.Context, req *models.UnlockAccountRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/lockouts/unlock", body: req}, nil)
+}
+
+func (c *Client) ListAuditEvents(ctx context.Context, query models.AuditQuery) (*Page[models.AuditEvent], error) {
+	values := pageValues(query.Page, query.PageSize)
+	setValue(values, "actor_id", query.ActorID)
+	setValue(values, "action", query.Action)
+	setValue(values, "resource_type", query.ResourceType)
+	setValue(values, "resource_id", query.ResourceID)
+	if !query.From.IsZero() {
+		values.Set("from", query.From.Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		values.Set("to", query.To.Format(time.RFC3339))
+	}
+	return callPage[models.AuditEvent](ctx, c, request{method: http.MethodGet, path: "/api/audit", query: values})
+}
+
+// IterateAuditEvents walks every audit event matching query, newest first,
+// starting at query.Page.
+func (c *Client) IterateAuditEvents(query models.AuditQuery) *Iterator[models.AuditEvent] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.AuditEvent], error) {
+		query.Page = page
+		return c.ListAuditEvents(ctx, query)
+	})
+}
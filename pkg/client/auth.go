@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+func (c *Client) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPost, path: "/api/auth/register", body: req, public: true})
+}
+
+// Login authenticates with email and password and keeps the returned tokens
+// for later calls. When the response asks for a second factor, finish with
+// LoginTwoFactor or LoginTwoFactorSetup.
+func (c *Client) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	return c.login(ctx, "/api/auth/login", req)
+}
+
+// LoginTwoFactor completes a login with a TOTP or recovery code.
+func (c *Client) LoginTwoFactor(ctx context.Context, req *models.TwoFactorLoginRequest) (*models.LoginResponse, error) {
+	return c.login(ctx, "/api/auth/login/2fa", req)
+}
+
+// LoginTwoFactorSetup starts the mandatory two-factor enrolment of a login;
+// confirm it with LoginTwoFactor.
+func (c *Client) LoginTwoFactorSetup(ctx context.Context, req *models.TwoFactorChallengeRequest) (*models.TwoFactorSetupResponse, error) {
+	return callData[models.TwoFactorSetupResponse](ctx, c, request{method: http.MethodPost, path: "/api/auth/login/2fa/setup", body: req, public: true})
+}
+
+// Refresh exchanges the current refresh token for new tokens. Calls that fail
+// with 401 do this automatically.
+func (c *Client) Refresh(ctx context.Context) error {
+	return c.refresh(ctx, c.Tokens().AccessToken)
+}
+
+// Logout revokes the current session and forgets its tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/auth/logout"}, nil); err != nil {
+		return err
+	}
+	c.SetTokens(Tokens{})
+	return nil
+}
+
+func (c *Client) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/auth/verify-email", body: req, public: true}, nil)
+}
+
+func (c *Client) ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/auth/resend-verification", body: req, public: true}, nil)
+}
+
+func (c *Client) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/auth/forgot-password", body: req, public: true}, nil)
+}
+
+func (c *Client) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/auth/reset-password", body: req, public: true}, nil)
+}
+
+// AcceptInvitation creates the invited account.
+func (c *Client) AcceptInvitation(ctx context.Context, req *models.AcceptInvitationRequest) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPost, path: "/api/auth/accept-invite", body: req, public: true})
+}
+
+// OIDCLogin returns the identity provider URL to send the user to.
+func (c *Client) OIDCLogin(ctx context.Context) (*models.OIDCLoginResponse, error) {
+	return callData[models.OIDCLoginResponse](ctx, c, request{method: http.MethodGet, path: "/api/auth/oidc/login", public: true})
+}
+
+// OIDCCallback completes a single sign-on login with the parameters the
+// identity provider redirected back with and keeps the returned tokens.
+func (c *Client) OIDCCallback(ctx context.Context, req *models.OIDCCallbackRequest) (*models.LoginResponse, error) {
+	query := url.Values{}
+	setValue(query, "code", req.Code)
+	setValue(query, "state", req.State)
+	setValue(query, "error", req.Error)
+	setValue(query, "error_description", req.ErrorDescription)
+
+	login, err := callData[models.LoginResponse](ctx, c, request{method: http.MethodGet, path: "/api/auth/oidc/callback", query: query, public: true})
+	if err != nil {
+		return nil, err
+	}
+	c.storeLogin(login)
+	return login, nil
+}
+
+func (c *Client) login(ctx context.Context, path string, req interface{}) (*models.LoginResponse, error) {
+	login, err := callData[models.LoginResponse](ctx, c, request{method: http.MethodPost, path: path, body: req, public: true})
+	if err != nil {
+		return nil, err
+	}
+	c.storeLogin(login)
+	return login, nil
+}
@@ -0,0 +1,424 @@
+// Package client is a Go SDK for the Vinodhini Software API. It covers every
+// route registered by routes.SetupRoutes, decodes responses into the models
+// types the server uses, refreshes expired session tokens, backs off when it
+// is rate limited and pages through list endpoints.
+//
+//	c := client.New("https://api.example.com")
+//	if _, err := c.Login(ctx, &models.LoginRequest{Email: email, Password: password}); err != nil {
+//		return err
+//	}
+//	it := c.IterateProjects(models.PaginationQuery{Status: "active"})
+//	for it.Next(ctx) {
+//		fmt.Println(it.Value().Name)
+//	}
+//	return it.Err()
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Tokens are the credentials of a logged in session.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// apiKey, when set, is sent instead of session tokens and never refreshed
+	apiKey string
+
+	mu             sync.Mutex
+	tokens         Tokens
+	onTokenRefresh func(Tokens)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTokens starts the client with the tokens of an existing session.
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithAPIKey authenticates every request with a personal API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTokenRefreshHandler registers a function called with the new tokens
+// whenever the client logs in or refreshes the session, so callers can
+// persist them.
+func WithTokenRefreshHandler(fn func(Tokens)) Option {
+	return func(c *Client) {
+		c.onTokenRefresh = fn
+	}
+}
+
+// WithRetry sets how often a rate-limited request is retried and the bounds
+// of the exponential backoff between attempts. A Retry-After longer than
+// maxBackoff is returned to the caller instead of waited out.
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithUserAgent sets the User-Agent header, which the server records on
+// sessions.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New returns a client for the API served at baseURL, e.g.
+// https://api.example.com.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		userAgent:  "vinodhini-go-client",
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tokens returns the current session tokens.
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// SetTokens replaces the session tokens.
+func (c *Client) SetTokens(tokens Tokens) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = tokens
+}
+
+// Error is returned for every response that is not a success. Code is the
+// stable error code from the response body, Details the rejected fields of a
+// VALIDATION_FAILED error.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    []utils.FieldError
+	// RetryAfter is set when the server asked the client to wait
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+}
+
+// StatusCode returns the HTTP status of an *Error in err's chain, or 0.
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// public requests are sent without credentials
+	public bool
+	// ifMatch, when set, is sent as If-Match so the write only applies to
+	// the version of the record the caller last read
+	ifMatch string
+}
+
+// etag formats a record version the way the server sends it in ETag.
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// call performs req and decodes the data of the Response envelope into out.
+func (c *Client) call(ctx context.Context, req request, out interface{}) error {
+	resp := utils.Response{Data: out}
+	return c.do(ctx, req, &resp)
+}
+
+// callData performs req and returns its decoded data.
+func callData[T any](ctx context.Context, c *Client, req request) (*T, error) {
+	var out T
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// callList performs req and returns the list in its data.
+func callList[T any](ctx context.Context, c *Client, req request) ([]T, error) {
+	var out []T
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// callPage performs req and decodes a PaginatedResponse into page.
+func callPage[T any](ctx context.Context, c *Client, req request) (*Page[T], error) {
+	page := &Page[T]{}
+	resp := utils.PaginatedResponse{Data: &page.Items}
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	page.Meta = resp.Meta
+	return page, nil
+}
+
+// do sends req, refreshing the session once on 401 and retrying with backoff
+// on 429 and 503, and decodes the body into out.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return err
+		}
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		scheme, credential, session := c.credential(req)
+
+		resp, err := c.send(ctx, req, body, scheme, credential)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode < http.StatusBadRequest {
+			if out == nil || len(data) == 0 {
+				return nil
+			}
+			return json.Unmarshal(data, out)
+		}
+
+		apiErr := decodeError(resp, data)
+
+		if resp.StatusCode == http.StatusUnauthorized && session && !refreshed {
+			refreshed = true
+			if err := c.refresh(ctx, credential); err != nil {
+				return apiErr
+			}
+			attempt--
+			continue
+		}
+
+		if (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) && attempt < c.maxRetries {
+			wait := c.backoff(attempt)
+			if apiErr.RetryAfter > 0 {
+				wait = apiErr.RetryAfter
+			}
+			if wait > c.maxBackoff {
+				return apiErr
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return apiErr
+	}
+}
+
+// credential returns the Authorization scheme and credential for req and
+// whether it is a session access token that can be refreshed. API keys go
+// under the ApiKey scheme, session tokens under Bearer.
+func (c *Client) credential(req request) (string, string, bool) {
+	if req.public {
+		return "", "", false
+	}
+	if c.apiKey != "" {
+		return "ApiKey", c.apiKey, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return "Bearer", c.tokens.AccessToken, c.tokens.RefreshToken != ""
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte, scheme, credential string) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if req.ifMatch != "" {
+		httpReq.Header.Set("If-Match", req.ifMatch)
+	}
+	if credential != "" {
+		httpReq.Header.Set("Authorization", scheme+" "+credential)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// refresh exchanges the refresh token for new tokens. Refresh tokens rotate,
+// so concurrent callers are serialised and only the first one whose access
+// token went stale performs the exchange.
+func (c *Client) refresh(ctx context.Context, stale string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokens.AccessToken != stale {
+		return nil
+	}
+	if c.tokens.RefreshToken == "" {
+		return errors.New("no refresh token")
+	}
+
+	var login models.LoginResponse
+	req := request{
+		method: http.MethodPost,
+		path:   "/api/auth/refresh",
+		body:   models.RefreshTokenRequest{RefreshToken: c.tokens.RefreshToken},
+		public: true,
+	}
+	if err := c.call(ctx, req, &login); err != nil {
+		return err
+	}
+
+	c.tokens = Tokens{AccessToken: login.Token, RefreshToken: login.RefreshToken}
+	if c.onTokenRefresh != nil {
+		c.onTokenRefresh(c.tokens)
+	}
+	return nil
+}
+
+// storeLogin keeps the tokens of a completed login. Logins that still need a
+// second factor carry no tokens and leave the session untouched.
+func (c *Client) storeLogin(login *models.LoginResponse) {
+	if login.Token == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = Tokens{AccessToken: login.Token, RefreshToken: login.RefreshToken}
+	if c.onTokenRefresh != nil {
+		c.onTokenRefresh(c.tokens)
+	}
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := time.Duration(float64(c.minBackoff) * math.Pow(2, float64(attempt)))
+	if wait > c.maxBackoff || wait <= 0 {
+		return c.maxBackoff
+	}
+	return wait
+}
+
+func decodeError(resp *http.Response, data []byte) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	var body utils.Response
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Error
+		apiErr.Details = body.Details
+	} else {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pathf builds a path from a format and escaped ID segments.
+func pathf(format string, ids ...string) string {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = url.PathEscape(id)
+	}
+	return fmt.Sprintf(format, args...)
+}
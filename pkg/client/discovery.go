@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vinodhini/software-api/internal/openapi"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// These endpoints are served without the Response envelope. The /api/docs
+// page and its script are meant for browsers and have no method here.
+
+// JWKS returns the public keys that verify access tokens.
+func (c *Client) JWKS(ctx context.Context) (*utils.JWKS, error) {
+	var keys utils.JWKS
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/.well-known/jwks.json", public: true}, &keys); err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+// OpenAPI returns the OpenAPI specification of the server.
+func (c *Client) OpenAPI(ctx context.Context) (*openapi.Document, error) {
+	var doc openapi.Document
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/openapi.json", public: true}, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
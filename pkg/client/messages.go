@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+func (c *Client) CreateMessage(ctx context.Context, req *models.CreateMessageRequest) (*models.Message, error) {
+	return callData[models.Message](ctx, c, request{method: http.MethodPost, path: "/api/messages", body: req})
+}
+
+// ListMessages returns the messages of every project the caller can read.
+func (c *Client) ListMessages(ctx context.Context, page, pageSize int) (*Page[models.Message], error) {
+	return callPage[models.Message](ctx, c, request{method: http.MethodGet, path: "/api/messages", query: pageValues(page, pageSize)})
+}
+
+// IterateMessages walks every message the caller can read.
+func (c *Client) IterateMessages(pageSize int) *Iterator[models.Message] {
+	return newIterator(1, func(ctx context.Context, page int) (*Page[models.Message], error) {
+		return c.ListMessages(ctx, page, pageSize)
+	})
+}
+
+func (c *Client) ListProjectMessages(ctx context.Context, projectID string, page, pageSize int) (*Page[models.Message], error) {
+	return callPage[models.Message](ctx, c, request{method: http.MethodGet, path: pathf("/api/projects/%s/messages", projectID), query: pageValues(page, pageSize)})
+}
+
+// IterateProjectMessages walks every message of a project.
+func (c *Client) IterateProjectMessages(projectID string, pageSize int) *Iterator[models.Message] {
+	return newIterator(1, func(ctx context.Context, page int) (*Page[models.Message], error) {
+		return c.ListProjectMessages(ctx, projectID, page, pageSize)
+	})
+}
+
+func (c *Client) GetMessage(ctx context.Context, id string) (*models.Message, error) {
+	return callData[models.Message](ctx, c, request{method: http.MethodGet, path: pathf("/api/messages/%s", id)})
+}
+
+func (c *Client) DeleteMessage(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/messages/%s", id)}, nil)
+}
+
+func (c *Client) RestoreMessage(ctx context.Context, id string) (*models.Message, error) {
+	return callData[models.Message](ctx, c, request{method: http.MethodPost, path: pathf("/api/messages/%s/restore", id)})
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// Page is one page of a list endpoint.
+type Page[T any] struct {
+	Items []T
+	Meta  utils.Pagination
+}
+
+// HasNext reports whether later pages exist.
+func (p *Page[T]) HasNext() bool {
+	return p.Meta.Page < p.Meta.TotalPage
+}
+
+// Iterator walks every item of a list endpoint, fetching pages as it goes.
+// Use it like a scanner:
+//
+//	for it.Next(ctx) {
+//		item := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	fetch func(ctx context.Context, page int) (*Page[T], error)
+	page  *Page[T]
+	next  int
+	index int
+	err   error
+}
+
+func newIterator[T any](firstPage int, fetch func(ctx context.Context, page int) (*Page[T], error)) *Iterator[T] {
+	if firstPage < 1 {
+		firstPage = 1
+	}
+	return &Iterator[T]{fetch: fetch, next: firstPage}
+}
+
+// Next advances to the next item and reports whether there is one.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.page != nil && it.index+1 < len(it.page.Items) {
+		it.index++
+		return true
+	}
+	if it.page != nil && !it.page.HasNext() {
+		return false
+	}
+
+	// Skip pages that come back empty until the last one
+	for {
+		page, err := it.fetch(ctx, it.next)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = page, 0
+		it.next++
+		if len(page.Items) > 0 {
+			return true
+		}
+		if !page.HasNext() {
+			return false
+		}
+	}
+}
+
+// Value returns the current item.
+func (it *Iterator[T]) Value() T {
+	return it.page.Items[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All collects the remaining items.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for it.Next(ctx) {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
+
+func paginationValues(query models.PaginationQuery) url.Values {
+	values := pageValues(query.Page, query.PageSize)
+	setValue(values, "search", query.Search)
+	setValue(values, "status", query.Status)
+	if query.IncludeDeleted {
+		values.Set("include_deleted", "true")
+	}
+	return values
+}
+
+func pageValues(page, pageSize int) url.Values {
+	values := url.Values{}
+	if page > 0 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		values.Set("page_size", strconv.Itoa(pageSize))
+	}
+	return values
+}
+
+func setValue(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+func (c *Client) CreateProject(ctx context.Context, req *models.CreateProjectRequest) (*models.Project, error) {
+	return callData[models.Project](ctx, c, request{method: http.MethodPost, path: "/api/projects", body: req})
+}
+
+func (c *Client) ListProjects(ctx context.Context, query models.PaginationQuery) (*Page[models.Project], error) {
+	return callPage[models.Project](ctx, c, request{method: http.MethodGet, path: "/api/projects", query: paginationValues(query)})
+}
+
+// IterateProjects walks every project matching query, starting at query.Page.
+func (c *Client) IterateProjects(query models.PaginationQuery) *Iterator[models.Project] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.Project], error) {
+		query.Page = page
+		return c.ListProjects(ctx, query)
+	})
+}
+
+func (c *Client) GetProject(ctx context.Context, id string) (*models.Project, error) {
+	return callData[models.Project](ctx, c, request{method: http.MethodGet, path: pathf("/api/projects/%s", id)})
+}
+
+// UpdateProject applies req if the project is still at version, the Version
+// of the copy the caller last read. Otherwise it fails with status 412.
+func (c *Client) UpdateProject(ctx context.Context, id string, version int64, req *models.UpdateProjectRequest) (*models.Project, error) {
+	return callData[models.Project](ctx, c, request{method: http.MethodPut, path: pathf("/api/projects/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/projects/%s", id)}, nil)
+}
+
+func (c *Client) RestoreProject(ctx context.Context, id string) (*models.Project, error) {
+	return callData[models.Project](ctx, c, request{method: http.MethodPost, path: pathf("/api/projects/%s/restore", id)})
+}
+
+func (c *Client) AssignEmployees(ctx context.Context, projectID string, req *models.AssignEmployeesRequest) error {
+	return c.call(ctx, request{method: http.MethodPost, path: pathf("/api/projects/%s/assign", projectID), body: req}, nil)
+}
+
+func (c *Client) UpdateProjectProgress(ctx context.Context, id string, version int64, req *models.UpdateProjectProgressRequest) (*models.Project, error) {
+	return callData[models.Project](ctx, c, request{method: http.MethodPatch, path: pathf("/api/projects/%s/progress", id), body: req, ifMatch: etag(version)})
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+func (c *Client) CreateServiceRequest(ctx context.Context, req *models.CreateServiceRequestRequest) (*models.ServiceRequest, error) {
+	return callData[models.ServiceRequest](ctx, c, request{method: http.MethodPost, path: "/api/service-requests", body: req})
+}
+
+func (c *Client) ListServiceRequests(ctx context.Context, query models.PaginationQuery) (*Page[models.ServiceRequest], error) {
+	return callPage[models.ServiceRequest](ctx, c, request{method: http.MethodGet, path: "/api/service-requests", query: paginationValues(query)})
+}
+
+// IterateServiceRequests walks every service request matching query, starting
+// at query.Page.
+func (c *Client) IterateServiceRequests(query models.PaginationQuery) *Iterator[models.ServiceRequest] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.ServiceRequest], error) {
+		query.Page = page
+		return c.ListServiceRequests(ctx, query)
+	})
+}
+
+func (c *Client) GetServiceRequest(ctx context.Context, id string) (*models.ServiceRequest, error) {
+	return callData[models.ServiceRequest](ctx, c, request{method: http.MethodGet, path: pathf("/api/service-requests/%s", id)})
+}
+
+func (c *Client) UpdateServiceRequest(ctx context.Context, id string, version int64, req *models.UpdateServiceRequestRequest) (*models.ServiceRequest, error) {
+	return callData[models.ServiceRequest](ctx, c, request{method: http.MethodPut, path: pathf("/api/service-requests/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) DeleteServiceRequest(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/service-requests/%s", id)}, nil)
+}
+
+func (c *Client) RestoreServiceRequest(ctx context.Context, id string) (*models.ServiceRequest, error) {
+	return callData[models.ServiceRequest](ctx, c, request{method: http.MethodPost, path: pathf("/api/service-requests/%s/restore", id)})
+}
+
+// ApproveServiceRequest approves a pending request and returns the project
+// created for it.
+func (c *Client) ApproveServiceRequest(ctx context.Context, id string, req *models.ApproveServiceRequestRequest) (*models.Project, error) {
+	return callData[models.Project](ctx, c, request{method: http.MethodPost, path: pathf("/api/service-requests/%s/approve", id), body: req})
+}
+
+func (c *Client) RejectServiceRequest(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodPost, path: pathf("/api/service-requests/%s/reject", id)}, nil)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+// ListServiceTypes returns the service types with the given status, or all of
+// them when status is empty. It needs no credentials.
+func (c *Client) ListServiceTypes(ctx context.Context, status string) ([]models.ServiceType, error) {
+	query := url.Values{}
+	setValue(query, "status", status)
+	return callList[models.ServiceType](ctx, c, request{method: http.MethodGet, path: "/api/service-types", query: query, public: true})
+}
+
+func (c *Client) CreateServiceType(ctx context.Context, req *models.CreateServiceTypeRequest) (*models.ServiceType, error) {
+	return callData[models.ServiceType](ctx, c, request{method: http.MethodPost, path: "/api/service-types", body: req})
+}
+
+func (c *Client) GetServiceType(ctx context.Context, id string) (*models.ServiceType, error) {
+	return callData[models.ServiceType](ctx, c, request{method: http.MethodGet, path: pathf("/api/service-types/%s", id)})
+}
+
+func (c *Client) UpdateServiceType(ctx context.Context, id string, req *models.UpdateServiceTypeRequest) (*models.ServiceType, error) {
+	return callData[models.ServiceType](ctx, c, request{method: http.MethodPut, path: pathf("/api/service-types/%s", id), body: req})
+}
+
+func (c *Client) DeleteServiceType(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/service-types/%s", id)}, nil)
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vinodhini/software-api/pkg/models"
+)
+
+// UserQuery filters ListUsers. Role limits the result to one role.
+type UserQuery struct {
+	models.PaginationQuery
+	Role string
+}
+
+func (c *Client) ListUsers(ctx context.Context, query UserQuery) (*Page[models.User], error) {
+	values := paginationValues(query.PaginationQuery)
+	setValue(values, "role", query.Role)
+	return callPage[models.User](ctx, c, request{method: http.MethodGet, path: "/api/users", query: values})
+}
+
+// IterateUsers walks every user matching query, starting at query.Page.
+func (c *Client) IterateUsers(query UserQuery) *Iterator[models.User] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.User], error) {
+		query.Page = page
+		return c.ListUsers(ctx, query)
+	})
+}
+
+func (c *Client) GetUser(ctx context.Context, id string) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodGet, path: pathf("/api/users/%s", id)})
+}
+
+func (c *Client) UpdateUser(ctx context.Context, id string, version int64, req *models.UpdateUserRequest) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPut, path: pathf("/api/users/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) PatchUser(ctx context.Context, id string, version int64, req *models.UpdateUserRequest) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPatch, path: pathf("/api/users/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/users/%s", id)}, nil)
+}
+
+// RestoreUser undeletes a user; clients and employees are restored this way
+// too.
+func (c *Client) RestoreUser(ctx context.Context, id string) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPost, path: pathf("/api/users/%s/restore", id)})
+}
+
+// DashboardStats returns the dashboard figures of the current user; the keys
+// depend on the role.
+func (c *Client) DashboardStats(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/users/dashboard/stats"}, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *Client) CreateEmployee(ctx context.Context, req *models.CreateEmployeeRequest) (*models.EmployeeResponse, error) {
+	return callData[models.EmployeeResponse](ctx, c, request{method: http.MethodPost, path: "/api/employees", body: req})
+}
+
+func (c *Client) ListEmployees(ctx context.Context, query models.PaginationQuery) (*Page[models.EmployeeResponse], error) {
+	return callPage[models.EmployeeResponse](ctx, c, request{method: http.MethodGet, path: "/api/employees", query: paginationValues(query)})
+}
+
+// IterateEmployees walks every employee matching query, starting at query.Page.
+func (c *Client) IterateEmployees(query models.PaginationQuery) *Iterator[models.EmployeeResponse] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.EmployeeResponse], error) {
+		query.Page = page
+		return c.ListEmployees(ctx, query)
+	})
+}
+
+func (c *Client) GetEmployee(ctx context.Context, id string) (*models.EmployeeResponse, error) {
+	return callData[models.EmployeeResponse](ctx, c, request{method: http.MethodGet, path: pathf("/api/employees/%s", id)})
+}
+
+func (c *Client) UpdateEmployee(ctx context.Context, id string, version int64, req *models.UpdateUserRequest) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPut, path: pathf("/api/employees/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) PatchEmployee(ctx context.Context, id string, version int64, req *models.UpdateUserRequest) (*models.User, error) {
+	return callData[models.User](ctx, c, request{method: http.MethodPatch, path: pathf("/api/employees/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) DeleteEmployee(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/employees/%s", id)}, nil)
+}
+
+func (c *Client) CreateClient(ctx context.Context, req *models.CreateClientRequest) (*models.ClientResponse, error) {
+	return callData[models.ClientResponse](ctx, c, request{method: http.MethodPost, path: "/api/clients", body: req})
+}
+
+func (c *Client) ListClients(ctx context.Context, query models.PaginationQuery) (*Page[models.ClientResponse], error) {
+	return callPage[models.ClientResponse](ctx, c, request{method: http.MethodGet, path: "/api/clients", query: paginationValues(query)})
+}
+
+// IterateClients walks every client matching query, starting at query.Page.
+func (c *Client) IterateClients(query models.PaginationQuery) *Iterator[models.ClientResponse] {
+	return newIterator(query.Page, func(ctx context.Context, page int) (*Page[models.ClientResponse], error) {
+		query.Page = page
+		return c.ListClients(ctx, query)
+	})
+}
+
+func (c *Client) GetClient(ctx context.Context, id string) (*models.ClientResponse, error) {
+	return callData[models.ClientResponse](ctx, c, request{method: http.MethodGet, path: pathf("/api/clients/%s", id)})
+}
+
+func (c *Client) UpdateClient(ctx context.Context, id string, version int64, req *models.UpdateUserRequest) (*models.ClientResponse, error) {
+	return callData[models.ClientResponse](ctx, c, request{method: http.MethodPut, path: pathf("/api/clients/%s", id), body: req, ifMatch: etag(version)})
+}
+
+func (c *Client) DeleteClient(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: pathf("/api/clients/%s", id)}, nil)
+}
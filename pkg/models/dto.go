@@ -0,0 +1,293 @@
+package models
+
+import "time"
+
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Name     string `json:"name" binding:"required"`
+	// Role defaults to client
+	Role Role `json:"role" binding:"omitempty,oneof=admin employee client"`
+}
+
+// CreateAdminRequest bootstraps an admin account from the admin CLI; there is
+// no HTTP route for it.
+type CreateAdminRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Name     string `json:"name" binding:"required"`
+}
+
+type CreateEmployeeRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Phone    string `json:"phone" binding:"required"`
+	Role     string `json:"role"`
+	Department string `json:"department" binding:"required"`
+	Salary   int    `json:"salary" binding:"required,min=0"`
+	Password string `json:"password" binding:"required,min=6"`
+	Status   string `json:"status" binding:"required,oneof=active inactive"`
+}
+
+type CreateClientRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Phone    string `json:"phone" binding:"required"`
+	Company  string `json:"company" binding:"required"`
+	Address  string `json:"address" binding:"required"`
+	Role     string `json:"role"`
+	Password string `json:"password" binding:"required,min=6"`
+	Status   string `json:"status" binding:"required,oneof=active inactive"`
+}
+
+type ClientResponse struct {
+	ID       string `json:"user_id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Company  string `json:"company"`
+	Address  string `json:"address"`
+	Role     string `json:"role"`
+	Status   string `json:"status"`
+	Hide     bool   `json:"hide"`
+	Version  int64  `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type EmployeeResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+	Status     string `json:"status"`
+	Phone      string `json:"phone,omitempty"`
+	Department string `json:"department,omitempty"`
+	Salary     int    `json:"salary,omitempty"`
+	Version    int64  `json:"version"`
+}
+
+type CreateInvitationRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	Role       Role   `json:"role" binding:"required,oneof=admin employee client"`
+	Name       string `json:"name" binding:"required"`
+	Phone      string `json:"phone"`
+	Department string `json:"department"`
+	Company    string `json:"company"`
+	Address    string `json:"address"`
+	Salary     int    `json:"salary" binding:"omitempty,min=0"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginResponse struct {
+	Token                  string   `json:"token,omitempty"`
+	RefreshToken           string   `json:"refresh_token,omitempty"`
+	ExpiresIn              int64    `json:"expires_in,omitempty"`
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"`
+	ChallengeToken         string   `json:"challenge_token,omitempty"`
+	RecoveryCodes          []string `json:"recovery_codes,omitempty"`
+	User                   User     `json:"user"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OIDCCallbackRequest carries the query parameters the identity provider
+// appends to the redirect URL.
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type UpdateUserRequest struct {
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty" binding:"omitempty,email"`
+	Phone     string `json:"phone,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Department string `json:"department,omitempty"`
+	Company   string `json:"company,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Salary    int    `json:"salary,omitempty"`
+	Password  string `json:"password,omitempty"`
+	Status    string `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+	Hide      *bool  `json:"hide,omitempty"`
+}
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	ClientID    string `json:"client_id" binding:"required"`
+	Status      Status `json:"status" binding:"omitempty,oneof=active pending completed in_progress"`
+	EmployeeIDs []string `json:"employee_ids,omitempty"`
+}
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      Status `json:"status,omitempty" binding:"omitempty,oneof=active pending completed rejected in_progress"`
+}
+
+type AssignEmployeesRequest struct {
+	EmployeeIDs []string `json:"employee_ids" binding:"required"`
+}
+
+type UpdateProjectProgressRequest struct {
+	Progress int `json:"progress" binding:"required,min=0,max=100"`
+}
+
+type CreateServiceRequestRequest struct {
+	Title       string  `json:"title" binding:"required"`
+	Description string  `json:"description"`
+	ProjectID   *string `json:"project_id,omitempty"`
+}
+
+type UpdateServiceRequestRequest struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Status      Status  `json:"status,omitempty" binding:"omitempty,oneof=active pending completed rejected"`
+	ProjectID   *string `json:"project_id,omitempty"`
+}
+
+type ApproveServiceRequestRequest struct {
+	EmployeeIDs []string `json:"employee_ids" binding:"required"`
+}
+
+type CreateMessageRequest struct {
+	Content   string `json:"content" binding:"required"`
+	ProjectID string `json:"project_id" binding:"required"`
+}
+
+type PaginationQuery struct {
+	Page     int    `form:"page,default=1" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size,default=10" binding:"omitempty,min=1,max=100"`
+	Search   string `form:"search"`
+	Status   string `form:"status" binding:"omitempty,oneof=active pending completed rejected"`
+	// IncludeDeleted lists soft-deleted records too; it is reserved to roles
+	// that may restore them
+	IncludeDeleted bool `form:"include_deleted"`
+}
+
+type InvitationQuery struct {
+	Page     int    `form:"page,default=1" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size,default=10" binding:"omitempty,min=1,max=100"`
+	Search   string `form:"search"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending accepted revoked"`
+}
+
+type RegistrationQuery struct {
+	Page     int `form:"page,default=1" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size,default=10" binding:"omitempty,min=1,max=100"`
+}
+
+type LockoutEventQuery struct {
+	Page     int    `form:"page,default=1" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size,default=10" binding:"omitempty,min=1,max=100"`
+	Email    string `form:"email"`
+	Type     string `form:"type" binding:"omitempty,oneof=locked unlocked"`
+}
+
+type AuditQuery struct {
+	Page         int       `form:"page,default=1" binding:"omitempty,min=1"`
+	PageSize     int       `form:"page_size,default=10" binding:"omitempty,min=1,max=100"`
+	ActorID      string    `form:"actor_id"`
+	Action       string    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// CreateAPIKeyResponse is the only response that contains the raw key.
+type CreateAPIKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
+
+type UnlockAccountRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type CreateServiceTypeRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Status      Status `json:"status" binding:"required,oneof=active inactive"`
+}
+
+type UpdateServiceTypeRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      Status `json:"status,omitempty" binding:"omitempty,oneof=active inactive"`
+}
+
+// DataExport holds every record the admin CLI exports and imports. It is
+// written as MongoDB extended JSON, so password hashes and two-factor
+// secrets, which the API never shows, are kept.
+type DataExport struct {
+	ExportedAt      time.Time        `bson:"exported_at"`
+	Users           []User           `bson:"users"`
+	Projects        []Project        `bson:"projects"`
+	ServiceRequests []ServiceRequest `bson:"service_requests"`
+	Messages        []Message        `bson:"messages"`
+	ServiceTypes    []ServiceType    `bson:"service_types"`
+}
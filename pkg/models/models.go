@@ -0,0 +1,305 @@
+package models
+
+import (
+	"time"
+)
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleEmployee Role = "employee"
+	RoleClient   Role = "client"
+)
+
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusPending   Status = "pending"
+	StatusCompleted Status = "completed"
+	StatusRejected  Status = "rejected"
+	StatusInProgress Status = "in_progress"
+)
+
+const (
+	UserStatusActive     = "active"
+	UserStatusInactive   = "inactive"
+	UserStatusUnverified = "unverified"
+	// UserStatusPendingApproval is a verified registration waiting for an
+	// admin, when registrations need approval
+	UserStatusPendingApproval = "pending_approval"
+)
+
+type User struct {
+	UserID    string             `bson:"_id,omitempty" json:"user_id"`
+	Email     string             `bson:"email" json:"email"`
+	Password  string             `bson:"password" json:"-"`
+	Name      string             `bson:"name" json:"name"`
+	Phone     string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Role      Role               `bson:"role" json:"role"`
+	Department string            `bson:"department,omitempty" json:"department,omitempty"`
+	Company   string             `bson:"company,omitempty" json:"company,omitempty"`
+	Address   string             `bson:"address,omitempty" json:"address,omitempty"`
+	Salary    int                `bson:"salary,omitempty" json:"salary,omitempty"`
+	Status    string             `bson:"status,omitempty" json:"status,omitempty"`
+	Hide      bool               `bson:"hide,omitempty" json:"hide,omitempty"`
+	EmailVerifiedAt *time.Time   `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	TwoFactorEnabled       bool     `bson:"two_factor_enabled,omitempty" json:"two_factor_enabled,omitempty"`
+	TwoFactorSecret        string   `bson:"two_factor_secret,omitempty" json:"-"`
+	TwoFactorPendingSecret string   `bson:"two_factor_pending_secret,omitempty" json:"-"`
+	TwoFactorLastStep      int64    `bson:"two_factor_last_step,omitempty" json:"-"`
+	RecoveryCodes          []string `bson:"recovery_codes,omitempty" json:"-"`
+	// Version is bumped by every write and sent as the ETag
+	Version   int64              `bson:"version" json:"version"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string             `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+type Project struct {
+	ID          string   `bson:"_id" json:"id"`
+	Name        string   `bson:"name" json:"name"`
+	Description string   `bson:"description" json:"description"`
+	ClientID    string   `bson:"client_id" json:"client_id"`
+	Client      *User    `bson:"-" json:"client,omitempty"`
+	Status      Status   `bson:"status" json:"status"`
+	Progress    int      `bson:"progress" json:"progress"`
+	EmployeeIDs []string `bson:"employee_ids" json:"employee_ids"`
+	Employees   []User   `bson:"-" json:"employees,omitempty"`
+	Version     int64    `bson:"version" json:"version"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy   string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+type ServiceRequest struct {
+	ID          string  `bson:"_id" json:"id"`
+	Title       string  `bson:"title" json:"title"`
+	Description string  `bson:"description" json:"description"`
+	ClientID    string  `bson:"client_id" json:"client_id"`
+	Client      *User   `bson:"-" json:"client,omitempty"`
+	ProjectID   *string `bson:"project_id,omitempty" json:"project_id,omitempty"`
+	Project     *Project `bson:"-" json:"project,omitempty"`
+	Status      Status  `bson:"status" json:"status"`
+	Version     int64   `bson:"version" json:"version"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy   string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+type Message struct {
+	ID        string    `bson:"_id" json:"id"`
+	Content   string    `bson:"content" json:"content"`
+	SenderID  string    `bson:"sender_id" json:"sender_id"`
+	Sender    *User     `bson:"-" json:"sender,omitempty"`
+	ProjectID string    `bson:"project_id" json:"project_id"`
+	Project   *Project  `bson:"-" json:"project,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+type ServiceType struct {
+	ID          string    `bson:"_id" json:"id"`
+	Name        string    `bson:"name" json:"name"`
+	Description string    `bson:"description" json:"description"`
+	Status      Status    `bson:"status" json:"status"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+type Session struct {
+	ID               string     `bson:"_id" json:"id"`
+	UserID           string     `bson:"user_id" json:"user_id"`
+	RefreshTokenHash string     `bson:"refresh_token_hash" json:"-"`
+	UserAgent        string     `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IP               string     `bson:"ip,omitempty" json:"ip,omitempty"`
+	ExpiresAt        time.Time  `bson:"expires_at" json:"expires_at"`
+	RevokedAt        *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	ReplacedBy       string     `bson:"replaced_by,omitempty" json:"-"`
+	CreatedAt        time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// ClientInfo describes the caller a session is issued to.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+)
+
+// UserToken is a single-use, expiring token sent to a user by email. Only the
+// SHA-256 hash of the token is stored.
+type UserToken struct {
+	ID        string       `bson:"_id" json:"id"`
+	UserID    string       `bson:"user_id" json:"user_id"`
+	Purpose   TokenPurpose `bson:"purpose" json:"purpose"`
+	TokenHash string       `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time    `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time   `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt time.Time    `bson:"created_at" json:"created_at"`
+}
+
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusRevoked  InvitationStatus = "revoked"
+)
+
+type Invitation struct {
+	ID             string           `bson:"_id" json:"id"`
+	Email          string           `bson:"email" json:"email"`
+	Role           Role             `bson:"role" json:"role"`
+	Name           string           `bson:"name" json:"name"`
+	Phone          string           `bson:"phone,omitempty" json:"phone,omitempty"`
+	Department     string           `bson:"department,omitempty" json:"department,omitempty"`
+	Company        string           `bson:"company,omitempty" json:"company,omitempty"`
+	Address        string           `bson:"address,omitempty" json:"address,omitempty"`
+	Salary         int              `bson:"salary,omitempty" json:"salary,omitempty"`
+	TokenHash      string           `bson:"token_hash" json:"-"`
+	Status         InvitationStatus `bson:"status" json:"status"`
+	InvitedBy      string           `bson:"invited_by" json:"invited_by"`
+	AcceptedUserID string           `bson:"accepted_user_id,omitempty" json:"accepted_user_id,omitempty"`
+	ExpiresAt      time.Time        `bson:"expires_at" json:"expires_at"`
+	AcceptedAt     *time.Time       `bson:"accepted_at,omitempty" json:"accepted_at,omitempty"`
+	CreatedAt      time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+// LoginAttempt tracks recent failed logins for one email address. Documents
+// expire once the address has had no failures for the configured window.
+type LoginAttempt struct {
+	Email        string     `bson:"_id" json:"email"`
+	FailedCount  int        `bson:"failed_count" json:"failed_count"`
+	LastFailedAt time.Time  `bson:"last_failed_at" json:"last_failed_at"`
+	LockedUntil  *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	ExpiresAt    time.Time  `bson:"expires_at" json:"-"`
+	UpdatedAt    time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+type LockoutEventType string
+
+const (
+	LockoutEventLocked   LockoutEventType = "locked"
+	LockoutEventUnlocked LockoutEventType = "unlocked"
+)
+
+type LockoutEvent struct {
+	ID          string           `bson:"_id" json:"id"`
+	Email       string           `bson:"email" json:"email"`
+	Type        LockoutEventType `bson:"type" json:"type"`
+	IP          string           `bson:"ip,omitempty" json:"ip,omitempty"`
+	FailedCount int              `bson:"failed_count,omitempty" json:"failed_count,omitempty"`
+	LockedUntil *time.Time       `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	ActorID     string           `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	CreatedAt   time.Time        `bson:"created_at" json:"created_at"`
+}
+
+// Actor is the caller behind a change, recorded in the audit log.
+type Actor struct {
+	ID        string
+	Role      string
+	IP        string
+	RequestID string
+}
+
+type AuditAction string
+
+const (
+	AuditActionCreate   AuditAction = "create"
+	AuditActionUpdate   AuditAction = "update"
+	AuditActionDelete   AuditAction = "delete"
+	AuditActionAssign   AuditAction = "assign"
+	AuditActionApprove  AuditAction = "approve"
+	AuditActionReject   AuditAction = "reject"
+	AuditActionRevoke   AuditAction = "revoke"
+	AuditActionResend   AuditAction = "resend"
+	AuditActionAccept   AuditAction = "accept"
+	AuditActionRegister AuditAction = "register"
+	AuditActionRestore  AuditAction = "restore"
+	AuditActionImport   AuditAction = "import"
+
+	// Security changes that leave no trace in the audit diff, as the
+	// password and two-factor secrets are never snapshotted
+	AuditActionResetPassword           AuditAction = "reset_password"
+	AuditActionEnableTwoFactor         AuditAction = "enable_two_factor"
+	AuditActionDisableTwoFactor        AuditAction = "disable_two_factor"
+	AuditActionRegenerateRecoveryCodes AuditAction = "regenerate_recovery_codes"
+)
+
+// AuditChange holds the JSON values of one field before and after a change.
+// Before is absent for created resources and After for deleted ones.
+type AuditChange struct {
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// AuditEvent records one change made through the API.
+type AuditEvent struct {
+	ID           string                 `bson:"_id" json:"id"`
+	ActorID      string                 `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	ActorRole    string                 `bson:"actor_role,omitempty" json:"actor_role,omitempty"`
+	Action       AuditAction            `bson:"action" json:"action"`
+	ResourceType string                 `bson:"resource_type" json:"resource_type"`
+	ResourceID   string                 `bson:"resource_id" json:"resource_id"`
+	Changes      map[string]AuditChange `bson:"changes,omitempty" json:"changes,omitempty"`
+	IP           string                 `bson:"ip,omitempty" json:"ip,omitempty"`
+	RequestID    string                 `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	CreatedAt    time.Time              `bson:"created_at" json:"created_at"`
+}
+
+const (
+	// APIKeyScopeRead allows safe (GET/HEAD) requests
+	APIKeyScopeRead = "read"
+	// APIKeyScopeWrite allows every request method and implies read
+	APIKeyScopeWrite = "write"
+)
+
+// APIKey is a personal access key that authenticates as its owner. Only a hash
+// of the key is stored; Prefix identifies the key in listings.
+type APIKey struct {
+	ID         string     `bson:"_id" json:"id"`
+	UserID     string     `bson:"user_id" json:"user_id"`
+	Name       string     `bson:"name" json:"name"`
+	Prefix     string     `bson:"prefix" json:"prefix"`
+	KeyHash    string     `bson:"key_hash" json:"-"`
+	Scopes     []string   `bson:"scopes" json:"scopes"`
+	ExpiresAt  time.Time  `bson:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// HasScope reports whether the key grants scope; write implies read.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == APIKeyScopeWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// OIDCState remembers an in-flight single sign-on request between the redirect
+// to the identity provider and the callback. ID is the hash of the state value.
+type OIDCState struct {
+	ID           string    `bson:"_id" json:"-"`
+	Nonce        string    `bson:"nonce" json:"-"`
+	CodeVerifier string    `bson:"code_verifier" json:"-"`
+	ExpiresAt    time.Time `bson:"expires_at" json:"-"`
+	CreatedAt    time.Time `bson:"created_at" json:"-"`
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the keys used to sign and verify JWTs. Exactly one key signs new
+// tokens; every key in the set is accepted for verification, so a retired key
+// can stay in the set until the tokens it signed have expired.
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// private is nil for keys that are only kept to verify older tokens
+	private interface{}
+	public  interface{}
+}
+
+// JWK is the public part of a signing key in RFC 7517 format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMACKeySet returns a single-key HS256 set for deployments that have not
+// configured asymmetric keys yet. Tokens signed with it carry no kid and its
+// key is never published.
+func NewHMACKeySet(secret string) *KeySet {
+	key := &signingKey{method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}
+	return &KeySet{active: key, keys: map[string]*signingKey{"": key}}
+}
+
+// LoadKeySet reads every *.pem file in dir. The file name without extension is
+// the key ID. Private keys (RSA or Ed25519, PKCS#1 or PKCS#8) can sign; public
+// keys only verify. activeKID selects the signing key; when empty the last
+// private key in file name order is used, so naming keys by date rotates them.
+func LoadKeySet(dir, activeKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	set := &KeySet{keys: make(map[string]*signingKey)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		key.id = strings.TrimSuffix(filepath.Base(path), ".pem")
+		set.keys[key.id] = key
+
+		if key.private != nil && (activeKID == "" || activeKID == key.id) {
+			set.active = key
+		}
+	}
+
+	if set.active == nil {
+		if activeKID != "" {
+			return nil, fmt.Errorf("no private key with id %q in %s", activeKID, dir)
+		}
+		return nil, fmt.Errorf("no private key found in %s", dir)
+	}
+
+	return set, nil
+}
+
+// ActiveKeyID returns the kid of the key that signs new tokens.
+func (k *KeySet) ActiveKeyID() string {
+	return k.active.id
+}
+
+// Sign signs claims with the active key and sets the kid header.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	if k.active.id != "" {
+		token.Header["kid"] = k.active.id
+	}
+	return token.SignedString(k.active.private)
+}
+
+// Parse verifies tokenString with the key named by its kid header. The token's
+// alg must match that key's algorithm, which rules out algorithm confusion such
+// as an HS256 token keyed with a published RSA public key.
+func (k *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.public, nil
+	})
+}
+
+// JWKS returns the public keys of the set, sorted by kid. Symmetric keys are
+// never included.
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range k.keys {
+		jwk := JWK{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
+// PublicKey decodes the key for verifying signatures. RSA, EC P-256 and
+// Ed25519 keys are supported, which covers what identity providers publish.
+func (j JWK) PublicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch j.Kty {
+	case "RSA":
+		n, err := decode(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decode(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decode(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func parsePEMKey(data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return &signingKey{method: jwt.SigningMethodRS256, private: key, public: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return &signingKey{method: jwt.SigningMethodRS256, public: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, private: key, public: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, public: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; use RSA or Ed25519", parsed)
+	}
+}
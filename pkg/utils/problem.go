@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses. Clients
+// that list it in Accept get a Problem; everyone else keeps the Response
+// envelope.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code and Errors are extension
+// members carrying the same values as the Response envelope.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes one rejected field of a request. Field is the JSON or
+// query name, Rule the failed validation tag, e.g. "required" or "oneof".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Report fields by the names clients send rather than the Go field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// BindError writes the 400 response for a failed ShouldBind* call, listing
+// every field that failed validation.
+func BindError(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, fieldError(fe))
+		}
+		writeError(c, http.StatusBadRequest, "VALIDATION_FAILED", "request validation failed", fields)
+	case errors.As(err, &typeErr):
+		field := FieldError{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String()}
+		field.Message = fmt.Sprintf("%s must be of type %s", field.Field, field.Param)
+		writeError(c, http.StatusBadRequest, "VALIDATION_FAILED", "request validation failed", []FieldError{field})
+	case errors.Is(err, io.EOF):
+		writeError(c, http.StatusBadRequest, "INVALID_BODY", "request body is required", nil)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		writeError(c, http.StatusBadRequest, "INVALID_BODY", "request body is not valid JSON", nil)
+	default:
+		writeError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+	}
+}
+
+func fieldError(fe validator.FieldError) FieldError {
+	// The namespace starts with the request type, e.g. "CreateClientRequest.email"
+	field := fe.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	return FieldError{
+		Field:   field,
+		Rule:    fe.Tag(),
+		Param:   fe.Param(),
+		Message: validationMessage(field, fe),
+	}
+}
+
+func validationMessage(field string, fe validator.FieldError) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "url", "uri":
+		return field + " must be a valid URL"
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.Join(strings.Fields(fe.Param()), ", "))
+	case "min", "gte":
+		if unit != "" {
+			return fmt.Sprintf("%s must contain at least %s%s", field, fe.Param(), unit)
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max", "lte":
+		if unit != "" {
+			return fmt.Sprintf("%s must contain at most %s%s", field, fe.Param(), unit)
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+	case "len":
+		return fmt.Sprintf("%s must contain exactly %s%s", field, fe.Param(), unit)
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
+
+// writeError is the single writer for error responses. It negotiates between
+// the Response envelope and a Problem on the Accept header.
+func writeError(c *gin.Context, status int, code, message string, fields []FieldError) {
+	if !wantsProblem(c) {
+		c.JSON(status, Response{
+			Success: false,
+			Error:   message,
+			Code:    code,
+			Details: fields,
+		})
+		return
+	}
+
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: message,
+		Code:   code,
+		Errors: fields,
+	}
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, problem)
+}
+
+func wantsProblem(c *gin.Context) bool {
+	if c.Request == nil || c.GetHeader("Accept") == "" {
+		return false
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, ProblemContentType) == ProblemContentType
+}
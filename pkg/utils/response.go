@@ -1,14 +1,20 @@
 package utils
 
 import (
+	"log"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/pkg/apperrors"
 )
 
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Code    string       `json:"code,omitempty"`
+	Details []FieldError `json:"details,omitempty"`
 }
 
 type PaginatedResponse struct {
@@ -33,10 +39,49 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 }
 
 func ErrorResponse(c *gin.Context, statusCode int, message string) {
-	c.JSON(statusCode, Response{
-		Success: false,
-		Error:   message,
-	})
+	writeError(c, statusCode, "", message, nil)
+}
+
+// ErrorResponseWithCode adds a machine-readable code so clients can tell apart
+// errors that share an HTTP status.
+func ErrorResponseWithCode(c *gin.Context, statusCode int, code string, message string) {
+	writeError(c, statusCode, code, message, nil)
+}
+
+// HandleError writes the response for an error returned by a service. Typed
+// errors keep their message and code; anything else is logged and answered
+// with a generic 500 so driver or library messages never reach clients.
+func HandleError(c *gin.Context, err error) {
+	appErr := apperrors.From(err)
+	if appErr.Kind == apperrors.KindInternal || appErr.Kind == apperrors.KindUpstream {
+		log.Printf("Internal error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+
+	ErrorResponseWithCode(c, HTTPStatus(appErr.Kind), appErr.Code, appErr.Message)
+}
+
+// HTTPStatus maps an error kind to its status code.
+func HTTPStatus(kind apperrors.Kind) int {
+	switch kind {
+	case apperrors.KindValidation:
+		return http.StatusBadRequest
+	case apperrors.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperrors.KindForbidden:
+		return http.StatusForbidden
+	case apperrors.KindNotFound:
+		return http.StatusNotFound
+	case apperrors.KindConflict:
+		return http.StatusConflict
+	case apperrors.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case apperrors.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case apperrors.KindUpstream:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func PaginatedSuccessResponse(c *gin.Context, statusCode int, data interface{}, pagination Pagination) {
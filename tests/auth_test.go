@@ -2,6 +2,7 @@ package tests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/vinodhini/software-api/pkg/utils"
 )
@@ -37,7 +38,7 @@ func TestCheckPassword(t *testing.T) {
 }
 
 func TestGenerateToken(t *testing.T) {
-	token, err := utils.GenerateToken(1, "test@example.com", "admin", "secret", 3600)
+	token, err := utils.GenerateToken("USER01", "test@example.com", "admin", "", utils.NewHMACKeySet("secret"), time.Hour)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -48,16 +49,20 @@ func TestGenerateToken(t *testing.T) {
 }
 
 func TestValidateToken(t *testing.T) {
-	secret := "test-secret"
-	token, _ := utils.GenerateToken(1, "test@example.com", "admin", secret, 3600)
+	keys := utils.NewHMACKeySet("test-secret")
+	token, _ := utils.GenerateToken("USER01", "test@example.com", "admin", "SESSION01", keys, time.Hour)
 
-	claims, err := utils.ValidateToken(token, secret)
+	claims, err := utils.ValidateToken(token, keys)
 	if err != nil {
 		t.Fatalf("Failed to validate token: %v", err)
 	}
 
-	if claims.UserID != 1 {
-		t.Errorf("Expected UserID 1, got %d", claims.UserID)
+	if claims.UserID != "USER01" {
+		t.Errorf("Expected UserID USER01, got %s", claims.UserID)
+	}
+
+	if claims.SessionID != "SESSION01" {
+		t.Errorf("Expected SessionID SESSION01, got %s", claims.SessionID)
 	}
 
 	if claims.Email != "test@example.com" {
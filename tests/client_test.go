@@ -0,0 +1,345 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/middleware"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/routes"
+	"github.com/vinodhini/software-api/pkg/client"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+func newTestClient(t *testing.T, handler http.Handler, opts ...client.Option) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	opts = append([]client.Option{client.WithHTTPClient(server.Client())}, opts...)
+	return client.New(server.URL, opts...)
+}
+
+func TestClient_CoversEveryRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	api := gin.New()
+	routes.SetupRoutes(api, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, func(*gin.Context) {}, policy.Default())
+
+	// Serve the same routes with a handler that records which one was hit
+	var mu sync.Mutex
+	called := make(map[string]bool)
+	recorder := gin.New()
+	for _, route := range api.Routes() {
+		recorder.Handle(route.Method, route.Path, func(ctx *gin.Context) {
+			mu.Lock()
+			called[ctx.Request.Method+" "+ctx.FullPath()] = true
+			mu.Unlock()
+			if ctx.Query("page") != "" {
+				utils.PaginatedSuccessResponse(ctx, http.StatusOK, []interface{}{}, utils.Pagination{Page: 1, PageSize: 10})
+				return
+			}
+			utils.SuccessResponse(ctx, http.StatusOK, "ok", nil)
+		})
+	}
+
+	c := newTestClient(t, recorder, client.WithTokens(client.Tokens{AccessToken: "token"}))
+	ctx := context.Background()
+	query := models.PaginationQuery{Page: 1}
+	calls := []func() error{
+		func() error { _, err := c.Register(ctx, &models.RegisterRequest{}); return err },
+		func() error { _, err := c.Login(ctx, &models.LoginRequest{}); return err },
+		func() error { _, err := c.LoginTwoFactor(ctx, &models.TwoFactorLoginRequest{}); return err },
+		func() error { _, err := c.LoginTwoFactorSetup(ctx, &models.TwoFactorChallengeRequest{}); return err },
+		func() error {
+			c.SetTokens(client.Tokens{AccessToken: "token", RefreshToken: "refresh"})
+			return c.Refresh(ctx)
+		},
+		func() error { return c.VerifyEmail(ctx, &models.VerifyEmailRequest{}) },
+		func() error { return c.ResendVerification(ctx, &models.ResendVerificationRequest{}) },
+		func() error { return c.ForgotPassword(ctx, &models.ForgotPasswordRequest{}) },
+		func() error { return c.ResetPassword(ctx, &models.ResetPasswordRequest{}) },
+		func() error { _, err := c.AcceptInvitation(ctx, &models.AcceptInvitationRequest{}); return err },
+		func() error { _, err := c.OIDCLogin(ctx); return err },
+		func() error { _, err := c.OIDCCallback(ctx, &models.OIDCCallbackRequest{State: "s"}); return err },
+		func() error { return c.Logout(ctx) },
+		func() error { _, err := c.SetupTwoFactor(ctx); return err },
+		func() error { _, err := c.EnableTwoFactor(ctx, &models.TwoFactorCodeRequest{}); return err },
+		func() error { return c.DisableTwoFactor(ctx, &models.TwoFactorCodeRequest{}) },
+		func() error { _, err := c.RegenerateRecoveryCodes(ctx, &models.TwoFactorCodeRequest{}); return err },
+		func() error { _, err := c.CreateAPIKey(ctx, &models.CreateAPIKeyRequest{}); return err },
+		func() error { _, err := c.ListAPIKeys(ctx); return err },
+		func() error { return c.RevokeAPIKey(ctx, "KEY01") },
+		func() error { _, err := c.CreateEmployee(ctx, &models.CreateEmployeeRequest{}); return err },
+		func() error { _, err := c.ListEmployees(ctx, query); return err },
+		func() error { _, err := c.GetEmployee(ctx, "EMP01"); return err },
+		func() error { _, err := c.UpdateEmployee(ctx, "EMP01", 1, &models.UpdateUserRequest{}); return err },
+		func() error { _, err := c.PatchEmployee(ctx, "EMP01", 1, &models.UpdateUserRequest{}); return err },
+		func() error { return c.DeleteEmployee(ctx, "EMP01") },
+		func() error { _, err := c.ListUsers(ctx, client.UserQuery{PaginationQuery: query}); return err },
+		func() error { _, err := c.GetUser(ctx, "USER01"); return err },
+		func() error { _, err := c.UpdateUser(ctx, "USER01", 1, &models.UpdateUserRequest{}); return err },
+		func() error { _, err := c.PatchUser(ctx, "USER01", 1, &models.UpdateUserRequest{}); return err },
+		func() error { return c.DeleteUser(ctx, "USER01") },
+		func() error { _, err := c.RestoreUser(ctx, "USER01"); return err },
+		func() error { _, err := c.DashboardStats(ctx); return err },
+		func() error { _, err := c.CreateClient(ctx, &models.CreateClientRequest{}); return err },
+		func() error { _, err := c.ListClients(ctx, query); return err },
+		func() error { _, err := c.GetClient(ctx, "CLIENT01"); return err },
+		func() error { _, err := c.UpdateClient(ctx, "CLIENT01", 1, &models.UpdateUserRequest{}); return err },
+		func() error { return c.DeleteClient(ctx, "CLIENT01") },
+		func() error { _, err := c.CreateProject(ctx, &models.CreateProjectRequest{}); return err },
+		func() error { _, err := c.ListProjects(ctx, query); return err },
+		func() error { _, err := c.GetProject(ctx, "PROJ01"); return err },
+		func() error { _, err := c.UpdateProject(ctx, "PROJ01", 1, &models.UpdateProjectRequest{}); return err },
+		func() error { return c.DeleteProject(ctx, "PROJ01") },
+		func() error { _, err := c.RestoreProject(ctx, "PROJ01"); return err },
+		func() error { return c.AssignEmployees(ctx, "PROJ01", &models.AssignEmployeesRequest{}) },
+		func() error {
+			_, err := c.UpdateProjectProgress(ctx, "PROJ01", 1, &models.UpdateProjectProgressRequest{})
+			return err
+		},
+		func() error { _, err := c.ListProjectMessages(ctx, "PROJ01", 1, 10); return err },
+		func() error {
+			_, err := c.CreateServiceRequest(ctx, &models.CreateServiceRequestRequest{})
+			return err
+		},
+		func() error { _, err := c.ListServiceRequests(ctx, query); return err },
+		func() error { _, err := c.GetServiceRequest(ctx, "SR01"); return err },
+		func() error {
+			_, err := c.UpdateServiceRequest(ctx, "SR01", 1, &models.UpdateServiceRequestRequest{})
+			return err
+		},
+		func() error { return c.DeleteServiceRequest(ctx, "SR01") },
+		func() error { _, err := c.RestoreServiceRequest(ctx, "SR01"); return err },
+		func() error {
+			_, err := c.ApproveServiceRequest(ctx, "SR01", &models.ApproveServiceRequestRequest{})
+			return err
+		},
+		func() error { return c.RejectServiceRequest(ctx, "SR01") },
+		func() error { _, err := c.CreateInvitation(ctx, &models.CreateInvitationRequest{}); return err },
+		func() error { _, err := c.ListInvitations(ctx, models.InvitationQuery{Page: 1}); return err },
+		func() error { _, err := c.ResendInvitation(ctx, "INV01"); return err },
+		func() error { return c.RevokeInvitation(ctx, "INV01") },
+		func() error { _, err := c.ListPendingRegistrations(ctx, models.RegistrationQuery{Page: 1}); return err },
+		func() error { _, err := c.ApproveRegistration(ctx, "USER01"); return err },
+		func() error { return c.RejectRegistration(ctx, "USER01") },
+		func() error { _, err := c.ListLockedAccounts(ctx); return err },
+		func() error { _, err := c.ListLockoutEvents(ctx, models.LockoutEventQuery{Page: 1}); return err },
+		func() error { return c.UnlockAccount(ctx, &models.UnlockAccountRequest{}) },
+		func() error { _, err := c.ListAuditEvents(ctx, models.AuditQuery{Page: 1}); return err },
+		func() error { _, err := c.ListServiceTypes(ctx, ""); return err },
+		func() error { _, err := c.CreateServiceType(ctx, &models.CreateServiceTypeRequest{}); return err },
+		func() error { _, err := c.GetServiceType(ctx, "ST01"); return err },
+		func() error {
+			_, err := c.UpdateServiceType(ctx, "ST01", &models.UpdateServiceTypeRequest{})
+			return err
+		},
+		func() error { return c.DeleteServiceType(ctx, "ST01") },
+		func() error { _, err := c.ListMessages(ctx, 1, 10); return err },
+		func() error { _, err := c.CreateMessage(ctx, &models.CreateMessageRequest{}); return err },
+		func() error { _, err := c.GetMessage(ctx, "MSG01"); return err },
+		func() error { return c.DeleteMessage(ctx, "MSG01") },
+		func() error { _, err := c.RestoreMessage(ctx, "MSG01"); return err },
+		func() error { _, err := c.JWKS(ctx); return err },
+		func() error { _, err := c.OpenAPI(ctx); return err },
+	}
+	for i, call := range calls {
+		if err := call(); err != nil {
+			t.Errorf("Call %d failed: %v", i, err)
+		}
+	}
+
+	// The docs page and its script are for browsers only
+	browserOnly := map[string]bool{"GET /api/docs": true, "GET /api/docs/init.js": true}
+	for _, route := range api.Routes() {
+		key := route.Method + " " + route.Path
+		if !called[key] && !browserOnly[key] {
+			t.Errorf("No client method calls %s", key)
+		}
+	}
+}
+
+func TestClient_AuthenticatesWithAPIKey(t *testing.T) {
+	server, admin := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := admin.CreateAPIKey(ctx, &models.CreateAPIKeyRequest{Name: "reports", Scopes: []string{models.APIKeyScopeRead}})
+	if err != nil {
+		t.Fatalf("Failed to create an API key: %v", err)
+	}
+
+	c := client.New(server.URL, client.WithHTTPClient(server.Client()), client.WithAPIKey(created.Key))
+	if _, err := c.ListProjects(ctx, models.PaginationQuery{Page: 1}); err != nil {
+		t.Fatalf("Expected the API key to authenticate, got %v", err)
+	}
+
+	// The key only has the read scope
+	_, err = c.CreateServiceType(ctx, &models.CreateServiceTypeRequest{Name: "Audit", Status: "active"})
+	if client.StatusCode(err) != http.StatusForbidden {
+		t.Errorf("Expected 403 for a write with a read-only key, got %v", err)
+	}
+}
+
+func TestClient_RefreshesExpiredToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var refreshes int
+	router.POST("/api/auth/refresh", func(ctx *gin.Context) {
+		var req models.RefreshTokenRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil || req.RefreshToken != "refresh-1" {
+			utils.ErrorResponseWithCode(ctx, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN", "invalid refresh token")
+			return
+		}
+		refreshes++
+		utils.SuccessResponse(ctx, http.StatusOK, "ok", models.LoginResponse{Token: "access-2", RefreshToken: "refresh-2"})
+	})
+	router.GET("/api/projects/:id", func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") != "Bearer access-2" {
+			utils.ErrorResponse(ctx, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+		utils.SuccessResponse(ctx, http.StatusOK, "ok", models.Project{ID: ctx.Param("id"), Name: "Website"})
+	})
+
+	var saved client.Tokens
+	c := newTestClient(t, router,
+		client.WithTokens(client.Tokens{AccessToken: "access-1", RefreshToken: "refresh-1"}),
+		client.WithTokenRefreshHandler(func(tokens client.Tokens) { saved = tokens }),
+	)
+
+	project, err := c.GetProject(context.Background(), "PROJ01")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after refreshing, got %v", err)
+	}
+	if project.ID != "PROJ01" || project.Name != "Website" {
+		t.Errorf("Unexpected project: %+v", project)
+	}
+	if refreshes != 1 {
+		t.Errorf("Expected one refresh, got %d", refreshes)
+	}
+	if saved.AccessToken != "access-2" || c.Tokens().RefreshToken != "refresh-2" {
+		t.Errorf("Expected the rotated tokens to be kept, got %+v", saved)
+	}
+
+	// A failed refresh reports the original 401
+	c.SetTokens(client.Tokens{AccessToken: "access-1", RefreshToken: "stale"})
+	_, err = c.GetProject(context.Background(), "PROJ01")
+	if client.StatusCode(err) != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %v", err)
+	}
+}
+
+func TestClient_BacksOffWhenRateLimited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var attempts int
+	router.GET("/api/service-types", func(ctx *gin.Context) {
+		attempts++
+		if attempts < 3 {
+			utils.ErrorResponseWithCode(ctx, http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded")
+			return
+		}
+		utils.SuccessResponse(ctx, http.StatusOK, "ok", []models.ServiceType{{ID: "ST01"}})
+	})
+	router.GET("/api/service-types/:id", func(ctx *gin.Context) {
+		ctx.Header("Retry-After", "120")
+		utils.ErrorResponseWithCode(ctx, http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded")
+	})
+
+	c := newTestClient(t, router, client.WithRetry(3, time.Millisecond, 50*time.Millisecond))
+
+	types, err := c.ListServiceTypes(context.Background(), "active")
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retrying, got %v", err)
+	}
+	if attempts != 3 || len(types) != 1 {
+		t.Errorf("Expected 3 attempts and one type, got %d attempts and %+v", attempts, types)
+	}
+
+	// A Retry-After beyond the backoff limit is returned instead of waited out
+	start := time.Now()
+	_, err = c.GetServiceType(context.Background(), "ST01")
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "RATE_LIMITED" || apiErr.RetryAfter != 120*time.Second {
+		t.Errorf("Expected a RATE_LIMITED error with Retry-After, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected the client not to wait for a long Retry-After")
+	}
+}
+
+func TestClient_ReadsRetryAfterFromRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RateLimitMiddleware(1, time.Minute))
+	router.GET("/api/service-types", func(ctx *gin.Context) {
+		utils.SuccessResponse(ctx, http.StatusOK, "ok", []models.ServiceType{})
+	})
+
+	c := newTestClient(t, router, client.WithRetry(1, time.Millisecond, time.Second))
+	if _, err := c.ListServiceTypes(context.Background(), ""); err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+
+	_, err := c.ListServiceTypes(context.Background(), "")
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "RATE_LIMITED" {
+		t.Fatalf("Expected RATE_LIMITED, got %v", err)
+	}
+	if apiErr.RetryAfter < 59*time.Second || apiErr.RetryAfter > time.Minute {
+		t.Errorf("Expected to be told to retry in about a minute, got %v", apiErr.RetryAfter)
+	}
+}
+
+func TestClient_IteratesPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	const total = 5
+	router.GET("/api/projects", func(ctx *gin.Context) {
+		page, _ := strconv.Atoi(ctx.Query("page"))
+		pageSize, _ := strconv.Atoi(ctx.Query("page_size"))
+		if ctx.Query("status") != "active" {
+			t.Errorf("Expected the status filter on every page, got %q", ctx.Query("status"))
+		}
+
+		var projects []models.Project
+		for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+			projects = append(projects, models.Project{ID: "PROJ0" + strconv.Itoa(i+1)})
+		}
+		utils.PaginatedSuccessResponse(ctx, http.StatusOK, projects, utils.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: (total + pageSize - 1) / pageSize,
+		})
+	})
+
+	c := newTestClient(t, router)
+	projects, err := c.IterateProjects(models.PaginationQuery{PageSize: 2, Status: "active"}).All(context.Background())
+	if err != nil {
+		t.Fatalf("Iteration failed: %v", err)
+	}
+	if len(projects) != total || projects[0].ID != "PROJ01" || projects[total-1].ID != "PROJ05" {
+		t.Errorf("Expected all %d projects in order, got %+v", total, projects)
+	}
+}
+
+func TestClient_ReturnsValidationDetails(t *testing.T) {
+	c := newTestClient(t, bindRouter())
+
+	_, err := c.CreateClient(context.Background(), &models.CreateClientRequest{Email: "not-an-email"})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected a *client.Error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != "VALIDATION_FAILED" || len(apiErr.Details) == 0 {
+		t.Errorf("Unexpected error: %+v", apiErr)
+	}
+}
@@ -0,0 +1,23 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/vinodhini/software-api/config"
+)
+
+func TestConfig_RegistrationMode(t *testing.T) {
+	tests := map[string]config.RegistrationMode{
+		"":            config.RegistrationClientOnly,
+		"Allowlist":   config.RegistrationAllowlist,
+		"invitation":  config.RegistrationInvitation,
+		"client_only": config.RegistrationClosed,
+		"open":        config.RegistrationClosed,
+	}
+	for value, want := range tests {
+		t.Setenv("REGISTRATION_MODE", value)
+		if got := config.Load().Registration.Mode; got != want {
+			t.Errorf("REGISTRATION_MODE=%q: expected %q, got %q", value, want, got)
+		}
+	}
+}
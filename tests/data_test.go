@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/repositories/memory"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newDataService(repos *repositories.Repositories) services.DataService {
+	return services.NewDataService(repos.Users, repos.Projects, repos.ServiceRequests, repos.Messages, repos.ServiceTypes, repos.Counters, services.NewAuditService(repos.AuditEvents))
+}
+
+func TestDataService_ExportImportRoundTrip(t *testing.T) {
+	source := memory.NewRepositories()
+	client := createUser(t, source, "USER07", models.RoleClient)
+	createUser(t, source, "USER100", models.RoleEmployee)
+	gone := createUser(t, source, "USER03", models.RoleEmployee)
+	if err := source.Users.Delete(gone.UserID, "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	client.Password = "$2a$10$hash"
+	client.TwoFactorSecret = "SECRET"
+	if err := source.Users.Update(client); err != nil {
+		t.Fatalf("Failed to update user: %v", err)
+	}
+
+	projectID := "PROJECT12"
+	if err := source.Projects.Create(&models.Project{ID: projectID, Name: "Website", ClientID: "USER07", EmployeeIDs: []string{"USER100"}}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := source.ServiceRequests.Create(&models.ServiceRequest{ID: "SERVICE04", Title: "Redesign", ClientID: "USER07", ProjectID: &projectID}); err != nil {
+		t.Fatalf("Failed to create service request: %v", err)
+	}
+	if err := source.Messages.Create(&models.Message{ID: "MESSAGE09", Content: "Hello", SenderID: "USER07", ProjectID: projectID}); err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+	if err := source.ServiceTypes.Create(&models.ServiceType{Name: "Web", Status: models.StatusActive}); err != nil {
+		t.Fatalf("Failed to create service type: %v", err)
+	}
+
+	data, err := newDataService(source).Export()
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	// Through extended JSON, as the admin CLI writes it
+	raw, err := bson.MarshalExtJSON(data, false, false)
+	if err != nil {
+		t.Fatalf("Failed to marshal export: %v", err)
+	}
+	var decoded models.DataExport
+	if err := bson.UnmarshalExtJSON(raw, false, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal export: %v", err)
+	}
+
+	target := memory.NewRepositories()
+	if err := newDataService(target).Import(&decoded, models.Actor{ID: "cli"}); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	imported, err := target.Users.FindByID("USER07")
+	if err != nil || imported.Password != "$2a$10$hash" || imported.TwoFactorSecret != "SECRET" {
+		t.Errorf("Expected the client with its credentials, got %+v (%v)", imported, err)
+	}
+	// Extended JSON keeps times to the millisecond
+	if !imported.CreatedAt.Equal(client.CreatedAt.Truncate(time.Millisecond)) || !imported.UpdatedAt.Equal(client.UpdatedAt.Truncate(time.Millisecond)) || imported.Version != 2 {
+		t.Errorf("Expected the client's timestamps and version, got %v %v %d", imported.CreatedAt, imported.UpdatedAt, imported.Version)
+	}
+	if _, total, _ := target.Users.List(1, 10, "", "", true); total != 3 {
+		t.Errorf("Expected 3 users with deleted ones, got %d", total)
+	}
+	if tombstone, err := target.Users.Restore("USER03"); err != nil || tombstone.DeletedBy != "ADMIN01" {
+		t.Errorf("Expected the deleted user to stay deleted, got %+v (%v)", tombstone, err)
+	}
+	project, err := target.Projects.FindByID(projectID)
+	if err != nil || len(project.EmployeeIDs) != 1 || project.EmployeeIDs[0] != "USER100" {
+		t.Errorf("Expected the project with its employee, got %+v (%v)", project, err)
+	}
+	request, err := target.ServiceRequests.FindByID("SERVICE04")
+	if err != nil || request.ProjectID == nil || *request.ProjectID != projectID {
+		t.Errorf("Expected the service request linked to its project, got %+v (%v)", request, err)
+	}
+	if messages, _ := target.Messages.FindByProject(projectID); len(messages) != 1 {
+		t.Errorf("Expected the project's message, got %d", len(messages))
+	}
+	if serviceTypes, _ := target.ServiceTypes.GetAll(nil); len(serviceTypes) != 1 || serviceTypes[0].Name != "Web" {
+		t.Errorf("Expected the service type, got %+v", serviceTypes)
+	}
+
+	// New records are numbered after the imported ones
+	ids := services.NewIDGenerator(target.Counters, &config.Config{IDs: config.IDConfig{
+		User:           config.IDFormat{Prefix: "USER", Width: 2},
+		Project:        config.IDFormat{Prefix: "PROJECT", Width: 2},
+		ServiceRequest: config.IDFormat{Prefix: "SERVICE", Width: 2},
+		Message:        config.IDFormat{Prefix: "MESSAGE", Width: 2},
+	}})
+	for name, next := range map[string]func() (string, error){
+		"USER101":   ids.NextUserID,
+		"PROJECT13": ids.NextProjectID,
+		"SERVICE05": ids.NextServiceRequestID,
+		"MESSAGE10": ids.NextMessageID,
+	} {
+		if id, err := next(); err != nil || id != name {
+			t.Errorf("Expected %s, got %s (%v)", name, id, err)
+		}
+	}
+}
+
+func TestDataService_SeedCountersOnlyRaises(t *testing.T) {
+	repos := memory.NewRepositories()
+	createUser(t, repos, "USER05", models.RoleClient)
+	for i := 0; i < 8; i++ {
+		repos.Counters.GetNextSequence(repositories.UserCounter)
+	}
+
+	highest, err := newDataService(repos).SeedCounters()
+	if err != nil || highest[repositories.UserCounter] != 5 {
+		t.Fatalf("Expected USER05 to be the highest, got %v (%v)", highest, err)
+	}
+	if next, _ := repos.Counters.GetNextSequence(repositories.UserCounter); next != 9 {
+		t.Errorf("Expected the counter to stay ahead at 9, got %d", next)
+	}
+}
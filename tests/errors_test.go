@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/pkg/apperrors"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+func TestHandleError_MapsKindsToStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{apperrors.Validation("NAME_REQUIRED", "name is required"), http.StatusBadRequest, "NAME_REQUIRED", "name is required"},
+		{apperrors.Unauthorized("INVALID_CREDENTIALS", "invalid credentials"), http.StatusUnauthorized, "INVALID_CREDENTIALS", "invalid credentials"},
+		{apperrors.Forbidden("ACCESS_DENIED", "access denied"), http.StatusForbidden, "ACCESS_DENIED", "access denied"},
+		{apperrors.NotFound("PROJECT_NOT_FOUND", "project %s not found", "PROJECT01"), http.StatusNotFound, "PROJECT_NOT_FOUND", "project PROJECT01 not found"},
+		{apperrors.Conflict("EMAIL_TAKEN", "email already exists"), http.StatusConflict, "EMAIL_TAKEN", "email already exists"},
+		{apperrors.Unprocessable("INVALID_CLIENT", "client ID does not refer to an existing client"), http.StatusUnprocessableEntity, "INVALID_CLIENT", "client ID does not refer to an existing client"},
+		{apperrors.PreconditionFailed("VERSION_MISMATCH", "stale"), http.StatusPreconditionFailed, "VERSION_MISMATCH", "stale"},
+		// Wrapped typed errors keep their kind
+		{fmt.Errorf("update failed: %w", apperrors.Conflict("STALE", "stale")), http.StatusConflict, "STALE", "stale"},
+		// Untyped errors must not leak their message
+		{errors.New("connection refused"), http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error"},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/api/test", nil)
+
+		utils.HandleError(ctx, tc.err)
+
+		if w.Code != tc.status {
+			t.Errorf("%v: expected status %d, got %d", tc.err, tc.status, w.Code)
+		}
+
+		var body utils.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if body.Success || body.Code != tc.code || body.Error != tc.message {
+			t.Errorf("%v: unexpected body %+v", tc.err, body)
+		}
+	}
+}
+
+func TestAppErrors_IsMatchesKind(t *testing.T) {
+	cause := errors.New("duplicate key")
+	err := apperrors.Conflict("EMAIL_TAKEN", "email already exists").Wrap(cause)
+
+	if !errors.Is(err, apperrors.KindConflict) || errors.Is(err, apperrors.KindNotFound) {
+		t.Error("Expected the error to match its own kind only")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected the wrapped cause to be reachable")
+	}
+	if apperrors.From(cause).Kind != apperrors.KindInternal {
+		t.Error("Expected untyped errors to be internal")
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
+
+func TestKeySet_RS256AndRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePEM(t, dir, "2024-01.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(oldKey))
+
+	oldSet, err := utils.LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to load key set: %v", err)
+	}
+	oldToken, _ := utils.GenerateToken("USER01", "test@example.com", "admin", "SESSION01", oldSet, time.Hour)
+
+	// Rotate: add an Ed25519 key; the newest file name becomes the signing key
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(newKey)
+	writePEM(t, dir, "2024-02.pem", "PRIVATE KEY", der)
+
+	keys, err := utils.LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to load rotated key set: %v", err)
+	}
+
+	if keys.ActiveKeyID() != "2024-02" {
+		t.Errorf("Expected active key 2024-02, got %s", keys.ActiveKeyID())
+	}
+
+	newToken, _ := utils.GenerateToken("USER01", "test@example.com", "admin", "SESSION01", keys, time.Hour)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &utils.Claims{})
+	if parsed.Header["kid"] != "2024-02" || parsed.Method.Alg() != "EdDSA" {
+		t.Errorf("Expected EdDSA token with kid 2024-02, got %v", parsed.Header)
+	}
+
+	// Tokens signed before the rotation stay valid
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := utils.ValidateToken(token, keys); err != nil {
+			t.Errorf("Expected token to validate after rotation, got: %v", err)
+		}
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kty != "RSA" || jwks.Keys[1].Kty != "OKP" {
+		t.Errorf("Expected RSA and OKP keys in JWKS, got: %+v", jwks.Keys)
+	}
+
+	// Pinning the old key keeps signing with it
+	pinned, err := utils.LoadKeySet(dir, "2024-01")
+	if err != nil || pinned.ActiveKeyID() != "2024-01" {
+		t.Errorf("Expected JWT_ACTIVE_KID to select the signing key, got %v", err)
+	}
+}
+
+func TestKeySet_RejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePEM(t, dir, "main.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	keys, err := utils.LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to load key set: %v", err)
+	}
+
+	// An HS256 token keyed with the published public key must not validate
+	publicDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.Claims{UserID: "USER01", Role: "admin"})
+	forged.Header["kid"] = "main"
+	forgedToken, _ := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+
+	if _, err := utils.ValidateToken(forgedToken, keys); err == nil {
+		t.Error("Expected HS256 token to be rejected by an RS256 key set")
+	}
+
+	// Neither may tokens from the HMAC fallback
+	hmacToken, _ := utils.GenerateToken("USER01", "test@example.com", "admin", "", utils.NewHMACKeySet("secret"), time.Hour)
+	if _, err := utils.ValidateToken(hmacToken, keys); err == nil {
+		t.Error("Expected token without a known kid to be rejected")
+	}
+
+	if len(utils.NewHMACKeySet("secret").JWKS().Keys) != 0 {
+		t.Error("Expected HMAC secrets never to be published")
+	}
+}
+
+func TestKeySet_PublicOnlyKeyVerifies(t *testing.T) {
+	dir := t.TempDir()
+
+	retired, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePEM(t, dir, "retired.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(retired))
+	retiredSet, _ := utils.LoadKeySet(dir, "")
+	token, _ := utils.GenerateToken("USER01", "test@example.com", "admin", "", retiredSet, time.Hour)
+
+	// Replace the retired private key with its public half and add a new signing key
+	os.Remove(filepath.Join(dir, "retired.pem"))
+	publicDER, _ := x509.MarshalPKIXPublicKey(&retired.PublicKey)
+	writePEM(t, dir, "retired.pem", "PUBLIC KEY", publicDER)
+	current, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePEM(t, dir, "current.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(current))
+
+	keys, err := utils.LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to load key set: %v", err)
+	}
+
+	if keys.ActiveKeyID() != "current" {
+		t.Errorf("Expected public-only key never to sign, got active key %s", keys.ActiveKeyID())
+	}
+
+	if _, err := utils.ValidateToken(token, keys); err != nil {
+		t.Errorf("Expected token signed by the retired key to validate, got: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/vinodhini/software-api/internal/migrations"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrations_UpDownStatus(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	db := openMongoTestDatabase(t, uri)
+	ctx := context.Background()
+	migrator := migrations.New(db)
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil || len(statuses) == 0 {
+		t.Fatalf("Expected migrations to list, got %v (%v)", statuses, err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("Expected migration %d to be pending", status.Version)
+		}
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil || len(applied) != len(statuses) {
+		t.Fatalf("Expected %d migrations applied, got %d (%v)", len(statuses), len(applied), err)
+	}
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Errorf("Expected nothing left to apply, got %d (%v)", len(applied), err)
+	}
+	if !hasIndex(t, db, "users", "email_1") {
+		t.Error("Expected the users email index after migration 1")
+	}
+
+	reverted, err := migrator.Down(ctx, len(statuses))
+	if err != nil || len(reverted) != len(statuses) || reverted[0].Version != statuses[len(statuses)-1].Version {
+		t.Fatalf("Expected every migration reverted newest first, got %+v (%v)", reverted, err)
+	}
+	if hasIndex(t, db, "users", "email_1") {
+		t.Error("Expected the users email index to be dropped")
+	}
+	statuses, _ = migrator.Status(ctx)
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Errorf("Expected migration %d to be pending again", status.Version)
+		}
+	}
+}
+
+func TestMigrations_ConcurrentInstancesApplyOnce(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	db := openMongoTestDatabase(t, uri)
+
+	var wg sync.WaitGroup
+	counts := make([]int, 3)
+	errs := make([]error, 3)
+	for i := range counts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			applied, err := migrations.New(db).Up(context.Background())
+			counts[i], errs[i] = len(applied), err
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range counts {
+		if errs[i] != nil {
+			t.Fatalf("Instance %d failed: %v", i, errs[i])
+		}
+		total += counts[i]
+	}
+	statuses, _ := migrations.New(db).Status(context.Background())
+	if total != len(statuses) {
+		t.Errorf("Expected each of %d migrations applied once, got %d applications", len(statuses), total)
+	}
+}
+
+func hasIndex(t *testing.T, db *mongo.Database, collection, name string) bool {
+	t.Helper()
+	specs, err := db.Collection(collection).Indexes().ListSpecifications(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list indexes: %v", err)
+	}
+	for _, spec := range specs {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vinodhini/software-api/internal/policy"
+)
+
+func TestPolicy_DefaultRoles(t *testing.T) {
+	engine := policy.Default()
+
+	admin := policy.Subject{ID: "ADMIN01", Role: "admin"}
+	employee := policy.Subject{ID: "EMP01", Role: "employee"}
+	client := policy.Subject{ID: "CLIENT01", Role: "client"}
+	project := policy.Resource{ClientID: "CLIENT01", MemberIDs: []string{"EMP01"}}
+	otherProject := policy.Resource{ClientID: "CLIENT02", MemberIDs: []string{"EMP02"}}
+
+	for _, action := range policy.Actions() {
+		if !engine.Permits(admin, action) {
+			t.Errorf("Expected admin to be granted %s", action)
+		}
+	}
+
+	if !engine.Can(employee, policy.ProjectRead, project) || engine.Can(employee, policy.ProjectRead, otherProject) {
+		t.Error("Expected employees to read only projects they are assigned to")
+	}
+	if !engine.Can(client, policy.ProjectRead, project) || engine.Can(client, policy.ProjectRead, otherProject) {
+		t.Error("Expected clients to read only their own projects")
+	}
+	if engine.Can(employee, policy.ProjectUpdate, project) || !engine.Can(employee, policy.ProjectUpdateStatus, project) {
+		t.Error("Expected employees to change only the status of assigned projects")
+	}
+	if engine.Permits(client, policy.ProjectAssign) || engine.Permits(employee, policy.UserList) {
+		t.Error("Expected admin-only actions to be refused")
+	}
+
+	// Deleting a message needs both authorship and access to the project
+	ownMessage := policy.Resource{OwnerID: "EMP01", MemberIDs: []string{"EMP01"}}
+	othersMessage := policy.Resource{OwnerID: "EMP03", MemberIDs: []string{"EMP01"}}
+	if !engine.Can(employee, policy.MessageDelete, ownMessage) || engine.Can(employee, policy.MessageDelete, othersMessage) {
+		t.Error("Expected employees to delete only their own messages")
+	}
+
+	err := engine.Authorize(client, policy.UserRead, policy.UserResource("EMP01"))
+	if !errors.Is(err, policy.ErrAccessDenied) {
+		t.Errorf("Expected access denied reading another profile, got: %v", err)
+	}
+
+	if scope := engine.Scope(employee, policy.ProjectList); scope.All || !scope.Allows(policy.Member) {
+		t.Errorf("Expected employee project listing to be limited to assignments, got: %+v", scope)
+	}
+}
+
+func TestPolicy_CustomRolesFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	doc := `{
+		"roles": [
+			{"name": "project_manager", "inherits": ["employee"], "rules": [
+				{"actions": ["project:create", "project:assign", "project:update"], "when": []},
+				{"actions": ["project:list", "project:read"]}
+			]},
+			{"name": "finance", "rules": [
+				{"actions": ["user:list", "user:read", "user:update_employment", "project:list"]}
+			]}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(doc), 0600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	engine, err := policy.Load(path)
+	if err != nil {
+		t.Fatalf("Expected policy to load, got: %v", err)
+	}
+
+	if !engine.HasRole("admin") || !engine.HasRole("project_manager") || !engine.HasRole("finance") {
+		t.Fatalf("Expected built-in and custom roles, got: %v", engine.Roles())
+	}
+
+	manager := policy.Subject{ID: "PM01", Role: "project_manager"}
+	unassigned := policy.Resource{ClientID: "CLIENT01", MemberIDs: []string{"EMP01"}}
+	if !engine.Can(manager, policy.ProjectAssign, unassigned) || !engine.Can(manager, policy.ProjectRead, unassigned) {
+		t.Error("Expected project managers to manage every project")
+	}
+	if !engine.Can(manager, policy.UserRead, policy.UserResource("PM01")) {
+		t.Error("Expected project managers to inherit the employee rules")
+	}
+	if engine.Permits(manager, policy.UserDelete) {
+		t.Error("Expected project managers not to delete users")
+	}
+
+	finance := policy.Subject{ID: "FIN01", Role: "finance"}
+	if !engine.Scope(finance, policy.ProjectList).All || engine.Permits(finance, policy.ProjectUpdate) {
+		t.Error("Expected finance to see all projects without changing them")
+	}
+}
+
+func TestPolicy_RejectsInvalidDefinitions(t *testing.T) {
+	cases := map[string][]policy.RoleDefinition{
+		"unknown action":    {{Name: "x", Rules: []policy.Rule{{Actions: []policy.Action{"project:destroy"}}}}},
+		"unknown condition": {{Name: "x", Rules: []policy.Rule{{Actions: []policy.Action{policy.ProjectRead}, When: []policy.Condition{"friend"}}}}},
+		"unknown parent":    {{Name: "x", Inherits: []string{"ghost"}}},
+		"inheritance cycle": {{Name: "a", Inherits: []string{"b"}}, {Name: "b", Inherits: []string{"a"}}},
+	}
+
+	for name, roles := range cases {
+		if _, err := policy.New(roles); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
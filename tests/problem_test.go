@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+func bindRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/clients", func(ctx *gin.Context) {
+		var req models.CreateClientRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			utils.BindError(ctx, err)
+			return
+		}
+		utils.SuccessResponse(ctx, http.StatusCreated, "ok", nil)
+	})
+	return router
+}
+
+func postClient(router *gin.Engine, body, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/clients", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBindError_ProblemDetails(t *testing.T) {
+	w := postClient(bindRouter(), `{"email": "not-an-email", "password": "123"}`, utils.ProblemContentType)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, utils.ProblemContentType) {
+		t.Errorf("Expected a problem+json content type, got %q", ct)
+	}
+
+	var problem utils.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Code != "VALIDATION_FAILED" || problem.Instance != "/api/clients" {
+		t.Errorf("Unexpected problem: %+v", problem)
+	}
+
+	rules := make(map[string]string)
+	for _, field := range problem.Errors {
+		rules[field.Field] = field.Rule
+		if field.Message == "" {
+			t.Errorf("Expected a message for %s", field.Field)
+		}
+	}
+	for field, rule := range map[string]string{"email": "email", "password": "min", "name": "required"} {
+		if rules[field] != rule {
+			t.Errorf("Expected %s to fail %s, got errors: %+v", field, rule, problem.Errors)
+		}
+	}
+}
+
+func TestBindError_LegacyEnvelope(t *testing.T) {
+	router := bindRouter()
+
+	// Without asking for problem+json clients keep the Response envelope
+	w := postClient(router, `{"email": "a@b.co"}`, "")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Expected a JSON content type, got %q", ct)
+	}
+
+	var body utils.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Success || body.Code != "VALIDATION_FAILED" || body.Error == "" || len(body.Details) == 0 {
+		t.Errorf("Unexpected response: %+v", body)
+	}
+
+	w = postClient(router, `{"email": `, "application/json")
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || body.Code != "INVALID_BODY" {
+		t.Errorf("Expected INVALID_BODY for malformed JSON, got %d %+v", w.Code, body)
+	}
+}
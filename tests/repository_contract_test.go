@@ -0,0 +1,592 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/migrations"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/repositories/memory"
+	"github.com/vinodhini/software-api/internal/repositories/postgres"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The contract every storage backend keeps. The database backends run when
+// MONGO_TEST_URI (a replica set, for transactions) or POSTGRES_TEST_DSN is
+// set; each test gets a database or schema of its own, dropped afterwards.
+
+func TestRepositoryContract_Memory(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T) *repositories.Repositories {
+		return memory.NewRepositories()
+	})
+}
+
+func TestRepositoryContract_Mongo(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+
+	testRepositoryContract(t, func(t *testing.T) *repositories.Repositories {
+		db := openMongoTestDatabase(t, uri)
+		if _, err := migrations.New(db).Up(context.Background()); err != nil {
+			t.Fatalf("Failed to migrate: %v", err)
+		}
+		repos, err := repositories.NewMongoRepositories(db, false)
+		if err != nil {
+			t.Fatalf("Failed to open repositories: %v", err)
+		}
+		return repos
+	})
+}
+
+// openMongoTestDatabase returns an empty database at uri, dropped when the
+// test ends.
+func openMongoTestDatabase(t *testing.T, uri string) *mongo.Database {
+	t.Helper()
+	cfg := &config.Config{MongoDB: config.MongoDBConfig{
+		URI:      uri,
+		Database: fmt.Sprintf("test_%d", time.Now().UnixNano()),
+		Timeout:  10 * time.Second,
+	}}
+	db, err := config.InitDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		db.Client().Disconnect(context.Background())
+	})
+	return db
+}
+
+func TestRepositoryContract_Postgres(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	testRepositoryContract(t, func(t *testing.T) *repositories.Repositories {
+		admin, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("Failed to open PostgreSQL: %v", err)
+		}
+		t.Cleanup(func() { admin.Close() })
+
+		schema := fmt.Sprintf("contract_%d", time.Now().UnixNano())
+		if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+		db, err := sql.Open("postgres", withSearchPath(dsn, schema))
+		if err != nil {
+			t.Fatalf("Failed to open PostgreSQL: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := postgres.Migrate(db); err != nil {
+			t.Fatalf("Failed to migrate: %v", err)
+		}
+		return postgres.NewRepositories(db)
+	})
+}
+
+// withSearchPath points every connection of dsn, in URL or key=value form,
+// at schema.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && strings.HasPrefix(u.Scheme, "postgres") {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
+
+func testRepositoryContract(t *testing.T, open func(t *testing.T) *repositories.Repositories) {
+	t.Run("Users", func(t *testing.T) { testUserContract(t, open(t)) })
+	t.Run("UsersByStatus", func(t *testing.T) { testUsersByStatusContract(t, open(t)) })
+	t.Run("Projects", func(t *testing.T) { testProjectContract(t, open(t)) })
+	t.Run("ServiceRequests", func(t *testing.T) { testServiceRequestContract(t, open(t)) })
+	t.Run("Messages", func(t *testing.T) { testMessageContract(t, open(t)) })
+	t.Run("Counters", func(t *testing.T) { testCounterContract(t, open(t)) })
+	t.Run("ServiceTypes", func(t *testing.T) { testServiceTypeContract(t, open(t)) })
+	t.Run("UnitOfWork", func(t *testing.T) { testUnitOfWorkContract(t, open(t)) })
+	t.Run("Purge", func(t *testing.T) { testPurgeContract(t, open(t)) })
+	t.Run("Import", func(t *testing.T) { testImportContract(t, open(t)) })
+}
+
+func createUser(t *testing.T, repos *repositories.Repositories, id string, role models.Role) *models.User {
+	t.Helper()
+	user := &models.User{UserID: id, Email: strings.ToLower(id) + "@example.com", Name: "User " + id, Role: role, Status: models.UserStatusActive}
+	if err := repos.Users.Create(user); err != nil {
+		t.Fatalf("Failed to create user %s: %v", id, err)
+	}
+	return user
+}
+
+func testUserContract(t *testing.T, repos *repositories.Repositories) {
+	user := createUser(t, repos, "USER01", models.RoleClient)
+	user.Company = "Acme"
+	createUser(t, repos, "USER02", models.RoleEmployee)
+
+	found, err := repos.Users.FindByEmail("user01@example.com")
+	if err != nil || found.UserID != "USER01" || found.Version != 1 {
+		t.Fatalf("Expected USER01 at version 1, got %+v (%v)", found, err)
+	}
+	if _, err := repos.Users.FindByID("USER99"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected ErrNoDocuments for a missing user, got %v", err)
+	}
+
+	if err := repos.Users.Update(user); err != nil || user.Version != 2 {
+		t.Fatalf("Expected update to version 2, got %d (%v)", user.Version, err)
+	}
+	stale := *found
+	stale.Name = "Lost update"
+	if err := repos.Users.Update(&stale); !errors.Is(err, repositories.ErrStaleVersion) {
+		t.Errorf("Expected ErrStaleVersion, got %v", err)
+	}
+
+	users, total, err := repos.Users.List(1, 10, "ACME", "", false)
+	if err != nil || total != 1 || len(users) != 1 || users[0].UserID != "USER01" {
+		t.Errorf("Expected search to find USER01 by company, got %d %+v (%v)", total, users, err)
+	}
+
+	if err := repos.Users.Delete("USER01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if err := repos.Users.Delete("USER01", "ADMIN01"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected ErrNoDocuments deleting twice, got %v", err)
+	}
+	if _, err := repos.Users.FindByID("USER01"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected deleted user to be hidden, got %v", err)
+	}
+	if exists, err := repos.Users.EmailExists("user01@example.com"); err != nil || !exists {
+		t.Errorf("Expected deleted user to keep its email, got %v (%v)", exists, err)
+	}
+	if _, total, _ := repos.Users.List(1, 10, "", "", false); total != 1 {
+		t.Errorf("Expected 1 live user, got %d", total)
+	}
+	if _, total, _ := repos.Users.List(1, 10, "", "", true); total != 2 {
+		t.Errorf("Expected 2 users with deleted ones, got %d", total)
+	}
+
+	tombstone, err := repos.Users.Restore("USER01")
+	if err != nil || tombstone.DeletedAt == nil || tombstone.DeletedBy != "ADMIN01" {
+		t.Fatalf("Expected the deleted record back, got %+v (%v)", tombstone, err)
+	}
+	restored, err := repos.Users.FindByID("USER01")
+	if err != nil || restored.Company != "Acme" || restored.Version != 4 {
+		t.Errorf("Expected restored user at version 4, got %+v (%v)", restored, err)
+	}
+	if _, err := repos.Users.Restore("USER01"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected ErrNoDocuments restoring a live user, got %v", err)
+	}
+}
+
+func testUsersByStatusContract(t *testing.T, repos *repositories.Repositories) {
+	for _, id := range []string{"USER01", "USER02", "USER03", "USER04"} {
+		user := createUser(t, repos, id, models.RoleClient)
+		if id != "USER02" {
+			user.Status = models.UserStatusPendingApproval
+			if err := repos.Users.Update(user); err != nil {
+				t.Fatalf("Failed to update %s: %v", id, err)
+			}
+		}
+		// Keep creation times apart so the order is certain
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := repos.Users.Delete("USER03", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+
+	users, total, err := repos.Users.ListByStatus(models.UserStatusPendingApproval, 1, 10)
+	if err != nil || total != 2 || len(users) != 2 || users[0].UserID != "USER01" || users[1].UserID != "USER04" {
+		t.Fatalf("Expected the live pending users oldest first, got %d %+v (%v)", total, users, err)
+	}
+	users, total, err = repos.Users.ListByStatus(models.UserStatusPendingApproval, 2, 1)
+	if err != nil || total != 2 || len(users) != 1 || users[0].UserID != "USER04" {
+		t.Errorf("Expected USER04 on the second page, got %d %+v (%v)", total, users, err)
+	}
+	if _, total, _ := repos.Users.ListByStatus(models.UserStatusInactive, 1, 10); total != 0 {
+		t.Errorf("Expected no inactive users, got %d", total)
+	}
+}
+
+func testProjectContract(t *testing.T, repos *repositories.Repositories) {
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	createUser(t, repos, "EMP01", models.RoleEmployee)
+	createUser(t, repos, "EMP02", models.RoleEmployee)
+
+	project := &models.Project{ID: "PROJECT01", Name: "Website", ClientID: "CLIENT01", Status: models.StatusActive, EmployeeIDs: []string{"EMP02", "EMP01"}}
+	if err := repos.Projects.Create(project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	orphan := &models.Project{ID: "PROJECT02", Name: "Orphan", ClientID: "GONE01", Status: models.StatusPending}
+	if err := repos.Projects.Create(orphan); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	found, err := repos.Projects.FindByID("PROJECT01")
+	if err != nil {
+		t.Fatalf("Failed to find project: %v", err)
+	}
+	if found.Client == nil || found.Client.UserID != "CLIENT01" {
+		t.Errorf("Expected the client to be loaded, got %+v", found.Client)
+	}
+	if len(found.Employees) != 2 || found.EmployeeIDs[0] != "EMP02" || found.EmployeeIDs[1] != "EMP01" {
+		t.Errorf("Expected employees in assignment order, got %v / %+v", found.EmployeeIDs, found.Employees)
+	}
+	if found, _ := repos.Projects.FindByID("PROJECT02"); found == nil || found.Client.Name != "Unknown Client" {
+		t.Errorf("Expected a placeholder for a missing client, got %+v", found)
+	}
+
+	found.Progress = 50
+	if err := repos.Projects.Update(found); err != nil || found.Version != 2 {
+		t.Fatalf("Expected update to version 2, got %d (%v)", found.Version, err)
+	}
+	if err := repos.Projects.Update(project); !errors.Is(err, repositories.ErrStaleVersion) {
+		t.Errorf("Expected ErrStaleVersion, got %v", err)
+	}
+
+	if err := repos.Projects.AssignEmployees("PROJECT01", []string{"EMP01"}); err != nil {
+		t.Fatalf("Failed to assign employees: %v", err)
+	}
+	if projects, _ := repos.Projects.FindByEmployee("EMP02"); len(projects) != 0 {
+		t.Errorf("Expected EMP02 to be unassigned, got %d project(s)", len(projects))
+	}
+	projects, total, err := repos.Projects.ListByEmployee(1, 10, "", "", "EMP01", false)
+	if err != nil || total != 1 || projects[0].ID != "PROJECT01" || projects[0].Progress != 50 || projects[0].Version != 3 {
+		t.Errorf("Expected PROJECT01 at version 3 for EMP01, got %d %+v (%v)", total, projects, err)
+	}
+
+	clientID := "CLIENT01"
+	if projects, total, _ := repos.Projects.List(1, 10, "web", "", &clientID, false); total != 1 || projects[0].Client.UserID != "CLIENT01" {
+		t.Errorf("Expected the client's project with its client, got %d %+v", total, projects)
+	}
+	if _, total, err := repos.Projects.List(1, 10, "web.*(", "", nil, false); err != nil || total != 0 {
+		t.Errorf("Expected the search term to be taken literally, got %d (%v)", total, err)
+	}
+	if projects, total, _ := repos.Projects.List(1, 1, "", "", nil, false); total != 2 || len(projects) != 1 || projects[0].ID != "PROJECT02" {
+		t.Errorf("Expected newest project on a page of one, got %d %+v", total, projects)
+	}
+
+	if err := repos.Projects.Delete("PROJECT01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	if projects, _ := repos.Projects.FindByClient("CLIENT01"); len(projects) != 0 {
+		t.Errorf("Expected deleted project to be hidden, got %d", len(projects))
+	}
+	if tombstone, err := repos.Projects.FindDeleted("PROJECT01"); err != nil || tombstone.ClientID != "CLIENT01" || tombstone.DeletedBy != "ADMIN01" {
+		t.Errorf("Expected the deleted project, got %+v (%v)", tombstone, err)
+	}
+	if _, err := repos.Projects.FindDeleted("PROJECT02"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected a live project not to be found as deleted, got %v", err)
+	}
+	if purged, err := repos.Projects.PurgeDeleted(time.Now().Add(time.Minute)); err != nil || purged != 1 {
+		t.Errorf("Expected 1 project purged, got %d (%v)", purged, err)
+	}
+	if _, err := repos.Projects.Restore("PROJECT01"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected purged project to be gone, got %v", err)
+	}
+}
+
+func testServiceRequestContract(t *testing.T, repos *repositories.Repositories) {
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	if err := repos.Projects.Create(&models.Project{ID: "PROJECT01", Name: "Website", ClientID: "CLIENT01"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	request := &models.ServiceRequest{ID: "SERVICE01", Title: "Redesign", ClientID: "CLIENT01", Status: models.StatusPending}
+	if err := repos.ServiceRequests.Create(request); err != nil {
+		t.Fatalf("Failed to create service request: %v", err)
+	}
+
+	projectID := "PROJECT01"
+	request.ProjectID = &projectID
+	if err := repos.ServiceRequests.Update(request); err != nil {
+		t.Fatalf("Failed to update service request: %v", err)
+	}
+	// Leaving the project unset keeps it
+	request.ProjectID = nil
+	request.Status = models.StatusActive
+	if err := repos.ServiceRequests.Update(request); err != nil || request.Version != 3 {
+		t.Fatalf("Expected update to version 3, got %d (%v)", request.Version, err)
+	}
+
+	found, err := repos.ServiceRequests.FindByID("SERVICE01")
+	if err != nil {
+		t.Fatalf("Failed to find service request: %v", err)
+	}
+	if found.ProjectID == nil || *found.ProjectID != "PROJECT01" || found.Project == nil || found.Project.Name != "Website" {
+		t.Errorf("Expected the project to be kept and loaded, got %v / %+v", found.ProjectID, found.Project)
+	}
+	if found.Client == nil || found.Client.UserID != "CLIENT01" || found.Status != models.StatusActive {
+		t.Errorf("Unexpected service request: %+v", found)
+	}
+
+	if requests, total, _ := repos.ServiceRequests.List(1, 10, "", string(models.StatusPending), nil, false); total != 0 || len(requests) != 0 {
+		t.Errorf("Expected no pending requests, got %d", total)
+	}
+	clientID := "CLIENT01"
+	if requests, total, _ := repos.ServiceRequests.List(1, 10, "redesign", "", &clientID, false); total != 1 || requests[0].Client.UserID != "CLIENT01" {
+		t.Errorf("Expected the request with its client, got %d %+v", total, requests)
+	}
+
+	if err := repos.ServiceRequests.Delete("SERVICE01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete service request: %v", err)
+	}
+	if requests, _ := repos.ServiceRequests.FindByClient("CLIENT01"); len(requests) != 0 {
+		t.Errorf("Expected deleted request to be hidden, got %d", len(requests))
+	}
+	if tombstone, err := repos.ServiceRequests.FindDeleted("SERVICE01"); err != nil || tombstone.ClientID != "CLIENT01" {
+		t.Errorf("Expected the deleted request, got %+v (%v)", tombstone, err)
+	}
+	if tombstone, err := repos.ServiceRequests.Restore("SERVICE01"); err != nil || tombstone.DeletedAt == nil {
+		t.Errorf("Expected the deleted record back, got %+v (%v)", tombstone, err)
+	}
+}
+
+func testMessageContract(t *testing.T, repos *repositories.Repositories) {
+	createUser(t, repos, "USER01", models.RoleClient)
+	for _, id := range []string{"MESSAGE01", "MESSAGE02"} {
+		if err := repos.Messages.Create(&models.Message{ID: id, Content: id, SenderID: "USER01", ProjectID: "PROJECT01"}); err != nil {
+			t.Fatalf("Failed to create message: %v", err)
+		}
+		// Keep creation times apart at MongoDB's millisecond precision
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	messages, total, err := repos.Messages.ListByProject("PROJECT01", 1, 10, false)
+	if err != nil || total != 2 || messages[0].ID != "MESSAGE02" || messages[0].Sender == nil || messages[0].Sender.UserID != "USER01" {
+		t.Fatalf("Expected newest message first with its sender, got %d %+v (%v)", total, messages, err)
+	}
+
+	if err := repos.Messages.Delete("MESSAGE01", "USER01"); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	if messages, _ := repos.Messages.FindByProject("PROJECT01"); len(messages) != 1 || messages[0].ID != "MESSAGE02" {
+		t.Errorf("Expected only the live message, got %+v", messages)
+	}
+	if _, total, _ := repos.Messages.ListByProject("PROJECT01", 1, 10, true); total != 2 {
+		t.Errorf("Expected 2 messages with deleted ones, got %d", total)
+	}
+	if message, err := repos.Messages.FindDeleted("MESSAGE01"); err != nil || message.ProjectID != "PROJECT01" {
+		t.Errorf("Expected the deleted message, got %+v (%v)", message, err)
+	}
+	if _, err := repos.Messages.Restore("MESSAGE01"); err != nil {
+		t.Errorf("Failed to restore message: %v", err)
+	}
+	if found, err := repos.Messages.FindByID("MESSAGE01"); err != nil || found.DeletedAt != nil {
+		t.Errorf("Expected restored message, got %+v (%v)", found, err)
+	}
+}
+
+func testCounterContract(t *testing.T, repos *repositories.Repositories) {
+	for want := 1; want <= 3; want++ {
+		if got, err := repos.Counters.GetNextSequence(repositories.ProjectCounter); err != nil || got != want {
+			t.Fatalf("Expected %d, got %d (%v)", want, got, err)
+		}
+	}
+	if got, _ := repos.Counters.GetNextSequence(repositories.UserCounter); got != 1 {
+		t.Errorf("Expected counters to be independent, got %d", got)
+	}
+
+	if err := repos.Counters.Raise(repositories.ProjectCounter, 10); err != nil {
+		t.Fatalf("Failed to raise counter: %v", err)
+	}
+	if err := repos.Counters.Raise(repositories.ProjectCounter, 5); err != nil {
+		t.Fatalf("Failed to raise counter: %v", err)
+	}
+	if got, _ := repos.Counters.GetNextSequence(repositories.ProjectCounter); got != 11 {
+		t.Errorf("Expected raising to never lower a counter, got %d", got)
+	}
+	if err := repos.Counters.Raise(repositories.MessageCounter, 4); err != nil {
+		t.Fatalf("Failed to raise counter: %v", err)
+	}
+	if got, _ := repos.Counters.GetNextSequence(repositories.MessageCounter); got != 5 {
+		t.Errorf("Expected a new counter to start from the raised value, got %d", got)
+	}
+}
+
+func testServiceTypeContract(t *testing.T, repos *repositories.Repositories) {
+	web := &models.ServiceType{Name: "Web", Status: models.StatusActive}
+	mobile := &models.ServiceType{Name: "Mobile", Status: models.StatusPending}
+	for _, serviceType := range []*models.ServiceType{web, mobile} {
+		if err := repos.ServiceTypes.Create(serviceType); err != nil || serviceType.ID == "" {
+			t.Fatalf("Expected service type with an ID, got %q (%v)", serviceType.ID, err)
+		}
+	}
+
+	active := string(models.StatusActive)
+	if serviceTypes, err := repos.ServiceTypes.GetAll(&active); err != nil || len(serviceTypes) != 1 || serviceTypes[0].ID != web.ID {
+		t.Errorf("Expected only the active service type, got %+v (%v)", serviceTypes, err)
+	}
+	if serviceTypes, _ := repos.ServiceTypes.GetAll(nil); len(serviceTypes) != 2 {
+		t.Errorf("Expected every service type, got %d", len(serviceTypes))
+	}
+
+	mobile.Status = models.StatusActive
+	if err := repos.ServiceTypes.Update(mobile.ID, mobile); err != nil {
+		t.Fatalf("Failed to update service type: %v", err)
+	}
+	if found, err := repos.ServiceTypes.GetByID(mobile.ID); err != nil || found.Status != models.StatusActive {
+		t.Errorf("Expected updated service type, got %+v (%v)", found, err)
+	}
+
+	if err := repos.ServiceTypes.Delete(web.ID); err != nil {
+		t.Fatalf("Failed to delete service type: %v", err)
+	}
+	if _, err := repos.ServiceTypes.GetByID(web.ID); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected ErrNoDocuments after delete, got %v", err)
+	}
+	if err := repos.ServiceTypes.Delete(web.ID); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected ErrNoDocuments deleting twice, got %v", err)
+	}
+}
+
+func testUnitOfWorkContract(t *testing.T, repos *repositories.Repositories) {
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	request := &models.ServiceRequest{ID: "SERVICE01", Title: "Redesign", ClientID: "CLIENT01", Status: models.StatusPending}
+	if err := repos.ServiceRequests.Create(request); err != nil {
+		t.Fatalf("Failed to create service request: %v", err)
+	}
+
+	// approve creates a project for the request and links the two
+	approve := func(fail error) error {
+		return repos.UnitOfWork.Do(func(tx repositories.Tx) error {
+			seq, err := tx.Counters().GetNextSequence(repositories.ProjectCounter)
+			if err != nil {
+				return err
+			}
+			projectID := fmt.Sprintf("PROJECT%02d", seq)
+			if err := tx.Projects().Create(&models.Project{ID: projectID, Name: "Redesign", ClientID: "CLIENT01"}); err != nil {
+				return err
+			}
+			stored, err := tx.ServiceRequests().FindByID("SERVICE01")
+			if err != nil {
+				return err
+			}
+			stored.ProjectID = &projectID
+			stored.Status = models.StatusActive
+			if err := tx.ServiceRequests().Update(stored); err != nil {
+				return err
+			}
+			return fail
+		})
+	}
+
+	failure := errors.New("fail")
+	if err := approve(failure); !errors.Is(err, failure) {
+		t.Fatalf("Expected the failure back, got %v", err)
+	}
+	if _, err := repos.Projects.FindByID("PROJECT01"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the project to be rolled back, got %v", err)
+	}
+	if found, _ := repos.ServiceRequests.FindByID("SERVICE01"); found.ProjectID != nil || found.Version != 1 {
+		t.Errorf("Expected the request to be rolled back, got %+v", found)
+	}
+
+	if err := approve(nil); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if found, _ := repos.ServiceRequests.FindByID("SERVICE01"); found.ProjectID == nil || *found.ProjectID != "PROJECT01" {
+		t.Errorf("Expected the request to link PROJECT01, got %+v", found)
+	}
+
+	// A write that fails inside the unit of work undoes the ones before it
+	err := repos.UnitOfWork.Do(func(tx repositories.Tx) error {
+		if _, err := tx.Counters().GetNextSequence(repositories.ProjectCounter); err != nil {
+			return err
+		}
+		if err := tx.Projects().Create(&models.Project{ID: "PROJECT02", Name: "Second", ClientID: "CLIENT01"}); err != nil {
+			return err
+		}
+		return tx.Projects().Create(&models.Project{ID: "PROJECT01", Name: "Duplicate", ClientID: "CLIENT01"})
+	})
+	if err == nil {
+		t.Fatal("Expected creating a duplicate project to fail")
+	}
+	if _, err := repos.Projects.FindByID("PROJECT02"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the project before the failure to be rolled back, got %v", err)
+	}
+	if next, _ := repos.Counters.GetNextSequence(repositories.ProjectCounter); next != 2 {
+		t.Errorf("Expected the counter to be rolled back to 1, got %d", next)
+	}
+}
+
+func testPurgeContract(t *testing.T, repos *repositories.Repositories) {
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	createUser(t, repos, "CLIENT02", models.RoleClient)
+	if err := repos.Projects.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	for _, id := range []string{"CLIENT01", "CLIENT02"} {
+		if err := repos.Users.Delete(id, "ADMIN01"); err != nil {
+			t.Fatalf("Failed to delete %s: %v", id, err)
+		}
+	}
+
+	cutoff := time.Now().Add(time.Minute)
+	if removed, err := repos.Users.PurgeDeleted(cutoff); err != nil || removed != 1 {
+		t.Fatalf("Expected only the client without records to be purged, got %d (%v)", removed, err)
+	}
+	if _, err := repos.Users.Restore("CLIENT01"); err != nil {
+		t.Errorf("Expected the client that owns a project to be kept, got %v", err)
+	}
+	if _, err := repos.Users.Restore("CLIENT02"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the other client to be purged, got %v", err)
+	}
+
+	// A deleted project still holds on to its client until it is purged too
+	if err := repos.Users.Delete("CLIENT01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete CLIENT01 again: %v", err)
+	}
+	if err := repos.Projects.Delete("PROJECT01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	if removed, _ := repos.Users.PurgeDeleted(cutoff); removed != 0 {
+		t.Errorf("Expected the client of a deleted project to be kept, got %d purged", removed)
+	}
+	if removed, err := repos.Projects.PurgeDeleted(cutoff); err != nil || removed != 1 {
+		t.Fatalf("Expected the project to be purged, got %d (%v)", removed, err)
+	}
+	if removed, err := repos.Users.PurgeDeleted(cutoff); err != nil || removed != 1 {
+		t.Errorf("Expected the client to be purged after its project, got %d (%v)", removed, err)
+	}
+}
+
+func testImportContract(t *testing.T, repos *repositories.Repositories) {
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	created := time.Date(2023, 5, 1, 9, 30, 0, 0, time.UTC)
+	deleted := created.Add(48 * time.Hour)
+	project := &models.Project{
+		ID: "PROJECT07", Name: "Archive", ClientID: "CLIENT01", EmployeeIDs: []string{},
+		Version: 3, CreatedAt: created, UpdatedAt: deleted, DeletedAt: &deleted, DeletedBy: "ADMIN01",
+	}
+	if err := repos.Projects.Import(project); err != nil {
+		t.Fatalf("Failed to import project: %v", err)
+	}
+
+	if _, err := repos.Projects.FindByID("PROJECT07"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the imported project to stay deleted, got %v", err)
+	}
+	tombstone, err := repos.Projects.Restore("PROJECT07")
+	if err != nil || tombstone.DeletedBy != "ADMIN01" || tombstone.DeletedAt == nil || !tombstone.DeletedAt.Equal(deleted) {
+		t.Fatalf("Expected the deletion stamp to be kept, got %+v (%v)", tombstone, err)
+	}
+	restored, err := repos.Projects.FindByID("PROJECT07")
+	if err != nil || !restored.CreatedAt.Equal(created) || restored.Version != 4 {
+		t.Errorf("Expected the creation time and version to be kept, got %+v (%v)", restored, err)
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/repositories"
+	"github.com/vinodhini/software-api/internal/repositories/memory"
+	"github.com/vinodhini/software-api/internal/services"
+	"github.com/vinodhini/software-api/pkg/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newRetentionService(repos *repositories.Repositories, retention config.RetentionConfig) services.RetentionService {
+	return services.NewRetentionService(repos.Users, repos.Projects, repos.ServiceRequests, repos.Messages, &config.Config{Retention: retention})
+}
+
+func TestRetention_PurgesAClientWithItsProjectInOneRun(t *testing.T) {
+	repos := memory.NewRepositories()
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	createUser(t, repos, "USER02", models.RoleEmployee)
+	if err := repos.Projects.Create(&models.Project{ID: "PROJECT01", Name: "Portal", ClientID: "CLIENT01"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := repos.Messages.Create(&models.Message{ID: "MESSAGE01", Content: "Hello", SenderID: "CLIENT01", ProjectID: "PROJECT01"}); err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+	if err := repos.Messages.Delete("MESSAGE01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete message: %v", err)
+	}
+	if err := repos.Projects.Delete("PROJECT01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	if err := repos.Users.Delete("CLIENT01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete client: %v", err)
+	}
+
+	retention := newRetentionService(repos, config.RetentionConfig{DeletedRecords: time.Millisecond})
+	time.Sleep(5 * time.Millisecond)
+
+	// Users are purged last, so the project no longer holds on to its client
+	removed, err := retention.PurgeDeleted()
+	if err != nil || removed != 3 {
+		t.Fatalf("Expected the message, project and client to be purged, got %d (%v)", removed, err)
+	}
+	if _, err := repos.Users.Restore("CLIENT01"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Expected the client to be gone, got %v", err)
+	}
+	if _, err := repos.Users.FindByID("USER02"); err != nil {
+		t.Errorf("Expected the live employee to be kept, got %v", err)
+	}
+}
+
+func TestRetention_KeepsRecordsWithinTheRetentionPeriod(t *testing.T) {
+	repos := memory.NewRepositories()
+	createUser(t, repos, "CLIENT01", models.RoleClient)
+	if err := repos.Users.Delete("CLIENT01", "ADMIN01"); err != nil {
+		t.Fatalf("Failed to delete client: %v", err)
+	}
+
+	removed, err := newRetentionService(repos, config.RetentionConfig{DeletedRecords: time.Hour}).PurgeDeleted()
+	if err != nil || removed != 0 {
+		t.Errorf("Expected nothing to be purged, got %d (%v)", removed, err)
+	}
+	if _, err := repos.Users.Restore("CLIENT01"); err != nil {
+		t.Errorf("Expected the client to be restorable, got %v", err)
+	}
+}
+
+func TestRetention_RunReturnsWhenDisabled(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		newRetentionService(memory.NewRepositories(), config.RetentionConfig{PurgeInterval: time.Hour}).Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return at once without DELETED_RETENTION")
+	}
+}
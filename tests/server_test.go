@@ -0,0 +1,356 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vinodhini/software-api/config"
+	"github.com/vinodhini/software-api/internal/app"
+	"github.com/vinodhini/software-api/internal/mailer"
+	"github.com/vinodhini/software-api/internal/policy"
+	"github.com/vinodhini/software-api/internal/repositories/memory"
+	"github.com/vinodhini/software-api/pkg/client"
+	"github.com/vinodhini/software-api/pkg/models"
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+const (
+	adminEmail    = "admin@example.com"
+	adminPassword = "admin-password"
+)
+
+// newTestServer runs the whole API over the in-memory backend, with one
+// admin account, and returns a client logged in as that admin.
+func newTestServer(t *testing.T) (*httptest.Server, *client.Client) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("STORAGE_BACKEND", config.StorageMemory)
+
+	cfg := config.Load()
+	repos := memory.NewRepositories()
+
+	hashed, err := utils.HashPassword(adminPassword)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	admin := &models.User{UserID: "ADMIN01", Email: adminEmail, Password: hashed, Name: "Admin", Role: models.RoleAdmin, Status: models.UserStatusActive}
+	if err := repos.Users.Create(admin); err != nil {
+		t.Fatalf("Failed to seed admin: %v", err)
+	}
+
+	api := app.New(cfg, repos, mailer.NewMemoryMailer(), utils.NewHMACKeySet("test-secret"), policy.Default())
+	server := httptest.NewServer(api.Router)
+	t.Cleanup(server.Close)
+
+	return server, login(t, server, adminEmail, adminPassword)
+}
+
+func login(t *testing.T, server *httptest.Server, email, password string) *client.Client {
+	t.Helper()
+	c := client.New(server.URL, client.WithHTTPClient(server.Client()))
+	if _, err := c.Login(context.Background(), &models.LoginRequest{Email: email, Password: password}); err != nil {
+		t.Fatalf("Failed to log in as %s: %v", email, err)
+	}
+	return c
+}
+
+func expectAPIError(t *testing.T, err error, status int, code string) {
+	t.Helper()
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected a *client.Error, got %v", err)
+	}
+	if apiErr.StatusCode != status || apiErr.Code != code {
+		t.Errorf("Expected %d %s, got %d %s", status, code, apiErr.StatusCode, apiErr.Code)
+	}
+}
+
+func TestServer_ServiceRequestToProject(t *testing.T) {
+	server, admin := newTestServer(t)
+	ctx := context.Background()
+
+	customer, err := admin.CreateClient(ctx, &models.CreateClientRequest{
+		Name: "Acme", Email: "client@example.com", Phone: "555-0100", Company: "Acme", Address: "1 Main St", Password: "client-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	employee, err := admin.CreateEmployee(ctx, &models.CreateEmployeeRequest{
+		Name: "Dev", Email: "dev@example.com", Phone: "555-0101", Department: "Engineering", Salary: 1000, Password: "employee-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+
+	// The client asks for work and the admin turns it into a project
+	clientAPI := login(t, server, "client@example.com", "client-password")
+	serviceRequest, err := clientAPI.CreateServiceRequest(ctx, &models.CreateServiceRequestRequest{Title: "New website"})
+	if err != nil {
+		t.Fatalf("Failed to create service request: %v", err)
+	}
+	if serviceRequest.ClientID != customer.ID || serviceRequest.Status != models.StatusPending {
+		t.Errorf("Unexpected service request: %+v", serviceRequest)
+	}
+
+	project, err := admin.ApproveServiceRequest(ctx, serviceRequest.ID, &models.ApproveServiceRequestRequest{EmployeeIDs: []string{employee.ID}})
+	if err != nil {
+		t.Fatalf("Failed to approve service request: %v", err)
+	}
+	if project.ClientID != customer.ID {
+		t.Errorf("Expected project for client %s, got %s", customer.ID, project.ClientID)
+	}
+
+	approved, err := admin.GetServiceRequest(ctx, serviceRequest.ID)
+	if err != nil || approved.Status != models.StatusActive {
+		t.Errorf("Expected approved service request, got %+v (%v)", approved, err)
+	}
+
+	// Both sides of the project can talk on it
+	if _, err := clientAPI.CreateMessage(ctx, &models.CreateMessageRequest{ProjectID: project.ID, Content: "Hello"}); err != nil {
+		t.Fatalf("Failed to post message: %v", err)
+	}
+	messages, err := admin.ListProjectMessages(ctx, project.ID, 1, 10)
+	if err != nil || len(messages.Items) != 1 || messages.Items[0].Content != "Hello" {
+		t.Errorf("Expected the message on the project, got %+v (%v)", messages, err)
+	}
+
+	// A client with a project cannot be deleted under the default rule
+	expectAPIError(t, admin.DeleteClient(ctx, customer.ID), http.StatusConflict, "CLIENT_IN_USE")
+
+	events, err := admin.ListAuditEvents(ctx, models.AuditQuery{ResourceID: serviceRequest.ID, Page: 1, PageSize: 10})
+	if err != nil || len(events.Items) == 0 {
+		t.Errorf("Expected audit events for the service request, got %+v (%v)", events, err)
+	}
+}
+
+func TestServer_RejectsStaleUpdates(t *testing.T) {
+	_, admin := newTestServer(t)
+	ctx := context.Background()
+
+	customer, err := admin.CreateClient(ctx, &models.CreateClientRequest{
+		Name: "Acme", Email: "client@example.com", Phone: "555-0100", Company: "Acme", Address: "1 Main St", Password: "client-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := admin.CreateProject(ctx, &models.CreateProjectRequest{Name: "Website", ClientID: customer.ID})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	updated, err := admin.UpdateProject(ctx, project.ID, project.Version, &models.UpdateProjectRequest{Name: "Website v2"})
+	if err != nil {
+		t.Fatalf("Failed to update project: %v", err)
+	}
+	if updated.Name != "Website v2" || updated.Version != project.Version+1 {
+		t.Errorf("Expected renamed project at version %d, got %+v", project.Version+1, updated)
+	}
+
+	_, err = admin.UpdateProject(ctx, project.ID, project.Version, &models.UpdateProjectRequest{Name: "Lost update"})
+	expectAPIError(t, err, http.StatusPreconditionFailed, "VERSION_MISMATCH")
+
+	current, err := admin.GetProject(ctx, project.ID)
+	if err != nil || current.Name != "Website v2" {
+		t.Errorf("Expected the first update to stick, got %+v (%v)", current, err)
+	}
+}
+
+func TestServer_IfMatchAcceptsWildcardsAndTagLists(t *testing.T) {
+	server, admin := newTestServer(t)
+	ctx := context.Background()
+
+	customer, err := admin.CreateClient(ctx, &models.CreateClientRequest{
+		Name: "Acme", Email: "client@example.com", Phone: "555-0100", Company: "Acme", Address: "1 Main St", Password: "client-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := admin.CreateProject(ctx, &models.CreateProjectRequest{Name: "Website", ClientID: customer.ID})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	update := func(ifMatch string) int {
+		req, _ := http.NewRequest(http.MethodPut, server.URL+"/api/projects/"+project.ID, strings.NewReader(`{"description": "Updated"}`))
+		req.Header.Set("Authorization", "Bearer "+admin.Tokens().AccessToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to update project: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	current := project.Version
+	if status := update(fmt.Sprintf(`"%d", W/"%d"`, current+5, current)); status != http.StatusOK {
+		t.Errorf("Expected a list holding the current tag to match, got %d", status)
+	}
+	if status := update(fmt.Sprintf(`"%d", "%d"`, current, current+5)); status != http.StatusPreconditionFailed {
+		t.Errorf("Expected a list of stale tags to fail, got %d", status)
+	}
+	if status := update("*"); status != http.StatusOK {
+		t.Errorf("Expected * to match the existing project, got %d", status)
+	}
+}
+
+func TestServer_DeletedRecordsCanBeRestored(t *testing.T) {
+	_, admin := newTestServer(t)
+	ctx := context.Background()
+
+	customer, err := admin.CreateClient(ctx, &models.CreateClientRequest{
+		Name: "Acme", Email: "client@example.com", Phone: "555-0100", Company: "Acme", Address: "1 Main St", Password: "client-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := admin.CreateProject(ctx, &models.CreateProjectRequest{Name: "Website", ClientID: customer.ID})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	if err := admin.DeleteProject(ctx, project.ID); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	_, err = admin.GetProject(ctx, project.ID)
+	expectAPIError(t, err, http.StatusNotFound, "PROJECT_NOT_FOUND")
+
+	restored, err := admin.RestoreProject(ctx, project.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("Expected the project back, got %+v (%v)", restored, err)
+	}
+	if _, err := admin.GetProject(ctx, project.ID); err != nil {
+		t.Errorf("Expected restored project to be readable, got %v", err)
+	}
+}
+
+func TestServer_ClientRoutesCannotChangeRolesOrOtherAccounts(t *testing.T) {
+	server, admin := newTestServer(t)
+	ctx := context.Background()
+
+	customer, err := admin.CreateClient(ctx, &models.CreateClientRequest{
+		Name: "Acme", Email: "client@example.com", Phone: "555-0100", Company: "Acme", Address: "1 Main St", Password: "client-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := admin.CreateEmployee(ctx, &models.CreateEmployeeRequest{
+		Name: "Dev", Email: "dev@example.com", Phone: "555-0101", Department: "Engineering", Salary: 1000, Password: "employee-password", Status: "active",
+	}); err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+	employee := login(t, server, "dev@example.com", "employee-password")
+
+	// Employees manage client details, not roles, credentials or status
+	_, err = employee.CreateClient(ctx, &models.CreateClientRequest{
+		Name: "Eve", Email: "eve@example.com", Phone: "555-0102", Company: "Eve", Address: "2 Main St", Password: "eve-password", Status: "active", Role: "admin",
+	})
+	expectAPIError(t, err, http.StatusForbidden, "ACCESS_DENIED")
+	_, err = employee.UpdateClient(ctx, customer.ID, customer.Version, &models.UpdateUserRequest{Role: "admin"})
+	expectAPIError(t, err, http.StatusForbidden, "ACCESS_DENIED")
+	_, err = employee.UpdateClient(ctx, customer.ID, customer.Version, &models.UpdateUserRequest{Password: "new-password"})
+	expectAPIError(t, err, http.StatusForbidden, "ACCESS_DENIED")
+
+	// Other accounts are not clients
+	_, err = employee.UpdateClient(ctx, "ADMIN01", 1, &models.UpdateUserRequest{Name: "Mallory"})
+	expectAPIError(t, err, http.StatusNotFound, "CLIENT_NOT_FOUND")
+
+	_, err = admin.UpdateClient(ctx, customer.ID, customer.Version, &models.UpdateUserRequest{Role: "owner"})
+	expectAPIError(t, err, http.StatusBadRequest, "UNKNOWN_ROLE")
+	updated, err := employee.UpdateClient(ctx, customer.ID, customer.Version, &models.UpdateUserRequest{Phone: "555-0199"})
+	if err != nil || updated.Phone != "555-0199" {
+		t.Errorf("Expected the employee to update the client's details, got %+v (%v)", updated, err)
+	}
+}
+
+func TestServer_RoleChangesApplyToOpenSessions(t *testing.T) {
+	server, admin := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := admin.CreateEmployee(ctx, &models.CreateEmployeeRequest{
+		Name: "Dev", Email: "dev@example.com", Phone: "555-0101", Department: "Engineering", Salary: 1000, Password: "employee-password", Status: "active",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create employee: %v", err)
+	}
+	employee := login(t, server, "dev@example.com", "employee-password")
+	if _, err := employee.ListClients(ctx, models.PaginationQuery{Page: 1, PageSize: 10}); err != nil {
+		t.Fatalf("Expected the employee to list clients, got %v", err)
+	}
+
+	user, err := admin.GetUser(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Failed to load employee: %v", err)
+	}
+	if _, err := admin.UpdateUser(ctx, user.UserID, user.Version, &models.UpdateUserRequest{Role: string(models.RoleClient)}); err != nil {
+		t.Fatalf("Failed to change role: %v", err)
+	}
+
+	// The token still says employee, but the account no longer is one
+	_, err = employee.ListClients(ctx, models.PaginationQuery{Page: 1, PageSize: 10})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 after the demotion, got %v", err)
+	}
+}
+
+func TestServer_KeepsServiceRequestsAndRestoresUnderLiveParents(t *testing.T) {
+	server, admin := newTestServer(t)
+	ctx := context.Background()
+
+	var customers []*models.ClientResponse
+	for _, email := range []string{"client@example.com", "other@example.com"} {
+		customer, err := admin.CreateClient(ctx, &models.CreateClientRequest{
+			Name: "Acme", Email: email, Phone: "555-0100", Company: "Acme", Address: "1 Main St", Password: "client-password", Status: "active",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		customers = append(customers, customer)
+	}
+	otherProject, err := admin.CreateProject(ctx, &models.CreateProjectRequest{Name: "Billing", ClientID: customers[1].ID})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	project, err := admin.CreateProject(ctx, &models.CreateProjectRequest{Name: "Website", ClientID: customers[0].ID})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	serviceRequest, err := login(t, server, "client@example.com", "client-password").CreateServiceRequest(ctx, &models.CreateServiceRequestRequest{Title: "New website"})
+	if err != nil {
+		t.Fatalf("Failed to create service request: %v", err)
+	}
+
+	// A request cannot be moved onto another client's project
+	_, err = admin.UpdateServiceRequest(ctx, serviceRequest.ID, serviceRequest.Version, &models.UpdateServiceRequestRequest{ProjectID: &otherProject.ID})
+	expectAPIError(t, err, http.StatusUnprocessableEntity, "INVALID_PROJECT")
+
+	// Nor is a project restored under a deleted client
+	if err := admin.DeleteProject(ctx, project.ID); err != nil {
+		t.Fatalf("Failed to delete project: %v", err)
+	}
+	if err := admin.DeleteServiceRequest(ctx, serviceRequest.ID); err != nil {
+		t.Fatalf("Failed to delete service request: %v", err)
+	}
+	if err := admin.DeleteClient(ctx, customers[0].ID); err != nil {
+		t.Fatalf("Failed to delete client: %v", err)
+	}
+	_, err = admin.RestoreProject(ctx, project.ID)
+	expectAPIError(t, err, http.StatusConflict, "PARENT_DELETED")
+	_, err = admin.RestoreServiceRequest(ctx, serviceRequest.ID)
+	expectAPIError(t, err, http.StatusConflict, "PARENT_DELETED")
+
+	if _, err := admin.RestoreUser(ctx, customers[0].ID); err != nil {
+		t.Fatalf("Failed to restore client: %v", err)
+	}
+	if _, err := admin.RestoreProject(ctx, project.ID); err != nil {
+		t.Errorf("Expected the project back with its client, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/vinodhini/software-api/pkg/utils"
+)
+
+// RFC 6238 Appendix B test vectors for HMAC-SHA1, truncated to 6 digits
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := utils.TOTPCode(secret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("Failed to generate TOTP code: %v", err)
+		}
+		if code != expected {
+			t.Errorf("At %d expected %s, got %s", unix, expected, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+
+	now := time.Now()
+	code, _ := utils.TOTPCode(secret, now)
+
+	if _, ok := utils.ValidateTOTP(secret, code, now); !ok {
+		t.Error("Current code should be valid")
+	}
+
+	if _, ok := utils.ValidateTOTP(secret, code, now.Add(30*time.Second)); !ok {
+		t.Error("Code from the previous period should be accepted for clock skew")
+	}
+
+	if _, ok := utils.ValidateTOTP(secret, code, now.Add(5*time.Minute)); ok {
+		t.Error("Stale code should be rejected")
+	}
+
+	if _, ok := utils.ValidateTOTP(secret, "12345", now); ok {
+		t.Error("Code with wrong length should be rejected")
+	}
+}